@@ -0,0 +1,66 @@
+// Counts and times the parsing done by a search of a large synthetic README, with and without the
+// per tool call parse cache. Run with `npm run bench:parse` after building.
+import { McpLogger } from '../build/logger.js'
+import { SearchUtils } from '../build/search-utils.js'
+import { searchSections } from '../build/section-search.js'
+import { withParseCache } from '../build/utils/parse-cache.js'
+
+const searchUtils = new SearchUtils(new McpLogger('Bench', true))
+
+// Count the parses behind the extractors
+const parses = { sections: 0, codeBlocks: 0, signatures: 0 }
+for (const [kind, method] of [['codeBlocks', 'parseCodeBlocks'], ['signatures', 'parseFunctionSignatures']]) {
+  const parse = searchUtils[method]
+  searchUtils[method] = function (...args) {
+    parses[kind]++
+    return parse.apply(this, args)
+  }
+}
+const split = String.prototype.split
+const countSplits = fn => {
+  String.prototype.split = function (separator, limit) {
+    if (separator instanceof RegExp && separator.source === '(?=^#+ )') parses.sections++
+    return split.call(this, separator, limit)
+  }
+  try {
+    return fn()
+  } finally {
+    String.prototype.split = split
+  }
+}
+
+const readme = Array.from({ length: 500 }, (_, i) => [
+  `## ${i % 5 === 0 ? `createClient option ${i}` : `Section ${i}`}`,
+  '',
+  `Section ${i} explains how the library handles requests and retries.`,
+  '',
+  '```js',
+  `function createClient${i}(options) {`,
+  `  return new Client({ retries: ${i}, ...options })`,
+  '}',
+  '```',
+].join('\n')).join('\n\n')
+
+// What a describe and a search of the README run over it
+function searchReadme() {
+  const sections = searchUtils.splitMarkdownSections(readme).map(content => ({ content, type: 'general' }))
+  searchUtils.parseMarkdownDocSections(readme)
+  searchUtils.extractCodeBlocks(readme, 'javascript')
+  searchUtils.extractCodeBlocks(readme)
+  searchUtils.extractFunctionSignatures(readme)
+  searchSections(searchUtils, { sections, query: 'createClient', language: 'javascript', contextSize: 3 })
+  // A second search in the same call, e.g. with a narrower query
+  searchSections(searchUtils, { sections, query: 'createClient retries', language: 'javascript', contextSize: 3 })
+}
+
+async function measure(label, run) {
+  for (const kind in parses) parses[kind] = 0
+  const start = performance.now()
+  await countSplits(run)
+  const elapsed = performance.now() - start
+  console.log(`${label}: ${elapsed.toFixed(1)}ms, parses ${JSON.stringify(parses)}`)
+}
+
+searchReadme() // Warm up
+await measure('without the cache', async () => searchReadme())
+await measure('with the cache   ', () => withParseCache(async () => searchReadme()))
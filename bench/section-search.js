@@ -0,0 +1,45 @@
+// Times searching a large synthetic README on the main thread and on the worker pool.
+// Run with `npm run bench` after building; PACKAGE_DOCS_SEARCH_WORKERS sets the pool size.
+import { McpLogger } from '../build/logger.js'
+import { SearchUtils } from '../build/search-utils.js'
+import { SectionSearchPool, getSearchWorkers, searchSections } from '../build/section-search.js'
+
+const logger = new McpLogger('Bench', true)
+const searchUtils = new SearchUtils(logger)
+
+function syntheticSections(count) {
+  return Array.from({ length: count }, (_, i) => ({
+    type: 'general',
+    content: [
+      `## ${i % 5 === 0 ? `createClient option ${i}` : `Section ${i}`}`,
+      '',
+      ...Array.from({ length: 20 }, (_, line) => `Line ${line} of section ${i} explains how the library handles requests and retries.`),
+      i % 3 === 0 ? `Pass retries to createClient to retry failed requests ${i} times.` : '',
+      '',
+      '```js',
+      `const client = createClient({ retries: ${i} })`,
+      'await client.get("/status")',
+      '```',
+    ].join('\n'),
+  }))
+}
+
+async function time(run, repeat = 5) {
+  await run() // Warm up, starting the pool's workers
+  const start = performance.now()
+  for (let i = 0; i < repeat; i++) await run()
+  return (performance.now() - start) / repeat
+}
+
+const workers = getSearchWorkers()
+const pool = new SectionSearchPool(searchUtils, logger, workers, 1)
+console.log(`${workers} workers`)
+
+for (const count of [50, 100, 200, 500, 1000, 2000, 5000]) {
+  const task = { sections: syntheticSections(count), query: 'createClient retries', language: 'javascript', contextSize: 3 }
+  const serial = await time(() => searchSections(searchUtils, task))
+  const parallel = await time(() => pool.search(task))
+  console.log(`${String(count).padStart(5)} sections: serial ${serial.toFixed(1)}ms, pool ${parallel.toFixed(1)}ms`)
+}
+
+await pool.close()
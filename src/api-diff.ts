@@ -0,0 +1,232 @@
+/**
+ * Helpers for listing a package's public API, and describing what changed in it between two
+ * versions, based on the exported symbols (and their signatures where available) of each version.
+ */
+
+export interface ApiSymbol {
+  name: string
+  kind: string
+  signature?: string // Omitted when the source only lists names, so changes can't be detected
+}
+
+export interface ApiSymbolChange {
+  name: string
+  kind: string
+  from?: string
+  to?: string
+}
+
+export interface ApiDiff {
+  added: ApiSymbol[]
+  removed: ApiSymbol[]
+  changed: ApiSymbolChange[]
+}
+
+// Compare signatures without the formatting differences between releases
+function normaliseSignature(signature: string): string {
+  return signature.replace(/\s+/g, " ").replace(/\s*([(),:;{}<>[\]=|&])\s*/g, "$1").trim()
+}
+
+/**
+ * Categorise the exported symbols of two versions as added, removed or changed.
+ * A symbol present in both versions counts as changed when both have signatures and they differ.
+ */
+export function diffApiSymbols(from: ApiSymbol[], to: ApiSymbol[]): ApiDiff {
+  const key = (symbol: ApiSymbol) => `${symbol.kind}:${symbol.name}`
+  const fromSymbols = new Map(from.map(symbol => [key(symbol), symbol]))
+  const toSymbols = new Map(to.map(symbol => [key(symbol), symbol]))
+
+  const diff: ApiDiff = { added: [], removed: [], changed: [] }
+
+  for (const [symbolKey, symbol] of toSymbols) {
+    const previous = fromSymbols.get(symbolKey)
+    if (!previous) {
+      diff.added.push(symbol)
+    } else if (
+      previous.signature &&
+      symbol.signature &&
+      normaliseSignature(previous.signature) !== normaliseSignature(symbol.signature)
+    ) {
+      diff.changed.push({ name: symbol.name, kind: symbol.kind, from: previous.signature, to: symbol.signature })
+    }
+  }
+
+  for (const [symbolKey, symbol] of fromSymbols) {
+    if (!toSymbols.has(symbolKey)) {
+      diff.removed.push(symbol)
+    }
+  }
+
+  const byName = (a: { name: string }, b: { name: string }) => a.name.localeCompare(b.name)
+  diff.added.sort(byName)
+  diff.removed.sort(byName)
+  diff.changed.sort(byName)
+
+  return diff
+}
+
+/**
+ * Extract the exported declarations from `go doc -all` output. Methods are named Type.Method,
+ * and a type's signature includes its full struct or interface body.
+ */
+export function parseGoApiSymbols(doc: string): ApiSymbol[] {
+  const symbols: ApiSymbol[] = []
+  const lines = doc.split("\n")
+  const isExported = (name: string) => /^[A-Z]/.test(name)
+
+  for (let i = 0; i < lines.length; i++) {
+    const line = lines[i]
+
+    // Declarations start in the first column; doc comments are indented
+    const method = line.match(/^func \(\w*\s*\*?(\w+)(?:\[[^\]]*\])?\) (\w+)/)
+    if (method) {
+      if (isExported(method[1]) && isExported(method[2])) {
+        symbols.push({ name: `${method[1]}.${method[2]}`, kind: "method", signature: line })
+      }
+      continue
+    }
+
+    const func = line.match(/^func (\w+)/)
+    if (func) {
+      if (isExported(func[1])) {
+        symbols.push({ name: func[1], kind: "func", signature: line })
+      }
+      continue
+    }
+
+    const type = line.match(/^type (\w+)/)
+    if (type) {
+      let signature = line
+      if (line.trimEnd().endsWith("{")) {
+        while (i + 1 < lines.length && !lines[i].startsWith("}")) {
+          signature += "\n" + lines[++i]
+        }
+      }
+      if (isExported(type[1])) {
+        symbols.push({ name: type[1], kind: "type", signature })
+      }
+      continue
+    }
+
+    const group = line.match(/^(const|var) \($/)
+    if (group) {
+      while (i + 1 < lines.length && !lines[i + 1].startsWith(")")) {
+        const member = lines[++i].match(/^\t(\w+)\b(.*)/)
+        if (member && isExported(member[1])) {
+          symbols.push({ name: member[1], kind: group[1], signature: member[0].trim() })
+        }
+      }
+      continue
+    }
+
+    const single = line.match(/^(const|var) (\w+)/)
+    if (single && isExported(single[2])) {
+      symbols.push({ name: single[2], kind: single[1], signature: line })
+    }
+  }
+
+  return symbols
+}
+
+/**
+ * Extract the exported declarations from `go doc -short` output, one per line. Constructors are
+ * listed indented under their type, and type bodies are elided as { ... }.
+ */
+export function parseGoShortSymbols(doc: string): ApiSymbol[] {
+  const symbols: ApiSymbol[] = []
+
+  for (const line of doc.split("\n")) {
+    const declaration = line.trim()
+    const match = declaration.match(/^(func|type|var|const) (\w+)/)
+    if (match && /^[A-Z]/.test(match[2])) {
+      symbols.push({ name: match[2], kind: match[1], signature: declaration })
+    }
+  }
+
+  return symbols
+}
+
+/**
+ * Extract the public classes, functions and data from pydoc output, from its CLASSES, FUNCTIONS
+ * and DATA sections. Names starting with an underscore are skipped.
+ */
+export function parsePydocSymbols(doc: string): ApiSymbol[] {
+  const symbols: ApiSymbol[] = []
+  let section = ""
+
+  for (const line of doc.split("\n")) {
+    // Section headings are in capitals in the first column
+    if (/^[A-Z][A-Z ]+$/.test(line)) {
+      section = line.trim()
+      continue
+    }
+
+    // Entries are indented by four spaces; deeper lines are their documentation
+    const entry = line.match(/^ {4}(\S.*)$/)
+    if (!entry) continue
+
+    if (section === "CLASSES") {
+      const cls = entry[1].match(/^class (\w+)(\(.*\))?/)
+      if (cls && !cls[1].startsWith("_")) {
+        symbols.push({ name: cls[1], kind: "class", signature: cls[0] })
+      }
+    } else if (section === "FUNCTIONS") {
+      const func = entry[1].match(/^(\w+)\(.*\)/)
+      if (func && !func[1].startsWith("_")) {
+        symbols.push({ name: func[1], kind: "function", signature: func[0] })
+      }
+    } else if (section === "DATA") {
+      const data = entry[1].match(/^(\w+) = /)
+      if (data && !data[1].startsWith("_")) {
+        symbols.push({ name: data[1], kind: "data", signature: entry[1] })
+      }
+    }
+  }
+
+  return symbols
+}
+
+/**
+ * Render a symbol list as markdown, grouped by kind in the order the kinds first appear
+ */
+export function formatSymbolList(symbols: ApiSymbol[]): string {
+  const groups = new Map<string, ApiSymbol[]>()
+  for (const symbol of symbols) {
+    groups.set(symbol.kind, [...(groups.get(symbol.kind) || []), symbol])
+  }
+
+  return Array.from(groups, ([kind, members]) =>
+    `### ${kind} (${members.length})\n\n` +
+    members.map(symbol => symbol.signature && symbol.signature !== symbol.name
+      ? `- \`${symbol.name}\`: \`${symbol.signature.split("\n")[0]}\``
+      : `- \`${symbol.name}\``
+    ).join("\n")
+  ).join("\n\n")
+}
+
+/**
+ * Render an API diff as markdown, with the summary counts first
+ */
+export function formatApiDiff(diff: ApiDiff, fromVersion: string, toVersion: string): string {
+  let markdown = `## ${fromVersion} → ${toVersion}\n\n`
+  markdown += `Added ${diff.added.length}, Removed ${diff.removed.length}, Changed ${diff.changed.length}\n\n`
+
+  if (diff.added.length > 0) {
+    markdown += "### Added\n\n"
+    markdown += diff.added.map(symbol => `- \`${symbol.name}\` (${symbol.kind})`).join("\n") + "\n\n"
+  }
+
+  if (diff.removed.length > 0) {
+    markdown += "### Removed\n\n"
+    markdown += diff.removed.map(symbol => `- \`${symbol.name}\` (${symbol.kind})`).join("\n") + "\n\n"
+  }
+
+  if (diff.changed.length > 0) {
+    markdown += "### Changed\n\n"
+    for (const change of diff.changed) {
+      markdown += `#### ${change.name} (${change.kind})\n\n\`\`\`diff\n- ${change.from?.replace(/\n/g, "\n- ")}\n+ ${change.to?.replace(/\n/g, "\n+ ")}\n\`\`\`\n\n`
+    }
+  }
+
+  return markdown.trimEnd() + "\n"
+}
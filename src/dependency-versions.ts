@@ -0,0 +1,284 @@
+/**
+ * Helpers for finding the version of a transitive dependency that a parent package uses, from
+ * lockfiles and from the requirements in the parent's own metadata.
+ */
+import { normalizePyPIName } from "./package-names.js"
+
+/**
+ * Split a package spec into its name and optional version, e.g. express@4.18.2,
+ * @types/node@20.0.0 or github.com/spf13/cobra@v1.8.0. A scope's leading @ isn't a separator.
+ */
+export function splitPackageSpec(spec: string): { name: string; version?: string } {
+  const at = spec.lastIndexOf("@")
+  return at > 0
+    ? { name: spec.slice(0, at), version: spec.slice(at + 1) || undefined }
+    : { name: spec }
+}
+
+/**
+ * Get the exact version a requirement pins, e.g. 1.2.3 from "1.2.3", "=1.2.3" or "==1.2.3",
+ * or undefined for a range (^1.2.3, >=1.2, ~1.2 and so on)
+ */
+export function pinnedVersion(requirement: string): string | undefined {
+  const match = requirement.trim().match(/^(?:={1,3}\s*)?v?(\d+(?:\.\d+)*(?:[-+][\w.+-]*)?)$/)
+  return match?.[1]
+}
+
+/**
+ * Check whether a version is a prerelease: a semver version with a - suffix (1.0.0-beta.1, as npm,
+ * crates.io and Go use) or a PEP 440 alpha, beta, release candidate or dev release (2.0rc1, 1.0.dev3)
+ */
+export function isPrerelease(version: string): boolean {
+  const release = version.replace(/^v/, "").split("+")[0]
+  return release.includes("-") || /\d[._]?(?:a|b|c|rc|alpha|beta|pre|preview|dev)[._]?\d*$/i.test(release)
+}
+
+/**
+ * Compare two versions for sorting: by their numeric release parts, then with a prerelease before
+ * its release (and a PEP 440 post release after it), then by the suffix with numbers compared as
+ * numbers, so beta.10 comes after beta.2
+ */
+export function compareVersions(a: string, b: string): number {
+  const parse = (version: string) => {
+    const match = version.replace(/^v/, "").split("+")[0].match(/^(\d+(?:\.\d+)*)(.*)$/)
+    const suffix = match?.[2] || ""
+    return {
+      release: (match?.[1] || "0").split(".").map(Number),
+      suffix,
+      rank: suffix === "" ? 0 : isPrerelease(version) ? -1 : 1,
+    }
+  }
+
+  const x = parse(a)
+  const y = parse(b)
+  for (let i = 0; i < Math.max(x.release.length, y.release.length); i++) {
+    const difference = (x.release[i] || 0) - (y.release[i] || 0)
+    if (difference !== 0) return difference
+  }
+  return x.rank - y.rank || x.suffix.localeCompare(y.suffix, undefined, { numeric: true })
+}
+
+/**
+ * Get the newest of a package's versions, leaving out prereleases unless they're wanted. When
+ * every version is a prerelease, the newest prerelease is returned rather than nothing.
+ */
+export function latestVersion(versions: string[], includePrerelease = false): string | undefined {
+  const stable = versions.filter(version => !isPrerelease(version))
+  const candidates = includePrerelease || stable.length === 0 ? versions : stable
+  return [...candidates].sort(compareVersions).pop()
+}
+
+/**
+ * Check whether a version satisfies a requirement as npm, Cargo and pip write them: comparators
+ * (>=1.2, <2, !=1.5), caret and tilde ranges (^1.2.3, ~1.2, ~=2.2), wildcards (1.x, 1.2.*, *),
+ * hyphen ranges (1.0.0 - 2.0.0) and || alternatives. A bare version is a caret range for Cargo and
+ * a wildcard on its missing parts for npm (1.2 means 1.2.x).
+ */
+export function satisfiesRequirement(version: string, requirement: string, language?: string): boolean {
+  return requirement.split("||").some(alternative => {
+    const hyphen = alternative.match(/^\s*(\S+)\s+-\s+(\S+)\s*$/)
+    const comparators = hyphen
+      ? [`>=${hyphen[1]}`, `<=${hyphen[2]}`]
+      : alternative.replace(/([<>=!~^]+)\s+/g, "$1").split(/[\s,]+/).filter(Boolean)
+    return comparators.every(comparator => satisfiesComparator(version, comparator, language))
+  })
+}
+
+function satisfiesComparator(version: string, comparator: string, language?: string): boolean {
+  const [, operator, target] = comparator.match(/^([<>=!~^]*)v?(.*)$/) || ["", "", comparator]
+  const parts = target.split(".")
+  const wildcard = parts.findIndex(part => /^[xX*]$/.test(part))
+  const given = wildcard === -1 ? parts : parts.slice(0, wildcard)
+  if (given.length === 0) return operator !== "<" && operator !== "!="
+  if (!/^\d/.test(given[0])) return false
+
+  const base = given.join(".")
+  // The version after the given parts with the one at index incremented, e.g. bump(1) of 1.2.3 is 2
+  const bump = (index: number) => given.slice(0, index)
+    .map((part, i) => i === index - 1 ? String(parseInt(part, 10) + 1) : part)
+    .join(".")
+  const inRange = (upper: string) => compareVersions(version, base) >= 0 && compareVersions(version, upper) < 0
+  const partial = wildcard !== -1 || given.length < 3
+  // Caret ranges allow changes that don't modify the first non-zero part
+  const caretUpper = () => {
+    const nonZero = given.findIndex(part => parseInt(part, 10) !== 0)
+    return bump(nonZero === -1 ? given.length : nonZero + 1)
+  }
+
+  switch (operator) {
+    case "^":
+      return inRange(caretUpper())
+    case "~":
+      return inRange(bump(Math.min(given.length, 2)))
+    case "~=":
+      return inRange(bump(Math.max(given.length - 1, 1)))
+    case ">=":
+      return compareVersions(version, base) >= 0
+    case ">":
+      return wildcard !== -1 ? compareVersions(version, bump(given.length)) >= 0 : compareVersions(version, base) > 0
+    case "<=":
+      return wildcard !== -1 ? compareVersions(version, bump(given.length)) < 0 : compareVersions(version, base) <= 0
+    case "<":
+      return compareVersions(version, base) < 0
+    case "!=":
+      return wildcard !== -1 ? !inRange(bump(given.length)) : compareVersions(version, base) !== 0
+    case "":
+      if (language === "rust") return inRange(caretUpper())
+      // Falls through to an exact match, or a wildcard on the missing parts
+    default:
+      return wildcard !== -1 || (language === "npm" && partial)
+        ? inRange(bump(given.length))
+        : compareVersions(version, base) === 0
+  }
+}
+
+/**
+ * Get the newest version satisfying a requirement, leaving out prereleases unless the requirement
+ * names one. Returns undefined when no version satisfies it.
+ */
+export function maxSatisfyingVersion(versions: string[], requirement: string, language?: string): string | undefined {
+  const satisfying = versions.filter(version => satisfiesRequirement(version, requirement, language))
+  const wantsPrerelease = requirement.split(/[\s,|<>=!~^]+/).some(part => /^v?\d/.test(part) && isPrerelease(part))
+  return satisfying.length > 0 ? latestVersion(satisfying, wantsPrerelease) : undefined
+}
+
+// Most recently published versions of an npm package considered when choosing one, so packages
+// with thousands of releases (@types/node) cost a bounded amount of version comparison
+export const RECENT_NPM_VERSIONS = 100
+
+/**
+ * Get the versions of an npm packument worth choosing between: its dist-tags and the
+ * RECENT_NPM_VERSIONS most recently published versions in its time map (the last ones listed in
+ * versions when it has no times), rather than every version it has ever published
+ */
+// eslint-disable-next-line @typescript-eslint/no-explicit-any
+export function recentNpmVersions(packument: any, limit = RECENT_NPM_VERSIONS): string[] {
+  const published = Object.entries(packument?.time || {})
+    .filter(([version]) => version !== "created" && version !== "modified" && packument.versions?.[version])
+
+  let recent: string[]
+  if (published.length > 0) {
+    // Keep the newest publish times, trimming as they're collected so the whole map is never sorted
+    let newest: Array<[string, unknown]> = []
+    for (const entry of published) {
+      newest.push(entry)
+      if (newest.length >= limit * 2) {
+        newest = newest.sort(([, a], [, b]) => String(b).localeCompare(String(a))).slice(0, limit)
+      }
+    }
+    recent = newest.sort(([, a], [, b]) => String(b).localeCompare(String(a))).slice(0, limit).map(([version]) => version)
+  } else {
+    recent = Object.keys(packument?.versions || {}).slice(-limit)
+  }
+
+  const tagged = Object.values(packument?.["dist-tags"] || {}).map(String)
+  return Array.from(new Set([...tagged, ...recent]))
+}
+
+/**
+ * Find the version of a dependency a parent package has installed, from an npm lockfile (v2 or
+ * later). A copy nested under the parent takes precedence over the hoisted one, as it's the one
+ * the parent resolves. Returns undefined when the parent or the dependency isn't in the lockfile.
+ */
+// eslint-disable-next-line @typescript-eslint/no-explicit-any
+export function findNpmLockVersion(lock: any, parent: string, dependency: string): string | undefined {
+  const packages = lock?.packages
+  if (!packages) return undefined
+
+  // Walk up from the parent's own node_modules, as node's module resolution does
+  const parentPath = Object.keys(packages).find(path => path === `node_modules/${parent}` || path.endsWith(`/node_modules/${parent}`))
+  if (!parentPath) return undefined
+
+  for (let base = parentPath; ; base = base.slice(0, base.lastIndexOf("/node_modules/"))) {
+    const version = packages[`${base}/node_modules/${dependency}`]?.version
+    if (version) return version
+    if (!base.includes("/node_modules/")) break
+  }
+  return packages[`node_modules/${dependency}`]?.version
+}
+
+/**
+ * Parse the [[package]] entries of a TOML lockfile (Cargo.lock, poetry.lock or uv.lock) into
+ * their names, versions and dependency lists
+ */
+export function parseLockPackages(lock: string): Array<{ name: string; version: string; dependencies: string[] }> {
+  return lock.split(/^\[\[package\]\]\s*$/m).slice(1).map(block => {
+    const name = block.match(/^name\s*=\s*"([^"]+)"/m)?.[1] || ""
+    const version = block.match(/^version\s*=\s*"([^"]+)"/m)?.[1] || ""
+    const list = block.match(/^dependencies\s*=\s*\[([\s\S]*?)\]/m)?.[1] || ""
+    const dependencies = Array.from(list.matchAll(/"([^"]+)"/g), match => match[1])
+    return { name, version, dependencies }
+  })
+}
+
+/**
+ * Find the version of a crate a parent crate depends on in a Cargo.lock. Cargo only qualifies a
+ * dependency with its version ("rand 0.8.5") when several versions are locked, so an unqualified
+ * one is the only locked version of that crate.
+ */
+export function findCargoLockVersion(lock: string, parent: string, dependency: string): string | undefined {
+  const packages = parseLockPackages(lock)
+  const parentPackage = packages.find(pkg => pkg.name === parent)
+  const entry = parentPackage?.dependencies.find(dep => dep.split(" ")[0] === dependency)
+  if (!entry) return undefined
+
+  return entry.split(" ")[1] || packages.find(pkg => pkg.name === dependency)?.version
+}
+
+/**
+ * Find the version of a Python project locked in a poetry.lock or uv.lock, comparing names as
+ * PyPI does. These lockfiles pin one version of each project for the whole environment.
+ */
+export function findPythonLockVersion(lock: string, dependency: string): string | undefined {
+  const wanted = normalizePyPIName(dependency)
+  return parseLockPackages(lock).find(pkg => normalizePyPIName(pkg.name) === wanted)?.version
+}
+
+/**
+ * Get the requirements of a go.mod, from single lines ("require a v1") and require ( ... ) blocks,
+ * noting those marked // indirect. Replace and exclude directives are skipped.
+ */
+export function parseGoModRequirements(goMod: string): Array<{ module: string; version: string; indirect: boolean }> {
+  const requirements: Array<{ module: string; version: string; indirect: boolean }> = []
+  let block: string | undefined
+
+  for (const line of goMod.split("\n")) {
+    const trimmed = line.trim()
+    const blockStart = trimmed.match(/^(\w+)\s*\($/)
+    if (blockStart) {
+      block = blockStart[1]
+      continue
+    }
+    if (block && trimmed === ")") {
+      block = undefined
+      continue
+    }
+
+    const requirement = block === "require" ? trimmed : block ? undefined : trimmed.match(/^require\s+(.*)$/)?.[1]
+    const match = requirement?.match(/^(\S+)\s+(v\S+)(.*)$/)
+    if (match) {
+      requirements.push({ module: match[1], version: match[2], indirect: /\/\/\s*indirect\b/.test(match[3]) })
+    }
+  }
+
+  return requirements
+}
+
+/**
+ * Find the version of a module a go.mod requires, for a package path within it: the requirement
+ * with the longest module path that is the package path or one of its parents
+ */
+export function findGoModRequirement(goMod: string, packagePath: string): { module: string; version: string } | undefined {
+  const requirement = parseGoModRequirements(goMod)
+    .filter(req => packagePath === req.module || packagePath.startsWith(`${req.module}/`))
+    .sort((a, b) => b.module.length - a.module.length)[0]
+  return requirement && { module: requirement.module, version: requirement.version }
+}
+
+/**
+ * Escape a module path for the Go module proxy, which writes capital letters as ! followed by
+ * the lowercase letter so paths stay unique on case insensitive file systems
+ */
+export function escapeGoModulePath(modulePath: string): string {
+  return modulePath.replace(/[A-Z]/g, letter => `!${letter.toLowerCase()}`)
+}
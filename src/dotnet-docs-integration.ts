@@ -0,0 +1,307 @@
+import axios from 'axios';
+import { McpLogger } from './logger.js';
+import { DocFormat, DocSource, DocResult, PackageMetadata, SearchUtils, isDocFormat, isDocSource } from './search-utils.js';
+import { PackageSearch } from './package-search.js';
+
+export interface DotnetDocArgs {
+  package: string;
+  version?: string;
+  format?: DocFormat;
+  source?: DocSource;
+  includeRaw?: boolean; // Return the README verbatim as well as the summary
+}
+
+export const isDotnetDocArgs = (args: unknown): args is DotnetDocArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as DotnetDocArgs).package === "string" &&
+    (typeof (args as DotnetDocArgs).version === "string" ||
+      (args as DotnetDocArgs).version === undefined) &&
+    (isDocFormat((args as DotnetDocArgs).format) ||
+      (args as DotnetDocArgs).format === undefined) &&
+    (isDocSource((args as DotnetDocArgs).source) ||
+      (args as DotnetDocArgs).source === undefined)
+  );
+};
+
+// Subset of a NuGet catalog entry that we use
+export interface NuGetCatalogEntry {
+  id: string;
+  version: string;
+  description?: string;
+  summary?: string;
+  authors?: string;
+  licenseExpression?: string;
+  licenseUrl?: string;
+  projectUrl?: string;
+  tags?: string[];
+  listed?: boolean;
+  deprecation?: {
+    reasons?: string[];
+    message?: string;
+  };
+  dependencyGroups?: Array<{
+    targetFramework?: string;
+    dependencies?: Array<{ id: string; range?: string }>;
+  }>;
+}
+
+interface RegistrationPage {
+  '@id': string;
+  items?: Array<{ catalogEntry: NuGetCatalogEntry }>;
+}
+
+const NUGET_REGISTRATION_BASE = 'https://api.nuget.org/v3/registration5-gz-semver2';
+const NUGET_FLAT_CONTAINER_BASE = 'https://api.nuget.org/v3-flatcontainer';
+
+// Number of versions listed under "Recent versions" in package descriptions
+const RECENT_VERSION_COUNT = 5;
+
+export class DotnetDocsHandler {
+  private logger: McpLogger;
+  private searchUtils: SearchUtils;
+  private packageSearch: PackageSearch;
+
+  constructor(logger: McpLogger) {
+    this.logger = logger.child('DotnetDocs');
+    this.searchUtils = new SearchUtils(logger);
+    this.packageSearch = new PackageSearch(logger);
+  }
+
+  /**
+   * Get catalog entries for a package from the NuGet registration index, oldest to newest.
+   * Packages with thousands of versions are split over many pages, so only the newest pages
+   * needed are fetched: up to the one containing the requested version, or enough for the
+   * latest stable version and the recent versions list.
+   * NuGet IDs are case-insensitive and the API expects them lowercased.
+   */
+  public async getCatalogEntries(packageId: string, version?: string): Promise<NuGetCatalogEntry[]> {
+    const lowerId = packageId.trim().toLowerCase();
+    const url = `${NUGET_REGISTRATION_BASE}/${encodeURIComponent(lowerId)}/index.json`;
+    this.logger.debug(`Fetching NuGet registration index: ${url}`);
+
+    const response = await axios.get(url);
+    const pages: RegistrationPage[] = response.data?.items || [];
+    const entries: NuGetCatalogEntry[] = [];
+
+    // Registration pages are ordered oldest to newest
+    for (const page of [...pages].reverse()) {
+      let items = page.items;
+
+      // Large packages don't inline their pages, so fetch them separately
+      if (!items) {
+        const pageResponse = await axios.get(page['@id']);
+        items = pageResponse.data?.items || [];
+      }
+
+      entries.unshift(...(items || []).map(item => item.catalogEntry));
+
+      const listed = entries.filter(entry => entry.listed !== false);
+      const done = version
+        ? entries.some(entry => entry.version.toLowerCase() === version.toLowerCase())
+        : listed.length >= RECENT_VERSION_COUNT && listed.some(entry => !entry.version.includes('-'));
+      if (done) {
+        break;
+      }
+    }
+
+    return entries;
+  }
+
+  /**
+   * Pick the requested version, or the newest listed stable version if none was requested
+   */
+  public selectVersion(entries: NuGetCatalogEntry[], version?: string): NuGetCatalogEntry | undefined {
+    if (version) {
+      return entries.find(entry => entry.version.toLowerCase() === version.toLowerCase());
+    }
+
+    const listed = entries.filter(entry => entry.listed !== false);
+    const stable = listed.filter(entry => !entry.version.includes('-'));
+
+    // Registration pages are ordered oldest to newest
+    return stable[stable.length - 1] || listed[listed.length - 1] || entries[entries.length - 1];
+  }
+
+  /**
+   * Fetch the embedded README for a package version from the flat container
+   */
+  public async fetchReadme(packageId: string, version: string): Promise<string | undefined> {
+    const url = `${NUGET_FLAT_CONTAINER_BASE}/${encodeURIComponent(packageId.toLowerCase())}/${encodeURIComponent(version.toLowerCase())}/readme`;
+
+    try {
+      this.logger.debug(`Fetching NuGet README: ${url}`);
+      const response = await axios.get(url, { responseType: 'text' });
+      return response.data ? String(response.data) : undefined;
+    } catch {
+      // Not every package embeds a README
+      return undefined;
+    }
+  }
+
+  /**
+   * Get structured metadata for a .NET package version
+   */
+  public async getPackageMetadata(packageName: string, version?: string): Promise<PackageMetadata> {
+    const entry = this.selectVersion(await this.getCatalogEntries(packageName, version), version);
+    if (!entry) {
+      throw new Error(`${version ? `Version ${version} of ${packageName}` : packageName} not found on NuGet`);
+    }
+    return this.toPackageMetadata(entry);
+  }
+
+  /**
+   * Map a version's catalog entry to structured metadata
+   */
+  private toPackageMetadata(entry: NuGetCatalogEntry): PackageMetadata {
+    // Dependencies are grouped by target framework; flatten them, keeping the first range seen
+    const dependencies: Record<string, string> = {};
+    for (const group of entry.dependencyGroups || []) {
+      for (const dependency of group.dependencies || []) {
+        if (!(dependency.id in dependencies)) {
+          dependencies[dependency.id] = dependency.range || '*';
+        }
+      }
+    }
+
+    return {
+      name: entry.id,
+      version: entry.version,
+      description: entry.description,
+      license: entry.licenseExpression || entry.licenseUrl,
+      homepage: entry.projectUrl,
+      keywords: entry.tags,
+      dependencies,
+      deprecated: entry.deprecation
+        ? entry.deprecation.message || entry.deprecation.reasons?.join(', ') || 'Deprecated'
+        : undefined,
+    };
+  }
+
+  /**
+   * Get documentation for a .NET package
+   */
+  public async describeDotnetPackage(args: DotnetDocArgs): Promise<DocResult> {
+    const { package: packageName, version } = args;
+    this.logger.debug(`Getting .NET documentation for ${packageName}${version ? `@${version}` : ""}`);
+
+    // Documentation comes from the registry, so there's nothing to read locally
+    if (args.source === "local") {
+      return {
+        error: `Local documentation isn't available for .NET packages. Use source "auto" or "network"`,
+      };
+    }
+
+    try {
+      const entries = await this.getCatalogEntries(packageName, version);
+      const entry = this.selectVersion(entries, version);
+
+      if (!entry) {
+        return {
+          error: version
+            ? `Version ${version} of ${packageName} not found on NuGet`
+            : `No versions of ${packageName} found on NuGet`,
+        };
+      }
+
+      let usage = `## ${entry.id} ${entry.version}\n\n`;
+
+      if (entry.description) {
+        usage += `${entry.description}\n\n`;
+      }
+
+      usage += `### Installation\n\n\`\`\`bash\ndotnet add package ${entry.id} --version ${entry.version}\n\`\`\`\n\n`;
+
+      const licence = entry.licenseExpression || entry.licenseUrl;
+      if (licence) {
+        usage += `**Licence:** ${licence}\n\n`;
+      }
+
+      const frameworks = (entry.dependencyGroups || [])
+        .map(group => group.targetFramework)
+        .filter((framework): framework is string => !!framework);
+      if (frameworks.length > 0) {
+        usage += `**Target frameworks:** ${frameworks.join(', ')}\n\n`;
+      }
+
+      const recentVersions = entries
+        .filter(e => e.listed !== false)
+        .map(e => e.version)
+        .slice(-RECENT_VERSION_COUNT)
+        .reverse();
+      if (recentVersions.length > 0) {
+        usage += `**Recent versions:** ${recentVersions.join(', ')}\n\n`;
+      }
+
+      usage += `### Links\n\n`;
+      usage += `- [NuGet](https://www.nuget.org/packages/${entry.id}/${entry.version})\n`;
+      if (entry.projectUrl) usage += `- [Project](${entry.projectUrl})\n`;
+
+      let example: string | undefined;
+      let languageNote: string | undefined;
+      const readme = await this.fetchReadme(entry.id, entry.version);
+      if (readme) {
+        languageNote = this.searchUtils.languageNote(readme);
+
+        // Extract relevant sections
+        const sections = readme.split(/#+\s/);
+        for (const section of sections) {
+          const lower = section.toLowerCase();
+          if (lower.startsWith("usage") || lower.startsWith("getting started")) {
+            usage += `\n### Usage\n\n${section.split("\n").slice(1).join("\n").trim()}\n`;
+          } else if (lower.startsWith("example")) {
+            example = section.split("\n").slice(1).join("\n").trim();
+          }
+        }
+      }
+
+      let description = entry.summary || entry.description || `.NET package: ${entry.id}`;
+      if (languageNote) {
+        description += `\n\n${languageNote}`;
+      }
+
+      return {
+        description,
+        usage,
+        example,
+        metadata: args.format === "json" ? this.toPackageMetadata(entry) : undefined,
+        readme: args.includeRaw ? readme : undefined,
+      };
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.status === 404) {
+        const suggestion = await this.packageSearch.suggestAlternatives("dotnet", packageName);
+        return {
+          error: `Package ${packageName} not found on NuGet.${suggestion ? ` ${suggestion}` : ""} Try installing it with 'dotnet add package ${packageName}'`,
+          suggestInstall: true,
+        };
+      }
+
+      const errorMessage = error instanceof Error ? error.message : String(error);
+      this.logger.error(`Error getting .NET documentation for ${packageName}:`, error);
+      return {
+        error: `Failed to fetch .NET documentation: ${errorMessage}`,
+      };
+    }
+  }
+
+  /**
+   * Get package documentation split into searchable sections
+   */
+  public async getSearchableContent(packageName: string): Promise<{
+    packageInfo: NuGetCatalogEntry;
+    sections: Array<{ content: string; type: string }>;
+  }> {
+    const entry = this.selectVersion(await this.getCatalogEntries(packageName));
+    if (!entry) {
+      throw new Error(`No versions of ${packageName} found on NuGet`);
+    }
+
+    const readme = await this.fetchReadme(entry.id, entry.version);
+
+    return {
+      packageInfo: entry,
+      sections: this.searchUtils.parseNpmDoc({ description: entry.description, readme }),
+    };
+  }
+}
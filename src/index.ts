@@ -1,22 +1,54 @@
 #!/usr/bin/env node
 import { StdioServerTransport } from "@modelcontextprotocol/sdk/server/stdio.js";
+import axios from "axios";
 import { logger } from './logger.js'
 import { PackageDocsServer } from './package-docs-server.js';
+import { installHttpCache } from './utils/http-cache.js';
+import { installRetryInterceptor } from './utils/retry-budget.js';
+
+// Logs always go to stderr, as stdout carries the JSON-RPC messages. MCP_VERBOSE=true enables the
+// debug logs that are silenced by default, and adds the stack trace and environment to startup
+// failure reports.
+const verbose = process.env.MCP_VERBOSE === "true";
+if (verbose) {
+  logger.setSilent(false);
+}
+
+/**
+ * Report why the server couldn't start in one line, or with the error's stack trace when verbose,
+ * then exit
+ */
+function exitWithError(message: string, error: unknown): never {
+  if (verbose) {
+    logger.error(message, error instanceof Error && error.stack ? error.stack : error);
+    logger.error(`Node ${process.version} on ${process.platform}, ENABLE_LSP=${process.env.ENABLE_LSP ?? "unset"}`);
+  } else {
+    logger.error(message, error instanceof Error ? String(error) : error);
+  }
+  process.exit(1);
+}
 
 // Initialise and run the server
 async function main() {
   try {
+    // Registry and repository responses are shared by the tool calls that request them, when
+    // PACKAGE_DOCS_HTTP_CACHE_MB enables the HTTP cache
+    installHttpCache();
+    // Failed registry requests are retried, within the budget of the tool call making them
+    installRetryInterceptor(axios, logger);
     const server = new PackageDocsServer();
     const transport = new StdioServerTransport();
     await server.connect(transport);
     logger.debug("Package docs MCP server running on stdio");
+
+    if (process.env.PACKAGE_DOCS_SELF_CHECK === "true") {
+      await server.selfCheck();
+    }
   } catch (error) {
-    logger.error("Failed to start server:", error);
-    process.exit(1);
+    exitWithError("Failed to start server:", error);
   }
 }
 
 main().catch((error) => {
-  logger.error("Unhandled error:", error);
-  process.exit(1);
+  exitWithError("Unhandled error:", error);
 });
@@ -0,0 +1,305 @@
+import axios from 'axios';
+import { McpLogger } from './logger.js';
+import { DocFormat, DocSource, DocResult, PackageMetadata, SearchUtils, isDocFormat, isDocSource } from './search-utils.js';
+import { createRepoClient } from './utils/repo-client.js';
+import { PackageSearch } from './package-search.js';
+
+export interface JavaDocArgs {
+  package: string;
+  version?: string;
+  format?: DocFormat;
+  source?: DocSource;
+  includeRaw?: boolean; // Return the README verbatim as well as the summary
+}
+
+export const isJavaDocArgs = (args: unknown): args is JavaDocArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as JavaDocArgs).package === "string" &&
+    (typeof (args as JavaDocArgs).version === "string" ||
+      (args as JavaDocArgs).version === undefined) &&
+    (isDocFormat((args as JavaDocArgs).format) ||
+      (args as JavaDocArgs).format === undefined) &&
+    (isDocSource((args as JavaDocArgs).source) ||
+      (args as JavaDocArgs).source === undefined)
+  );
+};
+
+export interface MavenCoordinates {
+  groupId: string;
+  artifactId: string;
+}
+
+// Subset of a Maven Central search document
+export interface MavenArtifact {
+  id: string;
+  g: string;
+  a: string;
+  v?: string;
+  latestVersion?: string;
+  p?: string;
+  timestamp?: number;
+  versionCount?: number;
+}
+
+// Metadata pulled from the artifact's POM
+export interface MavenPomInfo {
+  name?: string;
+  description?: string;
+  url?: string;
+  licenses: string[];
+  scmUrl?: string;
+}
+
+// groupId and artifactId segments allow letters, digits, dots, hyphens and underscores
+const MAVEN_ID_PATTERN = /^[A-Za-z0-9_.-]+$/;
+
+export class JavaDocsHandler {
+  private logger: McpLogger;
+  private searchUtils: SearchUtils;
+  private packageSearch: PackageSearch;
+
+  constructor(logger: McpLogger) {
+    this.logger = logger.child('JavaDocs');
+    this.searchUtils = new SearchUtils(logger);
+    this.packageSearch = new PackageSearch(logger);
+  }
+
+  /**
+   * Parse and validate groupId:artifactId coordinates
+   */
+  public parseCoordinates(coordinates: string): MavenCoordinates {
+    const parts = coordinates.trim().split(':');
+    if (parts.length !== 2 || !MAVEN_ID_PATTERN.test(parts[0]) || !MAVEN_ID_PATTERN.test(parts[1])) {
+      throw new Error(
+        `Invalid Maven coordinates '${coordinates}'. Expected groupId:artifactId (e.g. com.google.guava:guava)`
+      );
+    }
+
+    return { groupId: parts[0], artifactId: parts[1] };
+  }
+
+  /**
+   * Find an artifact on Maven Central, optionally at a specific version
+   */
+  public async findArtifact(coordinates: MavenCoordinates, version?: string): Promise<MavenArtifact | undefined> {
+    let query = `g:"${coordinates.groupId}" AND a:"${coordinates.artifactId}"`;
+    if (version) {
+      query += ` AND v:"${version}"`;
+    }
+
+    const response = await axios.get('https://search.maven.org/solrsearch/select', {
+      params: {
+        q: query,
+        rows: 1,
+        wt: 'json',
+        core: version ? 'gav' : undefined,
+      },
+    });
+
+    return response.data?.response?.docs?.[0];
+  }
+
+  /**
+   * Fetch and parse the POM for an artifact version from Maven Central
+   */
+  public async fetchPom(coordinates: MavenCoordinates, version: string): Promise<MavenPomInfo | undefined> {
+    const groupPath = coordinates.groupId.replace(/\./g, '/');
+    const pomUrl = `https://repo1.maven.org/maven2/${groupPath}/${coordinates.artifactId}/${version}/${coordinates.artifactId}-${version}.pom`;
+
+    try {
+      this.logger.debug(`Fetching POM: ${pomUrl}`);
+      const response = await axios.get(pomUrl, { responseType: 'text' });
+      return this.parsePom(String(response.data));
+    } catch (error) {
+      this.logger.debug(`Error fetching POM from ${pomUrl}: ${error}`);
+      return undefined;
+    }
+  }
+
+  /**
+   * Extract the project level metadata from a POM
+   */
+  public parsePom(pom: string): MavenPomInfo {
+    // Drop nested blocks whose name/url/description elements would shadow the project's own
+    const projectLevel = pom
+      .replace(/<!--[\s\S]*?-->/g, '')
+      .replace(/<(parent|dependencies|dependencyManagement|build|developers|contributors|organization|distributionManagement|profiles|repositories|pluginRepositories|mailingLists|issueManagement|ciManagement)>[\s\S]*?<\/\1>/g, '');
+
+    const element = (content: string, name: string): string | undefined => {
+      const match = content.match(new RegExp(`<${name}>([\\s\\S]*?)</${name}>`));
+      return match ? match[1].trim() : undefined;
+    };
+
+    const licensesBlock = element(pom, 'licenses') || '';
+    const licenses = Array.from(licensesBlock.matchAll(/<name>([\s\S]*?)<\/name>/g)).map(m => m[1].trim());
+    const scmBlock = element(projectLevel, 'scm');
+
+    return {
+      name: element(projectLevel.replace(/<(licenses|scm)>[\s\S]*?<\/\1>/g, ''), 'name'),
+      description: element(projectLevel, 'description'),
+      url: element(projectLevel.replace(/<(licenses|scm)>[\s\S]*?<\/\1>/g, ''), 'url'),
+      licenses,
+      scmUrl: scmBlock ? element(scmBlock, 'url') : undefined,
+    };
+  }
+
+  /**
+   * Get structured metadata for a Java package version
+   */
+  public async getPackageMetadata(packageName: string, version?: string): Promise<PackageMetadata> {
+    const coordinates = this.parseCoordinates(packageName);
+    const artifact = await this.findArtifact(coordinates, version);
+    if (!artifact) {
+      throw new Error(`Artifact ${packageName}${version ? `:${version}` : ''} not found on Maven Central`);
+    }
+
+    const resolvedVersion = version || artifact.latestVersion || artifact.v;
+    const pom = resolvedVersion ? await this.fetchPom(coordinates, resolvedVersion) : undefined;
+    return this.toPackageMetadata(coordinates, resolvedVersion, pom);
+  }
+
+  /**
+   * Map an artifact's coordinates and POM to structured metadata
+   */
+  private toPackageMetadata(coordinates: MavenCoordinates, resolvedVersion?: string, pom?: MavenPomInfo): PackageMetadata {
+    return {
+      name: `${coordinates.groupId}:${coordinates.artifactId}`,
+      version: resolvedVersion,
+      description: pom?.description,
+      license: pom?.licenses.join(', ') || undefined,
+      homepage: pom?.url,
+      repository: pom?.scmUrl,
+    };
+  }
+
+  /**
+   * Get documentation for a Java package
+   */
+  public async describeJavaPackage(args: JavaDocArgs): Promise<DocResult> {
+    const { package: packageName, version } = args;
+    this.logger.debug(`Getting Java documentation for ${packageName}${version ? `:${version}` : ""}`);
+
+    // Documentation comes from the registry, so there's nothing to read locally
+    if (args.source === "local") {
+      return {
+        error: `Local documentation isn't available for Java packages. Use source "auto" or "network"`,
+      };
+    }
+
+    let coordinates: MavenCoordinates;
+    try {
+      coordinates = this.parseCoordinates(packageName);
+    } catch (error) {
+      return {
+        error: error instanceof Error ? error.message : String(error),
+      };
+    }
+
+    try {
+      const artifact = await this.findArtifact(coordinates, version);
+      if (!artifact) {
+        const suggestion = version ? undefined : await this.packageSearch.suggestAlternatives("java", packageName);
+        return {
+          error: `Artifact ${packageName}${version ? `:${version}` : ''} not found on Maven Central.${suggestion ? ` ${suggestion}` : ''}`,
+          suggestInstall: true,
+        };
+      }
+
+      const resolvedVersion = version || artifact.latestVersion || artifact.v;
+      const pom = resolvedVersion ? await this.fetchPom(coordinates, resolvedVersion) : undefined;
+      const { groupId, artifactId } = coordinates;
+
+      let usage = `## ${groupId}:${artifactId} ${resolvedVersion || ''}\n\n`;
+
+      if (pom?.description) {
+        usage += `${pom.description}\n\n`;
+      }
+
+      usage += `### Installation\n\n`;
+      usage += `Maven:\n\n\`\`\`xml\n<dependency>\n  <groupId>${groupId}</groupId>\n  <artifactId>${artifactId}</artifactId>\n  <version>${resolvedVersion || 'VERSION'}</version>\n</dependency>\n\`\`\`\n\n`;
+      usage += `Gradle:\n\n\`\`\`kotlin\nimplementation("${groupId}:${artifactId}:${resolvedVersion || 'VERSION'}")\n\`\`\`\n\n`;
+
+      if (pom && pom.licenses.length > 0) {
+        usage += `**Licence:** ${pom.licenses.join(', ')}\n\n`;
+      }
+
+      if (artifact.versionCount) {
+        usage += `**Published versions:** ${artifact.versionCount}\n\n`;
+      }
+
+      usage += `### Links\n\n`;
+      usage += `- [Javadoc](https://javadoc.io/doc/${groupId}/${artifactId}${resolvedVersion ? `/${resolvedVersion}` : ''})\n`;
+      usage += `- [Maven Central](https://central.sonatype.com/artifact/${groupId}/${artifactId}${resolvedVersion ? `/${resolvedVersion}` : ''})\n`;
+      if (pom?.scmUrl) usage += `- [Repository](${pom.scmUrl})\n`;
+      if (pom?.url && pom.url !== pom.scmUrl) usage += `- [Homepage](${pom.url})\n`;
+
+      let example: string | undefined;
+      let languageNote: string | undefined;
+      let readme: string | undefined;
+      const repository = pom?.scmUrl || pom?.url;
+
+      if (repository) {
+        readme = await createRepoClient(repository, this.logger)?.getReadme();
+        if (readme) {
+          languageNote = this.searchUtils.languageNote(readme);
+
+          // Extract relevant sections
+          const sections = readme.split(/#+\s/);
+          for (const section of sections) {
+            const lower = section.toLowerCase();
+            if (lower.startsWith("usage") || lower.startsWith("getting started")) {
+              usage += `\n### Usage\n\n${section.split("\n").slice(1).join("\n").trim()}\n`;
+            } else if (lower.startsWith("example")) {
+              example = section.split("\n").slice(1).join("\n").trim();
+            }
+          }
+        }
+      }
+
+      let description = pom?.description || pom?.name || `Java package: ${groupId}:${artifactId}`;
+      if (languageNote) {
+        description += `\n\n${languageNote}`;
+      }
+
+      return {
+        description,
+        usage,
+        example,
+        metadata: args.format === "json" ? this.toPackageMetadata(coordinates, resolvedVersion, pom) : undefined,
+        readme: args.includeRaw ? readme : undefined,
+      };
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error);
+      this.logger.error(`Error getting Java documentation for ${packageName}:`, error);
+      return {
+        error: `Failed to fetch Java documentation: ${errorMessage}`,
+      };
+    }
+  }
+
+  /**
+   * Get package documentation split into searchable sections
+   */
+  public async getSearchableContent(packageName: string): Promise<{
+    packageInfo: MavenArtifact & MavenPomInfo;
+    sections: Array<{ content: string; type: string }>;
+  }> {
+    const coordinates = this.parseCoordinates(packageName);
+    const artifact = await this.findArtifact(coordinates);
+    if (!artifact) {
+      throw new Error(`Artifact ${packageName} not found on Maven Central`);
+    }
+
+    const version = artifact.latestVersion || artifact.v;
+    const pom = (version ? await this.fetchPom(coordinates, version) : undefined) || { licenses: [] };
+    const repository = pom.scmUrl || pom.url;
+    const readme = repository ? await createRepoClient(repository, this.logger)?.getReadme() : undefined;
+
+    return {
+      packageInfo: { ...artifact, ...pom },
+      sections: this.searchUtils.parseNpmDoc({ description: pom.description, readme }),
+    };
+  }
+}
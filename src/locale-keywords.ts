@@ -0,0 +1,150 @@
+/**
+ * Localised section heading keywords, so READMEs written in other languages
+ * have their installation/usage/example sections recognised like English ones.
+ */
+
+export type SectionCategory = "install" | "usage" | "example" | "api" | "config"
+
+// English keywords are always applied by the callers; these are the additional locale sets
+export const LOCALE_SECTION_KEYWORDS: Record<string, Record<SectionCategory, string[]>> = {
+  es: {
+    install: ["instalación", "instalacion"],
+    usage: ["uso", "cómo usar", "como usar", "primeros pasos", "inicio rápido", "guía"],
+    example: ["ejemplo"],
+    api: ["referencia", "métodos", "funciones"],
+    config: ["configuración", "configuracion", "opciones"],
+  },
+  fr: {
+    install: ["installation"],
+    usage: ["utilisation", "démarrage", "prise en main", "guide"],
+    example: ["exemple"],
+    api: ["référence", "méthodes", "fonctions"],
+    config: ["configuration", "options", "paramètres"],
+  },
+  de: {
+    install: ["installation", "einrichtung"],
+    usage: ["verwendung", "benutzung", "nutzung", "erste schritte", "schnellstart", "anleitung"],
+    example: ["beispiel"],
+    api: ["referenz", "methoden", "funktionen"],
+    config: ["konfiguration", "optionen", "einstellungen"],
+  },
+  pt: {
+    install: ["instalação", "instalacao"],
+    usage: ["uso", "utilização", "como usar", "primeiros passos", "início rápido", "guia"],
+    example: ["exemplo"],
+    api: ["referência", "métodos", "funções"],
+    config: ["configuração", "configuracao", "opções"],
+  },
+  ru: {
+    install: ["установка"],
+    usage: ["использование", "начало работы", "быстрый старт", "руководство"],
+    example: ["пример"],
+    api: ["справочник", "методы", "функции"],
+    config: ["конфигурация", "настройки", "параметры"],
+  },
+  zh: {
+    install: ["安装"],
+    usage: ["使用", "用法", "快速开始", "快速上手", "入门"],
+    example: ["示例", "例子"],
+    api: ["接口", "参考", "方法"],
+    config: ["配置", "选项"],
+  },
+  ja: {
+    install: ["インストール", "導入"],
+    usage: ["使い方", "使用方法", "はじめに", "クイックスタート"],
+    example: ["例", "サンプル"],
+    api: ["リファレンス", "メソッド", "関数"],
+    config: ["設定", "オプション"],
+  },
+  ko: {
+    install: ["설치"],
+    usage: ["사용법", "사용 방법", "시작하기", "빠른 시작"],
+    example: ["예제", "예시"],
+    api: ["레퍼런스", "메서드", "함수"],
+    config: ["설정", "옵션"],
+  },
+}
+
+/**
+ * Parse a comma separated list of locale codes (e.g. "es,zh-CN"), keeping those with keyword sets.
+ * Region suffixes are ignored, so "pt-BR" uses the Portuguese set.
+ */
+export function parseLocales(value: string | undefined): string[] {
+  if (!value) return []
+
+  return value
+    .split(",")
+    .map(locale => locale.trim().toLowerCase().split(/[-_]/)[0])
+    .filter((locale, index, all) => locale in LOCALE_SECTION_KEYWORDS && all.indexOf(locale) === index)
+}
+
+/**
+ * Get the localised keywords for a section category across the given locales
+ */
+export function getLocaleKeywords(category: SectionCategory, locales: string[]): string[] {
+  return locales.flatMap(locale => LOCALE_SECTION_KEYWORDS[locale]?.[category] || [])
+}
+
+// Display names for the languages detectLanguage can report
+export const LANGUAGE_NAMES: Record<string, string> = {
+  en: "English",
+  es: "Spanish",
+  fr: "French",
+  de: "German",
+  pt: "Portuguese",
+  ru: "Russian",
+  zh: "Chinese",
+  ja: "Japanese",
+  ko: "Korean",
+}
+
+// Frequent function words for the Latin script languages, which can't be told apart by script alone
+const STOPWORDS: Record<string, string[]> = {
+  en: ["the", "and", "is", "to", "of", "for", "with", "this", "you", "that", "can", "are"],
+  es: ["el", "la", "los", "las", "de", "que", "para", "con", "una", "por", "es", "puede"],
+  fr: ["le", "la", "les", "des", "est", "pour", "avec", "une", "dans", "vous", "qui", "pas"],
+  de: ["der", "die", "das", "und", "ist", "mit", "für", "eine", "nicht", "sie", "auf", "wird"],
+  pt: ["o", "os", "as", "de", "que", "para", "com", "uma", "não", "você", "é", "pode"],
+}
+
+/**
+ * Detect the predominant natural language of a document, ignoring code blocks, inline code, links and HTML.
+ * Returns a language code from LANGUAGE_NAMES, or undefined when there's too little prose to tell.
+ */
+export function detectLanguage(text: string): string | undefined {
+  const prose = text
+    .replace(/```[\s\S]*?```/g, " ")
+    .replace(/`[^`\n]*`/g, " ")
+    .replace(/<[^>]+>/g, " ")
+    .replace(/\]\([^)]*\)/g, "]")
+    .replace(/https?:\/\/\S+/g, " ")
+
+  // Non-Latin scripts identify the language directly; kana is checked first as Japanese also uses Han characters
+  const letters = (prose.match(/\p{L}/gu) || []).length
+  if (letters < 20) return undefined
+
+  const count = (pattern: RegExp) => (prose.match(pattern) || []).length
+  const kana = count(/[\p{Script=Hiragana}\p{Script=Katakana}]/gu)
+  const han = count(/\p{Script=Han}/gu)
+  const hangul = count(/\p{Script=Hangul}/gu)
+  const cyrillic = count(/\p{Script=Cyrillic}/gu)
+
+  if (kana / letters > 0.05) return "ja"
+  if (hangul / letters > 0.1) return "ko"
+  if (han / letters > 0.1) return "zh"
+  if (cyrillic / letters > 0.3) return "ru"
+
+  const words = prose.toLowerCase().match(/\p{L}+/gu) || []
+  let best: string | undefined
+  let bestScore = 0
+  for (const [language, stopwords] of Object.entries(STOPWORDS)) {
+    const score = words.filter(word => stopwords.includes(word)).length
+    if (score > bestScore) {
+      best = language
+      bestScore = score
+    }
+  }
+
+  // Require a handful of hits so a README that's mostly code isn't misclassified
+  return bestScore >= 3 ? best : undefined
+}
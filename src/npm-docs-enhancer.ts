@@ -1,7 +1,9 @@
 import axios from 'axios'
 import * as ts from 'typescript';
+import * as cheerio from 'cheerio';
 import { NodeHtmlMarkdown } from 'node-html-markdown';
 import { McpLogger } from './logger.js';
+import { ConcurrencyGate } from './utils/concurrency-gate.js';
 
 // Initialize HTML to Markdown converter with custom options
 const nhm = new NodeHtmlMarkdown({
@@ -11,9 +13,20 @@ const nhm = new NodeHtmlMarkdown({
   codeBlockStyle: 'fenced',
   emDelimiter: '*',
   strongDelimiter: '**',
-  keepDataImages: false
+  keepDataImages: false,
+  // Scripts, styles and inline SVGs would otherwise leak into the markdown as noise
+  ignore: ['script', 'style', 'noscript', 'svg', 'template']
 });
 
+// Generators of hosted API documentation sites whose pages can be parsed for API members
+export type ApiDocGenerator = 'typedoc' | 'jsdoc';
+
+// Member pages followed from a generated site's index page
+const MAX_API_DOC_PAGES = 10;
+
+// Member pages fetched at once
+const API_DOC_PAGE_CONCURRENCY = 3;
+
 // Interface for structured API documentation
 export interface ApiDocumentation {
   name: string;
@@ -63,6 +76,13 @@ export interface PackageApiDocumentation {
   examples?: string[];
 }
 
+// The primary thing a package exports, and how it's exported
+export interface MainExport {
+  name: string;
+  kind: 'function' | 'class' | 'object' | 'namespace' | 'variable';
+  style: 'default' | 'commonjs' | 'named'; // export default, module.exports/export =, or a named export
+}
+
 export interface DocResult {
   description?: string;
   usage?: string;
@@ -631,6 +651,309 @@ export class NpmDocsEnhancer {
     }
   }
 
+  /**
+   * Resolve a package's entry file from its exports["."], module or main fields
+   */
+  // eslint-disable-next-line @typescript-eslint/no-explicit-any
+  public resolveEntryPoint(manifest: any): string {
+    // Conditional exports nest, e.g. { ".": { "import": { "default": "./index.mjs" } } }
+    const resolveTarget = (target: unknown): string | undefined => {
+      if (typeof target === 'string') return target;
+      if (!target || typeof target !== 'object') return undefined;
+      const conditions = target as Record<string, unknown>;
+      for (const condition of ['import', 'module', 'node', 'require', 'default']) {
+        const resolved = resolveTarget(conditions[condition]);
+        if (resolved) return resolved;
+      }
+      return undefined;
+    };
+
+    const exports = manifest?.exports;
+    const rootExport = typeof exports === 'object' && exports !== null && !Array.isArray(exports) && '.' in exports
+      ? exports['.']
+      : exports;
+
+    const entry = resolveTarget(rootExport) || manifest?.module || manifest?.main || 'index.js';
+    return String(entry).replace(/^\.\//, '');
+  }
+
+  /**
+   * Identify a package's primary export from its type definitions or entry file source: the default export,
+   * the CommonJS module.exports (or export = in type definitions), or failing those a named export
+   * matching the package name (e.g. createStore for create-store)
+   */
+  public findMainExport(source: string, packageName: string): MainExport | undefined {
+    const sourceFile = ts.createSourceFile('entry.ts', source, ts.ScriptTarget.Latest, true);
+    const declarations = new Map<string, MainExport['kind']>();
+    const exported = new Set<string>();
+    let primary: { name: string; kind?: MainExport['kind']; style: MainExport['style'] } | undefined;
+
+    const expressionKind = (expression: ts.Expression): MainExport['kind'] | undefined => {
+      if (ts.isArrowFunction(expression) || ts.isFunctionExpression(expression)) return 'function';
+      if (ts.isClassExpression(expression)) return 'class';
+      if (ts.isObjectLiteralExpression(expression)) return 'object';
+      return undefined;
+    };
+
+    ts.forEachChild(sourceFile, (node) => {
+      const modifiers = ts.canHaveModifiers(node) ? ts.getModifiers(node) || [] : [];
+      const isExport = modifiers.some(modifier => modifier.kind === ts.SyntaxKind.ExportKeyword);
+      const isDefault = modifiers.some(modifier => modifier.kind === ts.SyntaxKind.DefaultKeyword);
+
+      if (ts.isFunctionDeclaration(node) || ts.isClassDeclaration(node)) {
+        const kind = ts.isFunctionDeclaration(node) ? 'function' : 'class';
+        const name = node.name?.text;
+        if (name) {
+          declarations.set(name, kind);
+          if (isExport) exported.add(name);
+        }
+        if (isExport && isDefault) {
+          primary = { name: name || 'default', kind, style: 'default' };
+        }
+      } else if (ts.isModuleDeclaration(node) && ts.isIdentifier(node.name)) {
+        // A namespace merged with a function or class of the same name keeps the declaration's kind
+        if (!declarations.has(node.name.text)) declarations.set(node.name.text, 'namespace');
+        if (isExport) exported.add(node.name.text);
+      } else if (ts.isVariableStatement(node)) {
+        for (const declaration of node.declarationList.declarations) {
+          if (!ts.isIdentifier(declaration.name)) continue;
+          const initializer = declaration.initializer;
+          declarations.set(declaration.name.text, (initializer && expressionKind(initializer)) || 'variable');
+          if (isExport) exported.add(declaration.name.text);
+        }
+      } else if (ts.isExportAssignment(node)) {
+        // export default x, or export = x in CommonJS type definitions
+        const style = node.isExportEquals ? 'commonjs' : 'default';
+        primary = ts.isIdentifier(node.expression)
+          ? { name: node.expression.text, style }
+          : { name: 'default', kind: expressionKind(node.expression) || 'variable', style };
+      } else if (
+        ts.isExpressionStatement(node) &&
+        ts.isBinaryExpression(node.expression) &&
+        node.expression.operatorToken.kind === ts.SyntaxKind.EqualsToken &&
+        node.expression.left.getText(sourceFile) === 'module.exports'
+      ) {
+        const value = node.expression.right;
+        const valueName = (ts.isFunctionExpression(value) || ts.isClassExpression(value)) && value.name
+          ? value.name.text
+          : 'module.exports';
+        primary = ts.isIdentifier(value)
+          ? { name: value.text, style: 'commonjs' }
+          : { name: valueName, kind: expressionKind(value) || 'variable', style: 'commonjs' };
+      }
+    });
+
+    if (primary) {
+      return { name: primary.name, kind: primary.kind || declarations.get(primary.name) || 'variable', style: primary.style };
+    }
+
+    // Named exports only: look for one named after the package, e.g. createStore or CreateStore for create-store
+    const baseName = packageName.split('/').pop() || packageName;
+    const camelName = baseName.replace(/[-_.](\w)/g, (_, letter: string) => letter.toUpperCase());
+    for (const name of exported) {
+      if (name.toLowerCase() === camelName.toLowerCase()) {
+        return { name, kind: declarations.get(name) || 'variable', style: 'named' };
+      }
+    }
+
+    return undefined;
+  }
+
+  /**
+   * List the ambient modules a declaration file declares (declare module "fs" { ... }) with the names
+   * declared in each, as type definitions packages such as @types/node describe their package this way
+   */
+  public extractDeclaredModules(typesContent: string): Array<{ name: string; declarations: string[] }> {
+    const sourceFile = ts.createSourceFile('declarations.d.ts', typesContent, ts.ScriptTarget.Latest, true);
+    const modules: Array<{ name: string; declarations: string[] }> = [];
+
+    ts.forEachChild(sourceFile, (node) => {
+      if (!ts.isModuleDeclaration(node) || !ts.isStringLiteral(node.name)) {
+        return;
+      }
+
+      const declarations = new Set<string>();
+      if (node.body && ts.isModuleBlock(node.body)) {
+        for (const statement of node.body.statements) {
+          if (ts.isVariableStatement(statement)) {
+            for (const declaration of statement.declarationList.declarations) {
+              if (ts.isIdentifier(declaration.name)) declarations.add(declaration.name.text);
+            }
+          } else if (
+            (ts.isFunctionDeclaration(statement) || ts.isClassDeclaration(statement) ||
+              ts.isInterfaceDeclaration(statement) || ts.isTypeAliasDeclaration(statement) ||
+              ts.isEnumDeclaration(statement) || ts.isModuleDeclaration(statement)) &&
+            statement.name && ts.isIdentifier(statement.name)
+          ) {
+            declarations.add(statement.name.text);
+          }
+        }
+      }
+
+      modules.push({ name: node.name.text, declarations: Array.from(declarations) });
+    });
+
+    return modules;
+  }
+
+  /**
+   * Identify a package's primary export, from its type definitions when given and otherwise its entry file
+   */
+  // eslint-disable-next-line @typescript-eslint/no-explicit-any
+  public async identifyMainExport(packageName: string, manifest: any, typesContent?: string): Promise<MainExport | undefined> {
+    if (typesContent) {
+      const mainExport = this.findMainExport(typesContent, packageName);
+      if (mainExport) {
+        return mainExport;
+      }
+    }
+
+    const entry = this.resolveEntryPoint(manifest);
+    try {
+      const version = manifest?.version ? `@${manifest.version}` : '';
+      const response = await axios.get(`https://unpkg.com/${packageName}${version}/${entry}`, { responseType: 'text' });
+      return typeof response.data === 'string' ? this.findMainExport(response.data, packageName) : undefined;
+    } catch {
+      this.logger.debug(`Could not fetch entry file ${entry} for ${packageName}`);
+      return undefined;
+    }
+  }
+
+  /**
+   * Format a package's primary export as a single line, e.g. "Main export: `axios` (default export, function)"
+   */
+  public formatMainExport(mainExport: MainExport): string {
+    const style = {
+      default: 'default export',
+      commonjs: 'CommonJS module.exports',
+      named: 'named export',
+    }[mainExport.style];
+    return `Main export: \`${mainExport.name}\` (${style}, ${mainExport.kind})`;
+  }
+
+  /**
+   * Detect whether an HTML page was generated by TypeDoc or JSDoc, from its generator meta tag or markup
+   */
+  public detectApiDocGenerator(html: string): ApiDocGenerator | undefined {
+    const $ = cheerio.load(html);
+    const generator = $('meta[name="generator"]').attr('content') || '';
+
+    if (/typedoc/i.test(generator) || $('.tsd-page-toolbar, .tsd-navigation, .tsd-panel').length > 0) {
+      return 'typedoc';
+    }
+    if (/jsdoc/i.test(generator) || /generated by\s+JSDoc/i.test($('footer').text())) {
+      return 'jsdoc';
+    }
+    return undefined;
+  }
+
+  /**
+   * Extract the API members documented on a TypeDoc or JSDoc page. A class page gives one class with its
+   * methods and properties; other pages give a function or variable per documented member.
+   */
+  public extractGeneratedApiDocs(html: string, generator: ApiDocGenerator): ApiDocumentation[] {
+    const $ = cheerio.load(html);
+    const clean = (text: string) => text.replace(/\s+/g, ' ').trim();
+
+    // Each generator marks up a member as a heading with its name, a signature and a description
+    const members = (generator === 'typedoc' ? $('section.tsd-member') : $('h4.name')).toArray().map(element => {
+      const member = $(element);
+      if (generator === 'typedoc') {
+        const kind = (member.attr('class') || '').match(/tsd-kind-([\w-]+)/)?.[1] || '';
+        return {
+          name: clean(member.find('h3').first().text()).replace(/^[^\w$]+/, ''),
+          kind,
+          signature: clean(member.find('.tsd-signature').first().text()) || undefined,
+          description: clean(member.find('.tsd-comment').first().text()) || undefined,
+        };
+      }
+      // JSDoc puts the description in the next sibling rather than inside the member
+      const signature = clean(member.text());
+      return {
+        name: signature.split(/[\s(]/)[0].replace(/^[^\w$]+/, ''),
+        kind: member.prevAll('h3.subsection-title').first().text().toLowerCase().includes('method') ? 'method' : 'member',
+        signature,
+        description: clean(member.nextAll('.description').first().text()) || undefined,
+      };
+    }).filter(member => member.name);
+
+    const title = clean($('h1').first().text()) || clean($('header h2').first().text());
+    const classMatch = title.match(/^(?:Class|Interface)\s+([\w$]+)/);
+    if (classMatch) {
+      return [{
+        name: classMatch[1],
+        description: clean($('.tsd-comment, .class-description').first().text()) || undefined,
+        type: title.startsWith('Interface') ? 'interface' : 'class',
+        isExported: true,
+        methods: members
+          .filter(member => /method|constructor|function/.test(member.kind))
+          .map(member => ({ name: member.name, signature: member.signature, description: member.description })),
+        properties: members
+          .filter(member => !/method|constructor|function/.test(member.kind))
+          .map(member => ({ name: member.name, type: member.signature?.split(':').slice(1).join(':').trim() || undefined, description: member.description })),
+      }];
+    }
+
+    return members.map(member => ({
+      name: member.name,
+      description: member.description,
+      type: /method|function/.test(member.kind) ? 'function' : 'variable',
+      signature: member.signature,
+      isExported: true,
+    }));
+  }
+
+  /**
+   * Fetch API documentation from a package's hosted TypeDoc or JSDoc site, following the index page's
+   * links to class and function pages, a few at a time. Returns undefined when the page wasn't
+   * generated by either.
+   */
+  public async fetchHostedApiDocumentation(packageName: string, url: string): Promise<PackageApiDocumentation | undefined> {
+    try {
+      const response = await axios.get(url, { responseType: 'text' });
+      const html = String(response.data);
+      const generator = this.detectApiDocGenerator(html);
+      if (!generator) {
+        return undefined;
+      }
+
+      this.logger.debug(`Found ${generator} documentation for ${packageName} at ${url}`);
+      const result: PackageApiDocumentation = { packageName, exports: this.extractGeneratedApiDocs(html, generator), types: [] };
+
+      // TypeDoc puts each class and function on its own page; JSDoc links them from its navigation
+      const $ = cheerio.load(html);
+      const linkSelector = generator === 'typedoc'
+        ? 'a[href*="classes/"], a[href*="functions/"], a[href*="interfaces/"]'
+        : 'nav a[href$=".html"]';
+      const pages = new Set<string>();
+      $(linkSelector).each((_, link) => {
+        const href = $(link).attr('href');
+        if (href) {
+          pages.add(new URL(href, url).toString().split('#')[0]);
+        }
+      });
+
+      const gate = new ConcurrencyGate(API_DOC_PAGE_CONCURRENCY);
+      const pageDocs = await Promise.all(Array.from(pages).slice(0, MAX_API_DOC_PAGES).map(page => gate.run(async () => {
+        try {
+          const pageResponse = await axios.get(page, { responseType: 'text' });
+          return this.extractGeneratedApiDocs(String(pageResponse.data), generator);
+        } catch {
+          // Skip pages that can't be fetched
+          return [];
+        }
+      })));
+      for (const item of pageDocs.flat()) {
+        (item.type === 'interface' ? result.types : result.exports).push(item);
+      }
+
+      return result.exports.length > 0 || result.types.length > 0 ? result : undefined;
+    } catch (error) {
+      this.logger.debug(`Could not fetch hosted documentation for ${packageName} from ${url}: ${error}`);
+      return undefined;
+    }
+  }
+
   /**
    * Format API documentation as markdown
    */
@@ -2,7 +2,26 @@ import { NpmDocsEnhancer, PackageApiDocumentation } from './npm-docs-enhancer.js
 import { logger } from './logger.js';
 import axios from 'axios';
 import { readFileSync, existsSync } from 'fs';
-import { join } from 'path';
+import { join, posix } from 'path';
+import { extractNpmPlatforms, formatPlatforms } from './platform-utils.js';
+import { PackageSearch } from './package-search.js';
+import { ApiSymbol } from './api-diff.js';
+import { isTypesPackage, typedPackageName } from './package-names.js';
+import { GitPackageSpec, parseGitPackageSpec } from './utils/github-client.js';
+import { createRepoClient } from './utils/repo-client.js';
+import { findNpmWorkspacePackage } from './project-manifests.js';
+import { latestVersion, recentNpmVersions } from './dependency-versions.js';
+import { ConcurrencyGate } from './utils/concurrency-gate.js';
+import { DocFormat, DocSource, MarkdownTable, PackageMetadata, RelevanceProfile, SearchUtils, isDocFormat, isDocSource, isRelevanceProfile, truncateMarkdown, truncateText } from './search-utils.js';
+
+// Most declaration files read for a type definitions package, following /// <reference path> directives
+const MAX_TYPES_FILES = 40;
+
+// Declaration files read at once from unpkg or the local install
+const TYPES_FILE_CONCURRENCY = 6;
+
+// Names listed for each module a type definitions package declares, before the rest are counted
+const MAX_DECLARATIONS_LISTED = 15;
 
 // Enhanced version of NpmDocArgs interface
 export interface NpmDocArgs {
@@ -14,6 +33,11 @@ export interface NpmDocArgs {
   query?: string;
   includeTypes?: boolean; // Whether to include TypeScript type definitions
   includeExamples?: boolean; // Whether to include code examples
+  profile?: RelevanceProfile; // Which README sections describe keeps
+  includePrerelease?: boolean; // Without a version, read the newest version even if it's a prerelease
+  format?: DocFormat; // Rendered markdown (default) or structured metadata
+  source?: DocSource; // Where to look for documentation: "auto", "local" or "network"
+  includeRaw?: boolean; // Return the README verbatim as well as the summary
 }
 
 // Enhanced version of isNpmDocArgs function
@@ -35,10 +59,29 @@ export const isNpmDocArgs = (args: unknown): args is NpmDocArgs => {
     (typeof (args as NpmDocArgs).includeTypes === "boolean" ||
       (args as NpmDocArgs).includeTypes === undefined) &&
     (typeof (args as NpmDocArgs).includeExamples === "boolean" ||
-      (args as NpmDocArgs).includeExamples === undefined)
+      (args as NpmDocArgs).includeExamples === undefined) &&
+    (isRelevanceProfile((args as NpmDocArgs).profile) ||
+      (args as NpmDocArgs).profile === undefined) &&
+    (isDocFormat((args as NpmDocArgs).format) ||
+      (args as NpmDocArgs).format === undefined) &&
+    (isDocSource((args as NpmDocArgs).source) ||
+      (args as NpmDocArgs).source === undefined)
   );
 };
 
+/**
+ * Get the directory of a package available locally to a project: installed in its node_modules,
+ * or a workspace package of the monorepo, which may not be published to the registry at all
+ */
+export function localNpmPackagePath(packageName: string, projectPath?: string): string | undefined {
+  const basePath = projectPath || process.cwd();
+  const installed = join(basePath, "node_modules", packageName);
+  if (existsSync(join(installed, "package.json"))) {
+    return installed;
+  }
+  return findNpmWorkspacePackage(basePath, packageName);
+}
+
 // Interface for registry configuration
 export interface NpmConfig {
   registry: string;
@@ -54,6 +97,7 @@ export interface DocResult {
   searchResults?: SearchResults;
   suggestInstall?: boolean;
   apiDocumentation?: PackageApiDocumentation;
+  options?: MarkdownTable[]; // Configuration option and parameter tables from the README
 }
 
 // Interface for search results
@@ -76,9 +120,233 @@ export interface SearchResult {
 // Class to handle NPM package documentation
 export class NpmDocsHandler {
   private enhancer: NpmDocsEnhancer;
+  private packageSearch: PackageSearch;
+  private searchUtils: SearchUtils;
+  // README markdown converted from each fetched packument, so an operation only converts it once
+  private readmeCache: WeakMap<object, string>;
 
   constructor() {
     this.enhancer = new NpmDocsEnhancer(logger);
+    this.packageSearch = new PackageSearch(logger);
+    this.searchUtils = new SearchUtils(logger);
+    this.readmeCache = new WeakMap();
+  }
+
+  /**
+   * Fetch package metadata from the registry configured for the package
+   */
+  // eslint-disable-next-line @typescript-eslint/no-explicit-any
+  public async fetchPackageInfo(packageName: string, version: string | undefined, config: NpmConfig): Promise<any> {
+    const headers: Record<string, string> = {};
+    if (config.token) {
+      headers.Authorization = `Bearer ${config.token}`;
+    }
+
+    const versionSuffix = version ? `/${version}` : "";
+    const url = `${config.registry}/${packageName}${versionSuffix}`;
+
+    const response = await axios.get(url, { headers });
+    return response.data;
+  }
+
+  /**
+   * Get the version a call reads from the registry: the one given, or with includePrerelease the
+   * newest version, prereleases included, as the latest dist-tag is a stable release. Undefined
+   * leaves the registry's latest, as does a packument that can't be read.
+   */
+  private async registryVersion(packageName: string, args: NpmDocArgs, config: NpmConfig): Promise<string | undefined> {
+    if (args.version || !args.includePrerelease) {
+      return args.version;
+    }
+
+    try {
+      const version = latestVersion(recentNpmVersions(await this.fetchPackageInfo(packageName, undefined, config)), true);
+      logger.debug(`Newest version of ${packageName}, prereleases included: ${version}`);
+      return version;
+    } catch (error) {
+      logger.debug(`Could not resolve the newest version of ${packageName}: ${error}`);
+      return undefined;
+    }
+  }
+
+  /**
+   * Get structured metadata for a package version from the registry
+   */
+  public async getPackageMetadata(packageName: string, version: string | undefined, config: NpmConfig): Promise<PackageMetadata> {
+    const packageInfo = await this.fetchPackageInfo(packageName, version, config);
+    // A packument keeps per-version fields such as dependencies on its version manifests
+    const manifest = version ? packageInfo : packageInfo.versions?.[packageInfo["dist-tags"]?.latest] || packageInfo;
+    return this.toPackageMetadata(packageName, manifest);
+  }
+
+  /**
+   * Get structured metadata for several versions of a package from a single registry request,
+   * as the packument already includes every version's manifest. Versions may also be dist-tags.
+   */
+  public async getVersionsMetadata(packageName: string, versions: string[], config: NpmConfig): Promise<PackageMetadata[]> {
+    const packageInfo = await this.fetchPackageInfo(packageName, undefined, config);
+
+    return versions.map(version => {
+      const resolved = packageInfo["dist-tags"]?.[version] || version.replace(/^v/, "");
+      const manifest = packageInfo.versions?.[resolved];
+      if (!manifest) {
+        throw new Error(`Version ${version} of ${packageName} not found in the registry`);
+      }
+      return this.toPackageMetadata(packageName, manifest);
+    });
+  }
+
+  /**
+   * Get the exported symbols of a package version (the latest by default) from its TypeScript
+   * definitions, or undefined when the version doesn't ship any
+   */
+  public async getApiSymbols(packageName: string, version?: string): Promise<ApiSymbol[] | undefined> {
+    const typesContent = await this.enhancer.fetchTypeDefinition(packageName, version);
+    if (!typesContent) {
+      return undefined;
+    }
+
+    const apiDocumentation = await this.enhancer.extractApiDocumentation(packageName, typesContent);
+    return [...apiDocumentation.exports, ...apiDocumentation.types].map(item => ({
+      name: item.name,
+      kind: item.type,
+      signature: item.signature || item.typeDefinition,
+    }));
+  }
+
+  /**
+   * Map a version manifest to structured metadata
+   */
+  // eslint-disable-next-line @typescript-eslint/no-explicit-any
+  private toPackageMetadata(packageName: string, manifest: any): PackageMetadata {
+    const repository = typeof manifest.repository === "string" ? manifest.repository : manifest.repository?.url;
+
+    return {
+      name: manifest.name || packageName,
+      version: manifest.version,
+      description: manifest.description,
+      license: typeof manifest.license === "string" ? manifest.license : manifest.license?.type,
+      homepage: manifest.homepage,
+      repository,
+      keywords: manifest.keywords,
+      dependencies: manifest.dependencies,
+      deprecated: manifest.deprecated,
+    };
+  }
+
+  /**
+   * Add the description notes, option tables, usage and examples from a package's README to a describe result
+   */
+  private addReadmeSections(result: DocResult, readme: string, profile?: RelevanceProfile): void {
+    const languageNote = this.searchUtils.languageNote(readme);
+    if (languageNote) {
+      result.description += `\n\n${languageNote}`;
+    }
+
+    const options = this.searchUtils.extractOptionTables(readme);
+    if (options.length > 0) {
+      result.options = options;
+    }
+
+    const signals = this.searchUtils.extractBadgeSignals(readme);
+    if (signals.length > 0) {
+      result.signals = signals;
+    }
+
+    const sections = readme.split(/#+\s/);
+
+    for (const section of sections) {
+      const lower = section.toLowerCase();
+      if (lower.startsWith("usage") || lower.startsWith("getting started")) {
+        // Truncate usage section to a reasonable length
+        const usage = section.split("\n").slice(1).join("\n").trim();
+        result.usage = truncateText(usage, 1000);
+      } else if (lower.startsWith("example")) {
+        // Truncate example section to a reasonable length
+        const example = section.split("\n").slice(1).join("\n").trim();
+        result.example = truncateText(example, 1000);
+      }
+    }
+
+    // An explicit profile replaces the usage section with the README sections relevant to that audience
+    if (profile) {
+      const relevant = this.searchUtils.extractRelevantContent(readme, profile);
+      result.usage = truncateText(relevant, 5000);
+    }
+  }
+
+  /**
+   * Fetch the package.json and README of a package installed from a git repository
+   */
+  // eslint-disable-next-line @typescript-eslint/no-explicit-any
+  private async fetchGitPackage(spec: GitPackageSpec): Promise<{ location: string; manifest: any; readme?: string }> {
+    const location = `${spec.repositoryUrl}${spec.ref ? `#${spec.ref}` : ""}`;
+    logger.debug(`Fetching NPM documentation for ${location} from its repository`);
+
+    const client = createRepoClient(spec.repositoryUrl, logger);
+    const [packageJson, readme] = await Promise.all([
+      client?.getFile("package.json", spec.ref),
+      client?.getReadme(spec.ref),
+    ]);
+
+    // eslint-disable-next-line @typescript-eslint/no-explicit-any
+    let manifest: any = {};
+    try {
+      manifest = packageJson ? JSON.parse(packageJson.content) : {};
+    } catch {
+      logger.debug(`Invalid package.json in ${location}`);
+    }
+
+    return { location, manifest, readme };
+  }
+
+  /**
+   * Describe a package installed from git by its package.json, noting where it comes from
+   */
+  // eslint-disable-next-line @typescript-eslint/no-explicit-any
+  private describeGitManifest(spec: GitPackageSpec, location: string, manifest: any): string {
+    const description = manifest.description || `npm package from ${spec.repositoryUrl}`;
+    return `${description}\n\nInstalled from git: ${location}${manifest.name ? ` (package name ${manifest.name})` : ""}`;
+  }
+
+  /**
+   * Describe a package installed from a git repository, using the package.json and README in the repository
+   */
+  private async describeGitPackage(spec: GitPackageSpec, profile?: RelevanceProfile): Promise<DocResult> {
+    const { location, manifest, readme } = await this.fetchGitPackage(spec);
+    if (!readme && !manifest.name) {
+      return {
+        error: `Could not fetch documentation for ${location}. Only GitHub and GitLab repositories are supported`,
+      };
+    }
+
+    const result: DocResult = {
+      description: this.describeGitManifest(spec, location, manifest)
+    };
+    if (readme) {
+      this.addReadmeSections(result, readme, profile);
+    }
+
+    return result;
+  }
+
+  /**
+   * Get a package's README as markdown, converting any HTML only the first time it's requested
+   */
+  // eslint-disable-next-line @typescript-eslint/no-explicit-any
+  public getReadmeMarkdown(packageInfo: any): string | undefined {
+    if (!packageInfo?.readme) {
+      return undefined;
+    }
+
+    const cached = this.readmeCache.get(packageInfo);
+    if (cached !== undefined) {
+      return cached;
+    }
+
+    const readme = this.enhancer.convertHtmlToMarkdown(packageInfo.readme);
+    this.readmeCache.set(packageInfo, readme);
+    return readme;
   }
 
   /**
@@ -91,12 +359,12 @@ export class NpmDocsHandler {
     isNpmPackageInstalledLocally: (packageName: string, projectPath?: string) => boolean,
     getLocalNpmDoc: (packageName: string, projectPath?: string) => DocResult
   ): Promise<DocResult> {
-    const { package: packageName, version, projectPath, includeTypes = true, includeExamples = true } = args;
-    logger.debug(`Getting NPM documentation for ${packageName}${version ? `@${version}` : ""}`);
+    const { package: packageName, projectPath, includeTypes = true, includeExamples = true, profile, source = "auto" } = args;
+    logger.debug(`Getting NPM documentation for ${packageName}${args.version ? `@${args.version}` : ""}`);
 
     try {
-      // Check if package is installed locally first
-      const isInstalled = isNpmPackageInstalledLocally(packageName, projectPath);
+      // Check if package is installed locally first, unless the registry was requested
+      const isInstalled = source !== "network" && isNpmPackageInstalledLocally(packageName, projectPath);
       // eslint-disable-next-line @typescript-eslint/no-explicit-any
       let packageInfo: any;
       let apiDocumentation: PackageApiDocumentation | undefined;
@@ -104,15 +372,25 @@ export class NpmDocsHandler {
 
       if (isInstalled) {
         logger.debug(`Using local documentation for ${packageName}`);
+
+        // Type definitions packages are described from their declarations, as their READMEs say little
+        if (isTypesPackage(packageName)) {
+          const packagePath = localNpmPackagePath(packageName, projectPath) as string;
+          const manifest = JSON.parse(readFileSync(join(packagePath, "package.json"), "utf-8"));
+          return await this.describeTypesPackage(packageName, manifest, async (path) => {
+            const filePath = join(packagePath, path);
+            return existsSync(filePath) ? readFileSync(filePath, "utf-8") : undefined;
+          });
+        }
+
         const localDoc = getLocalNpmDoc(packageName, projectPath);
 
         // Try to extract TypeScript definitions from local installation
         if (includeTypes) {
-          const basePath = projectPath || process.cwd();
-          const packagePath = join(basePath, "node_modules", packageName);
-          const packageJsonPath = join(packagePath, "package.json");
+          const packagePath = localNpmPackagePath(packageName, projectPath);
+          const packageJsonPath = packagePath && join(packagePath, "package.json");
 
-          if (existsSync(packageJsonPath)) {
+          if (packagePath && packageJsonPath && existsSync(packageJsonPath)) {
             packageInfo = JSON.parse(readFileSync(packageJsonPath, "utf-8"));
 
             // Check for TypeScript definitions
@@ -136,54 +414,67 @@ export class NpmDocsHandler {
         return localDoc;
       }
 
+      if (source === "local") {
+        return {
+          error: `${packageName} is not installed in ${projectPath || "the current directory"}. Install it, or use source "auto" or "network"`,
+          suggestInstall: true
+        };
+      }
+
+      // Packages installed from a git repository aren't in the registry
+      const gitSpec = parseGitPackageSpec(packageName);
+      if (gitSpec) {
+        return await this.describeGitPackage(gitSpec, profile);
+      }
+
       // If not installed, fetch from npm registry
       logger.debug(`Fetching NPM documentation for ${packageName} from registry`);
 
       try {
         const config = getRegistryConfigForPackage(packageName, projectPath);
-        const headers: Record<string, string> = {};
-        if (config.token) {
-          headers.Authorization = `Bearer ${config.token}`;
-        }
-
-        const versionSuffix = version ? `/${version}` : "";
-        const url = `${config.registry}/${packageName}${versionSuffix}`;
-
-        const response = await axios.get(url, { headers });
-        packageInfo = response.data;
+        const version = await this.registryVersion(packageName, args, config);
+        packageInfo = await this.fetchPackageInfo(packageName, version, config);
 
         if (packageInfo) {
           const result: DocResult = {
             description: packageInfo.description || "No description available"
           };
 
+          // os/cpu live on the version manifest, not the top level of the packument
+          const manifest = version ? packageInfo : packageInfo.versions?.[packageInfo["dist-tags"]?.latest] || packageInfo;
+          if (args.format === "json") {
+            result.metadata = this.toPackageMetadata(packageName, manifest);
+          }
+
+          if (isTypesPackage(packageName)) {
+            const typesResult = await this.describeTypesPackage(packageName, manifest, (path) =>
+              this.fetchUnpkgFile(packageName, manifest.version, path)
+            );
+            return {
+              ...typesResult,
+              metadata: result.metadata,
+              readme: args.includeRaw ? this.getReadmeMarkdown(packageInfo) : undefined
+            };
+          }
+
+          const platformLine = formatPlatforms(extractNpmPlatforms(manifest));
+          if (platformLine) {
+            result.description += `\n\n${platformLine}`;
+          }
+
           // Extract usage and examples from README if available
-          if (packageInfo.readme) {
-            // Convert HTML to Markdown if needed
-            const readme = this.enhancer.convertHtmlToMarkdown(packageInfo.readme);
-            const sections = readme.split(/#+\s/);
-
-            for (const section of sections) {
-              const lower = section.toLowerCase();
-              if (lower.startsWith("usage") || lower.startsWith("getting started")) {
-                // Truncate usage section to a reasonable length
-                const usage = section.split("\n").slice(1).join("\n").trim();
-                result.usage = usage.length > 1000
-                  ? usage.substring(0, 1000) + "... (truncated)"
-                  : usage;
-              } else if (lower.startsWith("example")) {
-                // Truncate example section to a reasonable length
-                const example = section.split("\n").slice(1).join("\n").trim();
-                result.example = example.length > 1000
-                  ? example.substring(0, 1000) + "... (truncated)"
-                  : example;
-              }
+          const readme = this.getReadmeMarkdown(packageInfo);
+          if (readme) {
+            this.addReadmeSections(result, readme, profile);
+            if (args.includeRaw) {
+              result.readme = readme;
             }
           }
 
           // Fetch TypeScript definitions from unpkg.com if requested
+          let typesContent: string | undefined;
           if (includeTypes) {
-            const typesContent = await this.enhancer.fetchTypeDefinition(packageName, version);
+            typesContent = await this.enhancer.fetchTypeDefinition(packageName, version);
 
             if (typesContent) {
               apiDocumentation = await this.enhancer.extractApiDocumentation(packageName, typesContent);
@@ -196,6 +487,25 @@ export class NpmDocsHandler {
             }
           }
 
+          // Without type definitions, fall back to API documentation hosted on a TypeDoc or JSDoc site
+          if (includeTypes && !apiDocumentation?.exports.length) {
+            const docsUrl = [manifest.documentation, manifest.homepage, packageInfo.homepage]
+              .find(url => typeof url === "string" && /^https?:\/\//.test(url) && !/github\.com|gitlab\.com|npmjs\.com/.test(url));
+            if (docsUrl) {
+              const hostedApi = await this.enhancer.fetchHostedApiDocumentation(packageName, docsUrl);
+              if (hostedApi) {
+                const apiMarkdown = this.enhancer.formatApiDocumentationAsMarkdown(hostedApi);
+                result.usage = result.usage ? `${result.usage}\n\n${apiMarkdown}` : apiMarkdown;
+              }
+            }
+          }
+
+          // Lead with the package's primary export, so it's clear what importing the package gives you
+          const mainExport = await this.enhancer.identifyMainExport(packageName, manifest, typesContent);
+          if (mainExport) {
+            result.description = `${this.enhancer.formatMainExport(mainExport)}\n\n${result.description}`;
+          }
+
           // Fetch examples from unpkg.com if requested
           if (includeExamples) {
             examples = await this.enhancer.fetchExamples(packageName, version);
@@ -218,8 +528,10 @@ export class NpmDocsHandler {
         }
       } catch (error) {
         if (axios.isAxiosError(error) && error.response?.status === 404) {
+          const { registry } = getRegistryConfigForPackage(packageName, projectPath);
+          const suggestion = await this.packageSearch.suggestAlternatives("npm", packageName, registry);
           return {
-            error: `Package ${packageName} not found. Try installing it with 'npm install ${packageName}'`,
+            error: `Package ${packageName} not found.${suggestion ? ` ${suggestion}` : ""} Try installing it with 'npm install ${packageName}'`,
             suggestInstall: true
           };
         }
@@ -234,6 +546,100 @@ export class NpmDocsHandler {
     }
   }
 
+  /**
+   * Describe a type definitions package (@types/*) from the declaration files it bundles rather than
+   * its README, which only says where the types came from: the modules and API it declares for the
+   * package it types
+   */
+  private async describeTypesPackage(
+    packageName: string,
+    // eslint-disable-next-line @typescript-eslint/no-explicit-any
+    manifest: any,
+    readFile: (path: string) => Promise<string | undefined>
+  ): Promise<DocResult> {
+    const typedPackage = typedPackageName(packageName);
+    const entry = manifest.types || manifest.typings || "index.d.ts";
+    const files = await this.collectTypeDeclarations(entry.endsWith(".ts") ? entry : `${entry}.d.ts`, readFile);
+
+    const result: DocResult = {
+      description: `Type definitions for ${typedPackage}${manifest.version ? ` (${packageName}@${manifest.version})` : ""}. ` +
+        `They describe ${typedPackage}'s API to TypeScript; install ${typedPackage} itself for the implementation.`
+    };
+    if (files.length === 0) {
+      result.error = `No declaration files found in ${packageName}`;
+      return result;
+    }
+
+    const declarations = files.join("\n");
+    const sections: string[] = [];
+
+    // Packages like @types/node declare one ambient module per module of the package
+    const modules = this.enhancer.extractDeclaredModules(declarations).filter(module => module.declarations.length > 0);
+    if (modules.length > 0) {
+      sections.push(`## Declared modules (${modules.length})\n\n` + modules.map(module => {
+        const shown = module.declarations.slice(0, MAX_DECLARATIONS_LISTED).map(name => `\`${name}\``).join(", ");
+        const more = module.declarations.length - MAX_DECLARATIONS_LISTED;
+        return `- \`${module.name}\`: ${shown}${more > 0 ? ` and ${more} more` : ""}`;
+      }).join("\n"));
+    }
+
+    const apiDocumentation = await this.enhancer.extractApiDocumentation(typedPackage, declarations);
+    if (apiDocumentation.exports.length > 0 || apiDocumentation.types.length > 0) {
+      sections.push(this.enhancer.formatApiDocumentationAsMarkdown(apiDocumentation));
+    }
+
+    if (sections.length > 0) {
+      result.usage = sections.join("\n\n");
+    }
+    return result;
+  }
+
+  /**
+   * Read a declaration file and the files it pulls in with /// <reference path="..." /> directives,
+   * breadth first, up to MAX_TYPES_FILES files. The files each one references are read in parallel,
+   * TYPES_FILE_CONCURRENCY at a time. References outside the package are skipped.
+   */
+  private async collectTypeDeclarations(entry: string, readFile: (path: string) => Promise<string | undefined>): Promise<string[]> {
+    const gate = new ConcurrencyGate(TYPES_FILE_CONCURRENCY);
+    const files: string[] = [];
+    let level = [posix.normalize(entry)];
+    const seen = new Set(level);
+
+    while (level.length > 0 && files.length < MAX_TYPES_FILES) {
+      const paths = level.slice(0, MAX_TYPES_FILES - files.length);
+      const contents = await Promise.all(paths.map(path => gate.run(() => readFile(path))));
+      level = [];
+
+      paths.forEach((path, index) => {
+        const content = contents[index];
+        if (!content) return;
+        files.push(content);
+
+        for (const match of content.matchAll(/^\/\/\/\s*<reference\s+path=["']([^"']+)["']/gm)) {
+          const referenced = posix.normalize(posix.join(posix.dirname(path), match[1]));
+          if (!referenced.startsWith("..") && !seen.has(referenced)) {
+            seen.add(referenced);
+            level.push(referenced);
+          }
+        }
+      });
+    }
+
+    return files;
+  }
+
+  /**
+   * Fetch a file from a published package version on unpkg, or undefined when it can't be fetched
+   */
+  private async fetchUnpkgFile(packageName: string, version: string | undefined, path: string): Promise<string | undefined> {
+    try {
+      const response = await axios.get(`https://unpkg.com/${packageName}${version ? `@${version}` : ""}/${path}`, { responseType: "text" });
+      return typeof response.data === "string" ? response.data : undefined;
+    } catch {
+      return undefined;
+    }
+  }
+
   /**
    * Get full documentation for an NPM package
    * Enhanced to provide comprehensive information for LLMs
@@ -246,7 +652,6 @@ export class NpmDocsHandler {
   ): Promise<DocResult> {
     const {
       package: packageName,
-      version,
       projectPath,
       section,
       maxLength = 20000,
@@ -255,7 +660,7 @@ export class NpmDocsHandler {
       includeExamples = true
     } = args;
 
-    logger.debug(`Getting full NPM documentation for ${packageName}${version ? `@${version}` : ""}`);
+    logger.debug(`Getting full NPM documentation for ${packageName}${args.version ? `@${args.version}` : ""}`);
 
     try {
       // Check if package is installed locally first
@@ -271,11 +676,10 @@ export class NpmDocsHandler {
 
         // Try to extract TypeScript definitions from local installation
         if (includeTypes) {
-          const basePath = projectPath || process.cwd();
-          const packagePath = join(basePath, "node_modules", packageName);
-          const packageJsonPath = join(packagePath, "package.json");
+          const packagePath = localNpmPackagePath(packageName, projectPath);
+          const packageJsonPath = packagePath && join(packagePath, "package.json");
 
-          if (existsSync(packageJsonPath)) {
+          if (packagePath && packageJsonPath && existsSync(packageJsonPath)) {
             packageInfo = JSON.parse(readFileSync(packageJsonPath, "utf-8"));
 
             // Check for TypeScript definitions
@@ -294,21 +698,26 @@ export class NpmDocsHandler {
         return localDoc;
       }
 
+      // Packages installed from a git repository aren't in the registry
+      const gitSpec = parseGitPackageSpec(packageName);
+      if (gitSpec) {
+        const gitPackage = await this.fetchGitPackage(gitSpec);
+        if (!gitPackage.readme) {
+          return { error: `No README found for ${gitPackage.location}` };
+        }
+        return {
+          description: this.describeGitManifest(gitSpec, gitPackage.location, gitPackage.manifest),
+          usage: truncateMarkdown(gitPackage.readme, maxLength),
+        };
+      }
+
       // If not installed, fetch from npm registry
       logger.debug(`Fetching NPM documentation for ${packageName} from registry`);
 
       try {
         const config = getRegistryConfigForPackage(packageName, projectPath);
-        const headers: Record<string, string> = {};
-        if (config.token) {
-          headers.Authorization = `Bearer ${config.token}`;
-        }
-
-        const versionSuffix = version ? `/${version}` : "";
-        const url = `${config.registry}/${packageName}${versionSuffix}`;
-
-        const response = await axios.get(url, { headers });
-        packageInfo = response.data;
+        const version = await this.registryVersion(packageName, args, config);
+        packageInfo = await this.fetchPackageInfo(packageName, version, config);
 
         if (!packageInfo) {
           return {
@@ -401,9 +810,12 @@ export class NpmDocsHandler {
         }
 
         // Process README content if available
-        if (packageInfo.readme) {
-          // Convert HTML to Markdown if needed
-          const readme = this.enhancer.convertHtmlToMarkdown(packageInfo.readme);
+        const readme = this.getReadmeMarkdown(packageInfo);
+        if (readme) {
+          const languageNote = this.searchUtils.languageNote(readme);
+          if (languageNote) {
+            result.description += `\n\n${languageNote}`;
+          }
 
           // If a specific section was requested
           if (section) {
@@ -423,7 +835,7 @@ export class NpmDocsHandler {
             let found = false;
             for (const sectionVar of sectionVariations) {
               const sectionRegex = new RegExp(`#+\\s+.*${sectionVar}.*(?:\\s|$|:)([\\s\\S]*?)(?:#+\\s+|$)`, 'i');
-              const match = readme ? readme.match(sectionRegex) : null;
+              const match = readme.match(sectionRegex);
 
               if (match && match[1]) {
                 result.usage = match[1].trim();
@@ -438,8 +850,8 @@ export class NpmDocsHandler {
               result.usage = formattedDoc;
             }
           }
-          // If a search query was provided
-          else if (query && readme) {
+          // If a search query was provided (a blank one is ignored rather than matching every line)
+          else if (query?.trim()) {
             const lines = readme.split('\n');
             const matchingLines: string[] = [];
             const matchedSections: Set<number> = new Set();
@@ -480,9 +892,7 @@ export class NpmDocsHandler {
 
             if (matchingLines.length > 0) {
               const content = matchingLines.join('\n');
-              result.usage = content.length > maxLength
-                ? content.substring(0, maxLength) + "... (truncated)"
-                : content;
+              result.usage = truncateMarkdown(content, maxLength);
             } else {
               result.error = `No matches found for '${query}' in documentation`;
               // Still provide the formatted doc as usage
@@ -498,9 +908,9 @@ export class NpmDocsHandler {
           result.usage = formattedDoc;
         }
 
-        // Truncate if necessary
-        if (result.usage && result.usage.length > maxLength) {
-          result.usage = result.usage.substring(0, maxLength) + "... (truncated)";
+        // Truncate if necessary, dropping whole trailing sections rather than cutting one short
+        if (result.usage) {
+          result.usage = truncateMarkdown(result.usage, maxLength);
         }
 
         // Always include the full formatted documentation in the result
@@ -7,19 +7,37 @@ import {
   McpError,
 } from "@modelcontextprotocol/sdk/types.js"
 import { getToolDefinitions } from "./tool-handlers.js"
-import { execFile } from "child_process"
-import { promisify } from "util"
 import axios from "axios"
 import { fileURLToPath } from "url"
-import { dirname, join } from "path"
+import { basename, delimiter, dirname, join } from "path"
 import { readFileSync, existsSync } from "fs"
 import { logger, McpLogger } from './logger.js'
-import { NpmDocsHandler, NpmDocArgs, isNpmDocArgs } from './npm-docs-integration.js'
-import { SearchUtils, DocResult, SearchDocArgs, GoDocArgs, PythonDocArgs, SwiftDocArgs, isSearchDocArgs, isGoDocArgs, isPythonDocArgs, isSwiftDocArgs } from './search-utils.js'
+import { NpmDocsHandler, NpmDocArgs, isNpmDocArgs, localNpmPackagePath } from './npm-docs-integration.js'
+import { SearchUtils, DocResult, SearchDocArgs, GoDocArgs, PythonDocArgs, SwiftDocArgs, SearchPackagesArgs, PackageDocArgs, ConfigDocArgs, ExamplesArgs, CompatibilityArgs, CompareVersionsArgs, ApiDiffArgs, SymbolListArgs, ProjectDependenciesArgs, ChangelogArgs, MigrationGuideArgs, PackageDependenciesArgs, DependencyTreeNode, SchemaArgs, DocSection, DocSource, PackageMetadata, SearchHit, isSearchDocArgs, isSearchPackagesArgs, isPackageDocArgs, isConfigDocArgs, isExamplesArgs, isCompatibilityArgs, isCompareVersionsArgs, isApiDiffArgs, isSymbolListArgs, isProjectDependenciesArgs, isChangelogArgs, isMigrationGuideArgs, isPackageDependenciesArgs, isSchemaArgs, isGoDocArgs, isPythonDocArgs, isSwiftDocArgs, clampContextSize, formatDocSection, formatMarkdownTable, formatSignals, highlightMatches, parseSearchQuery, stripEmoji, truncateMarkdown, truncateSections, truncateText } from './search-utils.js'
 import Fuse from "fuse.js"
+import { SectionSearchPool } from "./section-search.js"
 import { RegistryUtils } from './registry-utils.js'
 import TypeScriptLspClient from "./lsp/typescript-lsp-client.js"
 import { RustDocsHandler } from "./rust-docs-integration.js"
+import { PhpDocsHandler, isPhpDocArgs } from "./php-docs-integration.js"
+import { JavaDocsHandler, isJavaDocArgs } from "./java-docs-integration.js"
+import { DotnetDocsHandler, isDotnetDocArgs } from "./dotnet-docs-integration.js"
+import { extractPythonPlatforms, formatPlatforms } from "./platform-utils.js"
+import { formatPyPICompatibility } from "./pypi-classifiers.js"
+import { PackageSearch } from "./package-search.js"
+import { createRepoClient, RepoFile } from "./utils/repo-client.js"
+import { isCommandAllowed, runCommand, ToolNotInstalledError } from "./utils/command-runner.js"
+import { RepositoryRef, getGoRepositoryUrl, parseRepositoryUrl } from "./utils/github-client.js"
+import { withRetryBudget } from "./utils/retry-budget.js"
+import { extractHtmlCodeBlocks, extractHtmlTables, extractMainContent, extractPkgGoDevDocs, extractSphinxApi, findLinkedPages, isSphinxPage, resolveRelativeLinks } from "./utils/html-content.js"
+import { ConcurrencyGate } from "./utils/concurrency-gate.js"
+import { withParseCache } from "./utils/parse-cache.js"
+import { isRestructuredText, rstToMarkdown } from "./utils/rst-markdown.js"
+import { formatVersionComparison } from "./version-compare.js"
+import { isGoStandardLibrary, normalizePackageArgs, normalizePyPIName, pkgGoDevUrl, pypiJsonUrl } from "./package-names.js"
+import { escapeGoModulePath, findCargoLockVersion, findGoModRequirement, findNpmLockVersion, findPythonLockVersion, latestVersion, maxSatisfyingVersion, pinnedVersion, recentNpmVersions, splitPackageSpec } from "./dependency-versions.js"
+import { ProjectEcosystem, cargoDependencyNames, findGoModFiles, findProjectDependencies, formatSwiftConstraint, goModulePath, isSameSwiftPackage, parsePackageSwift } from "./project-manifests.js"
+import { ApiSymbol, diffApiSymbols, formatApiDiff, formatSymbolList, parseGoApiSymbols, parseGoShortSymbols, parsePydocSymbols } from "./api-diff.js"
 
 const __filename = fileURLToPath(import.meta.url)
 const __dirname = dirname(__filename)
@@ -27,7 +45,127 @@ const packageJson = JSON.parse(
   readFileSync(join(__dirname, "..", "package.json"), "utf-8"),
 )
 
-const execFileAsync = promisify(execFile)
+// File names commonly used for changelogs, in the order they're tried
+const CHANGELOG_FILE_NAMES = ["CHANGELOG.md", "CHANGES.md", "HISTORY.md", "RELEASES.md", "NEWS.md", "CHANGELOG", "CHANGES.rst", "HISTORY.rst"]
+
+// File names commonly used for migration and upgrade guides, in the order they're tried
+const MIGRATION_FILE_NAMES = ["MIGRATION.md", "MIGRATING.md", "UPGRADING.md", "UPGRADE.md", "docs/migration.md", "docs/MIGRATION.md", "docs/upgrading.md"]
+
+// How many dependencies describe_project_dependencies looks up at once
+const PROJECT_DEPENDENCY_CONCURRENCY = 8
+
+// Schema files get_schema looks for when the repository can't be listed, in order of preference
+const SCHEMA_FILE_NAMES = [
+  "openapi.yaml", "openapi.yml", "openapi.json", "swagger.yaml", "swagger.yml", "swagger.json",
+  "api/openapi.yaml", "api/openapi.json", "docs/openapi.yaml", "docs/openapi.json", "schema.json",
+]
+
+// Directories commonly holding a repository's API specs and schemas
+const SCHEMA_DIRECTORIES = ["api", "docs", "spec", "specs", "schema", "schemas", "openapi"]
+
+// File names that look like an OpenAPI or Swagger spec, or a JSON Schema
+const SCHEMA_FILE_PATTERN = /^(?:openapi|swagger)[\w.-]*\.(?:ya?ml|json)$|(?:^|[.-])schema\.json$/i
+
+// Default and maximum depth and size of get_package_dependencies trees
+const DEFAULT_DEPENDENCY_DEPTH = 2
+const MAX_DEPENDENCY_DEPTH = 10
+const DEFAULT_DEPENDENCY_NODES = 100
+const MAX_DEPENDENCY_NODES = 500
+
+// Languages of the describe tools, used to look up structured metadata when format is "json"
+const DESCRIBE_TOOL_LANGUAGES: Record<string, string> = {
+  describe_go_package: "go",
+  lookup_go_doc: "go",
+  describe_python_package: "python",
+  lookup_python_doc: "python",
+  describe_npm_package: "npm",
+  lookup_npm_doc: "npm",
+  describe_swift_package: "swift",
+  describe_rust_package: "rust",
+  describe_php_package: "php",
+  describe_java_package: "java",
+  describe_dotnet_package: "dotnet",
+}
+
+// Kinds of link in PyPI's project_urls. Projects choose their own labels, so each kind is matched
+// loosely, e.g. "Bug Tracker", "Issues" and "Issue tracker" are all issue links. Source links are
+// matched on whole words, so "Code of Conduct" isn't taken for the source code
+const PYPI_LINK_KINDS: Array<[string, RegExp]> = [
+  ["Documentation", /doc/i],
+  ["Source", /^(?!.*\bconduct\b).*\b(source|repository|repo|code|github|gitlab)\b/i],
+  ["Issues", /issue|bug|tracker/i],
+  ["Changelog", /change|history|release|news/i],
+  ["Homepage", /home/i],
+  ["Funding", /fund|donat|sponsor/i],
+]
+
+/**
+ * Get a PyPI project's links from its project_urls, home_page and docs_url, labelled by kind
+ * (keeping the first link of each kind) and with any other links under their own labels
+ */
+// eslint-disable-next-line @typescript-eslint/no-explicit-any
+function getPyPILinks(info: any): Record<string, string> {
+  const links: Record<string, string> = {}
+  const entries: Array<[string, string]> = Object.entries(info?.project_urls || {})
+  if (info?.home_page) entries.push(["Homepage", info.home_page])
+  if (info?.docs_url) entries.push(["Documentation", info.docs_url])
+
+  for (const [label, url] of entries) {
+    if (typeof url !== "string" || !/^https?:\/\//.test(url)) continue
+    const kind = PYPI_LINK_KINDS.find(([, pattern]) => pattern.test(label))?.[0] || label
+    if (!links[kind]) links[kind] = url
+  }
+
+  return links
+}
+
+/**
+ * Get a PyPI project's long description as markdown, converted from reStructuredText when that's
+ * what the project uploaded
+ */
+// eslint-disable-next-line @typescript-eslint/no-explicit-any
+function getPyPIDescription(info: any): string {
+  const description: string = info?.description || ""
+  return isRestructuredText(info?.description_content_type, description) ? rstToMarkdown(description) : description
+}
+
+/**
+ * Get the requirements of a PyPI release, leaving out those of extras. With unconditional set,
+ * requirements for some environments only (e.g. "; python_version < '3.8'") are left out too.
+ */
+// eslint-disable-next-line @typescript-eslint/no-explicit-any
+function getPyPIDependencies(info: any, unconditional = false): Record<string, string> {
+  // requires_dist entries look like "idna (<4,>=2.5)" or "urllib3>=1.21; extra == 'socks'"
+  const dependencies: Record<string, string> = {}
+  for (const requirement of info.requires_dist || []) {
+    if (requirement.includes("extra ==") || (unconditional && requirement.includes(";"))) continue
+    const match = requirement.match(/^([A-Za-z0-9._-]+)\s*(?:\[[^\]]*\])?\s*\(?([^;)]*)\)?/)
+    if (match) dependencies[match[1]] = match[2].trim() || "*"
+  }
+  return dependencies
+}
+
+/**
+ * Map a PyPI release's info to structured metadata
+ */
+// eslint-disable-next-line @typescript-eslint/no-explicit-any
+function getPyPIMetadata(info: any): PackageMetadata {
+  const links = getPyPILinks(info)
+  const dependencies = getPyPIDependencies(info)
+
+  return {
+    name: info.name,
+    version: info.version,
+    description: info.summary,
+    license: info.license || undefined,
+    homepage: links.Homepage,
+    repository: links.Source,
+    documentation: links.Documentation,
+    keywords: info.keywords ? info.keywords.split(/[,\s]+/).filter(Boolean) : undefined,
+    dependencies,
+    deprecated: info.yanked ? info.yanked_reason || "Yanked" : undefined,
+  }
+}
 
 /**
  * Sanitise input to prevent command injection
@@ -38,48 +176,299 @@ function sanitiseInput(input: string): string {
 }
 
 /**
- * Safely execute go doc command using execFile
+ * The result for a describe call limited to local sources when the package isn't available locally
+ */
+function localSourceError(packageName: string): DocResult {
+  return {
+    error: `No local documentation found for ${packageName}. Install it, or use source "auto" or "network"`,
+    suggestInstall: true
+  }
+}
+
+/**
+ * Identify the kind of a schema file from its content, e.g. "OpenAPI 3.1" or "JSON Schema"
+ */
+function describeSchemaKind(content: string): string | undefined {
+  const version = (key: string) => content.match(new RegExp(`^\\s*"?${key}"?\\s*:\\s*["']?([\\d.]+)`, "m"))?.[1]
+  const openApi = version("openapi")
+  if (openApi) return `OpenAPI ${openApi}`
+  const swagger = version("swagger")
+  if (swagger) return `Swagger ${swagger}`
+  if (/"\$schema"\s*:|^\s*\$schema\s*:/m.test(content)) return "JSON Schema"
+  return undefined
+}
+
+/**
+ * The registry page of a package, for when its metadata doesn't link any documentation
+ */
+function registryPageUrl(ecosystem: ProjectEcosystem, packageName: string): string {
+  switch (ecosystem) {
+    case "npm":
+      return `https://www.npmjs.com/package/${packageName}`
+    case "python":
+      return `https://pypi.org/project/${normalizePyPIName(packageName)}/`
+    case "rust":
+      return `https://docs.rs/${packageName}`
+    case "go":
+      return pkgGoDevUrl(packageName)
+  }
+}
+
+/**
+ * Safely execute go doc command using execFile, optionally within a module directory (where the
+ * package is a relative path such as ./subpkg)
  */
-async function safeGoDoc(packageName: string, symbol?: string): Promise<{ stdout: string }> {
+async function safeGoDoc(packageName: string, symbol?: string, cwd?: string): Promise<{ stdout: string }> {
   const sanitisedPackage = sanitiseInput(packageName)
   const args = ['doc']
 
   if (symbol) {
     const sanitisedSymbol = sanitiseInput(symbol)
-    args.push(`${sanitisedPackage}.${sanitisedSymbol}`)
+    // A relative package path can't be joined to the symbol with a dot, so pass them separately
+    args.push(...(cwd ? [sanitisedPackage, sanitisedSymbol] : [`${sanitisedPackage}.${sanitisedSymbol}`]))
   } else {
     args.push(sanitisedPackage)
   }
 
-  return await execFileAsync('go', args)
+  return await runCommand('go', { args, cwd })
+}
+
+/**
+ * Safely execute go doc -all to get the complete documentation for a package
+ */
+async function safeGoDocAll(packageName: string, cwd?: string): Promise<{ stdout: string }> {
+  const sanitisedPackage = sanitiseInput(packageName)
+  return await runCommand('go', { args: ['doc', '-all', sanitisedPackage], cwd })
+}
+
+/**
+ * Safely execute go doc -short to list a package's exported declarations, one per line
+ */
+async function safeGoDocShort(packageName: string, cwd?: string): Promise<{ stdout: string }> {
+  const sanitisedPackage = sanitiseInput(packageName)
+  return await runCommand('go', { args: ['doc', '-short', sanitisedPackage], cwd })
+}
+
+/**
+ * Safely execute go mod download to fetch a specific module version into the module cache
+ */
+async function safeGoModDownload(modulePath: string, version: string): Promise<{ stdout: string }> {
+  const sanitisedModule = sanitiseInput(modulePath)
+  const sanitisedVersion = sanitiseInput(version)
+  return await runCommand('go', { args: ['mod', 'download', '-json', `${sanitisedModule}@${sanitisedVersion}`] })
+}
+
+/**
+ * Safely execute pydoc to get the complete documentation for an installed Python package
+ */
+async function safePydoc(packageName: string, projectPath?: string): Promise<{ stdout: string }> {
+  const sanitisedPackage = sanitiseInput(packageName)
+  const python = await findPythonInterpreter(projectPath)
+  return await runCommand(python, {
+    args: ['-m', 'pydoc', sanitisedPackage],
+    cwd: projectPath,
+    env: pythonEnvironment(python),
+  })
 }
 
 /**
- * Safely execute go list command using execFile
+ * Safely execute go list command, in the project when one is given so its go.mod is used
  */
-async function safeGoList(packageName: string): Promise<{ stdout: string }> {
+async function safeGoList(packageName: string, cwd?: string): Promise<{ stdout: string }> {
   const sanitisedPackage = sanitiseInput(packageName)
-  return await execFileAsync('go', ['list', '-f', '{{.Dir}}', sanitisedPackage])
+  return await runCommand('go', { args: ['list', '-f', '{{.Dir}}', sanitisedPackage], cwd })
+}
+
+/**
+ * Safely execute python command, with the project's interpreter when it has one
+ */
+async function safePythonExec(code: string, projectPath?: string): Promise<{ stdout: string }> {
+  const python = await findPythonInterpreter(projectPath)
+  return await runCommand(python, { args: ['-c', code], cwd: projectPath, env: pythonEnvironment(python) })
+}
+
+/**
+ * Get the variables that activate the virtualenv or conda environment an interpreter belongs to,
+ * as its activate script would set them, so tools it runs find the environment's packages and
+ * commands. Interpreters found on the PATH run with the server's environment unchanged.
+ */
+function pythonEnvironment(python: string): Record<string, string> | undefined {
+  const binDir = dirname(python)
+  if (!["bin", "Scripts"].includes(basename(binDir))) {
+    return undefined
+  }
+  return {
+    VIRTUAL_ENV: dirname(binDir),
+    PATH: [binDir, process.env.PATH].filter(Boolean).join(delimiter),
+  }
+}
+
+// The Python interpreter found for each project path ("" for none), so detection runs once per project
+const pythonInterpreters = new Map<string, Promise<string>>()
+
+/**
+ * Find the Python interpreter whose packages a project uses: the project's virtualenv (.venv, venv or
+ * env, or the Poetry or Pipenv environment of a pyproject.toml or Pipfile), then an activated
+ * virtualenv or conda environment, then python3 or python on the PATH
+ */
+function findPythonInterpreter(projectPath?: string): Promise<string> {
+  const key = projectPath || ""
+  let interpreter = pythonInterpreters.get(key)
+  if (!interpreter) {
+    interpreter = detectPythonInterpreter(projectPath)
+    pythonInterpreters.set(key, interpreter)
+  }
+  return interpreter
+}
+
+/**
+ * Get the interpreter of a virtualenv or conda environment, if the directory is one and its
+ * interpreter may be run (see isCommandAllowed), otherwise the next interpreter is looked for
+ */
+function environmentPython(envDir: string): string | undefined {
+  const candidates = process.platform === "win32"
+    ? [join(envDir, "Scripts", "python.exe"), join(envDir, "python.exe")]
+    : [join(envDir, "bin", "python3"), join(envDir, "bin", "python")]
+  return candidates.find(candidate => existsSync(candidate) && isCommandAllowed(candidate))
+}
+
+async function detectPythonInterpreter(projectPath?: string): Promise<string> {
+  if (projectPath) {
+    for (const dir of [".venv", "venv", "env"]) {
+      const python = environmentPython(join(projectPath, dir))
+      if (python) return python
+    }
+
+    // Poetry and Pipenv keep their environments outside the project by default
+    const managers: Array<[string, string, string[]]> = [
+      ["pyproject.toml", "poetry", ["env", "info", "--path"]],
+      ["Pipfile", "pipenv", ["--venv"]],
+    ]
+    for (const [file, command, args] of managers) {
+      if (!existsSync(join(projectPath, file))) continue
+      try {
+        const { stdout } = await runCommand(command, { args, cwd: projectPath, timeout: 10000 })
+        const python = environmentPython(stdout.trim())
+        if (python) return python
+      } catch {
+        // The tool isn't installed, or the project's environment hasn't been created yet
+      }
+    }
+  }
+
+  for (const envDir of [process.env.VIRTUAL_ENV, process.env.CONDA_PREFIX]) {
+    const python = envDir ? environmentPython(envDir) : undefined
+    if (python) return python
+  }
+
+  for (const command of ["python3", "python"]) {
+    try {
+      await runCommand(command, { args: ["--version"], timeout: 5000 })
+      return command
+    } catch {
+      continue
+    }
+  }
+  return "python3"
 }
 
+// Tools whose results are returned as a markdown document rather than as JSON
+const MARKDOWN_TOOLS = ["get_npm_package_doc", "get_package_doc", "compare_versions", "compare_api", "get_package_changelog", "get_package_examples", "get_compatibility", "list_package_symbols", "describe_project_dependencies", "get_migration_guide", "get_schema"]
+
 /**
- * Safely execute python command using execFile
+ * Render a tool's result as the call's response: a markdown document for the full documentation
+ * tools, the result as JSON for the rest. Cached results are rendered the same way, so a repeated
+ * call gets the same shape of response.
  */
-async function safePythonExec(code: string): Promise<{ stdout: string }> {
-  return await execFileAsync('python3', ['-c', code])
+function renderToolResult(toolName: string, args: Record<string, unknown> | undefined, result: DocResult): { content: Array<{ type: "text"; text: string }> } {
+  // Errors are returned as JSON by every tool, as there's no document to render
+  if (!MARKDOWN_TOOLS.includes(toolName) || result.error) {
+    return { content: [{ type: "text", text: JSON.stringify(result) }] }
+  }
+
+  // Combine description, usage, and example into a single markdown document
+  let markdown = ""
+
+  if (result.description) {
+    // Tools about a whole project rather than one package have no package to title it with
+    markdown += args?.package
+      ? `# ${args.package}\n\n${result.description}\n\n`
+      : `${result.description}\n\n`
+  }
+
+  if (result.usage) {
+    markdown += result.usage
+  }
+
+  // Only add examples if they're not already included in usage
+  if (result.example && !result.usage?.includes(result.example)) {
+    markdown += `\n\n## Additional Examples\n\n${result.example}`
+  }
+
+  return { content: [{ type: "text", text: markdown }] }
 }
 
+/**
+ * Describe why a local command was passed over for a fallback source, telling a tool that isn't
+ * installed ("go not found, using pkg.go.dev") apart from one that ran and failed
+ */
+function describeCommandFallback(error: unknown, fallback: string): string {
+  if (error instanceof ToolNotInstalledError) {
+    return `${error.command} not found, using ${fallback}`
+  }
+  return `${error instanceof Error ? error.message : String(error)}, using ${fallback}`
+}
 
 export class PackageDocsServer {
   private server: Server
   private cache: Map<string, DocResult>
+  private callGate: ConcurrencyGate
   private logger: McpLogger
   private lspClient?: TypeScriptLspClient
   private lspEnabled: boolean
   private npmDocsHandler: NpmDocsHandler
   private rustDocsHandler: RustDocsHandler
+  private phpDocsHandler: PhpDocsHandler
+  private javaDocsHandler: JavaDocsHandler
+  private dotnetDocsHandler: DotnetDocsHandler
   private searchUtils: SearchUtils
+  private sectionSearch: SectionSearchPool
   private registryUtils: RegistryUtils
+  private packageSearch: PackageSearch
+
+  /**
+   * Check that at least one documentation source is usable: a local toolchain (go, python3)
+   * or the network. Logs a warning when none are, as every tool call would then fail.
+   */
+  public async selfCheck(): Promise<boolean> {
+    const probe = async (name: string, check: () => Promise<unknown>) => {
+      try {
+        await check()
+        return { name, ok: true }
+      } catch (error) {
+        return { name, ok: false, reason: error instanceof Error ? error.message : String(error) }
+      }
+    }
+
+    const results = await Promise.all([
+      probe("go", () => runCommand("go", { args: ["version"], timeout: 5000 })),
+      probe("python3", () => runCommand("python3", { args: ["--version"], timeout: 5000 })),
+      probe("network (registry.npmjs.org)", () => axios.head("https://registry.npmjs.org/", { timeout: 5000 })),
+    ])
+
+    const available = results.filter(result => result.ok).map(result => result.name)
+    if (available.length === 0) {
+      // Logged as an error, as warnings are silenced by default when running as an MCP server
+      this.logger.error(
+        "No documentation sources are available, so tool calls will fail:\n" +
+        results.map(result => `  ${result.name}: ${result.reason}`).join("\n")
+      )
+      return false
+    }
+
+    this.logger.debug(`Available documentation sources: ${available.join(", ")}`)
+    return true
+  }
 
   /**
    * Connect the server to a transport
@@ -92,8 +481,13 @@ export class PackageDocsServer {
     this.logger = logger.child('PackageDocs')
     this.npmDocsHandler = new NpmDocsHandler()
     this.rustDocsHandler = new RustDocsHandler(logger)
+    this.phpDocsHandler = new PhpDocsHandler(logger)
+    this.javaDocsHandler = new JavaDocsHandler(logger)
+    this.dotnetDocsHandler = new DotnetDocsHandler(logger)
     this.searchUtils = new SearchUtils(logger)
+    this.sectionSearch = new SectionSearchPool(this.searchUtils, logger)
     this.registryUtils = new RegistryUtils(logger)
+    this.packageSearch = new PackageSearch(logger)
 
     this.server = new Server(
       {
@@ -108,6 +502,7 @@ export class PackageDocsServer {
     )
 
     this.cache = new Map()
+    this.callGate = new ConcurrencyGate()
 
     // Check if LSP functionality is enabled via environment variable
     this.lspEnabled = process.env.ENABLE_LSP === "true"
@@ -132,7 +527,7 @@ export class PackageDocsServer {
       return getToolDefinitions(this.lspEnabled, this.lspClient)
     })
 
-    this.server.setRequestHandler(CallToolRequestSchema, async (request) => {
+    this.server.setRequestHandler(CallToolRequestSchema, (request) => this.runToolCall(async () => {
       if (!request.params.arguments) {
         throw new McpError(ErrorCode.InvalidParams, "Arguments are required")
       }
@@ -148,7 +543,9 @@ export class PackageDocsServer {
         }
       }
 
-      // Handle regular package documentation tools
+      // Handle regular package documentation tools, with the package name normalised so variants
+      // such as "Lodash" and "lodash" share lookups and cache entries
+      request.params.arguments = normalizePackageArgs(request.params.name, request.params.arguments) as typeof request.params.arguments
       const cacheKey = JSON.stringify({
         name: request.params.name,
         args: request.params.arguments,
@@ -158,14 +555,7 @@ export class PackageDocsServer {
       const cachedResult = this.cache.get(cacheKey)
       if (cachedResult) {
         this.logger.debug(`Cache hit for ${request.params.name}`)
-        return {
-          content: [
-            {
-              type: "text",
-              text: JSON.stringify(cachedResult),
-            },
-          ],
-        }
+        return renderToolResult(request.params.name, request.params.arguments, cachedResult)
       }
 
       try {
@@ -179,11 +569,34 @@ export class PackageDocsServer {
                 "Invalid search_package_docs arguments"
               )
             }
+            // Checked before anything is fetched, as an empty query can't match anything
+            if (parseSearchQuery(request.params.arguments.query).length === 0) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "query is required: give one or more terms to search the documentation for"
+              )
+            }
             result = await this.searchPackageDocs(request.params.arguments)
             break;
 
+          case "search_packages":
+            if (!isSearchPackagesArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid search_packages arguments"
+              )
+            }
+            if (!request.params.arguments.query.trim()) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "query is required: give a package name or keywords to search for"
+              )
+            }
+            result = await this.searchPackages(request.params.arguments)
+            break
+
           case "describe_rust_package":
-            result = await this.describeRustPackage(request.params.arguments as { package: string, version?: string })
+            result = await this.describeRustPackage(request.params.arguments as { package: string, version?: string, target?: string, features?: string[], format?: string, source?: DocSource, raw?: boolean, includePrerelease?: boolean })
             break
 
           case "describe_go_package":
@@ -234,6 +647,36 @@ export class PackageDocsServer {
             result = await this.describeSwiftPackage(request.params.arguments)
             break
 
+          case "describe_php_package":
+            if (!isPhpDocArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid describe_php_package arguments"
+              )
+            }
+            result = await this.phpDocsHandler.describePhpPackage(request.params.arguments)
+            break
+
+          case "describe_java_package":
+            if (!isJavaDocArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid describe_java_package arguments"
+              )
+            }
+            result = await this.javaDocsHandler.describeJavaPackage(request.params.arguments)
+            break
+
+          case "describe_dotnet_package":
+            if (!isDotnetDocArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid describe_dotnet_package arguments"
+              )
+            }
+            result = await this.dotnetDocsHandler.describeDotnetPackage(request.params.arguments)
+            break
+
           case "get_npm_package_doc":
             if (!isNpmDocArgs(request.params.arguments)) {
               throw new McpError(
@@ -244,6 +687,126 @@ export class PackageDocsServer {
             result = await this.getNpmPackageDoc(request.params.arguments)
             break
 
+          case "get_package_doc":
+            if (!isPackageDocArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid get_package_doc arguments"
+              )
+            }
+            result = await this.getPackageDoc(request.params.arguments)
+            break
+
+          case "get_config_docs":
+            if (!isConfigDocArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid get_config_docs arguments"
+              )
+            }
+            result = await this.getConfigDocs(request.params.arguments)
+            break
+
+          case "get_package_examples":
+            if (!isExamplesArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid get_package_examples arguments"
+              )
+            }
+            result = await this.getPackageExamples(request.params.arguments)
+            break
+
+          case "get_compatibility":
+            if (!isCompatibilityArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid get_compatibility arguments"
+              )
+            }
+            result = await this.getCompatibility(request.params.arguments)
+            break
+
+          case "compare_versions":
+            if (!isCompareVersionsArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid compare_versions arguments"
+              )
+            }
+            result = await this.compareVersions(request.params.arguments)
+            break
+
+          case "compare_api":
+            if (!isApiDiffArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid compare_api arguments"
+              )
+            }
+            result = await this.compareApi(request.params.arguments)
+            break
+
+          case "list_package_symbols":
+            if (!isSymbolListArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid list_package_symbols arguments"
+              )
+            }
+            result = await this.listPackageSymbols(request.params.arguments)
+            break
+
+          case "describe_project_dependencies":
+            if (!isProjectDependenciesArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid describe_project_dependencies arguments"
+              )
+            }
+            result = await this.describeProjectDependencies(request.params.arguments)
+            break
+
+          case "get_package_changelog":
+            if (!isChangelogArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid get_package_changelog arguments"
+              )
+            }
+            result = await this.getPackageChangelog(request.params.arguments)
+            break
+
+          case "get_migration_guide":
+            if (!isMigrationGuideArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid get_migration_guide arguments"
+              )
+            }
+            result = await this.getMigrationGuide(request.params.arguments)
+            break
+
+          case "get_package_dependencies":
+            if (!isPackageDependenciesArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid get_package_dependencies arguments"
+              )
+            }
+            result = await this.getPackageDependencies(request.params.arguments)
+            break
+
+          case "get_schema":
+            if (!isSchemaArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                "Invalid get_schema arguments"
+              )
+            }
+            result = await this.getPackageSchema(request.params.arguments)
+            break
+
           default:
             throw new McpError(
               ErrorCode.MethodNotFound,
@@ -251,46 +814,48 @@ export class PackageDocsServer {
             )
         }
 
-        // Cache the result
-        this.cache.set(cacheKey, result)
-
-        // For get_npm_package_doc, return the markdown content directly
-        if (request.params.name === "get_npm_package_doc") {
-          // Combine description, usage, and example into a single markdown document
-          let markdown = ""
-
-          if (result.description) {
-            markdown += `# ${request.params.arguments.package}\n\n${result.description}\n\n`
-          }
-
-          if (result.usage) {
-            markdown += result.usage
+        // Replace the rendered markdown with structured metadata when JSON output was requested
+        const describeLanguage = DESCRIBE_TOOL_LANGUAGES[request.params.name]
+        const describeArgs = request.params.arguments as { package: string; version?: string; projectPath?: string; format?: string; source?: string; includeRaw?: boolean; rawMaxLength?: number }
+        if (describeLanguage && describeArgs.format === "json" && !result.error) {
+          // Handlers return the metadata of the registry entries they read. Documentation read
+          // without the registry (installed packages, go doc) has it looked up, unless the call is
+          // local only, as registry metadata needs the network; those keep the rendered result.
+          const metadata = result.metadata ?? (describeArgs.source !== "local"
+            ? await this.getPackageMetadata(describeLanguage, describeArgs)
+            : undefined)
+          if (metadata) {
+            result = {
+              description: result.description,
+              metadata,
+              options: result.options,
+              readme: result.readme,
+            }
           }
+        }
 
-          // Only add examples if they're not already included in usage
-          if (result.example && !result.usage?.includes(result.example)) {
-            markdown += `\n\n## Additional Examples\n\n${result.example}`
+        // Put the whole README after the summary, for anything its section filtering left out.
+        // Handlers return the README they read. Documentation read without one (installed packages,
+        // go doc) has it fetched, unless the call is local only.
+        if (describeLanguage && describeArgs.includeRaw === true && !result.error) {
+          let readme = result.readme
+          if (readme === undefined && describeArgs.source !== "local") {
+            try {
+              readme = await this.getPackageReadme(describeLanguage as ConfigDocArgs["language"], describeArgs.package, describeArgs.projectPath)
+            } catch (error) {
+              this.logger.debug(`Could not fetch the raw README for ${describeArgs.package}: ${error}`)
+            }
           }
 
-          return {
-            content: [
-              {
-                type: "text",
-                text: markdown,
-              },
-            ],
-          }
-        } else {
-          // For other tools, return the result as JSON
-          return {
-            content: [
-              {
-                type: "text",
-                text: JSON.stringify(result),
-              },
-            ],
-          }
+          const summary: DocResult = { ...result }
+          delete summary.readme
+          result = readme ? { ...summary, readme: truncateText(readme, describeArgs.rawMaxLength ?? 20000) } : summary
         }
+
+        // Cache the result
+        this.cache.set(cacheKey, result)
+
+        return renderToolResult(request.params.name, request.params.arguments, result)
       } catch (error) {
         if (error instanceof McpError) {
           throw error
@@ -312,8 +877,16 @@ export class PackageDocsServer {
           isError: true,
         }
       }
-    })
+    }))
+
+  }
 
+  /**
+   * Run a tool call once fewer than the concurrency limit are running. Every HTTP request made for
+   * the call shares one retry budget, and every document it reads is parsed once.
+   */
+  private runToolCall<T>(call: () => Promise<T>): Promise<T> {
+    return this.callGate.run(() => withRetryBudget(() => withParseCache(call)))
   }
 
     /**
@@ -324,9 +897,11 @@ export class PackageDocsServer {
             // Check if the project has a Cargo.toml file
             const cargoTomlPath = projectPath ? join(projectPath, "Cargo.toml") : "Cargo.toml";
             if (existsSync(cargoTomlPath)) {
-                const cargoToml = readFileSync(cargoTomlPath, "utf-8");
-                // Simple check if the crate is mentioned in Cargo.toml
-                if (cargoToml.includes(crateName)) {
+                // Match whole dependency names rather than any mention of the crate, so serde
+                // isn't taken as installed because serde_json is. crates.io treats - and _ alike.
+                const normalise = (name: string) => name.toLowerCase().replace(/-/g, "_");
+                const dependencies = cargoDependencyNames(readFileSync(cargoTomlPath, "utf-8"));
+                if (dependencies.some(name => normalise(name) === normalise(crateName))) {
                     return true;
                 }
             }
@@ -365,21 +940,23 @@ export class PackageDocsServer {
    */
   private async isGoPackageInstalledLocally(packageName: string, projectPath?: string): Promise<boolean> {
     try {
-      // Check if the project has a go.mod file
-      const goModPath = projectPath ? join(projectPath, "go.mod") : "go.mod"
-      if (existsSync(goModPath)) {
+      // Check whether the package is in, or required by, the project's module or the modules of
+      // the go.work workspace it's in
+      for (const goModPath of findGoModFiles(projectPath || ".")) {
         const goMod = readFileSync(goModPath, "utf-8")
-        // Simple check if the package is mentioned in go.mod
-        if (goMod.includes(packageName)) {
+        const modulePath = goModulePath(goMod)
+        const isLocal = modulePath && (packageName === modulePath || packageName.startsWith(`${modulePath}/`))
+        if (isLocal || findGoModRequirement(goMod, packageName)) {
           return true
         }
       }
 
       // Try to find the package in GOPATH
-      const { stdout } = await safeGoList(packageName)
+      const { stdout } = await safeGoList(packageName, projectPath)
       return !!stdout.trim()
-    } catch {
+    } catch (error) {
       // If the command fails, the package is likely not installed
+      this.logger.debug(describeCommandFallback(error, "pkg.go.dev"))
       return false
     }
   }
@@ -387,7 +964,7 @@ export class PackageDocsServer {
   /**
    * Check if a Python package is installed locally
    */
-  private async isPythonPackageInstalledLocally(packageName: string): Promise<boolean> {
+  private async isPythonPackageInstalledLocally(packageName: string, projectPath?: string): Promise<boolean> {
     try {
       // Check if we can import the package
       const pythonCode = `
@@ -396,23 +973,21 @@ import sys
 spec = importlib.util.find_spec('${packageName}')
 print(spec is not None)
 `
-      const { stdout } = await safePythonExec(pythonCode)
+      const { stdout } = await safePythonExec(pythonCode, projectPath)
       return stdout.trim() === "True"
-    } catch {
+    } catch (error) {
+      this.logger.debug(describeCommandFallback(error, "PyPI"))
       return false
     }
   }
 
   /**
-   * Check if an NPM package is installed locally
+   * Check if an NPM package is installed locally, or is a workspace package of the project
    */
   private isNpmPackageInstalledLocally(packageName: string, projectPath?: string): boolean {
     try {
-      // Check in the project's node_modules directory
-      const basePath = projectPath || process.cwd()
-      const packageJsonPath = join(basePath, "node_modules", packageName, "package.json")
-
-      return existsSync(packageJsonPath)
+      // Check in the project's node_modules directory, then among its workspace packages
+      return localNpmPackagePath(packageName, projectPath) !== undefined
     } catch {
       return false
     }
@@ -426,13 +1001,8 @@ print(spec is not None)
       // Check if the project has a Package.swift file
       const packageSwiftPath = projectPath ? join(projectPath, "Package.swift") : "Package.swift"
       if (existsSync(packageSwiftPath)) {
-        const packageSwift = readFileSync(packageSwiftPath, "utf-8")
-
-        // Extract the package name from the URL
-        const packageName = this.extractSwiftPackageNameFromUrl(packageUrl)
-
-        // Simple check if the package is mentioned in Package.swift
-        if (packageName && (packageSwift.includes(packageUrl) || packageSwift.includes(packageName))) {
+        const dependencies = parsePackageSwift(readFileSync(packageSwiftPath, "utf-8"))
+        if (dependencies.some(dependency => isSameSwiftPackage(dependency, packageUrl))) {
           return true
         }
       }
@@ -446,9 +1016,12 @@ print(spec is not None)
   /**
    * Get documentation from a locally installed Go package
    */
-  private async getLocalGoDoc(packageName: string, symbol?: string): Promise<DocResult> {
+  private async getLocalGoDoc(packageName: string, symbol?: string, raw = false, projectPath?: string): Promise<DocResult> {
     try {
-      const { stdout } = await safeGoDoc(packageName, symbol)
+      const { stdout } = await safeGoDoc(packageName, symbol, projectPath)
+      if (raw) {
+        return { usage: stdout }
+      }
 
       // Parse the go doc output into a structured format
       const lines = stdout.split("\n")
@@ -492,7 +1065,7 @@ print(spec is not None)
   /**
    * Get documentation from a locally installed Python package
    */
-  private async getLocalPythonDoc(packageName: string, symbol?: string): Promise<DocResult> {
+  private async getLocalPythonDoc(packageName: string, symbol?: string, projectPath?: string, raw = false): Promise<DocResult> {
     try {
       const pythonCode = symbol
         ? `
@@ -504,7 +1077,10 @@ import ${packageName}
 help(${packageName})
 `
 
-      const { stdout } = await safePythonExec(pythonCode)
+      const { stdout } = await safePythonExec(pythonCode, projectPath)
+      if (raw) {
+        return { usage: stdout }
+      }
 
       // Parse the Python help output into a structured format
       const lines = stdout.split("\n")
@@ -550,8 +1126,7 @@ help(${packageName})
    */
   private getLocalNpmDoc(packageName: string, projectPath?: string): DocResult {
     try {
-      const basePath = projectPath || process.cwd()
-      const packagePath = join(basePath, "node_modules", packageName)
+      const packagePath = localNpmPackagePath(packageName, projectPath) ?? join(projectPath || process.cwd(), "node_modules", packageName)
       const packageJsonPath = join(packagePath, "package.json")
       const readmePaths = [
         join(packagePath, "README.md"),
@@ -572,6 +1147,11 @@ help(${packageName})
         if (existsSync(readmePath)) {
           const readme = readFileSync(readmePath, "utf-8")
 
+          const languageNote = this.searchUtils.languageNote(readme)
+          if (languageNote) {
+            result.description += `\n\n${languageNote}`
+          }
+
           // Extract usage and examples from README
           const sections = readme.split(/#+\s/)
           for (const section of sections) {
@@ -614,29 +1194,27 @@ help(${packageName})
 
       // Try to get documentation using swift-doc if available
       try {
-        // Use execFileAsync for safer command execution
+        // Run in the project, whose Package.swift declares the dependency
         const args = ['doc', 'generate', packageName, '--module-name', packageName]
         if (symbol) {
           args.push('--symbol', symbol)
         }
-        const { stdout } = await execFileAsync('swift', args)
+        const { stdout } = await runCommand('swift', { args, cwd: projectPath })
         return {
           description: stdout.trim()
         }
-      } catch {
+      } catch (error) {
         // If swift-doc fails, try to extract info from Package.swift
+        this.logger.debug(describeCommandFallback(error, "Package.swift"))
         const packageSwiftPath = projectPath ? join(projectPath, "Package.swift") : "Package.swift"
         if (existsSync(packageSwiftPath)) {
-          const packageSwift = readFileSync(packageSwiftPath, "utf-8")
+          const dependency = parsePackageSwift(readFileSync(packageSwiftPath, "utf-8"))
+            .find(candidate => isSameSwiftPackage(candidate, packageUrl))
 
-          // Try to find the package declaration
-          const packageRegex = new RegExp(`\\b${packageName}\\b[\\s\\S]*?\\{[\\s\\S]*?\\}`, "i")
-          const packageMatch = packageSwift.match(packageRegex)
-
-          if (packageMatch) {
+          if (dependency) {
             return {
               description: `Swift package: ${packageName}`,
-              usage: packageMatch[0]
+              usage: `Declared in Package.swift as ${dependency.url} (${formatSwiftConstraint(dependency.constraint)})`
             }
           }
         }
@@ -710,21 +1288,64 @@ help(${packageName})
    * Search for content within package documentation
    * Enhanced to provide more comprehensive context in search results
    */
+  /**
+   * Search a package registry for packages matching a term
+   */
+  private async searchPackages(args: SearchPackagesArgs): Promise<DocResult> {
+    const { query, language, projectPath } = args
+    const limit = Math.min(Math.max(args.limit ?? 10, 1), 50)
+    const page = Math.max(Math.floor(args.page ?? 1), 1)
+    this.logger.debug(`Searching ${language} packages for "${query}" (page ${page})`)
+
+    try {
+      const registry = language === "npm"
+        ? this.registryUtils.getRegistryConfigForPackage(query, projectPath).registry
+        : undefined
+      const results = await this.packageSearch.searchPackages(language, query, limit, registry, page)
+      const packages = this.packageSearch.rankResults(query, results)
+
+      if (packages.length === 0) {
+        return {
+          error: page > 1
+            ? `No more ${language} packages matching "${query}" after page ${page - 1}`
+            : `No ${language} packages found matching "${query}"`,
+          packages: [],
+        }
+      }
+
+      // A full page suggests the registry has more results
+      const more = packages.length === limit ? `; request page ${page + 1} for more` : ""
+      return {
+        description: `Found ${packages.length} ${language} package${packages.length === 1 ? "" : "s"} matching "${query}"${page > 1 ? ` on page ${page}` : ""}${more}`,
+        packages,
+      }
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error searching ${language} packages for ${query}:`, error)
+      return {
+        error: `Failed to search ${language} packages: ${errorMessage}`,
+      }
+    }
+  }
+
   private async searchPackageDocs(args: SearchDocArgs): Promise<DocResult> {
-    const { package: packageName, query, language, fuzzy = true, projectPath } = args
+    const { package: packageName, query, language, fuzzy = true, projectPath, symbol, source = "auto", format = "markdown", ranking } = args
+    const contextSize = clampContextSize(args.contextSize)
     const packageUrl = packageName
-    this.logger.debug(`Searching ${language} package ${packageName} for "${query}"`)
+    this.logger.debug(`Searching ${language} package ${packageName}${symbol ? ` (${symbol})` : ""} for "${query}"`)
 
     try {
       let docContent: string | Array<{ content: string; type: string }> = ""
       let isInstalled = false
       // eslint-disable-next-line @typescript-eslint/no-explicit-any
       let packageInfo: any = null
+      // Set when the documentation fetched is already limited to the requested symbol
+      let symbolScoped = false
 
-      // Check if package is installed locally first
+      // Check if package is installed locally first, unless the network was requested
       switch (language) {
         case "rust":
-          isInstalled = await this.isRustCrateInstalledLocally(packageName)
+          isInstalled = source !== "network" && await this.isRustCrateInstalledLocally(packageName)
           if (isInstalled) {
             const localDoc = await this.getLocalRustDoc(packageName)
             if (!localDoc.error) {
@@ -734,7 +1355,7 @@ help(${packageName})
                 { content: localDoc.example || "", type: "example" }
               ].filter(item => item.content)
             }
-          } else {
+          } else if (source !== "local") {
             // If not installed, try to fetch from docs.rs and crates.io
             try {
               // Get crate details from crates.io
@@ -782,9 +1403,28 @@ help(${packageName})
           break
 
         case "go":
-          isInstalled = await this.isGoPackageInstalledLocally(packageName, projectPath)
+          // Standard library packages come with Go, so there's nothing to look up: go doc is tried
+          // first below, and pkg.go.dev only used when it fails (as it does without Go installed)
+          isInstalled = source !== "network" && !isGoStandardLibrary(packageName) &&
+            await this.isGoPackageInstalledLocally(packageName, projectPath)
+
+          // go doc can document a single symbol directly, so search within that when one is given
+          if (symbol && source !== "network") {
+            try {
+              const { stdout } = await safeGoDoc(packageName, symbol, projectPath)
+              docContent = this.searchUtils.parseGoDoc(stdout)
+              symbolScoped = true
+            } catch (cmdError) {
+              this.logger.debug(`go doc failed for ${packageName}.${symbol}: ${describeCommandFallback(cmdError, "the package documentation")}`)
+            }
+          }
+
+          if (symbolScoped) {
+            break
+          }
+
           if (isInstalled) {
-            const localDoc = await this.getLocalGoDoc(packageName, undefined)
+            const localDoc = await this.getLocalGoDoc(packageName, undefined, false, projectPath)
             if (!localDoc.error) {
               docContent = this.searchUtils.parseGoDoc(
                 [localDoc.description, localDoc.usage, localDoc.example]
@@ -797,12 +1437,19 @@ help(${packageName})
             let docFetched = false
 
             // First try using go doc command (works for standard library and cached modules)
-            try {
-              const { stdout } = await safeGoDoc(packageName)
-              docContent = this.searchUtils.parseGoDoc(stdout)
-              docFetched = true
-            } catch (cmdError) {
-              this.logger.debug(`go doc command failed for ${packageName}: ${cmdError}`)
+            if (source !== "network") {
+              try {
+                const { stdout } = await safeGoDoc(packageName, undefined, projectPath)
+                docContent = this.searchUtils.parseGoDoc(stdout)
+                docFetched = true
+              } catch (cmdError) {
+                this.logger.debug(`go doc command failed for ${packageName}: ${describeCommandFallback(cmdError, "pkg.go.dev")}`)
+              }
+            }
+
+            // Local only lookups stop at go doc
+            if (source === "local") {
+              break
             }
 
             // If go doc command fails, try to get package info from pkg.go.dev API
@@ -889,7 +1536,7 @@ help(${packageName})
 
                     // Add import example
                     docContent.push({
-                      content: `// Import the package\nimport "${packageName}"\n\n// For more details, visit: https://pkg.go.dev/${encodeURIComponent(packageName)}`,
+                      content: `// Import the package\nimport "${packageName}"\n\n// For more details, visit: ${pkgGoDevUrl(packageName)}`,
                       type: "example"
                     })
 
@@ -904,14 +1551,15 @@ help(${packageName})
             // If GitHub fetch fails or it's not a GitHub URL, try web scraping approach
             if (!docFetched) {
               try {
-                const url = `https://pkg.go.dev/${encodeURIComponent(packageName)}`
+                const url = pkgGoDevUrl(packageName)
                 this.logger.debug(`Attempting to fetch documentation from: ${url}`)
 
                 const response = await axios.get(url)
 
                 if (response.data) {
-                  // Extract basic package information from HTML
-                  const html = response.data
+                  // Extract basic package information from HTML, with links resolved against the page
+                  // it was served from, after any redirect
+                  const html = resolveRelativeLinks(String(response.data), response.request?.res?.responseUrl ?? url)
 
                   // Simple extraction of package description
                   const descriptionMatch = html.match(/<meta name="description" content="([^"]+)"/)
@@ -921,9 +1569,8 @@ help(${packageName})
                   const docMatch = html.match(/<div class="Documentation-content">[\s\S]*?<\/div>/)
                   let documentation = docMatch ? docMatch[0] : ""
 
-                  // Try to extract package overview
-                  const overviewMatch = html.match(/<section id="pkg-overview"[\s\S]*?<\/section>/)
-                  const overview = overviewMatch ? overviewMatch[0] : ""
+                  // Extract the package overview and declarations
+                  const { overview, functions: funcSignatures, types: typeDefinitions } = extractPkgGoDevDocs(html)
 
                   // Try to extract constants
                   const constantsMatch = html.match(/<section id="pkg-constants"[\s\S]*?<\/section>/)
@@ -942,27 +1589,14 @@ help(${packageName})
                   const types = typesMatch ? typesMatch[0] : ""
 
                   // Extract code examples if available
-                  const examplesMatch = html.match(/<pre class="Documentation-exampleCode">[\s\S]*?<\/pre>/g)
-                  const examples = examplesMatch ? examplesMatch.join("\n\n") : ""
+                  const examples = extractHtmlCodeBlocks(html, ".Documentation-exampleCode")
+                    .map(block => `\`\`\`go\n${block.code}\n\`\`\``)
+                    .join("\n\n")
 
                   // Extract API documentation - look for function and type definitions
                   const apiDocsMatch = html.match(/<h3 id="[^"]*">[\s\S]*?<pre[\s\S]*?<\/pre>/g) || []
                   const apiDocs = apiDocsMatch.join("\n\n")
 
-                  // Extract function signatures
-                  const funcSignatures: string[] = []
-                  const funcSignatureMatches = html.matchAll(/<h3 id="([^"]*)">func\s+([^<]+)<\/h3>/g)
-                  for (const match of funcSignatureMatches) {
-                    funcSignatures.push(`func ${match[2]}`)
-                  }
-
-                  // Extract type definitions
-                  const typeDefinitions: string[] = []
-                  const typeDefMatches = html.matchAll(/<h3 id="([^"]*)">type\s+([^<]+)<\/h3>/g)
-                  for (const match of typeDefMatches) {
-                    typeDefinitions.push(`type ${match[2]}`)
-                  }
-
                   // Clean up HTML tags from the extracted content
                   const cleanHtml = (html: string): string => {
                     return html
@@ -978,7 +1612,7 @@ help(${packageName})
 
                   // Combine all the extracted content
                   documentation = [
-                    overview ? cleanHtml(overview) : "",
+                    overview || "",
                     constants ? cleanHtml(constants) : "",
                     variables ? cleanHtml(variables) : "",
                     functions ? cleanHtml(functions) : "",
@@ -989,6 +1623,11 @@ help(${packageName})
                     typeDefinitions.length > 0 ? "Type Definitions:\n" + typeDefinitions.join("\n") : ""
                   ].filter(Boolean).join("\n\n");
 
+                  // Fall back to the page's main content when none of its sections were recognised
+                  if (!documentation) {
+                    documentation = cleanHtml(extractMainContent(html))
+                  }
+
                   // Create content sections
                   docContent = [
                     { content: description, type: "description" },
@@ -1004,7 +1643,7 @@ help(${packageName})
                   } else {
                     // Add default example
                     docContent.push({
-                      content: `// Import the package\nimport "${packageName}"\n\n// For more details, visit: https://pkg.go.dev/${encodeURIComponent(packageName)}`,
+                      content: `// Import the package\nimport "${packageName}"\n\n// For more details, visit: ${pkgGoDevUrl(packageName)}`,
                       type: "example"
                     });
                   }
@@ -1023,7 +1662,7 @@ help(${packageName})
                   type: "description"
                 },
                 {
-                  content: `// Import the package\nimport "${packageName}"\n\n// For more details, visit: https://pkg.go.dev/${encodeURIComponent(packageName)}`,
+                  content: `// Import the package\nimport "${packageName}"\n\n// For more details, visit: ${pkgGoDevUrl(packageName)}`,
                   type: "example"
                 }
               ]
@@ -1032,66 +1671,58 @@ help(${packageName})
           break
 
         case "python":
-          isInstalled = await this.isPythonPackageInstalledLocally(packageName)
+          isInstalled = source !== "network" && await this.isPythonPackageInstalledLocally(packageName, projectPath)
           if (isInstalled) {
-            const localDoc = await this.getLocalPythonDoc(packageName, undefined)
+            const localDoc = await this.getLocalPythonDoc(packageName, symbol, projectPath)
             if (!localDoc.error) {
+              symbolScoped = !!symbol
               docContent = this.searchUtils.parsePythonDoc(
                 [localDoc.description, localDoc.usage, localDoc.example]
                   .filter(Boolean)
                   .join("\n\n")
               )
             }
-          } else {
-            // Try to fetch from PyPI
-            const url = `https://pypi.org/pypi/${packageName}/json`
-            const response = await axios.get(url)
-            if (response.data && response.data.info) {
-              packageInfo = response.data.info
-
-              // Extract more comprehensive information
-              const description = packageInfo.summary || ""
-              const longDescription = packageInfo.description || ""
+          }
 
-              // Try to parse the long description as markdown/rst
-              docContent = [
-                { content: description, type: "description" },
-                { content: longDescription, type: "documentation" }
-              ]
+          // Search the PyPI project description when the package isn't installed or pydoc failed, as describe does
+          if (docContent.length === 0 && source !== "local") {
+            try {
+              const response = await axios.get(pypiJsonUrl(packageName))
+              if (response.data && response.data.info) {
+                packageInfo = response.data.info
 
-              // Convert docContent to array if it's a string
-              if (typeof docContent === "string") {
+                // Split the long description (converted from reStructuredText if need be) at its headings
                 docContent = [
-                  { content: description, type: "description" },
-                  { content: longDescription, type: "documentation" }
-                ]
-              }
-
-              // Add project URLs if available
-              if (packageInfo.project_urls) {
-                let urlsContent = "### Project URLs\n\n"
-                for (const [name, url] of Object.entries(packageInfo.project_urls)) {
-                  urlsContent += `- ${name}: ${url}\n`
-                }
-                if (Array.isArray(docContent)) {
+                  { content: packageInfo.summary || "", type: "description" },
+                  ...this.searchUtils.splitMarkdownSections(getPyPIDescription(packageInfo))
+                    .filter(section => section.trim())
+                    .map(section => ({ content: section.trim(), type: "documentation" }))
+                ].filter(section => section.content)
+
+                // Add project URLs if available
+                if (packageInfo.project_urls) {
+                  let urlsContent = "### Project URLs\n\n"
+                  for (const [name, url] of Object.entries(packageInfo.project_urls)) {
+                    urlsContent += `- ${name}: ${url}\n`
+                  }
                   docContent.push({ content: urlsContent, type: "links" })
                 }
-              }
 
-              // Add classifiers if available
-              if (packageInfo.classifiers && packageInfo.classifiers.length > 0) {
-                const classifiersContent = "### Classifiers\n\n- " +
-                  packageInfo.classifiers.join("\n- ")
-                if (Array.isArray(docContent)) {
+                // Add classifiers if available
+                if (packageInfo.classifiers && packageInfo.classifiers.length > 0) {
+                  const classifiersContent = "### Classifiers\n\n- " +
+                    packageInfo.classifiers.join("\n- ")
                   docContent.push({ content: classifiersContent, type: "metadata" })
                 }
               }
+            } catch (error) {
+              this.logger.debug(`Error fetching ${packageName} from PyPI: ${error}`)
             }
           }
           break
 
         case "npm":
-          isInstalled = this.isNpmPackageInstalledLocally(packageName, projectPath)
+          isInstalled = source !== "network" && this.isNpmPackageInstalledLocally(packageName, projectPath)
           if (isInstalled) {
             const localDoc = this.getLocalNpmDoc(packageName, projectPath)
             if (!localDoc.error) {
@@ -1104,11 +1735,10 @@ help(${packageName})
 
             // Try to get additional information from package.json
             try {
-              const basePath = projectPath || process.cwd()
-              const packagePath = join(basePath, "node_modules", packageName)
-              const packageJsonPath = join(packagePath, "package.json")
+              const packagePath = localNpmPackagePath(packageName, projectPath)
+              const packageJsonPath = packagePath && join(packagePath, "package.json")
 
-              if (existsSync(packageJsonPath)) {
+              if (packageJsonPath && existsSync(packageJsonPath)) {
                 packageInfo = JSON.parse(readFileSync(packageJsonPath, "utf-8"))
 
                 // Add dependencies information
@@ -1138,21 +1768,18 @@ help(${packageName})
             } catch (error) {
               this.logger.error(`Error reading package.json: ${error}`)
             }
-          } else {
+          } else if (source !== "local") {
             // Fetch from npm registry
             const config = this.registryUtils.getRegistryConfigForPackage(packageName, projectPath)
-            const headers: Record<string, string> = {}
-            if (config.token) {
-              headers.Authorization = `Bearer ${config.token}`
-            }
-
-            const url = `${config.registry}/${packageName}`
-            const response = await axios.get(url, { headers })
-            if (response.data) {
-              packageInfo = response.data
+            const fetchedInfo = await this.npmDocsHandler.fetchPackageInfo(packageName, undefined, config)
+            if (fetchedInfo) {
+              packageInfo = fetchedInfo
 
               // Parse README and other metadata
-              docContent = this.searchUtils.parseNpmDoc(packageInfo)
+              docContent = this.searchUtils.parseNpmDoc({
+                description: packageInfo.description,
+                readme: this.npmDocsHandler.getReadmeMarkdown(packageInfo),
+              })
 
               // Add additional sections with more comprehensive information
 
@@ -1190,7 +1817,7 @@ help(${packageName})
           break
 
         case "swift":
-          isInstalled = await this.isSwiftPackageInstalledLocally(packageName, projectPath)
+          isInstalled = source !== "network" && await this.isSwiftPackageInstalledLocally(packageName, projectPath)
           if (isInstalled) {
             const localDoc = await this.getLocalSwiftDoc(packageName, undefined, projectPath)
             if (!localDoc.error) {
@@ -1200,67 +1827,124 @@ help(${packageName})
                   .join("\n\n")
               )
             }
-          } else {
-            // Try to fetch from GitHub if it's a GitHub URL
-            if (packageName.includes('github.com')) {
+          } else if (source !== "local") {
+            // Try to fetch the README from the package's repository
+            const repoClient = createRepoClient(packageName, this.logger, { genericHosts: true })
+            if (repoClient) {
               try {
-                // Convert github.com URL to raw.githubusercontent.com URL for the README
-                const githubParts = packageName.replace(/\.git$/, '').split('github.com/')
-                if (githubParts.length === 2) {
-                  const repoPath = githubParts[1]
-                  const readmeUrl = `https://raw.githubusercontent.com/${repoPath}/main/README.md`
-
-                  const response = await axios.get(readmeUrl)
-                  if (response.data) {
-                    // Parse the README content
-                    const readme = response.data
-
-                    // Extract sections
-                    const sections = readme.split(/#+\s/)
-                    let description = ""
-                    let usage = ""
-                    let example = ""
-
-                    for (const section of sections) {
-                      const lower = section.toLowerCase()
-                      if (lower.startsWith("introduction") || lower.startsWith("about") || lower.startsWith("overview")) {
-                        description = section
-                      } else if (lower.startsWith("usage") || lower.startsWith("getting started")) {
-                        usage = section
-                      } else if (lower.startsWith("example")) {
-                        example = section
-                      }
+                const readme = await repoClient.getReadme()
+                if (readme) {
+                  // Extract sections
+                  const sections = readme.split(/#+\s/)
+                  let description = ""
+                  let usage = ""
+                  let example = ""
+
+                  for (const section of sections) {
+                    const lower = section.toLowerCase()
+                    if (lower.startsWith("introduction") || lower.startsWith("about") || lower.startsWith("overview")) {
+                      description = section
+                    } else if (lower.startsWith("usage") || lower.startsWith("getting started")) {
+                      usage = section
+                    } else if (lower.startsWith("example")) {
+                      example = section
                     }
-
-                    docContent = [
-                      { content: description || "Swift package", type: "description" },
-                      { content: usage || "", type: "usage" },
-                      { content: example || "", type: "example" }
-                    ].filter(item => item.content)
                   }
+
+                  docContent = [
+                    { content: description || "Swift package", type: "description" },
+                    { content: usage || "", type: "usage" },
+                    { content: example || "", type: "example" }
+                  ].filter(item => item.content)
                 }
               } catch (githubError) {
-                this.logger.error(`Error fetching GitHub README: ${githubError}`)
+                this.logger.error(`Error fetching repository README: ${githubError}`)
               }
             }
           }
           break
+
+        case "php":
+          // Registry documentation is the only source for these ecosystems
+          if (source === "local") {
+            break
+          }
+          try {
+            const phpContent = await this.phpDocsHandler.getSearchableContent(packageName)
+            packageInfo = phpContent.packageInfo
+            docContent = phpContent.sections
+          } catch (error) {
+            this.logger.error(`Error fetching PHP documentation: ${error}`)
+          }
+          break
+
+        case "java":
+          // Registry documentation is the only source for these ecosystems
+          if (source === "local") {
+            break
+          }
+          try {
+            const javaContent = await this.javaDocsHandler.getSearchableContent(packageName)
+            packageInfo = javaContent.packageInfo
+            docContent = javaContent.sections
+          } catch (error) {
+            this.logger.error(`Error fetching Java documentation: ${error}`)
+          }
+          break
+
+        case "dotnet":
+          // Registry documentation is the only source for these ecosystems
+          if (source === "local") {
+            break
+          }
+          try {
+            const dotnetContent = await this.dotnetDocsHandler.getSearchableContent(packageName)
+            packageInfo = dotnetContent.packageInfo
+            docContent = dotnetContent.sections
+          } catch (error) {
+            this.logger.error(`Error fetching .NET documentation: ${error}`)
+          }
+          break
       }
 
       // If no content was found, return an error
       if (!docContent || (Array.isArray(docContent) && docContent.length === 0)) {
         return {
-          error: `No documentation found for ${packageName}`,
+          error: source === "local"
+            ? `No local documentation found for ${packageName}. Install it, or use source "auto" or "network"`
+            : `No documentation found for ${packageName}`,
           suggestInstall: !isInstalled
         }
       }
 
+      // Otherwise narrow the documentation to sections whose title mentions the symbol
+      if (symbol && !symbolScoped) {
+        const wanted = symbol.toLowerCase()
+        const sections = Array.isArray(docContent)
+          ? docContent
+          : this.searchUtils.splitMarkdownSections(docContent).map(content => ({ content, type: "general" }))
+        const symbolSections = sections.filter(section =>
+          section.content.split('\n')[0].toLowerCase().includes(wanted)
+        )
+
+        if (symbolSections.length === 0) {
+          return {
+            error: `No documentation for ${symbol} found in ${packageName}`,
+            searchResults: {
+              results: [],
+              totalResults: 0
+            }
+          }
+        }
+
+        docContent = symbolSections
+      }
+
       // Perform search on the documentation content
       // eslint-disable-next-line @typescript-eslint/no-explicit-any
       const searchResults: any[] = []
 
       if (Array.isArray(docContent)) {
-        // For structured content (array of sections)
         if (fuzzy) {
           // Use fuzzy search with improved options
           const fuseOptions = {
@@ -1275,101 +1959,22 @@ help(${packageName})
           const fuse = new Fuse(docContent, fuseOptions)
           const results = fuse.search(query)
 
-          for (const result of results) {
-            const section = result.item
-            const symbol = this.searchUtils.extractSymbol(section.content, language)
-
-            // Extract more context around the match
-            const lines = section.content.split('\n')
-            const firstLine = lines[0]
-
-            // Find the specific line that contains the match
-            let matchLineIndex = -1
-            for (let i = 0; i < lines.length; i++) {
-              if (lines[i].toLowerCase().includes(query.toLowerCase())) {
-                matchLineIndex = i
-                break
-              }
-            }
-
-            // Extract more context around the match
-            let contextLines: string[]
-            if (matchLineIndex >= 0) {
-              // Get more context around the specific match
-              const contextStart = Math.max(0, matchLineIndex - 5)
-              const contextEnd = Math.min(lines.length, matchLineIndex + 10)
-              contextLines = lines.slice(contextStart, contextEnd)
-            } else {
-              // If no specific match found, take the first several lines
-              contextLines = lines.slice(1, Math.min(lines.length, 15))
-            }
-
-            // Include code examples in the context if present
-            const codeExampleMatch = section.content.match(/```[\s\S]*?```/)
-            if (codeExampleMatch && !contextLines.some(line => line.includes("```"))) {
-              contextLines.push("") // Add a blank line
-              contextLines.push("Code example:")
-              contextLines.push(codeExampleMatch[0])
-            }
-
-            searchResults.push({
-              symbol,
-              match: firstLine,
-              context: contextLines.join('\n'),
-              score: result.score || 0,
-              type: section.type
-            })
-          }
+          searchResults.push(...await this.sectionSearch.search({
+            sections: results.map(result => result.item),
+            fuzzyScores: results.map(result => result.score ?? 1),
+            query,
+            language,
+            contextSize,
+            ranking
+          }))
         } else {
           // Use exact search with improved context
-          for (const section of docContent) {
-            if (section.content.toLowerCase().includes(query.toLowerCase())) {
-              const symbol = this.searchUtils.extractSymbol(section.content, language)
-              const lines = section.content.split('\n')
-              const firstLine = lines[0]
-
-              // Find the specific line that contains the match
-              let matchLineIndex = -1
-              for (let i = 0; i < lines.length; i++) {
-                if (lines[i].toLowerCase().includes(query.toLowerCase())) {
-                  matchLineIndex = i
-                  break
-                }
-              }
-
-              // Extract more context around the match
-              let contextLines: string[]
-              if (matchLineIndex >= 0) {
-                // Get more context around the specific match
-                const contextStart = Math.max(0, matchLineIndex - 5)
-                const contextEnd = Math.min(lines.length, matchLineIndex + 10)
-                contextLines = lines.slice(contextStart, contextEnd)
-              } else {
-                // If no specific match found, take the first several lines
-                contextLines = lines.slice(1, Math.min(lines.length, 15))
-              }
-
-              // Include code examples in the context if present
-              const codeExampleMatch = section.content.match(/```[\s\S]*?```/)
-              if (codeExampleMatch && !contextLines.some(line => line.includes("```"))) {
-                contextLines.push("") // Add a blank line
-                contextLines.push("Code example:")
-                contextLines.push(codeExampleMatch[0])
-              }
-
-              searchResults.push({
-                symbol,
-                match: firstLine,
-                context: contextLines.join('\n'),
-                score: 0,
-                type: section.type
-              })
-            }
-          }
+          searchResults.push(...await this.sectionSearch.search({ sections: docContent, query, language, contextSize, ranking }))
         }
       } else {
         // For plain text content
         const lines = docContent.split('\n')
+        const queryTerms = parseSearchQuery(query).flat()
 
         // Find all matching lines
         const matchingLineIndices: number[] = []
@@ -1379,7 +1984,8 @@ help(${packageName})
             if (this.searchUtils.fuzzyMatch(line, query)) {
               matchingLineIndices.push(i)
             }
-          } else if (line.toLowerCase().includes(query.toLowerCase())) {
+          } else if (queryTerms.some(term => line.toLowerCase().includes(term))) {
+            // Lines matching any term are grouped, then groups not matching the whole query dropped
             matchingLineIndices.push(i)
           }
         }
@@ -1389,7 +1995,7 @@ help(${packageName})
         let currentGroup: number[] = []
 
         for (let i = 0; i < matchingLineIndices.length; i++) {
-          if (i === 0 || matchingLineIndices[i] > matchingLineIndices[i - 1] + 10) {
+          if (i === 0 || matchingLineIndices[i] > matchingLineIndices[i - 1] + contextSize) {
             if (currentGroup.length > 0) {
               groupedMatches.push(currentGroup)
             }
@@ -1409,9 +2015,12 @@ help(${packageName})
           const lastMatchIndex = group[group.length - 1]
 
           // Get context around the group
-          const contextStart = Math.max(0, firstMatchIndex - 5)
-          const contextEnd = Math.min(lines.length, lastMatchIndex + 10)
+          const contextStart = Math.max(0, firstMatchIndex - Math.ceil(contextSize / 2))
+          const contextEnd = Math.min(lines.length, lastMatchIndex + contextSize)
           const context = lines.slice(contextStart, contextEnd).join('\n')
+          if (!fuzzy && !this.searchUtils.matchesQuery(context, query)) {
+            continue
+          }
 
           // Find a suitable heading for this match
           let heading = "Match"
@@ -1425,7 +2034,12 @@ help(${packageName})
           searchResults.push({
             match: heading,
             context,
-            score: 0
+            source: docContent,
+            sourceLine: firstMatchIndex,
+            // Rank fuzzy groups by their closest line, so a near exact match isn't tied with a distant one
+            score: fuzzy
+              ? Math.min(...group.map(index => this.searchUtils.fuzzyRank(lines[index], query) ?? 1))
+              : this.searchUtils.scoreSectionMatch(context, query, ranking)
           })
         }
       }
@@ -1436,6 +2050,33 @@ help(${packageName})
       // Limit number of results but ensure we have enough context
       const limitedResults = searchResults.slice(0, 5)
 
+      // Clients rendering their own results get the verbatim text around each match and its offsets
+      const hits: SearchHit[] | undefined = format === "json"
+        ? limitedResults.map(result => ({
+          section: result.match,
+          type: result.type,
+          symbol: result.symbol,
+          score: result.score,
+          ...this.searchUtils.locateMatch(result.source, query, contextSize, result.sourceLine)
+        }))
+        : undefined
+      for (const result of limitedResults) {
+        delete result.source
+        delete result.sourceLine
+      }
+
+      // Point out where each result matched, with the first matching line highlighted
+      for (const result of limitedResults) {
+        if (!result.context) continue
+        const matches = this.searchUtils.findMatchSpans(result.context, query)
+        if (matches.length === 0) continue
+        result.matches = matches
+        const matchedLine = result.context.split('\n').find((line: string) => this.searchUtils.findMatchSpans(line, query).length > 0)
+        if (matchedLine) {
+          result.highlight = highlightMatches(matchedLine.trim(), this.searchUtils.findMatchSpans(matchedLine.trim(), query))
+        }
+      }
+
       // Add package metadata to provide context
       let packageMetadata = ""
       if (packageInfo) {
@@ -1451,6 +2092,19 @@ help(${packageName})
           if (packageInfo.summary) packageMetadata += `Description: ${packageInfo.summary}\n`
           if (packageInfo.home_page) packageMetadata += `Homepage: ${packageInfo.home_page}\n`
           if (packageInfo.license) packageMetadata += `Licence: ${packageInfo.license}\n`
+        } else if (language === "php") {
+          if (packageInfo.description) packageMetadata += `Description: ${packageInfo.description}\n`
+          if (packageInfo.repository) packageMetadata += `Repository: ${packageInfo.repository}\n`
+        } else if (language === "java") {
+          if (packageInfo.latestVersion) packageMetadata += `Version: ${packageInfo.latestVersion}\n`
+          if (packageInfo.description) packageMetadata += `Description: ${packageInfo.description}\n`
+          if (packageInfo.url) packageMetadata += `Homepage: ${packageInfo.url}\n`
+          if (packageInfo.licenses?.length) packageMetadata += `Licence: ${packageInfo.licenses.join(", ")}\n`
+        } else if (language === "dotnet") {
+          if (packageInfo.version) packageMetadata += `Version: ${packageInfo.version}\n`
+          if (packageInfo.description) packageMetadata += `Description: ${packageInfo.description}\n`
+          if (packageInfo.projectUrl) packageMetadata += `Homepage: ${packageInfo.projectUrl}\n`
+          if (packageInfo.licenseExpression) packageMetadata += `Licence: ${packageInfo.licenseExpression}\n`
         } else if (language === "swift") {
           const packageName = this.extractSwiftPackageNameFromUrl(packageUrl)
           if (!packageName) {
@@ -1466,7 +2120,8 @@ help(${packageName})
       return {
         description: packageMetadata || undefined,
         searchResults: {
-          results: limitedResults,
+          results: hits ? [] : limitedResults,
+          hits,
           totalResults: searchResults.length,
           suggestInstall: !isInstalled && searchResults.length === 0
         }
@@ -1611,241 +2266,221 @@ help(${packageName})
    * Optimized to return concise results to save LLM context
    */
   private async describeGoPackage(args: GoDocArgs): Promise<DocResult> {
-    const { package: packageName, symbol, projectPath } = args
-    this.logger.debug(`Getting Go documentation for ${packageName}${symbol ? `.${symbol}` : ""}`)
+    const { symbol, projectPath, source = "auto", raw = false } = args
+    // A version can be given separately or as package@version, as with go get
+    const [packageName, pathVersion] = args.package.split("@", 2)
+    const requestedVersion = args.version || pathVersion
+    this.logger.debug(`Getting Go documentation for ${packageName}${symbol ? `.${symbol}` : ""}${requestedVersion ? `@${requestedVersion}` : ""}`)
 
     try {
-      // Check if package is installed locally first
-      const isInstalled = await this.isGoPackageInstalledLocally(packageName, projectPath)
+      // Check if package is installed locally first, unless the network was requested. The local
+      // copy may be any version, so a requested version is always downloaded instead. Standard
+      // library packages come with Go, so there's nothing to look up: go doc is tried first below,
+      // and pkg.go.dev only used when it fails (as it does without Go installed).
+      const isInstalled = !requestedVersion && source !== "network" && !isGoStandardLibrary(packageName) &&
+        await this.isGoPackageInstalledLocally(packageName, projectPath)
 
       if (isInstalled) {
         this.logger.debug(`Using local documentation for ${packageName}`)
-        return await this.getLocalGoDoc(packageName, symbol)
+        return await this.getLocalGoDoc(packageName, symbol, raw, projectPath)
       }
 
-      // If not installed, try to fetch from pkg.go.dev
-      this.logger.debug(`Fetching Go documentation for ${packageName} from pkg.go.dev`)
-
-      try {
-        // First try using go doc command (works for standard library and cached modules)
-        const { stdout } = await safeGoDoc(packageName, symbol)
-
-        // Parse the output into a structured format
-        const lines = stdout.split("\n")
-        const result: DocResult = {}
-
-        let section: "description" | "usage" | "example" = "description"
-        let content: string[] = []
+      const version = await this.registryVersion("go", { ...args, package: packageName, version: requestedVersion })
 
-        for (const line of lines) {
-          if (line.startsWith("func") || line.startsWith("type")) {
-            if (content.length > 0) {
-              result[section] = content.join("\n").trim()
-            }
-            section = "usage"
-            content = [line]
-          } else if (line.includes("Example")) {
-            if (content.length > 0) {
-              result[section] = content.join("\n").trim()
-            }
-            section = "example"
-            content = []
-          } else {
-            content.push(line)
+      // First try using go doc command (works for standard library and cached modules), on the
+      // requested version downloaded to the module cache when there is one. It's skipped when the
+      // network was requested.
+      if (source !== "network") {
+        try {
+          return await this.getGoDocDescription(packageName, version, symbol, raw, projectPath)
+        } catch (cmdError) {
+          if (source === "local") {
+            return localSourceError(packageName)
           }
+          this.logger.debug(describeCommandFallback(cmdError, "pkg.go.dev"))
         }
+      }
 
-        if (content.length > 0) {
-          result[section] = content.join("\n").trim()
+      // Otherwise fetch the documentation from pkg.go.dev, starting with its API
+      this.logger.debug(`Fetching Go documentation for ${packageName} from pkg.go.dev`)
+      try {
+        if (version) {
+          throw new Error("the pkg.go.dev API only describes the latest version")
         }
+        const url = `https://pkg.go.dev/api/packages/${encodeURIComponent(packageName)}`
+        this.logger.debug(`Fetching from pkg.go.dev API: ${url}`)
 
-        return result
-      } catch {
-        // If go doc command fails, try to fetch from pkg.go.dev API
-        try {
-          const url = `https://pkg.go.dev/api/packages/${encodeURIComponent(packageName)}`
-          this.logger.debug(`Fetching from pkg.go.dev API: ${url}`)
-
-          const response = await axios.get(url)
+        const response = await axios.get(url)
 
-          if (response.data) {
-            const pkgInfo = response.data
+        if (response.data) {
+          const pkgInfo = response.data
 
-            // Create a structured result from the API response
-            const result: DocResult = {
-              description: pkgInfo.Synopsis || `Go package: ${packageName}`
-            }
+          // Create a structured result from the API response
+          const result: DocResult = {
+            description: pkgInfo.Synopsis || `Go package: ${packageName}`
+          }
 
-            // Add documentation if available
-            if (pkgInfo.Documentation) {
-              result.usage = pkgInfo.Documentation
-            }
+          // Add documentation if available
+          if (pkgInfo.Documentation) {
+            result.usage = pkgInfo.Documentation
+          }
 
-            // Add import example
-            result.example = `// Import the package
+          // Add import example
+          result.example = `// Import the package
 import "${packageName}"
 
-// See full documentation at: https://pkg.go.dev/${encodeURIComponent(packageName)}`
+// See full documentation at: ${pkgGoDevUrl(packageName)}`
 
-            return result
-          }
-        } catch (apiError) {
-          this.logger.error(`Error fetching from pkg.go.dev API: ${apiError}`)
+          return result
         }
+      } catch (apiError) {
+        this.logger.error(`Error fetching from pkg.go.dev API: ${apiError}`)
+      }
 
-        // If both methods fail, try to fetch from GitHub if it's a GitHub URL
-        if (packageName.includes('github.com')) {
-          try {
-            // Extract GitHub owner and repo from the package name
-            const githubMatch = packageName.match(/github\.com\/([^/]+)\/([^/]+)/)
-            if (githubMatch) {
-              const owner = githubMatch[1]
-              const repo = githubMatch[2]
-
-              // Try to fetch README.md from the main branch
-              const readmeUrl = `https://raw.githubusercontent.com/${owner}/${repo}/main/README.md`
-              this.logger.debug(`Attempting to fetch README from GitHub: ${readmeUrl}`)
-
-              const readmeResponse = await axios.get(readmeUrl)
-              if (readmeResponse.data) {
-                const readme = readmeResponse.data
-
-                // Parse the README content
-                const sections = readme.split(/#+\s/)
-                let description = ""
-                let usage = ""
-                let example = ""
-
-                // Extract relevant sections
-                for (const section of sections) {
-                  const lower = section.toLowerCase()
-                  if (lower.startsWith("introduction") || lower.startsWith("about") ||
-                      lower.startsWith("overview") || lower.startsWith("description")) {
-                    description = section
-                  } else if (lower.startsWith("usage") || lower.startsWith("getting started") ||
-                            lower.startsWith("quickstart") || lower.startsWith("installation")) {
-                    usage = section
-                  } else if (lower.startsWith("example")) {
-                    example = section
-                  }
-                }
-
-                // If we couldn't find a description section, use the first section
-                if (!description && sections.length > 1) {
-                  description = sections[1]
-                }
-
-                // Format the description
-                const formattedDescription = description ?
-                  description.split("\n").slice(0, 3).join("\n").trim() :
-                  `Go package: ${packageName}`
-
-                // Format the usage
-                const formattedUsage = usage ?
-                  usage :
-                  `For detailed documentation, visit: https://pkg.go.dev/${encodeURIComponent(packageName)}`
-
-                // Format the example
-                const formattedExample = example ?
-                  example :
-                  `// Import the package\nimport "${packageName}"\n\n// For more details, visit: https://pkg.go.dev/${encodeURIComponent(packageName)}`
-
-                return {
-                  description: formattedDescription,
-                  usage: formattedUsage,
-                  example: formattedExample
+      // If both methods fail, try to fetch from GitHub if it's a GitHub URL (whose README is only
+      // for the latest version)
+      if (!version && packageName.includes('github.com')) {
+        try {
+          // Extract GitHub owner and repo from the package name
+          const githubMatch = packageName.match(/github\.com\/([^/]+)\/([^/]+)/)
+          if (githubMatch) {
+            const owner = githubMatch[1]
+            const repo = githubMatch[2]
+
+            // Try to fetch README.md from the main branch
+            const readmeUrl = `https://raw.githubusercontent.com/${owner}/${repo}/main/README.md`
+            this.logger.debug(`Attempting to fetch README from GitHub: ${readmeUrl}`)
+
+            const readmeResponse = await axios.get(readmeUrl)
+            if (readmeResponse.data) {
+              const readme = readmeResponse.data
+
+              // Parse the README content
+              const sections = readme.split(/#+\s/)
+              let description = ""
+              let usage = ""
+              let example = ""
+
+              // Extract relevant sections
+              for (const section of sections) {
+                const lower = section.toLowerCase()
+                if (lower.startsWith("introduction") || lower.startsWith("about") ||
+                    lower.startsWith("overview") || lower.startsWith("description")) {
+                  description = section
+                } else if (lower.startsWith("usage") || lower.startsWith("getting started") ||
+                          lower.startsWith("quickstart") || lower.startsWith("installation")) {
+                  usage = section
+                } else if (lower.startsWith("example")) {
+                  example = section
                 }
               }
-            }
-          } catch (githubError) {
-            this.logger.error(`Error fetching from GitHub: ${githubError}`)
-          }
-        }
-
-        // If GitHub fetch fails or it's not a GitHub URL, try web scraping approach
-        try {
-          const url = `https://pkg.go.dev/${encodeURIComponent(packageName)}`
-          this.logger.debug(`Attempting to fetch documentation from: ${url}`)
-
-          const response = await axios.get(url)
 
-          if (response.data) {
-            // Extract basic package information from HTML
-            const html = response.data
-
-            // Simple extraction of package description
-            const descriptionMatch = html.match(/<meta name="description" content="([^"]+)"/)
-            const description = descriptionMatch ? descriptionMatch[1] : `Go package: ${packageName}`
+              // If we couldn't find a description section, use the first section
+              if (!description && sections.length > 1) {
+                description = sections[1]
+              }
 
-            // Try to extract documentation content
-            const docMatch = html.match(/<div class="Documentation-content">[\s\S]*?<\/div>/)
-            const documentation = docMatch ? docMatch[0] : ""
+              // Format the description
+              const formattedDescription = description ?
+                description.split("\n").slice(0, 3).join("\n").trim() :
+                `Go package: ${packageName}`
 
-            // Try to extract package overview
-            const overviewMatch = html.match(/<section id="pkg-overview"[\s\S]*?<\/section>/)
-            const overview = overviewMatch ? overviewMatch[0] : ""
+              // Format the usage
+              const formattedUsage = usage ?
+                usage :
+                `For detailed documentation, visit: ${pkgGoDevUrl(packageName)}`
 
-            // Extract function signatures
-            const funcSignatures: string[] = []
-            const funcSignatureMatches = html.matchAll(/<h3 id="([^"]*)">func\s+([^<]+)<\/h3>/g)
-            for (const match of funcSignatureMatches) {
-              funcSignatures.push(`func ${match[2]}`)
-            }
+              // Format the example
+              const formattedExample = example ?
+                example :
+                `// Import the package\nimport "${packageName}"\n\n// For more details, visit: ${pkgGoDevUrl(packageName)}`
 
-            // Extract type definitions
-            const typeDefinitions: string[] = []
-            const typeDefMatches = html.matchAll(/<h3 id="([^"]*)">type\s+([^<]+)<\/h3>/g)
-            for (const match of typeDefMatches) {
-              typeDefinitions.push(`type ${match[2]}`)
+              return {
+                description: formattedDescription,
+                usage: formattedUsage,
+                example: formattedExample,
+                readme: args.includeRaw ? readme : undefined
+              }
             }
+          }
+        } catch (githubError) {
+          this.logger.error(`Error fetching from GitHub: ${githubError}`)
+        }
+      }
 
-            // Extract code examples if available
-            const examplesMatch = html.match(/<pre class="Documentation-exampleCode">[\s\S]*?<\/pre>/g)
-            const examples = examplesMatch ? examplesMatch.join("\n\n") : ""
-
-            // Clean up HTML tags from the extracted content
-            const cleanHtml = (html: string): string => {
-              return html
-                .replace(/<[^>]*>/g, '') // Remove HTML tags
-                .replace(/&lt;/g, '<')   // Replace HTML entities
-                .replace(/&gt;/g, '>')
-                .replace(/&amp;/g, '&')
-                .replace(/&quot;/g, '"')
-                .replace(/&#39;/g, "'")
-                .replace(/\s+/g, ' ')    // Normalize whitespace
-                .trim();
-            };
+      // If GitHub fetch fails or it's not a GitHub URL, try web scraping approach
+      try {
+        const url = pkgGoDevUrl(packageName, version)
+        this.logger.debug(`Attempting to fetch documentation from: ${url}`)
 
-            // Combine all the extracted content for usage
-            const usage = [
-              overview ? cleanHtml(overview) : "",
-              documentation ? cleanHtml(documentation) : "",
-              funcSignatures.length > 0 ? "## Function Signatures\n" + funcSignatures.join("\n") : "",
-              typeDefinitions.length > 0 ? "## Type Definitions\n" + typeDefinitions.join("\n") : ""
-            ].filter(Boolean).join("\n\n");
+        const response = await axios.get(url)
 
-            // Create example content
-            const example = examples || `// Import the package
+        if (response.data) {
+          // Extract basic package information from HTML, with links resolved against the page it
+          // was served from, after any redirect
+          const html = resolveRelativeLinks(String(response.data), response.request?.res?.responseUrl ?? url)
+
+          // Simple extraction of package description
+          const descriptionMatch = html.match(/<meta name="description" content="([^"]+)"/)
+          const description = descriptionMatch ? descriptionMatch[1] : `Go package: ${packageName}`
+
+          // Try to extract documentation content
+          const docMatch = html.match(/<div class="Documentation-content">[\s\S]*?<\/div>/)
+          const documentation = docMatch ? docMatch[0] : ""
+
+          // Extract the package overview and declarations, which works the same for the standard
+          // library (net/http) and subpackages as for module roots
+          const { overview, functions: funcSignatures, types: typeDefinitions } = extractPkgGoDevDocs(html)
+
+          // Extract code examples if available
+          const examples = extractHtmlCodeBlocks(html, ".Documentation-exampleCode")
+            .map(block => `\`\`\`go\n${block.code}\n\`\`\``)
+            .join("\n\n")
+
+          // Clean up HTML tags from the extracted content
+          const cleanHtml = (html: string): string => {
+            return html
+              .replace(/<[^>]*>/g, '') // Remove HTML tags
+              .replace(/&lt;/g, '<')   // Replace HTML entities
+              .replace(/&gt;/g, '>')
+              .replace(/&amp;/g, '&')
+              .replace(/&quot;/g, '"')
+              .replace(/&#39;/g, "'")
+              .replace(/\s+/g, ' ')    // Normalize whitespace
+              .trim();
+          };
+
+          // Combine all the extracted content for usage
+          const usage = [
+            overview || "",
+            // The whole documentation repeats the overview, so it's only a fallback
+            !overview && documentation ? cleanHtml(documentation) : "",
+            funcSignatures.length > 0 ? "## Function Signatures\n" + funcSignatures.join("\n") : "",
+            typeDefinitions.length > 0 ? "## Type Definitions\n" + typeDefinitions.join("\n") : ""
+          ].filter(Boolean).join("\n\n");
+
+          // Create example content
+          const example = examples || `// Import the package
 import "${packageName}"
 
-// For more details, visit: https://pkg.go.dev/${encodeURIComponent(packageName)}`
+// For more details, visit: ${pkgGoDevUrl(packageName)}`
 
-            return {
-              description,
-              usage: usage || `For detailed documentation, visit: https://pkg.go.dev/${encodeURIComponent(packageName)}`,
-              example
-            }
+          return {
+            description,
+            usage: usage || `For detailed documentation, visit: ${pkgGoDevUrl(packageName)}`,
+            example
           }
-        } catch (webError) {
-          this.logger.error(`Error fetching from pkg.go.dev website: ${webError}`)
         }
+      } catch (webError) {
+        this.logger.error(`Error fetching from pkg.go.dev website: ${webError}`)
+      }
 
-        // If all methods fail, return a more helpful error
-        return {
-          description: `Go package: ${packageName}`,
-          error: `Could not fetch detailed documentation for ${packageName}. You can view it online at https://pkg.go.dev/${encodeURIComponent(packageName)}`,
-          suggestInstall: false
-        }
+      // If all methods fail, return a more helpful error
+      const suggestion = await this.packageSearch.suggestAlternatives("go", packageName)
+      return {
+        description: `Go package: ${packageName}`,
+        error: `Could not fetch detailed documentation for ${packageName}.${suggestion ? ` ${suggestion}` : ""} You can view it online at ${pkgGoDevUrl(packageName)}`,
+        suggestInstall: false
       }
     } catch (error) {
       const errorMessage = error instanceof Error ? error.message : String(error)
@@ -1856,28 +2491,82 @@ import "${packageName}"
     }
   }
 
+  /**
+   * Describe a Go package from go doc, run on the given version downloaded to the module cache, or
+   * on the package as found from the project (or the standard library) without one
+   */
+  private async getGoDocDescription(packageName: string, version: string | undefined, symbol: string | undefined, raw: boolean, projectPath?: string): Promise<DocResult> {
+    let stdout: string
+    if (version) {
+      const { moduleDir, packageDir } = await this.downloadGoModule(packageName, version)
+      stdout = (await safeGoDoc(packageDir, symbol, moduleDir)).stdout
+    } else {
+      stdout = (await safeGoDoc(packageName, symbol, projectPath)).stdout
+    }
+    if (raw) {
+      return { usage: stdout }
+    }
+
+    // Parse the output into a structured format
+    const lines = stdout.split("\n")
+    const result: DocResult = {}
+
+    let section: "description" | "usage" | "example" = "description"
+    let content: string[] = []
+
+    for (const line of lines) {
+      if (line.startsWith("func") || line.startsWith("type")) {
+        if (content.length > 0) {
+          result[section] = content.join("\n").trim()
+        }
+        section = "usage"
+        content = [line]
+      } else if (line.includes("Example")) {
+        if (content.length > 0) {
+          result[section] = content.join("\n").trim()
+        }
+        section = "example"
+        content = []
+      } else {
+        content.push(line)
+      }
+    }
+
+    if (content.length > 0) {
+      result[section] = content.join("\n").trim()
+    }
+
+    return result
+  }
+
   /**
    * Get documentation for a Python package
    * Optimized to return concise results to save LLM context
    */
   private async describePythonPackage(args: PythonDocArgs): Promise<DocResult> {
-    const { package: packageName, symbol } = args
-    this.logger.debug(`Getting Python documentation for ${packageName}${symbol ? `.${symbol}` : ""}`)
+    const { package: packageName, symbol, projectPath, source = "auto", raw = false } = args
+    this.logger.debug(`Getting Python documentation for ${packageName}${symbol ? `.${symbol}` : ""}${args.version ? ` ${args.version}` : ""}`)
 
     try {
-      // Check if package is installed locally first
-      const isInstalled = await this.isPythonPackageInstalledLocally(packageName)
+      // Check if package is installed locally first, unless the network was requested. The local
+      // copy may be any version, so a requested version is always read from PyPI instead.
+      const isInstalled = !args.version && source !== "network" && await this.isPythonPackageInstalledLocally(packageName, projectPath)
 
       if (isInstalled) {
         this.logger.debug(`Using local documentation for ${packageName}`)
-        return await this.getLocalPythonDoc(packageName, symbol)
+        return await this.getLocalPythonDoc(packageName, symbol, projectPath, raw)
+      }
+
+      if (source === "local") {
+        return localSourceError(packageName)
       }
+      const version = await this.registryVersion("python", args)
 
       // If not installed, try to fetch from PyPI
       this.logger.debug(`Fetching Python documentation for ${packageName} from PyPI`)
 
       try {
-        const url = `https://pypi.org/pypi/${packageName}/json`
+        const url = pypiJsonUrl(packageName, version)
         const response = await axios.get(url)
 
         if (response.data && response.data.info) {
@@ -1885,13 +2574,47 @@ import "${packageName}"
             description: response.data.info.summary || "No description available"
           }
 
+          // Surface the platforms covered by the release's wheels
+          const releaseFiles = response.data.releases?.[response.data.info.version] || response.data.urls || []
+          const platformLine = formatPlatforms(extractPythonPlatforms(releaseFiles))
+          if (platformLine) {
+            result.description += `\n\n${platformLine}`
+          }
+
           // Add more detailed description if available, but limit size
           if (response.data.info.description) {
             // Truncate description to a reasonable length
-            const description = response.data.info.description
-            result.usage = description.length > 1000
-              ? description.substring(0, 1000) + "... (truncated)"
-              : description
+            const description = getPyPIDescription(response.data.info)
+            result.usage = truncateText(description, 1000)
+          }
+
+          const links = getPyPILinks(response.data.info)
+          if (Object.keys(links).length > 0) {
+            result.description += "\n\nLinks:\n" + Object.entries(links).map(([kind, url]) => `- ${kind}: ${url}`).join("\n")
+          }
+
+          // Supported Python versions, maturity and licence from the classifiers, and the latest releases
+          const compatibility = formatPyPICompatibility(
+            response.data.info.classifiers || [],
+            response.data.info.requires_python || undefined,
+            response.data.releases
+          )
+          if (compatibility) {
+            result.description += `\n\n${compatibility}`
+          }
+
+          // Projects documented with Sphinx (e.g. on Read the Docs) have a real API reference, read on
+          // request as it can take several more requests
+          const apiReference = args.includeApiReference ? await this.getSphinxApiReference(packageName, response.data.info) : undefined
+          if (apiReference) {
+            result.usage = result.usage ? `${result.usage}\n\n${apiReference}` : apiReference
+          }
+
+          if (args.format === "json") {
+            result.metadata = getPyPIMetadata(response.data.info)
+          }
+          if (args.includeRaw && response.data.info.description) {
+            result.readme = getPyPIDescription(response.data.info)
           }
 
           return result
@@ -1901,10 +2624,13 @@ import "${packageName}"
             suggestInstall: true
           }
         }
-      } catch {
-        // If PyPI request fails, suggest installation
+      } catch (error) {
+        // If PyPI request fails, suggest installation and any similarly named packages
+        const suggestion = axios.isAxiosError(error) && error.response?.status === 404
+          ? await this.packageSearch.suggestAlternatives("python", packageName)
+          : undefined
         return {
-          error: `Package ${packageName} not found. Try installing it with 'pip install ${packageName}'`,
+          error: `Package ${packageName} not found.${suggestion ? ` ${suggestion}` : ""} Try installing it with 'pip install ${packageName}'`,
           suggestInstall: true
         }
       }
@@ -1918,37 +2644,840 @@ import "${packageName}"
   }
 
   /**
-   * Get documentation for a Rust package
+   * Get the API reference from a Python package's Sphinx documentation, linked from its PyPI project URLs.
+   * When the docs home page has no autodoc entries, the pages it links as the API reference are tried.
    */
-  private async describeRustPackage(args: { package: string, version?: string }): Promise<DocResult> {
-    const { package: crateName, version } = args
-    this.logger.debug(`Getting Rust documentation for ${crateName}${version ? ` version ${version}` : ""}`)
+  // eslint-disable-next-line @typescript-eslint/no-explicit-any
+  private async getSphinxApiReference(packageName: string, info: any): Promise<string | undefined> {
+    const links = getPyPILinks(info)
+    const docsUrl = links.Documentation || Object.values(links).find(url => /readthedocs\.(io|org)/.test(url))
+    if (!docsUrl) {
+      return undefined
+    }
 
     try {
-      // Check if crate is installed locally first
-      const isInstalled = await this.isRustCrateInstalledLocally(crateName)
-
-      if (isInstalled) {
-        this.logger.debug(`Using local documentation for ${crateName}`)
-        return await this.getLocalRustDoc(crateName)
+      const response = await axios.get(docsUrl, { responseType: "text", timeout: 10000 })
+      const html = String(response.data)
+      if (!isSphinxPage(html)) {
+        return undefined
       }
 
-      // If not installed, try to fetch from docs.rs
-      this.logger.debug(`Fetching Rust documentation for ${crateName} from docs.rs`)
-
-      try {
-        // Get crate details from crates.io
-        const crateDetails = await this.rustDocsHandler.getCrateDetails(crateName)
+      let entries = extractSphinxApi(html)
+      if (entries.length === 0) {
+        // Follow the home page's links to its API reference pages
+        const pages = findLinkedPages(html, docsUrl, /\bapi\b|reference/i)
+        for (const page of pages.slice(0, 5)) {
+          try {
+            const pageResponse = await axios.get(page, { responseType: "text", timeout: 10000 })
+            entries.push(...extractSphinxApi(String(pageResponse.data)))
+          } catch {
+            // Skip pages that can't be fetched
+          }
+        }
+        entries = entries.filter((entry, index) => entries.findIndex(other => other.name === entry.name) === index)
+      }
 
-        // Get documentation from docs.rs
-        const documentation = await this.rustDocsHandler.getCrateDocumentation(crateName, version)
+      if (entries.length === 0) {
+        return undefined
+      }
 
-        // Extract a brief description from the documentation
-        const briefDescription = documentation.split('\n\n')[0] || crateDetails.description || `Rust crate: ${crateName}`
+      this.logger.debug(`Found ${entries.length} Sphinx API entries for ${packageName} at ${docsUrl}`)
+      return truncateText(
+        "## API Reference\n\n" + entries
+          .map(entry => `### ${entry.name} (${entry.kind})\n\n\`\`\`python\n${entry.signature}\n\`\`\`${entry.description ? `\n\n${entry.description}` : ""}`)
+          .join("\n\n"),
+        10000
+      )
+    } catch (error) {
+      this.logger.debug(`Could not fetch Sphinx documentation for ${packageName} from ${docsUrl}: ${error}`)
+      return undefined
+    }
+  }
 
+  /**
+   * Get structured registry metadata for a package
+   */
+  private async getPackageMetadata(
+    language: string,
+    args: { package: string; version?: string; projectPath?: string }
+  ): Promise<PackageMetadata> {
+    const { package: packageName, version, projectPath } = args
+
+    switch (language) {
+      case "npm":
+        return await this.npmDocsHandler.getPackageMetadata(
+          packageName,
+          version,
+          this.registryUtils.getRegistryConfigForPackage(packageName, projectPath)
+        )
+
+      case "python":
+        return getPyPIMetadata((await axios.get(pypiJsonUrl(packageName, version))).data.info)
+
+      case "rust":
+        return await this.getCrateMetadata(await this.rustDocsHandler.getCrateDetails(packageName), version)
+
+      case "go": {
+        // go list only knows about packages in the module cache or standard library
+        try {
+          const { stdout } = await runCommand('go', { args: ['list', '-json', sanitiseInput(packageName)], cwd: projectPath })
+          const goPackage = JSON.parse(stdout)
+          return {
+            name: goPackage.ImportPath,
+            version: goPackage.Module?.Version,
+            description: goPackage.Doc,
+            repository: goPackage.Module?.Path ? `https://${goPackage.Module.Path}` : undefined,
+            dependencies: Object.fromEntries((goPackage.Imports || []).map((imported: string) => [imported, ""])),
+          }
+        } catch (error) {
+          this.logger.debug(`go list failed for ${packageName}: ${error}`)
+          return {
+            name: packageName,
+            homepage: pkgGoDevUrl(packageName),
+          }
+        }
+      }
+
+      case "swift":
+        return {
+          name: this.extractSwiftPackageNameFromUrl(packageName) || packageName,
+          repository: packageName,
+        }
+
+      case "php":
+        return await this.phpDocsHandler.getPackageMetadata(packageName, version)
+
+      case "java":
+        return await this.javaDocsHandler.getPackageMetadata(packageName, version)
+
+      case "dotnet":
+        return await this.dotnetDocsHandler.getPackageMetadata(packageName, version)
+
+      default:
+        return { name: packageName }
+    }
+  }
+
+  /**
+   * Map a crate's crates.io details to structured metadata for a version (the latest by default),
+   * with the dependencies of that version
+   */
+  private async getCrateMetadata(crateDetails: Awaited<ReturnType<RustDocsHandler["getCrateDetails"]>>, version?: string): Promise<PackageMetadata> {
+    const resolvedVersion = version || crateDetails.latestVersion || crateDetails.versions[0]?.version
+    const crateVersion = crateDetails.versions.find(v => v.version === resolvedVersion)
+    const dependencies: Record<string, string> = {}
+    if (resolvedVersion) {
+      for (const dependency of await this.rustDocsHandler.getCrateDependencies(crateDetails.name, resolvedVersion)) {
+        if (dependency.kind === "normal") dependencies[dependency.name] = dependency.req
+      }
+    }
+
+    return {
+      name: crateDetails.name,
+      version: resolvedVersion,
+      description: crateDetails.description,
+      license: crateVersion?.license || crateDetails.license,
+      homepage: crateDetails.homepage,
+      repository: crateDetails.repository,
+      keywords: crateDetails.keywords,
+      dependencies,
+      deprecated: crateVersion?.isYanked ? "Yanked" : undefined,
+    }
+  }
+
+  /**
+   * Resolve a package's source repository from its ecosystem's metadata: the import path for Go,
+   * the package URL for Swift, and the registry's repository field (or PyPI's project URLs) otherwise
+   */
+  private async resolveRepository(language: string, packageName: string, projectPath?: string): Promise<RepositoryRef | undefined> {
+    if (language === "go") {
+      return getGoRepositoryUrl(packageName)
+    }
+    if (language === "swift") {
+      return parseRepositoryUrl(packageName)
+    }
+
+    try {
+      const { repository } = await this.getPackageMetadata(language, { package: packageName, projectPath })
+      return repository ? parseRepositoryUrl(repository) : undefined
+    } catch (error) {
+      this.logger.debug(`Could not resolve the repository of ${packageName}: ${error}`)
+      return undefined
+    }
+  }
+
+  /**
+   * Find a package's changelog: the file PyPI projects link from their project URLs, otherwise the
+   * first of the usual changelog files in the repository. changelogUrl is the linked changelog,
+   * which may be a page that couldn't be read.
+   */
+  private async findChangelog(language: string, packageName: string, repository?: string): Promise<{ changelog?: string; source?: string; changelogUrl?: string }> {
+    let changelogUrl: string | undefined
+
+    // PyPI projects often link their changelog directly from the project URLs
+    if (language === "python") {
+      const response = await axios.get(pypiJsonUrl(packageName))
+      changelogUrl = getPyPILinks(response.data?.info).Changelog
+    }
+
+    // A changelog linked as a file on GitHub can be read directly
+    const blobMatch = changelogUrl?.match(/^https:\/\/github\.com\/([^/]+\/[^/]+)\/blob\/(.+)$/)
+    if (blobMatch) {
+      try {
+        const response = await axios.get(`https://raw.githubusercontent.com/${blobMatch[1]}/${blobMatch[2]}`, { responseType: "text" })
+        return { changelog: String(response.data), source: changelogUrl, changelogUrl }
+      } catch (error) {
+        this.logger.debug(`Error fetching linked changelog ${changelogUrl}: ${error}`)
+      }
+    }
+
+    if (repository) {
+      const file = await createRepoClient(repository, this.logger)?.getFile(CHANGELOG_FILE_NAMES)
+      if (file) {
+        return { changelog: file.content, source: file.path, changelogUrl }
+      }
+    }
+
+    return { changelogUrl }
+  }
+
+  /**
+   * Get a package's changelog from its repository, optionally narrowed to a single version's entry
+   */
+  private async getPackageChangelog(args: ChangelogArgs): Promise<DocResult> {
+    const { package: packageName, language, version, projectPath, maxLength = 20000 } = args
+    this.logger.debug(`Getting changelog for ${language} package ${packageName}`)
+
+    try {
+      const repository = (await this.resolveRepository(language, packageName, projectPath))?.url
+      const { changelog, source, changelogUrl } = await this.findChangelog(language, packageName, repository)
+
+      if (!changelog) {
+        return {
+          error: changelogUrl
+            ? `Could not read the changelog for ${packageName} directly, but it is published at ${changelogUrl}`
+            : `No changelog found for ${packageName}${repository ? ` in ${repository}` : ""}. Checked ${CHANGELOG_FILE_NAMES.join(", ")}; the project may publish release notes on GitHub Releases instead.`
+        }
+      }
+
+      let usage = changelog
+      let error: string | undefined
+      if (version) {
+        const entry = this.searchUtils.extractVersionSection(changelog, version)
+        if (entry) {
+          usage = entry
+        } else {
+          error = `No entry for version ${version} found in the changelog`
+        }
+      }
+
+      usage = truncateMarkdown(usage, maxLength)
+
+      return {
+        description: `Changelog for ${packageName}${version ? ` ${version}` : ""} (from ${source})`,
+        usage,
+        error,
+      }
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error getting changelog for ${packageName}:`, error)
+      return {
+        error: `Failed to fetch changelog: ${errorMessage}`
+      }
+    }
+  }
+
+  /**
+   * Get only the migration guidance for upgrading a package: the migration sections of its README,
+   * its migration guide file, and the breaking changes its changelog lists after fromVersion
+   */
+  private async getMigrationGuide(args: MigrationGuideArgs): Promise<DocResult> {
+    const { package: packageName, language, fromVersion, toVersion, projectPath, maxLength = 20000 } = args
+    this.logger.debug(`Getting migration guide for ${language} package ${packageName}${fromVersion ? ` from ${fromVersion}` : ""}`)
+
+    try {
+      const repository = (await this.resolveRepository(language, packageName, projectPath))?.url
+      const [readme, guide, { changelog, source: changelogSource }] = await Promise.all([
+        this.getPackageReadme(language, packageName, projectPath),
+        repository ? createRepoClient(repository, this.logger)?.getFile(MIGRATION_FILE_NAMES) : Promise.resolve(undefined),
+        this.findChangelog(language, packageName, repository).catch(() => ({ changelog: undefined, source: undefined })),
+      ])
+
+      const sections: string[] = []
+      const sources: string[] = []
+
+      if (guide) {
+        const guideSections = this.searchUtils.extractMigrationSections(guide.content, fromVersion)
+        // A dedicated guide without migration headings is still all migration guidance
+        const content = guideSections.length > 0
+          ? guideSections.map(formatDocSection).join("\n\n")
+          : guide.content.trim()
+        if (content) {
+          sections.push(`# From ${guide.path}\n\n${content}`)
+          sources.push(guide.path)
+        }
+      }
+
+      if (readme) {
+        const readmeSections = this.searchUtils.extractMigrationSections(readme, fromVersion)
+        if (readmeSections.length > 0) {
+          sections.push(`# From the README\n\n${readmeSections.map(formatDocSection).join("\n\n")}`)
+          sources.push("README")
+        }
+      }
+
+      if (changelog) {
+        const breaking = this.searchUtils.extractBreakingChanges(changelog, fromVersion, toVersion)
+        if (breaking.length > 0) {
+          sections.push(`# Breaking changes from ${changelogSource}\n\n${breaking.map(entry => `## ${entry.version}\n\n${entry.content}`).join("\n\n")}`)
+          sources.push(changelogSource ?? "changelog")
+        }
+      }
+
+      const range = `${fromVersion ? ` since ${fromVersion}` : ""}${toVersion ? ` up to ${toVersion}` : ""}`
+      if (sections.length === 0) {
+        return {
+          error: `No migration guidance found for ${packageName}${range}${repository ? ` in ${repository}` : ""}. Checked the README, ${MIGRATION_FILE_NAMES.join(", ")} and the changelog's breaking changes.`
+        }
+      }
+
+      return {
+        description: `Migration guide for ${packageName}${range} (from ${sources.join(", ")})`,
+        usage: truncateSections(sections, maxLength),
+      }
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error getting migration guide for ${packageName}:`, error)
+      return {
+        error: `Failed to fetch migration guide: ${errorMessage}`
+      }
+    }
+  }
+
+  /**
+   * Get the OpenAPI spec or JSON Schema a package's repository ships. Schema-like files are looked
+   * for at the root and in the usual spec directories, falling back to common file names when the
+   * repository can't be listed, and the first found is returned with the others listed.
+   */
+  private async getPackageSchema(args: SchemaArgs): Promise<DocResult> {
+    const { package: packageName, language, path, projectPath, maxLength = 50000 } = args
+    this.logger.debug(`Getting schema for ${language} package ${packageName}`)
+
+    try {
+      const repository = await this.resolveRepository(language, packageName, projectPath)
+      const repoClient = repository ? createRepoClient(repository, this.logger) : undefined
+      if (!repoClient) {
+        return {
+          error: `Could not find the source repository of ${packageName} to look for a schema in`
+        }
+      }
+
+      let candidates: string[]
+      if (path) {
+        candidates = [path]
+      } else {
+        const root = await repoClient.listDir()
+        const directories = root.filter(entry => entry.type === "dir" && SCHEMA_DIRECTORIES.includes(entry.name.toLowerCase()))
+        const listings = [root, ...await Promise.all(directories.map(directory => repoClient.listDir(directory.path)))]
+        candidates = listings.flat()
+          .filter(entry => entry.type === "file" && SCHEMA_FILE_PATTERN.test(entry.name))
+          .map(entry => entry.path)
+          // OpenAPI and Swagger specs describe more of a package's API than a JSON Schema
+          .sort((a, b) => Number(/schema\.json$/i.test(a)) - Number(/schema\.json$/i.test(b)))
+        if (root.length === 0) candidates = SCHEMA_FILE_NAMES
+      }
+
+      let schema: RepoFile | undefined
+      for (const candidate of candidates) {
+        const file = await repoClient.getFile(candidate)
+        // A file named like a schema may be something else, such as a package's own config
+        if (file && (path || describeSchemaKind(file.content))) {
+          schema = file
+          break
+        }
+      }
+
+      if (!schema) {
         return {
-          description: briefDescription,
-          usage: `## ${crateName} ${crateDetails.versions[0]?.version || ''}
+          error: path
+            ? `${path} not found in ${repoClient.repository.url}`
+            : `No OpenAPI spec or JSON Schema found in ${repoClient.repository.url}. Checked ${candidates.length > 0 ? candidates.join(", ") : `the root and ${SCHEMA_DIRECTORIES.join(", ")} directories`}`
+        }
+      }
+
+      const schemaPath = schema.path
+      const kind = describeSchemaKind(schema.content) || "Schema"
+      const codeLanguage = schemaPath.endsWith(".json") ? "json" : "yaml"
+      const others = candidates.filter(candidate => candidate !== schemaPath)
+      return {
+        description: `${kind} for ${packageName} (from ${schemaPath} in ${repoClient.repository.url})` +
+          (others.length > 0 && !path ? `. Other schema files: ${others.join(", ")}` : ""),
+        usage: `\`\`\`${codeLanguage}\n${truncateText(schema.content, maxLength)}\n\`\`\``,
+      }
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error getting schema for ${packageName}:`, error)
+      return {
+        error: `Failed to fetch schema: ${errorMessage}`
+      }
+    }
+  }
+
+  /**
+   * Compare the dependencies and metadata of two versions of a package
+   */
+  private async compareVersions(args: CompareVersionsArgs): Promise<DocResult> {
+    const { package: packageName, language, fromVersion, toVersion, projectPath } = args
+    this.logger.debug(`Comparing ${language} package ${packageName} ${fromVersion} to ${toVersion}`)
+
+    try {
+      // npm's packument holds every version, so both are read from one registry request
+      const [from, to] = language === "npm"
+        ? await this.npmDocsHandler.getVersionsMetadata(
+          packageName,
+          [fromVersion, toVersion],
+          this.registryUtils.getRegistryConfigForPackage(packageName, projectPath)
+        )
+        : await Promise.all([
+          this.getPackageMetadata(language, { package: packageName, version: fromVersion, projectPath }),
+          this.getPackageMetadata(language, { package: packageName, version: toVersion, projectPath }),
+        ])
+
+      return {
+        description: `Changes in ${packageName} between ${fromVersion} and ${toVersion}`,
+        usage: formatVersionComparison(from, to),
+      }
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error comparing versions of ${packageName}:`, error)
+      return {
+        error: `Failed to compare ${packageName} ${fromVersion} and ${toVersion}: ${errorMessage}`
+      }
+    }
+  }
+
+  /**
+   * Compare the exported symbols of two versions of a package
+   */
+  private async compareApi(args: ApiDiffArgs): Promise<DocResult> {
+    const { package: packageName, language, fromVersion, toVersion } = args
+    this.logger.debug(`Comparing the API of ${language} package ${packageName} ${fromVersion} to ${toVersion}`)
+
+    try {
+      const [from, to] = await Promise.all([
+        this.getApiSymbols(language, packageName, fromVersion),
+        this.getApiSymbols(language, packageName, toVersion),
+      ])
+
+      let usage = formatApiDiff(diffApiSymbols(from, to), fromVersion, toVersion)
+      if (language === "rust") {
+        usage += "\nNote: docs.rs item listings don't include signatures, so changed items can't be detected for Rust crates.\n"
+      }
+
+      return {
+        description: `API changes in ${packageName} between ${fromVersion} and ${toVersion}`,
+        usage,
+      }
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error comparing the API of ${packageName}:`, error)
+      return {
+        error: `Failed to compare the API of ${packageName} ${fromVersion} and ${toVersion}: ${errorMessage}`
+      }
+    }
+  }
+
+  /**
+   * List the exported symbols of a package, grouped by kind, as a starting point for looking up
+   * one of them with a describe tool's symbol argument
+   */
+  private async listPackageSymbols(args: SymbolListArgs): Promise<DocResult> {
+    const { package: packageName, language, version, projectPath } = args
+    this.logger.debug(`Listing symbols of ${language} package ${packageName}`)
+
+    try {
+      let symbols: ApiSymbol[] | undefined
+      switch (language) {
+        case "go":
+          symbols = parseGoShortSymbols((await safeGoDocShort(packageName, projectPath)).stdout)
+          break
+        case "python":
+          symbols = parsePydocSymbols((await safePydoc(packageName, projectPath)).stdout)
+          break
+        case "npm":
+          symbols = await this.npmDocsHandler.getApiSymbols(packageName, version)
+          if (!symbols) {
+            return {
+              error: `${packageName}${version ? `@${version}` : ""} does not include TypeScript definitions, so its symbols can't be listed`
+            }
+          }
+          break
+        case "rust":
+          symbols = await this.rustDocsHandler.getCrateItems(packageName, version)
+          break
+      }
+
+      if (symbols.length === 0) {
+        return {
+          error: `No exported symbols found for ${packageName}`,
+          suggestInstall: language === "go" || language === "python"
+        }
+      }
+
+      return {
+        description: `${symbols.length} exported symbol${symbols.length === 1 ? "" : "s"} in ${packageName}. Look one up with the describe tool's symbol argument.`,
+        usage: formatSymbolList(symbols)
+      }
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error listing the symbols of ${packageName}:`, error)
+      return {
+        error: `Failed to list the symbols of ${packageName}: ${errorMessage}`,
+        suggestInstall: language === "go" || language === "python"
+      }
+    }
+  }
+
+  /**
+   * Get a package's transitive dependencies as a tree, resolving each requirement to the newest
+   * version that satisfies it (or the latest release, for requirements such as git URLs that no
+   * published version does). The tree is walked a level at a time so the depth and node limits cut
+   * off the deepest dependencies first, and a package version already in the tree isn't expanded again.
+   */
+  private async getPackageDependencies(args: PackageDependenciesArgs): Promise<DocResult> {
+    const { package: packageName, language, version, projectPath } = args
+    const depth = Math.min(Math.max(Math.floor(args.depth ?? DEFAULT_DEPENDENCY_DEPTH), 1), MAX_DEPENDENCY_DEPTH)
+    const maxNodes = Math.min(Math.max(Math.floor(args.maxNodes ?? DEFAULT_DEPENDENCY_NODES), 1), MAX_DEPENDENCY_NODES)
+    this.logger.debug(`Getting dependency tree for ${language} package ${packageName} to depth ${depth}`)
+
+    // Registry responses are shared by every node naming the same package
+    // eslint-disable-next-line @typescript-eslint/no-explicit-any
+    const fetches = new Map<string, Promise<any>>()
+    const cached = <T>(key: string, fetch: () => Promise<T>): Promise<T> => {
+      if (!fetches.has(key)) fetches.set(key, fetch())
+      return fetches.get(key) as Promise<T>
+    }
+    const packageKey = (name: string) => language === "python" ? normalizePyPIName(name) : name
+
+    const resolveRelease = async (name: string, requirement?: string): Promise<{ version?: string; dependencies: Record<string, string> }> => {
+      switch (language) {
+        case "npm": {
+          const packument = await cached(`npm:${name}`, () => this.npmDocsHandler.fetchPackageInfo(
+            name,
+            undefined,
+            this.registryUtils.getRegistryConfigForPackage(name, projectPath)
+          ))
+          // Requirements usually match a recent release, so older ones are only compared when none does
+          const resolved = (requirement && (packument["dist-tags"]?.[requirement] ||
+            maxSatisfyingVersion(recentNpmVersions(packument), requirement, "npm") ||
+            maxSatisfyingVersion(Object.keys(packument.versions || {}), requirement, "npm"))) ||
+            packument["dist-tags"]?.latest
+          return { version: resolved, dependencies: packument.versions?.[resolved]?.dependencies || {} }
+        }
+
+        case "rust": {
+          const crate = await cached(`rust:${name}`, () => this.rustDocsHandler.getCrateDetails(name))
+          const versions = crate.versions.filter(v => !v.isYanked).map(v => v.version)
+          const resolved = (requirement && maxSatisfyingVersion(versions, requirement, "rust")) || crate.latestVersion || versions[0]
+          if (!resolved) return { dependencies: {} }
+          // Optional dependencies are only built with the features that enable them
+          const dependencies = await cached(`rust:${name}@${resolved}`, () => this.rustDocsHandler.getCrateDependencies(name, resolved))
+          return {
+            version: resolved,
+            dependencies: Object.fromEntries(dependencies
+              .filter(dependency => dependency.kind === "normal" && !dependency.optional)
+              .map(dependency => [dependency.name, dependency.req])),
+          }
+        }
+
+        default: {
+          const project = await cached(`python:${packageKey(name)}`, async () => (await axios.get(pypiJsonUrl(name))).data)
+          const versions = Object.keys(project.releases || {})
+          const resolved = (requirement && maxSatisfyingVersion(versions, requirement, "python")) || project.info.version
+          // The project's JSON describes its latest release, so only older releases are fetched
+          const info = resolved === project.info.version
+            ? project.info
+            : await cached(`python:${packageKey(name)}@${resolved}`, async () => (await axios.get(pypiJsonUrl(name, resolved))).data.info)
+          // Requirements for other Python versions or platforms aren't part of the tree
+          return { version: resolved, dependencies: getPyPIDependencies(info, true) }
+        }
+      }
+    }
+
+    const root: DependencyTreeNode = { name: packageName, requirement: version }
+    const expanded = new Set<string>()
+    const gate = new ConcurrencyGate(PROJECT_DEPENDENCY_CONCURRENCY)
+    let nodeCount = 1
+    let truncated = false
+    let level: Array<{ node: DependencyTreeNode; ancestors: Set<string> }> = [{ node: root, ancestors: new Set() }]
+
+    try {
+      for (let currentDepth = 0; level.length > 0; currentDepth++) {
+        // Fetch the whole level at once, then expand it in order so the node limit cuts the same nodes every time
+        const releases = await Promise.all(level.map(({ node }) => gate.run(() => resolveRelease(node.name, node.requirement)
+          .catch((error: unknown) => {
+            if (node === root) throw error
+            return error instanceof Error ? error : new Error(String(error))
+          }))))
+
+        const next: typeof level = []
+        for (let i = 0; i < level.length; i++) {
+          const { node, ancestors } = level[i]
+          const release = releases[i]
+          if (release instanceof Error) {
+            node.note = `Could not resolve: ${release.message}`
+            continue
+          }
+
+          node.version = release.version
+          const key = `${packageKey(node.name)}@${node.version}`
+          const dependencies = Object.entries(release.dependencies)
+          if (ancestors.has(packageKey(node.name))) {
+            node.note = "Cycle: this package already depends on itself further up the tree"
+            continue
+          }
+          if (dependencies.length === 0) continue
+          if (expanded.has(key)) {
+            node.note = "Dependencies listed where this version first appears in the tree"
+            continue
+          }
+          if (currentDepth >= depth) {
+            node.note = `${dependencies.length} dependencies beyond the depth limit`
+            truncated = true
+            continue
+          }
+
+          expanded.add(key)
+          node.dependencies = []
+          const childAncestors = new Set([...ancestors, packageKey(node.name)])
+          for (const [index, [name, requirement]] of dependencies.entries()) {
+            if (nodeCount >= maxNodes) {
+              node.note = `${dependencies.length - index} more dependencies not listed, as the tree reached ${maxNodes} packages`
+              truncated = true
+              break
+            }
+            const child: DependencyTreeNode = { name, requirement }
+            node.dependencies.push(child)
+            next.push({ node: child, ancestors: childAncestors })
+            nodeCount++
+          }
+        }
+        level = next
+      }
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error getting dependencies for ${packageName}:`, error)
+      return {
+        error: `Failed to fetch dependencies of ${packageName}: ${errorMessage}`
+      }
+    }
+
+    return {
+      description: `Dependency tree of ${packageName}@${root.version} (${nodeCount} packages, depth ${depth})` +
+        (truncated ? ". Some dependencies were cut off by the depth or node limit; see each node's note" : ""),
+      dependencyTree: root,
+    }
+  }
+
+  /**
+   * Summarise the direct dependencies a project's manifests declare, grouped by ecosystem, with the
+   * current version, a one-line description and a documentation link for each
+   */
+  private async describeProjectDependencies(args: ProjectDependenciesArgs): Promise<DocResult> {
+    const { projectPath, includeDev = false } = args
+    this.logger.debug(`Describing the dependencies of ${projectPath}`)
+
+    if (!existsSync(projectPath)) {
+      return { error: `Project path ${projectPath} does not exist` }
+    }
+
+    const dependencies = findProjectDependencies(projectPath, includeDev)
+    if (dependencies.length === 0) {
+      return {
+        error: `No dependencies found in ${projectPath}. Looked for package.json, go.mod, requirements.txt, pyproject.toml and Cargo.toml in the project and the directories directly below it.`
+      }
+    }
+
+    // Registry lookups are independent, so run several at once without flooding any one registry
+    const gate = new ConcurrencyGate(PROJECT_DEPENDENCY_CONCURRENCY)
+    const rows = await Promise.all(dependencies.map(dependency => gate.run(async () => {
+      let metadata: PackageMetadata | undefined
+      try {
+        metadata = await this.getPackageMetadata(dependency.ecosystem, { package: dependency.name, projectPath })
+      } catch (error) {
+        this.logger.debug(`Could not get metadata for ${dependency.name}: ${error}`)
+      }
+
+      return {
+        ...dependency,
+        version: metadata?.version,
+        summary: metadata?.description?.trim().split("\n")[0],
+        docs: metadata?.documentation || metadata?.homepage || registryPageUrl(dependency.ecosystem, dependency.name),
+      }
+    })))
+
+    const sections: string[] = []
+    for (const ecosystem of ["npm", "python", "go", "rust"] as ProjectEcosystem[]) {
+      const group = rows.filter(row => row.ecosystem === ecosystem)
+      if (group.length === 0) continue
+
+      sections.push(`## ${ecosystem} (${group.length})\n\n` + formatMarkdownTable({
+        headers: ["Package", "Requested", "Current", "Summary", "Docs"],
+        rows: group.map(row => [
+          `${row.name}${row.dev ? " (dev)" : ""}`,
+          row.requirement,
+          row.version || "unknown",
+          row.summary || "",
+          row.docs,
+        ]),
+      }))
+    }
+
+    const manifests = Array.from(new Set(dependencies.map(dependency => dependency.manifest)))
+    return {
+      description: `${dependencies.length} direct dependenc${dependencies.length === 1 ? "y" : "ies"} declared in ${manifests.join(", ")}`,
+      usage: sections.join("\n\n")
+    }
+  }
+
+  /**
+   * Download the module providing a Go package at a version into the module cache, returning the
+   * module's directory and the package's path relative to it (e.g. ./http2)
+   */
+  private async downloadGoModule(packageName: string, version: string): Promise<{ moduleDir: string; packageDir: string }> {
+    // The module path is the longest prefix of the package path that go mod download accepts
+    const parts = packageName.split("/")
+    for (let length = parts.length; length > 0; length--) {
+      const modulePath = parts.slice(0, length).join("/")
+      try {
+        const { stdout } = await safeGoModDownload(modulePath, version)
+        return {
+          moduleDir: JSON.parse(stdout).Dir,
+          packageDir: ["."].concat(parts.slice(length)).join("/")
+        }
+      } catch (error) {
+        // Without go there's no shorter module path worth trying
+        if (error instanceof ToolNotInstalledError) throw error
+        continue
+      }
+    }
+    throw new Error(`Could not download ${packageName}@${version}. Make sure Go is installed and the version exists.`)
+  }
+
+  /**
+   * Get the exported symbols of a package version
+   */
+  private async getApiSymbols(language: ApiDiffArgs["language"], packageName: string, version: string): Promise<ApiSymbol[]> {
+    switch (language) {
+      case "go": {
+        const { moduleDir, packageDir } = await this.downloadGoModule(packageName, version)
+        const { stdout } = await safeGoDocAll(packageDir, moduleDir)
+        return parseGoApiSymbols(stdout)
+      }
+
+      case "npm": {
+        const symbols = await this.npmDocsHandler.getApiSymbols(packageName, version)
+        if (!symbols) {
+          throw new Error(`${packageName}@${version} does not include TypeScript definitions`)
+        }
+        return symbols
+      }
+
+      case "rust":
+        return await this.rustDocsHandler.getCrateItems(packageName, version)
+    }
+  }
+
+  /**
+   * Say whether docs.rs built a crate's documentation with the requested features. docs.rs builds
+   * one set of features per version (set in the crate's [package.metadata.docs.rs]), so items gated
+   * behind a feature outside that set are missing from its documentation.
+   */
+  private async describeRustFeatureCoverage(crateName: string, version: string | undefined, features: string[]): Promise<string> {
+    try {
+      const flags = await this.rustDocsHandler.getFeatureFlags(crateName, version)
+      const known = new Map(flags.map(flag => [flag.name, flag]))
+
+      const unknown = features.filter(feature => !known.has(feature))
+      const disabled = features.filter(feature => known.has(feature) && !known.get(feature)!.enabled)
+      const enabled = features.filter(feature => known.get(feature)?.enabled)
+
+      const lines: string[] = []
+      if (enabled.length > 0) {
+        lines.push(`Features included in the docs.rs build: ${enabled.join(", ")}`)
+      }
+      if (disabled.length > 0) {
+        lines.push(`Features not enabled in the docs.rs build, so items gated behind them aren't documented here: ${disabled.join(", ")}. Run \`cargo doc --features ${disabled.join(",")}\` to document them locally.`)
+      }
+      if (unknown.length > 0) {
+        lines.push(`Unknown features for ${crateName}: ${unknown.join(", ")}${flags.length > 0 ? ` (available: ${flags.map(flag => flag.name).join(", ")})` : ""}`)
+      }
+      return lines.join("\n")
+    } catch (error) {
+      this.logger.debug(`Could not get the feature flags of ${crateName}: ${error}`)
+      return `Could not check which features docs.rs built ${crateName} with`
+    }
+  }
+
+  /**
+   * Get documentation for a Rust package
+   */
+  private async describeRustPackage(args: { package: string, version?: string, target?: string, features?: string[], format?: string, source?: DocSource, raw?: boolean, includePrerelease?: boolean, includeRaw?: boolean }): Promise<DocResult> {
+    const { package: crateName, target, features = [], source = "auto", raw = false } = args
+    this.logger.debug(`Getting Rust documentation for ${crateName}${args.version ? ` version ${args.version}` : ""}`)
+
+    // Target triples look like x86_64-pc-windows-msvc or wasm32-unknown-unknown
+    if (target && !/^[a-z0-9_]+(?:-[a-z0-9_.]+){1,3}$/.test(target)) {
+      return { error: `Invalid target ${target}: expected a target triple such as x86_64-pc-windows-msvc` }
+    }
+
+    try {
+      // Check if crate is installed locally first, unless the network was requested
+      const isInstalled = source !== "network" && await this.isRustCrateInstalledLocally(crateName)
+
+      if (isInstalled) {
+        this.logger.debug(`Using local documentation for ${crateName}`)
+        return await this.getLocalRustDoc(crateName)
+      }
+
+      if (source === "local") {
+        return localSourceError(crateName)
+      }
+      const version = await this.registryVersion("rust", args)
+
+      // If not installed, try to fetch from docs.rs
+      this.logger.debug(`Fetching Rust documentation for ${crateName} from docs.rs`)
+
+      try {
+        // Get crate details from crates.io
+        const crateDetails = await this.rustDocsHandler.getCrateDetails(crateName)
+
+        // Get documentation from docs.rs
+        const documentation = await this.rustDocsHandler.getCrateDocumentation(crateName, version, target)
+        const metadata = args.format === "json" ? await this.getCrateMetadata(crateDetails, version) : undefined
+        const readme = args.includeRaw ? documentation : undefined
+        if (raw) {
+          return { description: crateDetails.description, usage: documentation, metadata, readme }
+        }
+
+        // Extract a brief description from the documentation
+        const briefDescription = documentation.split('\n\n')[0] || crateDetails.description || `Rust crate: ${crateName}`
+
+        // Surface the build targets docs.rs lists for the crate
+        const platformLine = formatPlatforms(await this.rustDocsHandler.getSupportedTargets(crateName, version))
+
+        const notes = [platformLine, target ? `Documentation as built for ${target}.` : ""]
+        if (features.length > 0) {
+          notes.push(await this.describeRustFeatureCoverage(crateName, version, features))
+        }
+        const dependencyLine = features.length > 0
+          ? `${crateName} = { version = "${version || crateDetails.latestVersion || '*'}", features = [${features.map(feature => `"${feature}"`).join(", ")}] }`
+          : `${crateName} = "${version || crateDetails.latestVersion || '*'}"`
+
+        return {
+          description: [briefDescription, ...notes].filter(Boolean).join("\n\n"),
+          metadata,
+          usage: `## ${crateName} ${version || crateDetails.latestVersion || ''}
 
 ${crateDetails.description || ''}
 
@@ -1958,7 +3487,7 @@ Add this to your Cargo.toml:
 
 \`\`\`toml
 [dependencies]
-${crateName} = "${version || crateDetails.versions[0]?.version || '*'}"
+${dependencyLine}
 \`\`\`
 
 ### Links
@@ -1969,12 +3498,16 @@ ${crateDetails.homepage ? `- [Homepage](${crateDetails.homepage})` : ''}
 `,
           example: documentation.includes('# Examples')
             ? documentation.split('# Examples')[1]?.split('#')[0]?.trim()
-            : undefined
+            : undefined,
+          readme
         }
-      } catch {
-        // If fetching fails, suggest installation
+      } catch (error) {
+        // If fetching fails, suggest installation and any similarly named crates
+        const suggestion = String(error).includes("404")
+          ? await this.packageSearch.suggestAlternatives("rust", crateName)
+          : undefined
         return {
-          error: `Crate ${crateName} not found. Try adding it to your Cargo.toml.`,
+          error: `Crate ${crateName} not found.${suggestion ? ` ${suggestion}` : ""} Try adding it to your Cargo.toml.`,
           suggestInstall: true
         }
       }
@@ -1991,18 +3524,22 @@ ${crateDetails.homepage ? `- [Homepage](${crateDetails.homepage})` : ''}
    * Get documentation for a Swift package
    */
   private async describeSwiftPackage(args: SwiftDocArgs): Promise<DocResult> {
-    const { package: packageUrl, symbol, projectPath } = args
+    const { package: packageUrl, symbol, projectPath, source = "auto" } = args
     this.logger.debug(`Getting Swift documentation for ${packageUrl}${symbol ? `.${symbol}` : ""}`)
 
     try {
-      // Check if package is installed locally first
-      const isInstalled = await this.isSwiftPackageInstalledLocally(packageUrl, projectPath)
+      // Check if package is installed locally first, unless the network was requested
+      const isInstalled = source !== "network" && await this.isSwiftPackageInstalledLocally(packageUrl, projectPath)
 
       if (isInstalled) {
         this.logger.debug(`Using local documentation for ${packageUrl}`)
         return await this.getLocalSwiftDoc(packageUrl, symbol, projectPath)
       }
 
+      if (source === "local") {
+        return localSourceError(packageUrl)
+      }
+
       // If not installed, try to fetch from GitHub or other sources
       this.logger.debug(`Fetching Swift documentation for ${packageUrl} from remote sources`)
 
@@ -2016,46 +3553,48 @@ ${crateDetails.homepage ? `- [Homepage](${crateDetails.homepage})` : ''}
           }
         }
 
-        // Try to fetch README from GitHub if it's a GitHub URL
-        if (packageUrl.includes('github.com')) {
+        // Try to fetch the README from the package's repository
+        const repoClient = createRepoClient(packageUrl, this.logger, { genericHosts: true })
+        if (repoClient) {
           try {
-            // Convert github.com URL to raw.githubusercontent.com URL for the README
-            const githubParts = packageUrl.replace(/\.git$/, '').split('github.com/')
-            if (githubParts.length === 2) {
-              const repoPath = githubParts[1]
-              const readmeUrl = `https://raw.githubusercontent.com/${repoPath}/main/README.md`
-
-              const response = await axios.get(readmeUrl)
-              if (response.data) {
-                // Parse the README content
-                const readme = response.data
-
-                // Extract relevant sections
-                const sections = readme.split(/#+\s/)
-                let description = ""
-                let usage = ""
-                let example = ""
-
-                for (const section of sections) {
-                  const lower = section.toLowerCase()
-                  if (lower.startsWith("introduction") || lower.startsWith("about") || lower.startsWith("overview")) {
-                    description = section.split("\n").slice(1).join("\n").trim()
-                  } else if (lower.startsWith("usage") || lower.startsWith("getting started")) {
-                    usage = section.split("\n").slice(1).join("\n").trim()
-                  } else if (lower.startsWith("example")) {
-                    example = section.split("\n").slice(1).join("\n").trim()
-                  }
-                }
+            const readme = await repoClient.getReadme()
+            if (readme) {
+              // Extract relevant sections
+              const sections = readme.split(/#+\s/)
+              let description = ""
+              let usage = ""
+              let example = ""
 
-                return {
-                  description: description || `Swift package: ${packageName}`,
-                  usage: usage || undefined,
-                  example: example || undefined
+              for (const section of sections) {
+                const lower = section.toLowerCase()
+                if (lower.startsWith("introduction") || lower.startsWith("about") || lower.startsWith("overview")) {
+                  description = section.split("\n").slice(1).join("\n").trim()
+                } else if (lower.startsWith("usage") || lower.startsWith("getting started")) {
+                  usage = section.split("\n").slice(1).join("\n").trim()
+                } else if (lower.startsWith("example")) {
+                  example = section.split("\n").slice(1).join("\n").trim()
                 }
               }
+
+              // The package's own dependencies, from its manifest
+              const manifest = await repoClient.getFile("Package.swift")
+              const dependencies = manifest ? parsePackageSwift(manifest.content) : []
+              if (dependencies.length > 0) {
+                const list = dependencies
+                  .map(dependency => `- ${dependency.name ? `${dependency.name} (${dependency.url})` : dependency.url}: ${formatSwiftConstraint(dependency.constraint)}`)
+                  .join("\n")
+                usage = `${usage ? `${usage}\n\n` : ""}## Dependencies\n\n${list}`
+              }
+
+              return {
+                description: description || `Swift package: ${packageName}`,
+                usage: usage || undefined,
+                example: example || undefined,
+                readme: args.includeRaw ? readme : undefined
+              }
             }
           } catch (githubError) {
-            this.logger.error(`Error fetching GitHub README: ${githubError}`)
+            this.logger.error(`Error fetching repository README: ${githubError}`)
           }
         }
 
@@ -2096,6 +3635,612 @@ ${crateDetails.homepage ? `- [Homepage](${crateDetails.homepage})` : ''}
    * Get full documentation for an NPM package
    * Enhanced to provide comprehensive information for LLMs
    */
+  /**
+   * Get full documentation for a package in any supported language
+   */
+  private async getPackageDoc(args: PackageDocArgs): Promise<DocResult> {
+    // An explicit version wins over the one the parent uses
+    if (args.parent && !args.version) {
+      try {
+        const version = await this.resolveDependencyVersion(args.language, args.parent, args.package, args.projectPath)
+        this.logger.debug(`${args.parent} uses ${args.package}@${version}`)
+        args = { ...args, version }
+      } catch (error) {
+        const errorMessage = error instanceof Error ? error.message : String(error)
+        return { error: `Could not resolve the version of ${args.package} used by ${args.parent}: ${errorMessage}` }
+      }
+    }
+
+    switch (args.language) {
+      case "go":
+        return await this.getGoPackageDocumentation(args)
+      case "python":
+        return await this.getPythonPackageDocumentation(args)
+      case "rust":
+        return await this.getRustPackageDocumentation(args)
+      case "swift":
+        return await this.getSwiftPackageDocumentation(args)
+      case "npm":
+        // Section filters work on the README's sections rather than the rendered npm documentation
+        if (args.include || args.exclude) {
+          return await this.getNpmReadmeDocumentation(args)
+        }
+        return await this.getNpmPackageDoc({
+          package: args.package,
+          version: args.version,
+          includePrerelease: args.includePrerelease,
+          projectPath: args.projectPath,
+          section: args.section,
+          maxLength: args.maxLength,
+          query: args.query,
+        })
+      default:
+        return {
+          error: `Full documentation retrieval is not supported for ${args.language} packages`,
+        }
+    }
+  }
+
+  /**
+   * Get the version a describe or doc call reads from the registry: the one given, or with
+   * includePrerelease the newest one, prereleases included, as the registries' own latest versions
+   * are stable releases. Handlers only call this once they're going to the network, so local
+   * sources and installed packages never wait on the version list. Undefined leaves the registry's
+   * latest, as does a version list that can't be read.
+   */
+  private async registryVersion(
+    language: "npm" | "python" | "rust" | "go",
+    args: { package: string, version?: string, includePrerelease?: boolean, source?: DocSource, projectPath?: string }
+  ): Promise<string | undefined> {
+    if (args.version || !args.includePrerelease || args.source === "local") {
+      return args.version
+    }
+
+    try {
+      const version = await this.resolveLatestVersion(language, args.package, true, args.projectPath)
+      this.logger.debug(`Newest version of ${args.package}, prereleases included: ${version}`)
+      return version
+    } catch (error) {
+      this.logger.debug(`Could not resolve the newest version of ${args.package}: ${error}`)
+      return undefined
+    }
+  }
+
+  /**
+   * Get the latest version of a package from its registry's version list, leaving out prereleases
+   * unless they're wanted, and yanked releases. Go versions come from the module proxy's list of
+   * tagged versions for the module providing the package.
+   */
+  private async resolveLatestVersion(
+    language: "npm" | "python" | "rust" | "go",
+    packageName: string,
+    includePrerelease: boolean,
+    projectPath?: string
+  ): Promise<string | undefined> {
+    switch (language) {
+      case "npm": {
+        const config = this.registryUtils.getRegistryConfigForPackage(packageName, projectPath)
+        const packageInfo = await this.npmDocsHandler.fetchPackageInfo(packageName, undefined, config)
+        return latestVersion(recentNpmVersions(packageInfo), includePrerelease)
+      }
+
+      case "python": {
+        const { data } = await axios.get(pypiJsonUrl(packageName))
+        const releases = Object.entries(data.releases || {}) as Array<[string, Array<{ yanked?: boolean }>]>
+        return latestVersion(
+          releases.filter(([, files]) => files.some(file => !file.yanked)).map(([version]) => version),
+          includePrerelease
+        )
+      }
+
+      case "rust": {
+        const crateDetails = await this.rustDocsHandler.getCrateDetails(packageName)
+        return latestVersion(crateDetails.versions.filter(v => !v.isYanked).map(v => v.version), includePrerelease)
+      }
+
+      case "go": {
+        // Standard library packages have no module versions of their own
+        const parts = packageName.split("/")
+        if (!parts[0].includes(".")) return undefined
+
+        // The module is the longest prefix of the package path the proxy knows
+        for (let length = parts.length; length > 1; length--) {
+          const modulePath = escapeGoModulePath(parts.slice(0, length).join("/"))
+          try {
+            const { data } = await axios.get(`https://proxy.golang.org/${modulePath}/@v/list`, { responseType: "text" })
+            const versions = String(data).split("\n").filter(Boolean)
+            if (versions.length > 0) return latestVersion(versions, includePrerelease)
+          } catch {
+            // Not a module, so try the parent path
+          }
+        }
+        return undefined
+      }
+    }
+  }
+
+  /**
+   * Resolve the version of a dependency that a parent package uses: from the project's lockfile
+   * when there is one, otherwise from the requirements the parent's own metadata pins (its go.mod
+   * for Go modules)
+   */
+  private async resolveDependencyVersion(
+    language: PackageDocArgs["language"],
+    parentSpec: string,
+    dependency: string,
+    projectPath?: string
+  ): Promise<string> {
+    const { name: parent, version: parentVersion } = splitPackageSpec(parentSpec)
+    const basePath = projectPath || process.cwd()
+    const readLockfile = (name: string) => {
+      const path = join(basePath, name)
+      return existsSync(path) ? readFileSync(path, "utf-8") : undefined
+    }
+
+    // Lockfiles record what the project actually installed, so they're only used for the installed parent
+    if (!parentVersion) {
+      let locked: string | undefined
+      switch (language) {
+        case "npm": {
+          const lock = readLockfile("package-lock.json")
+          locked = lock ? findNpmLockVersion(JSON.parse(lock), parent, dependency) : undefined
+          break
+        }
+        case "rust": {
+          const lock = readLockfile("Cargo.lock")
+          locked = lock ? findCargoLockVersion(lock, parent, dependency) : undefined
+          break
+        }
+        case "python": {
+          const lock = readLockfile("uv.lock") || readLockfile("poetry.lock")
+          locked = lock ? findPythonLockVersion(lock, dependency) : undefined
+          break
+        }
+      }
+      if (locked) return locked
+    }
+
+    if (language === "go") {
+      // Go modules pin the minimum version of each requirement in their go.mod
+      const projectGoMod = readLockfile("go.mod")
+      const parentModule = projectGoMod ? findGoModRequirement(projectGoMod, parent) : undefined
+      const modulePath = escapeGoModulePath(parentModule?.module || parent)
+      const version = parentVersion || parentModule?.version ||
+        (await axios.get(`https://proxy.golang.org/${modulePath}/@latest`)).data.Version
+      const { data: goMod } = await axios.get(`https://proxy.golang.org/${modulePath}/@v/${encodeURIComponent(version)}.mod`, { responseType: "text" })
+      const requirement = findGoModRequirement(goMod, dependency)
+      if (!requirement) {
+        throw new Error(`${parent}@${version} does not require ${dependency}`)
+      }
+      return requirement.version
+    }
+
+    if (language === "swift") {
+      throw new Error("Swift packages are not supported")
+    }
+
+    const metadata = await this.getPackageMetadata(language, { package: parent, version: parentVersion, projectPath })
+    const requirement = Object.entries(metadata.dependencies || {})
+      .find(([name]) => language === "python"
+        ? normalizePyPIName(name) === normalizePyPIName(dependency)
+        : name === dependency)?.[1]
+    if (requirement === undefined) {
+      throw new Error(`${metadata.name}@${metadata.version} does not depend on ${dependency}`)
+    }
+
+    const version = pinnedVersion(requirement)
+    if (!version) {
+      throw new Error(`${metadata.name}@${metadata.version} requires ${dependency} ${requirement} rather than an exact version. Pass a version, or a projectPath with a lockfile`)
+    }
+    return version
+  }
+
+  /**
+   * Narrow full documentation sections to the requested section/query and render them as markdown.
+   * Sections other than the overview are fenced with codeLanguage when the source isn't markdown.
+   */
+  private buildFullDocResult(
+    description: string | undefined,
+    sections: DocSection[],
+    args: PackageDocArgs,
+    codeLanguage?: string
+  ): DocResult {
+    const { section, query, include, exclude, minLevel, stripHeadingEmoji, maxLength = 20000 } = args
+    const titled = stripHeadingEmoji ? sections.map(s => ({ ...s, title: stripEmoji(s.title) })) : sections
+    const filtered = this.searchUtils.filterDocSections(titled, { include, exclude, minLevel })
+    const selection = this.searchUtils.selectDocumentation(filtered, { section, query })
+
+    // Markdown sections keep their heading level so nested subsections stay nested
+    const rendered = selection.sections
+      .map(s => codeLanguage && s.title !== "Overview"
+        ? formatDocSection({ ...s, content: `\`\`\`${codeLanguage}\n${s.content}\n\`\`\`` })
+        : formatDocSection(s))
+
+    // Summarise the README's badges after the overview, and list diagrams and other content
+    // images, when returning the whole document
+    if (!section && !query) {
+      const signals = formatSignals(this.searchUtils.extractBadgeSignals(sections.map(s => s.content).join("\n\n")))
+      if (signals) {
+        rendered.splice(selection.sections[0]?.title === "Overview" ? 1 : 0, 0, signals)
+      }
+
+      const images = this.searchUtils.extractImages(sections.map(s => s.content).join("\n\n"))
+      if (images.length > 0) {
+        rendered.push(`## Images\n\n${images.map(image => `- ${image.alt || "Image"}: ${image.url}`).join("\n")}`)
+      }
+    }
+
+    // Whole trailing sections are dropped to fit maxLength, so every section returned is complete
+    const usage = truncateSections(rendered, maxLength)
+
+    const languageNote = this.searchUtils.languageNote(sections.map(s => s.content).join("\n\n"))
+
+    return {
+      description: languageNote ? `${description || ""}\n\n${languageNote}`.trim() : description,
+      usage,
+      error: selection.error,
+    }
+  }
+
+  /**
+   * Get the full `go doc -all` documentation for a Go package
+   */
+  private async getGoPackageDocumentation(args: PackageDocArgs): Promise<DocResult> {
+    const { package: packageName, projectPath } = args
+    this.logger.debug(`Getting full Go documentation for ${packageName}${args.version ? `@${args.version}` : ""}`)
+
+    try {
+      // The local copy of an installed module is documented as it is, rather than the newest version
+      const isInstalled = !args.version && args.includePrerelease &&
+        (isGoStandardLibrary(packageName) || await this.isGoPackageInstalledLocally(packageName, projectPath))
+      const version = isInstalled ? undefined : await this.registryVersion("go", args)
+
+      // A specific version is documented from its own copy in the module cache
+      let stdout: string
+      if (version) {
+        const { moduleDir, packageDir } = await this.downloadGoModule(packageName, version)
+        stdout = (await safeGoDocAll(packageDir, moduleDir)).stdout
+      } else {
+        stdout = (await safeGoDocAll(packageName, projectPath)).stdout
+      }
+      const sections = this.searchUtils.parseGoDocAll(stdout)
+
+      // The overview opens with a "package x // import ..." line, followed by the package comment
+      const overview = sections.find(s => s.title === "Overview")
+      const description = overview?.content
+        .split("\n\n")
+        .find(paragraph => !paragraph.startsWith("package "))
+        ?.trim()
+
+      return this.buildFullDocResult(description, sections, args, "go")
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error getting full Go documentation for ${packageName}:`, error)
+      if (error instanceof ToolNotInstalledError) {
+        return {
+          error: `Failed to fetch Go documentation for ${packageName}: ${errorMessage}. Install Go to read full documentation, or use search_package_docs, which falls back to pkg.go.dev.`,
+        }
+      }
+      return {
+        error: `Failed to fetch Go documentation for ${packageName}: ${errorMessage}. Make sure Go is installed and the package is available in your module or the standard library.`,
+        suggestInstall: true,
+      }
+    }
+  }
+
+  /**
+   * Get full documentation for a Python package, from pydoc when installed locally or the PyPI project description otherwise
+   */
+  private async getPythonPackageDocumentation(args: PackageDocArgs): Promise<DocResult> {
+    const { package: packageName, projectPath } = args
+    this.logger.debug(`Getting full Python documentation for ${packageName}`)
+
+    try {
+      if (!args.version && await this.isPythonPackageInstalledLocally(packageName, projectPath)) {
+        const { stdout } = await safePydoc(packageName, projectPath)
+        const sections = this.searchUtils.parsePythonDocAll(stdout)
+        const description = sections.find(s => s.title === "Name")?.content
+
+        return this.buildFullDocResult(description, sections, args, "python")
+      }
+
+      const url = pypiJsonUrl(packageName, await this.registryVersion("python", args))
+      const response = await axios.get(url)
+      const info = response.data?.info
+
+      if (!info?.description) {
+        return {
+          error: `No documentation found for ${packageName} on PyPI`,
+          suggestInstall: true
+        }
+      }
+
+      return this.buildFullDocResult(
+        info.summary,
+        this.searchUtils.parseMarkdownDocSections(getPyPIDescription(info)),
+        args
+      )
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.status === 404) {
+        const suggestion = await this.packageSearch.suggestAlternatives("python", packageName)
+        return {
+          error: `Package ${packageName} not found.${suggestion ? ` ${suggestion}` : ""} Try installing it with 'pip install ${packageName}'`,
+          suggestInstall: true
+        }
+      }
+
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error getting full Python documentation for ${packageName}:`, error)
+      return {
+        error: `Failed to fetch Python documentation: ${errorMessage}`
+      }
+    }
+  }
+
+  /**
+   * Get full documentation for a Rust crate from docs.rs
+   */
+  private async getRustPackageDocumentation(args: PackageDocArgs): Promise<DocResult> {
+    const { package: crateName } = args
+    this.logger.debug(`Getting full Rust documentation for ${crateName}`)
+
+    try {
+      const version = await this.registryVersion("rust", args)
+      const documentation = await this.rustDocsHandler.getCrateDocumentation(crateName, version)
+      const sections = this.searchUtils.parseMarkdownDocSections(documentation)
+
+      return this.buildFullDocResult(documentation.split("\n\n")[0], sections, args)
+    } catch (error) {
+      const suggestion = String(error).includes("404")
+        ? await this.packageSearch.suggestAlternatives("rust", crateName)
+        : undefined
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error getting full Rust documentation for ${crateName}:`, error)
+      return {
+        error: `Failed to fetch Rust documentation: ${errorMessage}${suggestion ? ` ${suggestion}` : ""}`,
+        suggestInstall: true
+      }
+    }
+  }
+
+  /**
+   * Get full documentation for a Swift package from its repository README
+   */
+  private async getSwiftPackageDocumentation(args: PackageDocArgs): Promise<DocResult> {
+    const { package: packageUrl } = args
+    this.logger.debug(`Getting full Swift documentation for ${packageUrl}`)
+
+    try {
+      const repoClient = createRepoClient(packageUrl, this.logger, { genericHosts: true })
+      if (!repoClient) {
+        return {
+          error: `${packageUrl} isn't a repository URL. Pass the package's Git URL, e.g. https://github.com/apple/swift-argument-parser`
+        }
+      }
+
+      const readme = await repoClient.getReadme()
+      if (!readme) {
+        return {
+          error: `No README found for ${packageUrl} on its main or master branch`
+        }
+      }
+
+      const sections = this.searchUtils.parseMarkdownDocSections(readme)
+      const packageName = this.extractSwiftPackageNameFromUrl(packageUrl)
+
+      return this.buildFullDocResult(
+        sections.find(s => s.title === "Overview")?.content || `Swift package: ${packageName}`,
+        sections,
+        args
+      )
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error getting full Swift documentation for ${packageUrl}:`, error)
+      return {
+        error: `Failed to fetch Swift documentation: ${errorMessage}`
+      }
+    }
+  }
+
+  /**
+   * Get full documentation for an npm package from its README
+   */
+  private async getNpmReadmeDocumentation(args: PackageDocArgs): Promise<DocResult> {
+    const { package: packageName, projectPath } = args
+    this.logger.debug(`Getting README documentation for ${packageName}`)
+
+    try {
+      const config = this.registryUtils.getRegistryConfigForPackage(packageName, projectPath)
+      const version = await this.registryVersion("npm", args)
+      const packageInfo = await this.npmDocsHandler.fetchPackageInfo(packageName, version, config)
+      const readme = this.npmDocsHandler.getReadmeMarkdown(packageInfo)
+      if (!readme) {
+        return {
+          error: `No README found for ${packageName}`
+        }
+      }
+
+      return this.buildFullDocResult(
+        packageInfo.description,
+        this.searchUtils.parseMarkdownDocSections(readme),
+        args
+      )
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error getting README documentation for ${packageName}:`, error)
+      return {
+        error: `Failed to fetch NPM documentation: ${errorMessage}`
+      }
+    }
+  }
+
+  /**
+   * Get a package's README (or equivalent long-form documentation) as markdown
+   */
+  private async getPackageReadme(language: ConfigDocArgs["language"], packageName: string, projectPath?: string): Promise<string | undefined> {
+    switch (language) {
+      case "npm": {
+        const config = this.registryUtils.getRegistryConfigForPackage(packageName, projectPath)
+        const packageInfo = await this.npmDocsHandler.fetchPackageInfo(packageName, undefined, config)
+        return this.npmDocsHandler.getReadmeMarkdown(packageInfo)
+      }
+      case "python": {
+        const response = await axios.get(pypiJsonUrl(packageName))
+        return response.data?.info?.description ? getPyPIDescription(response.data.info) : undefined
+      }
+      case "rust":
+        return await this.rustDocsHandler.getCrateDocumentation(packageName)
+      case "swift":
+        return await createRepoClient(packageName, this.logger, { genericHosts: true })?.getReadme()
+      case "go": {
+        const repository = getGoRepositoryUrl(packageName)
+        return repository && await createRepoClient(repository, this.logger)?.getReadme()
+      }
+      case "php":
+        return (await this.phpDocsHandler.getSearchableContent(packageName)).sections.map(s => s.content).join("\n\n")
+      case "java":
+        return (await this.javaDocsHandler.getSearchableContent(packageName)).sections.map(s => s.content).join("\n\n")
+      case "dotnet":
+        return (await this.dotnetDocsHandler.getSearchableContent(packageName)).sections.map(s => s.content).join("\n\n")
+      default:
+        return undefined
+    }
+  }
+
+  /**
+   * Get the code examples from a package's README, each labelled with what it demonstrates
+   */
+  private async getPackageExamples(args: ExamplesArgs): Promise<DocResult> {
+    const { package: packageName, language, projectPath } = args
+    // Every example is returned unless maxExamples caps them
+    const maxExamples = args.maxExamples === undefined ? Infinity : Math.max(1, Math.floor(args.maxExamples))
+    this.logger.debug(`Getting examples for ${language} package ${packageName}`)
+
+    try {
+      const readme = await this.getPackageReadme(language, packageName, projectPath)
+      if (!readme) {
+        return {
+          error: `No README found for ${packageName}`,
+          suggestInstall: true
+        }
+      }
+
+      const examples = this.searchUtils.extractLabelledExamples(readme)
+      if (examples.length === 0) {
+        return {
+          error: `No code examples found in the README for ${packageName}`
+        }
+      }
+
+      const selected = this.searchUtils.selectExamples(examples, maxExamples)
+      const omitted = examples.length - selected.length
+
+      return {
+        description: `${selected.length} example${selected.length === 1 ? "" : "s"} from the ${packageName} README` +
+          (omitted > 0 ? ` (${omitted} more omitted; pass a larger maxExamples to see them)` : ""),
+        usage: selected
+          .map(example => `### ${example.label}\n\n${this.searchUtils.formatCodeBlock(example)}`)
+          .join("\n\n")
+      }
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error getting examples for ${packageName}:`, error)
+      return {
+        error: `Failed to fetch examples: ${errorMessage}`
+      }
+    }
+  }
+
+  /**
+   * Get the versions of runtimes, frameworks and platforms a package supports, from the compatibility
+   * tables, requirements sections and statements in its README
+   */
+  private async getCompatibility(args: CompatibilityArgs): Promise<DocResult> {
+    const { package: packageName, language, projectPath } = args
+    this.logger.debug(`Getting compatibility for ${language} package ${packageName}`)
+
+    try {
+      const readme = await this.getPackageReadme(language, packageName, projectPath)
+      if (!readme) {
+        return {
+          error: `No README found for ${packageName}`,
+          suggestInstall: true
+        }
+      }
+
+      const { sections, statements } = this.searchUtils.extractCompatibility(readme)
+      if (sections.length === 0 && statements.length === 0) {
+        return {
+          error: `No compatibility information found in the README for ${packageName}`
+        }
+      }
+
+      const parts = sections.map(section => {
+        // READMEs often use HTML tables, which the markdown table parser doesn't see
+        const tables = section.tables.length > 0 || !section.content.includes("<table")
+          ? section.tables
+          : extractHtmlTables(section.content)
+        const body = tables.length > 0
+          ? tables.map(table => formatMarkdownTable(table)).join("\n\n")
+          : truncateText(section.content, 2000)
+        return formatDocSection({ title: section.title, content: body, level: section.level })
+      })
+      if (statements.length > 0) {
+        parts.push(`## Stated compatibility\n\n${statements.map(statement => `- ${statement}`).join("\n")}`)
+      }
+
+      return {
+        description: `Compatibility information from the ${packageName} README`,
+        usage: parts.join("\n\n")
+      }
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error getting compatibility for ${packageName}:`, error)
+      return {
+        error: `Failed to fetch compatibility information: ${errorMessage}`
+      }
+    }
+  }
+
+  /**
+   * Get the documentation for a package's configuration file format from its README
+   */
+  private async getConfigDocs(args: ConfigDocArgs): Promise<DocResult> {
+    const { package: packageName, language, projectPath } = args
+    this.logger.debug(`Getting configuration documentation for ${language} package ${packageName}`)
+
+    try {
+      const readme = await this.getPackageReadme(language, packageName, projectPath)
+      if (!readme) {
+        return {
+          error: `No README found for ${packageName}`,
+          suggestInstall: true
+        }
+      }
+
+      const { sections, examples, files } = this.searchUtils.extractConfigDocs(readme)
+      if (sections.length === 0) {
+        return {
+          error: `No configuration documentation found in the README for ${packageName}`
+        }
+      }
+
+      return {
+        description: files.length > 0
+          ? `Configuration files: ${files.join(", ")}`
+          : `Configuration documentation for ${packageName}`,
+        usage: sections.map(formatDocSection).join("\n\n"),
+        example: examples.length > 0 ? examples.join("\n\n") : undefined
+      }
+    } catch (error) {
+      const errorMessage = error instanceof Error ? error.message : String(error)
+      this.logger.error(`Error getting configuration documentation for ${packageName}:`, error)
+      return {
+        error: `Failed to fetch configuration documentation: ${errorMessage}`
+      }
+    }
+  }
+
   private async getNpmPackageDoc(args: NpmDocArgs): Promise<DocResult> {
     // Set default values for includeTypes and includeExamples
     const enhancedArgs: NpmDocArgs = {
@@ -0,0 +1,134 @@
+/**
+ * Helpers for normalising the package names users pass in, so common variants
+ * (a stray trailing slash, different case) find the same package.
+ */
+import { parseGitPackageSpec } from "./utils/github-client.js"
+
+// The ecosystem each language-specific tool looks packages up in
+const TOOL_LANGUAGES: Record<string, string> = {
+  describe_go_package: "go",
+  lookup_go_doc: "go",
+  describe_python_package: "python",
+  lookup_python_doc: "python",
+  describe_npm_package: "npm",
+  lookup_npm_doc: "npm",
+  get_npm_package_doc: "npm",
+  describe_rust_package: "rust",
+  describe_php_package: "php",
+  describe_java_package: "java",
+  describe_dotnet_package: "dotnet",
+}
+
+/**
+ * Normalise a package name for an ecosystem's lookups:
+ * - Go import paths are case sensitive, so only the scheme and trailing slashes are dropped
+ *   (and the host, which isn't case sensitive, lowercased)
+ * - npm, crates.io and Packagist names are lowercase, so they're lowercased; npm specs pointing at
+ *   a git repository are left alone
+ * - Python names double as import paths for local lookups (e.g. requests.sessions), so they're only
+ *   trimmed here; PyPI's own normalisation is applied to registry URLs
+ * - Maven coordinates and other names are only trimmed
+ */
+export function normalizePackageName(language: string, name: string): string {
+  const trimmed = name.trim()
+
+  switch (language) {
+    case "go": {
+      const path = trimmed.replace(/^https?:\/\//, "").replace(/\/+$/, "")
+      const [host, ...rest] = path.split("/")
+      return host.includes(".") ? [host.toLowerCase(), ...rest].join("/") : path
+    }
+    case "npm":
+      return parseGitPackageSpec(trimmed)
+        ? trimmed
+        : trimmed.replace(/\/+$/, "").toLowerCase()
+    case "rust":
+    case "php":
+      return trimmed.replace(/\/+$/, "").toLowerCase()
+    case "python":
+      return trimmed.replace(/\/+$/, "")
+    default:
+      return trimmed
+  }
+}
+
+/**
+ * Normalise the package name in a tool call's arguments, for tools taking a package and either a
+ * language argument or a language implied by the tool's name. Other arguments are returned as is.
+ */
+export function normalizePackageArgs(toolName: string, args: unknown): unknown {
+  if (typeof args !== "object" || args === null) {
+    return args
+  }
+
+  const { package: packageName, language } = args as { package?: unknown; language?: unknown }
+  const ecosystem = TOOL_LANGUAGES[toolName] ?? (typeof language === "string" ? language : undefined)
+  if (typeof packageName !== "string" || !ecosystem) {
+    return args
+  }
+
+  return { ...args, package: normalizePackageName(ecosystem, packageName) }
+}
+
+/**
+ * Normalise a Python project name as PyPI does (PEP 503): lowercased, with runs of dots, hyphens
+ * and underscores replaced by a single hyphen, so Foo.Bar_Baz and foo-bar-baz are the same project
+ */
+export function normalizePyPIName(name: string): string {
+  return name.trim().toLowerCase().replace(/[-_.]+/g, "-")
+}
+
+/**
+ * Build a PyPI JSON API URL for a project, optionally at a release
+ */
+export function pypiJsonUrl(name: string, version?: string): string {
+  const project = encodeURIComponent(normalizePyPIName(name))
+  return version
+    ? `https://pypi.org/pypi/${project}/${encodeURIComponent(version)}/json`
+    : `https://pypi.org/pypi/${project}/json`
+}
+
+/**
+ * Build the pkg.go.dev URL of a Go package, optionally at a module version. Each path segment is
+ * encoded on its own, so the slashes of standard library and subpackage paths (net/http,
+ * golang.org/x/net/http2) are kept rather than escaped.
+ */
+export function pkgGoDevUrl(importPath: string, version?: string): string {
+  const path = importPath.split("/").map(encodeURIComponent).join("/")
+  return `https://pkg.go.dev/${path}${version ? `@${encodeURIComponent(version)}` : ""}`
+}
+
+// The top-level directories of the Go standard library. Module paths without a dot in their first
+// segment (mycorp/foo, as private and replaced modules can be) mustn't be taken for standard library
+// packages, so only these roots are; a root added by a later Go release is looked up like a module.
+const GO_STANDARD_LIBRARY_ROOTS = new Set([
+  "archive", "bufio", "builtin", "bytes", "cmp", "compress", "container", "context", "crypto",
+  "database", "debug", "embed", "encoding", "errors", "expvar", "flag", "fmt", "go", "hash", "html",
+  "image", "index", "io", "iter", "log", "maps", "math", "mime", "net", "os", "path", "plugin",
+  "reflect", "regexp", "runtime", "simd", "slices", "sort", "strconv", "strings", "structs", "sync",
+  "syscall", "testing", "text", "time", "unicode", "unique", "unsafe", "uuid", "weak",
+])
+
+/**
+ * Check whether a Go import path is in the standard library (fmt, net/http), which go doc documents
+ * from the local toolchain
+ */
+export function isGoStandardLibrary(importPath: string): boolean {
+  return GO_STANDARD_LIBRARY_ROOTS.has(importPath.split("/")[0])
+}
+
+/**
+ * Check whether an npm package only holds type definitions for another package, e.g. @types/node
+ */
+export function isTypesPackage(name: string): boolean {
+  return /^@types\/[^/]+$/.test(name)
+}
+
+/**
+ * Get the name of the package a type definitions package describes. Scoped packages have their
+ * scope folded into the name with a double underscore, so @types/babel__core is for @babel/core.
+ */
+export function typedPackageName(typesPackage: string): string {
+  const name = typesPackage.replace(/^@types\//, "")
+  return name.includes("__") ? `@${name.replace("__", "/")}` : name
+}
@@ -0,0 +1,259 @@
+import axios from 'axios';
+import { McpLogger } from './logger.js';
+import rustHttpClient from './utils/rust-http-client.js';
+
+export type PackageSearchLanguage = "go" | "python" | "npm" | "rust" | "php" | "java" | "dotnet";
+
+export interface PackageSearchResult {
+  name: string;
+  description?: string;
+  version?: string;
+  downloads?: number; // Download count over downloadsPeriod, where the registry reports one
+  downloadsPeriod?: "week" | "month" | "90 days" | "all time";
+  importedBy?: number; // Packages importing a Go package, pkg.go.dev's popularity signal
+}
+
+// Results per page of PyPI's search page, which can't be changed
+const PYPI_SEARCH_PAGE_SIZE = 20;
+
+/**
+ * Searches package registries by name/keyword
+ */
+export class PackageSearch {
+  private logger: McpLogger;
+
+  constructor(logger: McpLogger) {
+    this.logger = logger.child('PackageSearch');
+  }
+
+  /**
+   * Search a registry for packages matching a query. Results are paged by limit, so page 2 holds
+   * the limit results after the first limit in the registry's relevance order.
+   */
+  public async searchPackages(
+    language: PackageSearchLanguage,
+    query: string,
+    limit: number = 10,
+    registry: string = "https://registry.npmjs.org",
+    page: number = 1
+  ): Promise<PackageSearchResult[]> {
+    const offset = (page - 1) * limit;
+    switch (language) {
+      case "npm":
+        return this.searchNpm(query, limit, offset, registry);
+      case "python":
+        return this.searchPyPI(query, limit, offset);
+      case "rust":
+        return this.searchCratesIo(query, limit, page);
+      case "php":
+        return this.searchPackagist(query, limit, page);
+      case "java":
+        return this.searchMavenCentral(query, limit, offset);
+      case "go":
+        return this.searchPkgGoDev(query, limit, page);
+      case "dotnet":
+        return this.searchNuGet(query, limit, offset);
+      default:
+        return [];
+    }
+  }
+
+  /**
+   * Order results so exact and prefix name matches come before the registry's own relevance order
+   */
+  public rankResults(query: string, results: PackageSearchResult[]): PackageSearchResult[] {
+    const normalisedQuery = query.trim().toLowerCase();
+
+    const tier = (name: string): number => {
+      const normalisedName = name.toLowerCase();
+      if (normalisedName === normalisedQuery) return 0;
+      if (normalisedName.startsWith(normalisedQuery)) return 1;
+      if (normalisedName.includes(normalisedQuery)) return 2;
+      return 3;
+    };
+
+    // Array.prototype.sort is stable, so registry order is kept within each tier
+    return [...results].sort((a, b) => tier(a.name) - tier(b.name));
+  }
+
+  /**
+   * Build a "Did you mean" hint from registry packages with names close to the requested one
+   */
+  public async suggestAlternatives(
+    language: PackageSearchLanguage,
+    packageName: string,
+    registry?: string
+  ): Promise<string | undefined> {
+    try {
+      const results = await this.searchPackages(language, packageName, 10, registry);
+      const requested = packageName.toLowerCase();
+
+      const suggestions = results
+        .map(result => result.name)
+        .filter(name => name.toLowerCase() !== requested)
+        .map(name => ({ name, distance: levenshteinDistance(name.toLowerCase(), requested) }))
+        .sort((a, b) => a.distance - b.distance)
+        .slice(0, 3)
+        .map(suggestion => suggestion.name);
+
+      return suggestions.length > 0 ? `Did you mean: ${suggestions.join(", ")}?` : undefined;
+    } catch (error) {
+      this.logger.debug(`Error looking up suggestions for ${packageName}: ${error}`);
+      return undefined;
+    }
+  }
+
+  private async searchNpm(query: string, limit: number, offset: number, registry: string): Promise<PackageSearchResult[]> {
+    const response = await axios.get(`${registry}/-/v1/search`, {
+      params: { text: query, size: limit, from: offset },
+    });
+
+    // The public registry reports downloads with each result, other registries may not
+    // eslint-disable-next-line @typescript-eslint/no-explicit-any
+    return (response.data?.objects || []).map((item: any) => ({
+      name: item.package.name,
+      description: item.package.description,
+      version: item.package.version,
+      downloads: item.downloads?.weekly,
+      downloadsPeriod: item.downloads?.weekly !== undefined ? "week" : undefined,
+    }));
+  }
+
+  private async searchPyPI(query: string, limit: number, offset: number): Promise<PackageSearchResult[]> {
+    // PyPI has no JSON search API, so read the results from the search pages, which have a fixed size
+    const results: PackageSearchResult[] = [];
+    const snippetPattern = /<span class="package-snippet__name">([^<]+)<\/span>\s*<span class="package-snippet__version">([^<]+)<\/span>[\s\S]*?<p class="package-snippet__description">([^<]*)<\/p>/g;
+    let skip = offset % PYPI_SEARCH_PAGE_SIZE;
+
+    for (let page = Math.floor(offset / PYPI_SEARCH_PAGE_SIZE) + 1; results.length < limit; page++) {
+      const response = await axios.get('https://pypi.org/search/', {
+        params: { q: query, page },
+        responseType: 'text',
+      });
+
+      const matches = Array.from(String(response.data).matchAll(snippetPattern));
+      for (const match of matches.slice(skip)) {
+        results.push({
+          name: match[1].trim(),
+          version: match[2].trim(),
+          description: match[3].trim() || undefined,
+        });
+        if (results.length >= limit) break;
+      }
+      skip = 0;
+
+      if (matches.length < PYPI_SEARCH_PAGE_SIZE) break;
+    }
+
+    return results;
+  }
+
+  private async searchCratesIo(query: string, limit: number, page: number): Promise<PackageSearchResult[]> {
+    const response = await rustHttpClient.cratesIoFetch("crates", {
+      params: { q: query, per_page: limit, page },
+    });
+
+    if (response.contentType !== "json") {
+      throw new Error("Expected JSON response but got text");
+    }
+
+    const data = response.data as {
+      crates: Array<{ name: string; max_version: string; description?: string; recent_downloads?: number; downloads?: number }>;
+    };
+
+    return data.crates.map(crate => ({
+      name: crate.name,
+      description: crate.description,
+      version: crate.max_version,
+      downloads: crate.recent_downloads ?? crate.downloads,
+      downloadsPeriod: crate.recent_downloads !== undefined ? "90 days" : crate.downloads !== undefined ? "all time" : undefined,
+    }));
+  }
+
+  private async searchPackagist(query: string, limit: number, page: number): Promise<PackageSearchResult[]> {
+    const response = await axios.get('https://packagist.org/search.json', {
+      params: { q: query, per_page: limit, page },
+    });
+
+    // eslint-disable-next-line @typescript-eslint/no-explicit-any
+    return (response.data?.results || []).slice(0, limit).map((item: any) => ({
+      name: item.name,
+      description: item.description,
+      downloads: item.downloads,
+      downloadsPeriod: item.downloads !== undefined ? "all time" : undefined,
+    }));
+  }
+
+  private async searchMavenCentral(query: string, limit: number, offset: number): Promise<PackageSearchResult[]> {
+    // Coordinates such as com.example:artifact search better as separate terms
+    const response = await axios.get('https://search.maven.org/solrsearch/select', {
+      params: { q: query.replace(/:/g, ' '), rows: limit, start: offset, wt: 'json' },
+    });
+
+    // eslint-disable-next-line @typescript-eslint/no-explicit-any
+    return (response.data?.response?.docs || []).map((doc: any) => ({
+      name: `${doc.g}:${doc.a}`,
+      version: doc.latestVersion,
+    }));
+  }
+
+  private async searchNuGet(query: string, limit: number, offset: number): Promise<PackageSearchResult[]> {
+    const response = await axios.get('https://azuresearch-usnc.nuget.org/query', {
+      params: { q: query, take: limit, skip: offset },
+    });
+
+    // eslint-disable-next-line @typescript-eslint/no-explicit-any
+    return (response.data?.data || []).map((item: any) => ({
+      name: item.id,
+      description: item.description,
+      version: item.version,
+      downloads: item.totalDownloads,
+      downloadsPeriod: item.totalDownloads !== undefined ? "all time" : undefined,
+    }));
+  }
+
+  private async searchPkgGoDev(query: string, limit: number, page: number): Promise<PackageSearchResult[]> {
+    // pkg.go.dev has no search API, so read the results from the search page
+    const response = await axios.get('https://pkg.go.dev/search', {
+      params: { q: query, m: 'package', limit, page },
+      responseType: 'text',
+    });
+
+    const html = String(response.data);
+    const results: PackageSearchResult[] = [];
+    const resultPattern = /<span class="SearchSnippet-header-path">\(([^)]+)\)<\/span>[\s\S]*?<p class="SearchSnippet-synopsis"[^>]*>([\s\S]*?)<\/p>/;
+
+    // Each snippet is read on its own so its "Imported by" count can't be taken from the next one
+    for (const snippet of html.split('<div class="SearchSnippet">').slice(1)) {
+      const match = snippet.match(resultPattern);
+      if (!match) continue;
+      const importedBy = snippet.match(/Imported by[\s\S]*?<strong>([\d,]+)<\/strong>/)?.[1];
+      results.push({
+        name: match[1].trim(),
+        description: match[2].replace(/<[^>]*>/g, '').trim() || undefined,
+        importedBy: importedBy ? Number(importedBy.replace(/,/g, '')) : undefined,
+      });
+      if (results.length >= limit) break;
+    }
+
+    return results;
+  }
+}
+
+/**
+ * Edit distance between two strings
+ */
+export function levenshteinDistance(a: string, b: string): number {
+  let previous = Array.from({ length: b.length + 1 }, (_, i) => i);
+
+  for (let i = 1; i <= a.length; i++) {
+    const current = [i];
+    for (let j = 1; j <= b.length; j++) {
+      const cost = a[i - 1] === b[j - 1] ? 0 : 1;
+      current[j] = Math.min(current[j - 1] + 1, previous[j] + 1, previous[j - 1] + cost);
+    }
+    previous = current;
+  }
+
+  return previous[b.length];
+}
@@ -0,0 +1,309 @@
+import axios from 'axios';
+import { McpLogger } from './logger.js';
+import { DocFormat, DocSource, DocResult, PackageMetadata, SearchUtils, isDocFormat, isDocSource } from './search-utils.js';
+import { createRepoClient } from './utils/repo-client.js';
+import { PackageSearch } from './package-search.js';
+
+export interface PhpDocArgs {
+  package: string;
+  version?: string;
+  format?: DocFormat;
+  source?: DocSource;
+  includeRaw?: boolean; // Return the README verbatim as well as the summary
+}
+
+export const isPhpDocArgs = (args: unknown): args is PhpDocArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as PhpDocArgs).package === "string" &&
+    (typeof (args as PhpDocArgs).version === "string" ||
+      (args as PhpDocArgs).version === undefined) &&
+    (isDocFormat((args as PhpDocArgs).format) ||
+      (args as PhpDocArgs).format === undefined) &&
+    (isDocSource((args as PhpDocArgs).source) ||
+      (args as PhpDocArgs).source === undefined)
+  );
+};
+
+// Subset of a Packagist version entry that we use
+export interface PackagistVersion {
+  name: string;
+  description?: string;
+  version: string;
+  keywords?: string[];
+  homepage?: string;
+  license?: string[];
+  source?: {
+    url?: string;
+    type?: string;
+  };
+  require?: Record<string, string>;
+}
+
+// Subset of the Packagist package metadata response
+export interface PackagistPackage {
+  name: string;
+  description?: string;
+  repository?: string;
+  versions: Record<string, PackagistVersion>;
+  downloads?: {
+    total?: number;
+    monthly?: number;
+  };
+}
+
+// Composer package names are "vendor/package", lowercase, with limited punctuation
+const COMPOSER_NAME_PATTERN = /^[a-z0-9]([_.-]?[a-z0-9]+)*\/[a-z0-9](([_.]|-{1,2})?[a-z0-9]+)*$/;
+
+export class PhpDocsHandler {
+  private logger: McpLogger;
+  private searchUtils: SearchUtils;
+  private packageSearch: PackageSearch;
+
+  constructor(logger: McpLogger) {
+    this.logger = logger.child('PhpDocs');
+    this.searchUtils = new SearchUtils(logger);
+    this.packageSearch = new PackageSearch(logger);
+  }
+
+  /**
+   * Split a Composer package name into its vendor and package parts
+   */
+  public parsePackageName(packageName: string): { vendor: string; name: string } {
+    const normalised = packageName.trim().toLowerCase();
+    if (!COMPOSER_NAME_PATTERN.test(normalised)) {
+      throw new Error(`Invalid Composer package name '${packageName}'. Expected the form vendor/package (e.g. monolog/monolog)`);
+    }
+
+    const [vendor, name] = normalised.split('/');
+    return { vendor, name };
+  }
+
+  /**
+   * Get package metadata from Packagist
+   */
+  public async getPackageInfo(packageName: string): Promise<PackagistPackage> {
+    const { vendor, name } = this.parsePackageName(packageName);
+    const url = `https://packagist.org/packages/${encodeURIComponent(vendor)}/${encodeURIComponent(name)}.json`;
+    this.logger.debug(`Fetching Packagist metadata: ${url}`);
+
+    const response = await axios.get(url);
+    if (!response.data || !response.data.package) {
+      throw new Error(`No package metadata returned for ${vendor}/${name}`);
+    }
+
+    return response.data.package as PackagistPackage;
+  }
+
+  /**
+   * Pick the requested version, or the newest stable version if none was requested
+   */
+  public selectVersion(info: PackagistPackage, version?: string): PackagistVersion | undefined {
+    if (version) {
+      return info.versions[version] || info.versions[`v${version}`] || info.versions[version.replace(/^v/, '')];
+    }
+
+    const [latest] = this.newestVersions(
+      info,
+      v => !v.startsWith('dev-') && !v.endsWith('-dev') && !/(alpha|beta|rc)/i.test(v),
+      1
+    );
+    const fallback = latest || Object.keys(info.versions)[0];
+    return fallback ? info.versions[fallback] : undefined;
+  }
+
+  /**
+   * Get the newest `limit` versions accepted by the filter, newest first. Packages can have
+   * thousands of versions, so this keeps a bounded window rather than sorting them all.
+   */
+  private newestVersions(info: PackagistPackage, filter: (version: string) => boolean, limit: number): string[] {
+    const newest: string[] = [];
+
+    for (const version in info.versions) {
+      if (!filter(version)) continue;
+      if (newest.length === limit && this.compareVersions(version, newest[limit - 1]) <= 0) continue;
+
+      let index = newest.findIndex(v => this.compareVersions(version, v) > 0);
+      if (index === -1) index = newest.length;
+      newest.splice(index, 0, version);
+      if (newest.length > limit) newest.pop();
+    }
+
+    return newest;
+  }
+
+  /**
+   * Compare two dotted version strings numerically
+   */
+  private compareVersions(a: string, b: string): number {
+    const partsA = a.replace(/^v/, '').split('.').map(p => parseInt(p, 10) || 0);
+    const partsB = b.replace(/^v/, '').split('.').map(p => parseInt(p, 10) || 0);
+
+    for (let i = 0; i < Math.max(partsA.length, partsB.length); i++) {
+      const diff = (partsA[i] || 0) - (partsB[i] || 0);
+      if (diff !== 0) return diff;
+    }
+
+    return 0;
+  }
+
+  /**
+   * Get structured metadata for a PHP package version
+   */
+  public async getPackageMetadata(packageName: string, version?: string): Promise<PackageMetadata> {
+    const info = await this.getPackageInfo(packageName);
+    return this.toPackageMetadata(info, this.selectVersion(info, version));
+  }
+
+  /**
+   * Map a package's Packagist details and the selected version to structured metadata
+   */
+  private toPackageMetadata(info: PackagistPackage, selected?: PackagistVersion): PackageMetadata {
+    return {
+      name: info.name,
+      version: selected?.version,
+      description: info.description,
+      license: selected?.license?.join(', '),
+      homepage: selected?.homepage,
+      repository: info.repository || selected?.source?.url,
+      keywords: selected?.keywords,
+      dependencies: selected?.require,
+    };
+  }
+
+  /**
+   * Get documentation for a PHP package
+   */
+  public async describePhpPackage(args: PhpDocArgs): Promise<DocResult> {
+    const { package: packageName, version } = args;
+    this.logger.debug(`Getting PHP documentation for ${packageName}${version ? `@${version}` : ""}`);
+
+    // Documentation comes from the registry, so there's nothing to read locally
+    if (args.source === "local") {
+      return {
+        error: `Local documentation isn't available for PHP packages. Use source "auto" or "network"`,
+      };
+    }
+
+    try {
+      const info = await this.getPackageInfo(packageName);
+      const selected = this.selectVersion(info, version);
+
+      if (version && !selected) {
+        return {
+          error: `Version ${version} of ${info.name} not found on Packagist`,
+        };
+      }
+
+      const repository = info.repository || selected?.source?.url;
+      let usage = `## ${info.name} ${selected?.version || ''}\n\n`;
+
+      if (info.description) {
+        usage += `${info.description}\n\n`;
+      }
+
+      usage += `### Installation\n\n\`\`\`bash\ncomposer require ${info.name}${version ? `:${version}` : ''}\n\`\`\`\n\n`;
+
+      if (selected?.license && selected.license.length > 0) {
+        usage += `**Licence:** ${selected.license.join(', ')}\n\n`;
+      }
+
+      const recentVersions = this.newestVersions(info, v => !v.startsWith('dev-') && !v.endsWith('-dev'), 5);
+      if (recentVersions.length > 0) {
+        usage += `**Recent versions:** ${recentVersions.join(', ')}\n\n`;
+      }
+
+      usage += `### Links\n\n`;
+      usage += `- [Packagist](https://packagist.org/packages/${info.name})\n`;
+      if (repository) usage += `- [Repository](${repository})\n`;
+      if (selected?.homepage) usage += `- [Homepage](${selected.homepage})\n`;
+
+      let example: string | undefined;
+      let languageNote: string | undefined;
+      let readme: string | undefined;
+
+      if (repository) {
+        readme = await createRepoClient(repository, this.logger)?.getReadme();
+        if (readme) {
+          languageNote = this.searchUtils.languageNote(readme);
+
+          // Extract relevant sections
+          const sections = readme.split(/#+\s/);
+          for (const section of sections) {
+            const lower = section.toLowerCase();
+            if (lower.startsWith("usage") || lower.startsWith("getting started")) {
+              usage += `\n### Usage\n\n${section.split("\n").slice(1).join("\n").trim()}\n`;
+            } else if (lower.startsWith("example")) {
+              example = section.split("\n").slice(1).join("\n").trim();
+            }
+          }
+        }
+      }
+
+      let description = info.description || `PHP package: ${info.name}`;
+      if (languageNote) {
+        description += `\n\n${languageNote}`;
+      }
+
+      return {
+        description,
+        usage,
+        example,
+        metadata: args.format === "json" ? this.toPackageMetadata(info, selected) : undefined,
+        readme: args.includeRaw ? readme : undefined,
+      };
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response?.status === 404) {
+        const suggestion = await this.packageSearch.suggestAlternatives("php", packageName);
+        return {
+          error: `Package ${packageName} not found on Packagist.${suggestion ? ` ${suggestion}` : ""} Try installing it with 'composer require ${packageName}'`,
+          suggestInstall: true,
+        };
+      }
+
+      const errorMessage = error instanceof Error ? error.message : String(error);
+      this.logger.error(`Error getting PHP documentation for ${packageName}:`, error);
+      return {
+        error: `Failed to fetch PHP documentation: ${errorMessage}`,
+      };
+    }
+  }
+
+  /**
+   * Get package documentation split into searchable sections
+   */
+  public async getSearchableContent(packageName: string): Promise<{
+    packageInfo: PackagistPackage;
+    sections: Array<{ content: string; type: string }>;
+  }> {
+    const packageInfo = await this.getPackageInfo(packageName);
+    const sections: Array<{ content: string; type: string }> = [];
+
+    if (packageInfo.description) {
+      sections.push({ content: packageInfo.description, type: "description" });
+    }
+
+    const repository = packageInfo.repository || this.selectVersion(packageInfo)?.source?.url;
+    if (repository) {
+      const readme = await createRepoClient(repository, this.logger)?.getReadme();
+      if (readme) {
+        for (const section of this.searchUtils.splitMarkdownSections(readme)) {
+          if (!section.trim()) continue;
+
+          const heading = section.split('\n')[0].toLowerCase();
+          let type = "general";
+          if (heading.includes("example")) type = "example";
+          else if (heading.includes("usage") || heading.includes("getting started")) type = "usage";
+          else if (heading.includes("install")) type = "installation";
+          else if (heading.includes("config")) type = "configuration";
+          else if (heading.includes("api") || heading.includes("method")) type = "api";
+
+          sections.push({ content: section, type });
+        }
+      }
+    }
+
+    return { packageInfo, sections };
+  }
+}
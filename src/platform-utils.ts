@@ -0,0 +1,139 @@
+/**
+ * Helpers for working out which platforms/architectures a package supports.
+ * Mostly relevant for packages that ship native code or prebuilt binaries.
+ */
+
+// Node.js os/cpu names used in npm platform package names (e.g. @esbuild/linux-x64)
+const NPM_PLATFORM_PACKAGE_PATTERN = /(?:^|[/-])(darwin|linux|win32|freebsd|openbsd|netbsd|sunos|aix|android)-(x64|arm64|arm|ia32|x86|ppc64|s390x|riscv64|loong64|mips64el)(?:-(gnu|musl|msvc|gnueabihf|eabi))?$/
+
+export interface PythonDistributionFile {
+  filename: string
+  packagetype?: string
+}
+
+/**
+ * Extract supported platforms from an npm package manifest.
+ * Uses the os/cpu fields, and falls back to platform specific optionalDependencies
+ * (the pattern used by packages like esbuild and swc to ship prebuilt binaries).
+ */
+export function extractNpmPlatforms(manifest: {
+  os?: string[]
+  cpu?: string[]
+  optionalDependencies?: Record<string, string>
+}): string[] {
+  const platforms: string[] = []
+
+  const os = Array.isArray(manifest.os) ? manifest.os : []
+  const cpu = Array.isArray(manifest.cpu) ? manifest.cpu : []
+
+  if (os.length > 0 || cpu.length > 0) {
+    if (os.length > 0) platforms.push(`os: ${os.join(", ")}`)
+    if (cpu.length > 0) platforms.push(`cpu: ${cpu.join(", ")}`)
+    return platforms
+  }
+
+  for (const dependency of Object.keys(manifest.optionalDependencies || {})) {
+    const match = dependency.match(NPM_PLATFORM_PACKAGE_PATTERN)
+    if (match) {
+      const platform = `${match[1]}-${match[2]}${match[3] ? `-${match[3]}` : ""}`
+      if (!platforms.includes(platform)) {
+        platforms.push(platform)
+      }
+    }
+  }
+
+  return platforms
+}
+
+/**
+ * Extract supported platforms from the wheel tags of a PyPI release's files.
+ * Wheel filenames follow {name}-{version}(-{build})?-{python}-{abi}-{platform}.whl
+ */
+export function extractPythonPlatforms(files: PythonDistributionFile[]): string[] {
+  const archByOs = new Map<string, Set<string>>()
+  let hasPureWheel = false
+
+  for (const file of files) {
+    if (!file.filename.endsWith(".whl")) continue
+
+    const platformTag = file.filename.slice(0, -4).split("-").pop()
+    if (!platformTag) continue
+
+    // Compressed tag sets are separated by dots, e.g. manylinux_2_17_x86_64.manylinux2014_x86_64
+    for (const tag of platformTag.split(".")) {
+      if (tag === "any") {
+        hasPureWheel = true
+        continue
+      }
+
+      const parsed = parseWheelPlatformTag(tag)
+      if (!parsed) continue
+
+      const arches = archByOs.get(parsed.os) || new Set<string>()
+      arches.add(parsed.arch)
+      archByOs.set(parsed.os, arches)
+    }
+  }
+
+  const platforms = Array.from(archByOs.entries())
+    .sort(([a], [b]) => a.localeCompare(b))
+    .map(([os, arches]) => `${os} (${Array.from(arches).sort().join(", ")})`)
+
+  if (platforms.length === 0 && hasPureWheel) {
+    return ["any (pure Python)"]
+  }
+
+  return platforms
+}
+
+/**
+ * Map a single wheel platform tag to an os/arch pair
+ */
+function parseWheelPlatformTag(tag: string): { os: string; arch: string } | undefined {
+  const normaliseArch = (arch: string): string => {
+    switch (arch) {
+      case "amd64":
+        return "x86_64"
+      case "win32":
+      case "i686":
+        return "x86"
+      case "arm64":
+      case "aarch64":
+        return "arm64"
+      default:
+        return arch
+    }
+  }
+
+  if (tag === "win32") {
+    return { os: "windows", arch: "x86" }
+  }
+
+  let match = tag.match(/^win_(.+)$/)
+  if (match) {
+    return { os: "windows", arch: normaliseArch(match[1]) }
+  }
+
+  match = tag.match(/^(?:many|musl)?linux(?:1|2010|2014|_\d+_\d+)?_(.+)$/)
+  if (match) {
+    return { os: tag.startsWith("musllinux") ? "linux-musl" : "linux", arch: normaliseArch(match[1]) }
+  }
+
+  match = tag.match(/^macosx_\d+_\d+_(.+)$/)
+  if (match) {
+    return { os: "macos", arch: match[1] === "universal2" ? "universal" : normaliseArch(match[1]) }
+  }
+
+  return undefined
+}
+
+/**
+ * Format a list of platforms as a single "Platforms: ..." line
+ */
+export function formatPlatforms(platforms: string[]): string | undefined {
+  if (platforms.length === 0) {
+    return undefined
+  }
+
+  return `Platforms: ${platforms.join("; ")}`
+}
@@ -0,0 +1,435 @@
+/**
+ * Helpers for finding a project's manifests and the direct dependencies they declare, for each
+ * ecosystem the project (or each package of a monorepo) uses.
+ */
+import { existsSync, readdirSync, readFileSync } from "fs"
+import { dirname, join, relative, resolve } from "path"
+import { parseGoModRequirements } from "./dependency-versions.js"
+
+export type ProjectEcosystem = "npm" | "go" | "python" | "rust"
+
+export interface ProjectDependency {
+  name: string
+  requirement: string // The version or range the manifest asks for, or "*" when it doesn't say
+  ecosystem: ProjectEcosystem
+  manifest: string // Path of the declaring manifest, relative to the project
+  dev?: boolean
+}
+
+// Directories that never hold a project's own manifests
+const IGNORED_DIRECTORIES = new Set(["node_modules", "vendor", "target", "dist", "build", "venv", ".venv", "env", "__pycache__"])
+
+// Manifest file names, with the parser for each
+const MANIFEST_PARSERS: Record<string, { ecosystem: ProjectEcosystem; parse: (content: string) => Array<Omit<ProjectDependency, "ecosystem" | "manifest">> }> = {
+  "package.json": { ecosystem: "npm", parse: parsePackageJson },
+  "go.mod": { ecosystem: "go", parse: parseGoMod },
+  "requirements.txt": { ecosystem: "python", parse: parseRequirementsTxt },
+  "pyproject.toml": { ecosystem: "python", parse: parsePyprojectToml },
+  "Cargo.toml": { ecosystem: "rust", parse: parseCargoToml },
+}
+
+/**
+ * Find the manifests in a project's root and the directories directly below it (the packages of
+ * most monorepos), and the direct dependencies each declares. Dev dependencies are only included
+ * when asked for, and a dependency declared by several manifests of one ecosystem is listed once.
+ */
+export function findProjectDependencies(projectPath: string, includeDev = false): ProjectDependency[] {
+  const directories = [projectPath]
+  for (const entry of readdirSync(projectPath, { withFileTypes: true })) {
+    if (entry.isDirectory() && !entry.name.startsWith(".") && !IGNORED_DIRECTORIES.has(entry.name)) {
+      directories.push(join(projectPath, entry.name))
+    }
+  }
+
+  const dependencies = new Map<string, ProjectDependency>()
+  for (const directory of directories) {
+    for (const [fileName, { ecosystem, parse }] of Object.entries(MANIFEST_PARSERS)) {
+      const path = join(directory, fileName)
+      if (!existsSync(path)) continue
+
+      let declared: Array<Omit<ProjectDependency, "ecosystem" | "manifest">>
+      try {
+        declared = parse(readFileSync(path, "utf-8"))
+      } catch {
+        continue // An unparseable manifest is skipped rather than failing the whole project
+      }
+
+      for (const dependency of declared) {
+        const key = `${ecosystem}:${dependency.name.toLowerCase()}`
+        if ((dependency.dev && !includeDev) || dependencies.has(key)) continue
+        dependencies.set(key, { ...dependency, ecosystem, manifest: relative(projectPath, path) })
+      }
+    }
+  }
+
+  return Array.from(dependencies.values())
+}
+
+/**
+ * Find the directory of a workspace package of an npm, Yarn or Bun monorepo by its name, from the
+ * workspaces globs of the root package.json (either a list, or Yarn's { packages: [...] }).
+ * Returns undefined when the project isn't a monorepo or no workspace package has that name.
+ */
+export function findNpmWorkspacePackage(projectPath: string, packageName: string): string | undefined {
+  let workspaces: unknown
+  try {
+    workspaces = JSON.parse(readFileSync(join(projectPath, "package.json"), "utf-8")).workspaces
+  } catch {
+    return undefined
+  }
+  const patterns = Array.isArray(workspaces) ? workspaces : (workspaces as { packages?: unknown } | undefined)?.packages
+  if (!Array.isArray(patterns)) return undefined
+
+  const excluded = new Set(
+    patterns.filter(pattern => typeof pattern === "string" && pattern.startsWith("!"))
+      .flatMap(pattern => expandWorkspacePattern(projectPath, pattern.slice(1)))
+  )
+  for (const pattern of patterns) {
+    if (typeof pattern !== "string" || pattern.startsWith("!")) continue
+    for (const directory of expandWorkspacePattern(projectPath, pattern)) {
+      if (excluded.has(directory)) continue
+      try {
+        if (JSON.parse(readFileSync(join(directory, "package.json"), "utf-8")).name === packageName) {
+          return directory
+        }
+      } catch {
+        continue // Not a package, or an unparseable one
+      }
+    }
+  }
+  return undefined
+}
+
+// Deepest a ** in a workspaces glob is followed, as workspace packages are rarely nested further
+const MAX_WORKSPACE_GLOB_DEPTH = 4
+
+/**
+ * Expand a workspaces glob such as packages/* or apps/** to the directories it matches. Only *, ?
+ * and ** are supported, which covers the globs monorepos use in practice.
+ */
+function expandWorkspacePattern(projectPath: string, pattern: string): string[] {
+  const segments = pattern.replace(/^\.\//, "").replace(/\/+$/, "").split("/").filter(Boolean)
+  const subdirectories = (directory: string) => {
+    try {
+      return readdirSync(directory, { withFileTypes: true })
+        .filter(entry => entry.isDirectory() && !entry.name.startsWith(".") && entry.name !== "node_modules")
+        .map(entry => entry.name)
+    } catch {
+      return []
+    }
+  }
+
+  const expand = (directory: string, index: number, depth: number): string[] => {
+    if (index === segments.length) return [directory]
+    const segment = segments[index]
+    if (segment === "**") {
+      const deeper = depth < MAX_WORKSPACE_GLOB_DEPTH
+        ? subdirectories(directory).flatMap(name => expand(join(directory, name), index, depth + 1))
+        : []
+      return [...expand(directory, index + 1, depth), ...deeper]
+    }
+    if (!/[*?]/.test(segment)) {
+      return existsSync(join(directory, segment)) ? expand(join(directory, segment), index + 1, depth) : []
+    }
+    const source = segment.replace(/[.+^$()|[\]{}\\]/g, "\\$&").replace(/\*/g, "[^/]*").replace(/\?/g, "[^/]")
+    const matcher = new RegExp(`^${source}$`)
+    return subdirectories(directory)
+      .filter(name => matcher.test(name))
+      .flatMap(name => expand(join(directory, name), index + 1, depth))
+  }
+
+  return Array.from(new Set(expand(projectPath, 0, 0)))
+}
+
+/**
+ * Get the dependencies and dev dependencies of a package.json. Workspace and local path
+ * dependencies are skipped, as they're part of the project rather than registry packages.
+ */
+export function parsePackageJson(content: string): Array<Omit<ProjectDependency, "ecosystem" | "manifest">> {
+  const manifest = JSON.parse(content)
+  const local = /^(workspace:|file:|link:|portal:)/
+
+  return [
+    ...Object.entries(manifest.dependencies || {}).map(([name, requirement]) => ({ name, requirement: String(requirement) })),
+    ...Object.entries(manifest.devDependencies || {}).map(([name, requirement]) => ({ name, requirement: String(requirement), dev: true })),
+  ].filter(dependency => !local.test(dependency.requirement))
+}
+
+/**
+ * Get the direct requirements of a go.mod, leaving out those marked // indirect
+ */
+export function parseGoMod(content: string): Array<Omit<ProjectDependency, "ecosystem" | "manifest">> {
+  return parseGoModRequirements(content)
+    .filter(requirement => !requirement.indirect)
+    .map(requirement => ({ name: requirement.module, requirement: requirement.version }))
+}
+
+/**
+ * Split a PEP 508 requirement such as "requests[socks]>=2.31; python_version > '3.8'" into the
+ * project name and its version specifier
+ */
+function parsePep508(requirement: string): { name: string; requirement: string } | undefined {
+  const match = requirement.trim().match(/^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*([^;]*)/)
+  return match ? { name: match[1], requirement: match[2].trim() || "*" } : undefined
+}
+
+/**
+ * Get the requirements of a requirements.txt, skipping comments, options (-r, -e, --index-url)
+ * and direct URLs
+ */
+export function parseRequirementsTxt(content: string): Array<Omit<ProjectDependency, "ecosystem" | "manifest">> {
+  return content.split("\n")
+    .map(line => line.replace(/\s+#.*$/, "").trim())
+    .filter(line => line && !line.startsWith("#") && !line.startsWith("-") && !/^\w+\+?\w*:\/\//.test(line) && !line.includes(" @ "))
+    .map(parsePep508)
+    .filter((dependency): dependency is { name: string; requirement: string } => dependency !== undefined)
+}
+
+/**
+ * Get the dependencies of a pyproject.toml: the PEP 621 [project] dependencies, or Poetry's
+ * [tool.poetry.dependencies] (without the python entry) and its dev groups
+ */
+export function parsePyprojectToml(content: string): Array<Omit<ProjectDependency, "ecosystem" | "manifest">> {
+  const projectDependencies = content.match(/^\[project\](?:(?!^\[)[\s\S])*?^dependencies\s*=\s*\[([\s\S]*?)\]\s*$/m)?.[1]
+  if (projectDependencies) {
+    return Array.from(projectDependencies.matchAll(/"([^"]+)"|'([^']+)'/g), match => parsePep508(match[1] ?? match[2]))
+      .filter((dependency): dependency is { name: string; requirement: string } => dependency !== undefined)
+  }
+
+  return Object.entries(parseTomlTables(content))
+    .filter(([table]) => table === "tool.poetry.dependencies" || /^tool\.poetry\.(dev-dependencies|group\.[\w-]+\.dependencies)$/.test(table))
+    .flatMap(([table, entries]) => entries
+      .filter(([name]) => name !== "python")
+      .filter(([, requirement]) => requirement !== "")
+      .map(([name, requirement]) => ({ name, requirement, dev: table !== "tool.poetry.dependencies" || undefined })))
+}
+
+// The Cargo.toml tables listing dependencies: [dependencies], [dev-dependencies] and
+// [build-dependencies], each also per target ([target.'cfg(unix)'.dependencies]), and a workspace's
+// shared [workspace.dependencies]. A dependency can also have a table of its own, named after it
+// ([dependencies.serde]).
+const CARGO_DEPENDENCY_TABLE = /^(?:workspace\.|target\.(?:'[^']*'|"[^"]*"|[^.'"]+)\.)?(dev-|build-)?dependencies(?:\.(.+))?$/
+
+/**
+ * Get every dependency a Cargo.toml declares, with an empty requirement for path and workspace
+ * dependencies (and others without a version)
+ */
+function parseCargoDependencies(content: string): Array<Omit<ProjectDependency, "ecosystem" | "manifest">> {
+  return Object.entries(parseTomlTables(content)).flatMap(([table, entries]) => {
+    const match = table.match(CARGO_DEPENDENCY_TABLE)
+    if (!match) return []
+
+    const dev = match[1] === "dev-" || undefined
+    if (match[2]) {
+      return [{ name: match[2].replace(/"/g, ""), requirement: entries.find(([key]) => key === "version")?.[1] ?? "", dev }]
+    }
+    return entries.map(([name, requirement]) => ({ name, requirement, dev }))
+  })
+}
+
+/**
+ * Get the dependencies and dev dependencies of a Cargo.toml, including build, target specific and
+ * workspace dependencies. Path dependencies and those inherited from the workspace are skipped, as
+ * they're part of the project or declared by the workspace root.
+ */
+export function parseCargoToml(content: string): Array<Omit<ProjectDependency, "ecosystem" | "manifest">> {
+  return parseCargoDependencies(content).filter(dependency => dependency.requirement !== "")
+}
+
+/**
+ * Get the names of every crate a Cargo.toml depends on, path and workspace dependencies included
+ */
+export function cargoDependencyNames(content: string): string[] {
+  return Array.from(new Set(parseCargoDependencies(content).map(dependency => dependency.name)))
+}
+
+/**
+ * Find the go.mod files in effect for a directory: those of the modules the go.work workspace it's
+ * in uses, or otherwise the go.mod of the module it's in. Either is looked for in the directory and
+ * the directories above it.
+ */
+export function findGoModFiles(directory: string): string[] {
+  const goWork = findUp(directory, "go.work")
+  if (goWork) {
+    // use ./a, or a use ( ... ) block of directories
+    const content = readFileSync(goWork, "utf-8").replace(/\/\/.*$/gm, "")
+    const used = Array.from(content.matchAll(/^\s*use\s*\(([^)]*)\)/gm), match => match[1].split("\n"))
+      .flat()
+      .concat(Array.from(content.matchAll(/^\s*use\s+([^\s(]+)/gm), match => match[1]))
+      .map(path => path.trim().replace(/^"|"$/g, ""))
+      .filter(Boolean)
+    return used.map(path => join(dirname(goWork), path, "go.mod")).filter(path => existsSync(path))
+  }
+
+  const goMod = findUp(directory, "go.mod")
+  return goMod ? [goMod] : []
+}
+
+/**
+ * Get the module path a go.mod declares
+ */
+export function goModulePath(goMod: string): string | undefined {
+  return goMod.match(/^\s*module\s+"?([^\s"]+)"?/m)?.[1]
+}
+
+/**
+ * Find a file in a directory or the nearest directory above it that has one
+ */
+function findUp(directory: string, fileName: string): string | undefined {
+  for (let current = resolve(directory); ; current = dirname(current)) {
+    const path = join(current, fileName)
+    if (existsSync(path)) return path
+    if (dirname(current) === current) return undefined
+  }
+}
+
+/**
+ * Read the key/value lines of each [table] of a TOML file. Values are reduced to the version they
+ * give: a string ("1.0"), or the version field of an inline table ({ version = "1.0" }); inline
+ * tables without one (path, git and workspace dependencies) get an empty version.
+ */
+function parseTomlTables(content: string): Record<string, Array<[string, string]>> {
+  const tables: Record<string, Array<[string, string]>> = {}
+  let table = ""
+
+  for (const line of content.split("\n")) {
+    const header = line.match(/^\s*\[([^\]]+)\]\s*$/)
+    if (header) {
+      table = header[1].trim()
+      continue
+    }
+
+    const entry = line.match(/^\s*([\w.-]+|"[^"]+")\s*=\s*(.+?)\s*$/)
+    if (!entry || !table) continue
+
+    // Dotted keys set one field, e.g. serde.version = "1.0" or serde.workspace = true
+    const [name, field] = entry[1].replace(/"/g, "").split(".")
+    if (field && field !== "version") {
+      tables[table] = [...(tables[table] || []), [name, ""]]
+      continue
+    }
+
+    const value = entry[2].startsWith("{")
+      ? entry[2].match(/\bversion\s*=\s*"([^"]*)"/)?.[1] ?? ""
+      : entry[2].match(/^"([^"]*)"/)?.[1] || "*"
+    tables[table] = [...(tables[table] || []), [name, value]]
+  }
+
+  return tables
+}
+
+// How a Package.swift dependency constrains the version used
+export type SwiftConstraintKind = "from" | "upToNextMajor" | "upToNextMinor" | "exact" | "range" | "branch" | "revision" | "path"
+
+export interface SwiftPackageDependency {
+  url: string // Repository URL, registry identity (scope.name) or local path
+  name?: string // Explicit name, from the older .package(name:url:...) form
+  constraint?: { kind: SwiftConstraintKind; value: string }
+}
+
+/**
+ * Get the dependencies a Package.swift declares, in each of the forms SwiftPM accepts:
+ * .package(url:from:), .package(url:.upToNextMajor(from:)), .upToNextMinor, exact:, branch:,
+ * revision:, version ranges ("1.0.0"..<"2.0.0"), .package(name:url:...), .package(id:...) for
+ * registry packages and .package(path:) for local ones
+ */
+export function parsePackageSwift(content: string): SwiftPackageDependency[] {
+  // Comments could hide or fake declarations
+  const source = content.replace(/\/\*[\s\S]*?\*\//g, "").replace(/(^|[^:"])\/\/.*$/gm, "$1")
+
+  const dependencies: SwiftPackageDependency[] = []
+  for (const match of source.matchAll(/\.package\s*\(/g)) {
+    const args = readBalancedArguments(source, match.index! + match[0].length)
+    const location = args.match(/\b(url|id|path)\s*:\s*"([^"]+)"/)
+    if (!location) continue
+
+    const name = args.match(/\bname\s*:\s*"([^"]+)"/)?.[1]
+    dependencies.push({
+      url: location[2],
+      name,
+      constraint: location[1] === "path" ? { kind: "path", value: location[2] } : parseSwiftConstraint(args),
+    })
+  }
+
+  return dependencies
+}
+
+/**
+ * Read the text of a call's arguments, from just after its opening parenthesis to the matching
+ * closing one, skipping over string literals
+ */
+function readBalancedArguments(source: string, start: number): string {
+  let depth = 1
+  let inString = false
+  for (let i = start; i < source.length; i++) {
+    const char = source[i]
+    if (inString) {
+      if (char === "\\") i++
+      else if (char === '"') inString = false
+    } else if (char === '"') {
+      inString = true
+    } else if (char === "(") {
+      depth++
+    } else if (char === ")" && --depth === 0) {
+      return source.slice(start, i)
+    }
+  }
+  return source.slice(start)
+}
+
+/**
+ * Get the version constraint from a .package call's arguments
+ */
+function parseSwiftConstraint(args: string): SwiftPackageDependency["constraint"] {
+  const patterns: Array<[SwiftConstraintKind, RegExp]> = [
+    ["upToNextMajor", /\.upToNextMajor\s*\(\s*from\s*:\s*"([^"]+)"/],
+    ["upToNextMinor", /\.upToNextMinor\s*\(\s*from\s*:\s*"([^"]+)"/],
+    ["from", /\bfrom\s*:\s*"([^"]+)"/],
+    ["exact", /(?:\bexact\s*:|\.exact\s*\()\s*"([^"]+)"/],
+    ["branch", /(?:\bbranch\s*:|\.branch\s*\()\s*"([^"]+)"/],
+    ["revision", /(?:\brevision\s*:|\.revision\s*\()\s*"([^"]+)"/],
+    ["range", /("[^"]+"\s*\.\.[.<]\s*"[^"]+")/],
+  ]
+
+  for (const [kind, pattern] of patterns) {
+    const value = args.match(pattern)?.[1]
+    if (value) return { kind, value: kind === "range" ? value.replace(/"/g, "").replace(/\s+/g, "") : value }
+  }
+  return undefined
+}
+
+/**
+ * Describe a Package.swift version constraint in words, e.g. "up to next major from 4.89.0"
+ */
+export function formatSwiftConstraint(constraint: SwiftPackageDependency["constraint"]): string {
+  switch (constraint?.kind) {
+    case "from":
+    case "upToNextMajor":
+      return `up to next major from ${constraint.value}`
+    case "upToNextMinor":
+      return `up to next minor from ${constraint.value}`
+    case "exact":
+      return `exactly ${constraint.value}`
+    case "range":
+      return `versions ${constraint.value}`
+    case "branch":
+      return `branch ${constraint.value}`
+    case "revision":
+      return `revision ${constraint.value}`
+    case "path":
+      return "local package"
+    default:
+      return "no version constraint found"
+  }
+}
+
+/**
+ * Check whether a Package.swift dependency is the given package, comparing repository URLs without
+ * their scheme, .git suffix, trailing slash or case, or failing that the package's name
+ */
+export function isSameSwiftPackage(dependency: SwiftPackageDependency, packageUrl: string): boolean {
+  const normalise = (url: string) => url.toLowerCase().replace(/^[a-z+]+:\/\/(?:[^@/]+@)?/, "").replace(/\.git$|\/+$/g, "")
+  const lastComponent = (url: string) => normalise(url).split(/[/:]/).pop()
+  return normalise(dependency.url) === normalise(packageUrl) ||
+    (dependency.name || lastComponent(dependency.url)) === lastComponent(packageUrl)
+}
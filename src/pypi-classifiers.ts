@@ -0,0 +1,110 @@
+/**
+ * Helpers for summarising the trove classifiers and release history PyPI records for a project,
+ * such as the Python versions it supports and how mature it is.
+ */
+
+export interface PyPIReleaseFile {
+  upload_time_iso_8601?: string
+  upload_time?: string
+  yanked?: boolean
+}
+
+// Classifier categories listed first, as they matter most when choosing a package
+const CLASSIFIER_CATEGORY_ORDER = ["Development Status", "License", "Operating System", "Framework", "Typing"]
+
+/**
+ * Extract the Python versions a project declares support for from classifiers such as
+ * "Programming Language :: Python :: 3.12", in version order. Major-only classifiers
+ * ("Python :: 3") are only used when no minor versions are listed.
+ */
+export function extractPythonVersions(classifiers: string[]): string[] {
+  const versions = classifiers
+    .map(classifier => classifier.match(/^Programming Language :: Python :: (\d+(?:\.\d+)?)$/)?.[1])
+    .filter((version): version is string => version !== undefined)
+
+  const minorVersions = versions.filter(version => version.includes("."))
+  return Array.from(new Set(minorVersions.length > 0 ? minorVersions : versions)).sort((a, b) => {
+    const [aMajor, aMinor = 0] = a.split(".").map(Number)
+    const [bMajor, bMinor = 0] = b.split(".").map(Number)
+    return aMajor - bMajor || aMinor - bMinor
+  })
+}
+
+/**
+ * Group classifiers by their top-level category, e.g. "License :: OSI Approved :: MIT License"
+ * under License as "OSI Approved :: MIT License". Python version classifiers are left out, as
+ * extractPythonVersions covers them.
+ */
+export function groupClassifiers(classifiers: string[]): Record<string, string[]> {
+  const groups: Record<string, string[]> = {}
+
+  for (const classifier of classifiers) {
+    if (classifier.startsWith("Programming Language :: Python")) continue
+    const [category, ...rest] = classifier.split(" :: ")
+    if (rest.length === 0) continue
+    groups[category] = [...(groups[category] || []), rest.join(" :: ")]
+  }
+
+  return groups
+}
+
+/**
+ * Get a project's most recent releases with their upload dates, newest first. Releases without
+ * files, or whose files were all yanked, are skipped.
+ */
+export function recentReleases(
+  releases: Record<string, PyPIReleaseFile[]>,
+  limit = 10
+): Array<{ version: string; date: string }> {
+  return Object.entries(releases)
+    .filter(([, files]) => files.length > 0 && files.some(file => !file.yanked))
+    .map(([version, files]) => ({
+      version,
+      date: (files[0].upload_time_iso_8601 || files[0].upload_time || "").slice(0, 10),
+    }))
+    .sort((a, b) => b.date.localeCompare(a.date))
+    .slice(0, limit)
+}
+
+/**
+ * Render the Python versions, classifiers and recent releases of a project's PyPI metadata as
+ * markdown sections, leaving out any the project doesn't record
+ */
+export function formatPyPICompatibility(
+  classifiers: string[],
+  requiresPython?: string,
+  releases?: Record<string, PyPIReleaseFile[]>
+): string {
+  const sections: string[] = []
+
+  const versions = extractPythonVersions(classifiers)
+  if (versions.length > 0 || requiresPython) {
+    const supported = versions.length > 0
+      ? `${versions.join(", ")}${requiresPython ? ` (requires Python ${requiresPython})` : ""}`
+      : `Requires Python ${requiresPython}`
+    sections.push(`## Supported Python Versions\n\n${supported}`)
+  }
+
+  const groups = groupClassifiers(classifiers)
+  const categories = Object.keys(groups).sort((a, b) => {
+    const rank = (category: string) => {
+      const index = CLASSIFIER_CATEGORY_ORDER.indexOf(category)
+      return index === -1 ? CLASSIFIER_CATEGORY_ORDER.length : index
+    }
+    return rank(a) - rank(b)
+  })
+  if (categories.length > 0) {
+    sections.push(`## Classifiers\n\n${categories.map(category => `- ${category}: ${groups[category].join("; ")}`).join("\n")}`)
+  }
+
+  const latest = releases ? recentReleases(releases) : []
+  if (latest.length > 0) {
+    const total = Object.keys(releases || {}).length
+    sections.push(
+      `## Recent Releases\n\n${latest.map(release => `- ${release.version}${release.date ? ` (${release.date})` : ""}`).join("\n")}` +
+      (total > latest.length ? `\n\n${total} releases in total` : "")
+    )
+  }
+
+  return sections.join("\n\n")
+}
@@ -8,6 +8,54 @@ export interface NpmConfig {
   token?: string;
 }
 
+/**
+ * Expand the environment variable references of an .npmrc value as npm does: ${VAR}, ${VAR?}
+ * (empty when unset) and ${VAR:-default} (default when unset or empty). Unset variables without a
+ * default expand to an empty string. Backslashes before a reference escape each other in pairs, so
+ * \${VAR} is kept as ${VAR} and \\${VAR} becomes a backslash and the value.
+ */
+export function expandEnvReferences(value: string, env: Record<string, string | undefined> = process.env): string {
+  return value.replace(/(\\*)\$\{([^}:?]+)(\?|:-([^}]*))?\}/g, (match, escapes: string, name: string, modifier?: string, fallback?: string) => {
+    // Each pair of backslashes is one literal backslash; an odd one left over escapes the reference
+    const literal = escapes.slice(Math.ceil(escapes.length / 2));
+    if (escapes.length % 2 === 1) {
+      return `${literal}${match.slice(escapes.length)}`;
+    }
+    const resolved = env[name];
+    if (modifier?.startsWith(':-') && !resolved) {
+      return `${literal}${fallback ?? ''}`;
+    }
+    return `${literal}${resolved ?? ''}`;
+  });
+}
+
+/**
+ * Normalise a registry URL to the key its tokens are stored under, as npm does: the host and path
+ * without the scheme, ending in a slash (https://npm.example.com/repo becomes //npm.example.com/repo/)
+ */
+export function registryKey(registry: string): string {
+  const location = registry.trim().replace(/^[a-z][a-z0-9+.-]*:\/\//i, '').replace(/^\/+/, '');
+  const [host, ...path] = location.split('/');
+  const cleanPath = path.join('/').replace(/\/+$/, '');
+  return `//${host.toLowerCase()}/${cleanPath ? `${cleanPath}/` : ''}`;
+}
+
+/**
+ * Find the token for a registry among tokens keyed by registryKey: the one for the longest key the
+ * registry's key starts with, so a token for //npm.example.com/ applies to //npm.example.com/repo/
+ * but never to //npm.example.com.evil.net/
+ */
+export function findRegistryToken(registry: string, registryToToken: Map<string, string>): string | undefined {
+  const key = registryKey(registry);
+  let best: [string, string] | undefined;
+  for (const [tokenKey, token] of registryToToken) {
+    if (key.startsWith(tokenKey) && (!best || tokenKey.length > best[0].length)) {
+      best = [tokenKey, token];
+    }
+  }
+  return best?.[1];
+}
+
 export class RegistryUtils {
   private logger: McpLogger;
   private registryMap: Map<string, NpmConfig>;
@@ -41,7 +89,10 @@ export class RegistryUtils {
     registryMap.set("default", { registry: "https://registry.npmjs.org" });
 
     const scopeToRegistry = new Map<string, string>();
+    // Tokens are collected from every file before being matched to registries, so the order of
+    // registry and token lines doesn't matter
     const registryToToken = new Map<string, string>();
+    const scopeToToken = new Map<string, string>();
 
     this.logger.debug("Loading npm configuration...")
     this.logger.debug("Project directory:", projectPath || "not specified");
@@ -53,7 +104,7 @@ export class RegistryUtils {
       this.logger.debug("Found global .npmrc");
       try {
         const npmrcContent = readFileSync(globalNpmrcPath, "utf-8");
-        this.parseNpmrcContent(npmrcContent, scopeToRegistry, registryToToken, registryMap);
+        this.parseNpmrcContent(npmrcContent, scopeToRegistry, registryToToken, scopeToToken, registryMap);
       } catch (error) {
         this.logger.error("Error reading global .npmrc:", error);
       }
@@ -80,7 +131,7 @@ export class RegistryUtils {
           this.logger.debug("Found .npmrc at:", localNpmrcPath);
           try {
             const npmrcContent = readFileSync(localNpmrcPath, "utf-8");
-            this.parseNpmrcContent(npmrcContent, scopeToRegistry, registryToToken, registryMap);
+            this.parseNpmrcContent(npmrcContent, scopeToRegistry, registryToToken, scopeToToken, registryMap);
           } catch (error) {
             this.logger.error(`Error reading local .npmrc at ${localNpmrcPath}:`, error);
           }
@@ -91,17 +142,15 @@ export class RegistryUtils {
     try {
       // Associate tokens with registries
       for (const [scope, registry] of scopeToRegistry.entries()) {
-        const hostname = new URL(registry).host;
-        const token = registryToToken.get(hostname);
+        const token = scopeToToken.get(scope) ?? findRegistryToken(registry, registryToToken);
         this.logger.debug(`Setting config for scope ${scope}:`, { registry, token: token ? "[REDACTED]" : undefined });
         registryMap.set(scope, { registry, token });
       }
 
-      // Ensure default registry has its token if available
+      // Ensure default registry has its token if available, preferring one set for its URL over a bare _authToken
       const defaultConfig = registryMap.get("default");
       if (defaultConfig) {
-        const hostname = new URL(defaultConfig.registry).host;
-        const token = registryToToken.get(hostname);
+        const token = findRegistryToken(defaultConfig.registry, registryToToken) ?? scopeToToken.get("default");
         if (token) {
           this.logger.debug("Setting token for default registry");
           registryMap.set("default", { ...defaultConfig, token });
@@ -128,6 +177,7 @@ export class RegistryUtils {
     content: string,
     scopeToRegistry: Map<string, string>,
     registryToToken: Map<string, string>,
+    scopeToToken: Map<string, string>,
     registryMap: Map<string, NpmConfig>
   ): void {
     const lines = content.split("\n");
@@ -142,7 +192,8 @@ export class RegistryUtils {
       // registry=https://registry.example.com
       const registryMatch = trimmedLine.match(/^(?:@([^:]+):)?registry=(.+)$/);
       if (registryMatch) {
-        const [, scope, registry] = registryMatch;
+        const [, scope, rawRegistry] = registryMatch;
+        const registry = expandEnvReferences(rawRegistry);
         const cleanRegistry = registry.replace(/\/$/, "");
         if (scope) {
           scopeToRegistry.set(`@${scope}`, cleanRegistry);
@@ -155,49 +206,27 @@ export class RegistryUtils {
       // Handle authentication tokens
       // Match patterns like:
       // //registry.example.com/:_authToken=token
+      // //registry.example.com/path/:_authToken=token
       // @scope:_authToken=token
       // _authToken=token
-      const tokenMatch = trimmedLine.match(/^(?:\/\/([^/]+)\/:|@([^:]+):)?_authToken=(.+)$/);
+      const tokenMatch = trimmedLine.match(/^(?:(\/\/.+?):|@([^:]+):)?_authToken=(.+)$/);
       if (tokenMatch) {
-        const [, registry, scope, token] = tokenMatch;
+        const [, registry, scope, rawToken] = tokenMatch;
+        // Tokens are usually kept out of the file as ${NPM_TOKEN}, expanded from the environment
+        const token = expandEnvReferences(rawToken);
+        if (!token) {
+          this.logger.debug(`Skipping an .npmrc token that expands to nothing: ${rawToken}`);
+          continue;
+        }
         if (registry) {
-          // Store token for specific registry
-          // Handle both protocol and non-protocol URLs
-          registryToToken.set(registry, token);
-          if (!registry.includes("://")) {
-            registryToToken.set(`https://${registry}`, token);
-            registryToToken.set(`http://${registry}`, token);
-          }
+          // Store token for the registry's host and path, matched to registries once every file is read
+          registryToToken.set(registryKey(registry), token);
         } else if (scope) {
-          // Store token for scope, we'll resolve the registry later
-          const scopeRegistry = scopeToRegistry.get(`@${scope}`);
-          if (scopeRegistry) {
-            try {
-              // Try parsing as URL first
-              const url = new URL(scopeRegistry);
-              registryToToken.set(url.host, token);
-            } catch {
-              // If not a URL, treat as hostname
-              registryToToken.set(scopeRegistry, token);
-              registryToToken.set(`https://${scopeRegistry}`, token);
-              registryToToken.set(`http://${scopeRegistry}`, token);
-            }
-          }
+          // Store token for scope, applied to whichever registry the scope ends up using
+          scopeToToken.set(`@${scope}`, token);
         } else {
           // Default token
-          const defaultRegistry = registryMap.get("default")?.registry;
-          if (defaultRegistry) {
-            try {
-              // Try parsing as URL first
-              const url = new URL(defaultRegistry);
-              registryToToken.set(url.host, token);
-            } catch {
-              // If not a URL, treat as hostname
-              registryToToken.set(defaultRegistry, token);
-              registryToToken.set(`https://${defaultRegistry}`, token);
-              registryToToken.set(`http://${defaultRegistry}`, token);
-            }
-          }
+          scopeToToken.set("default", token);
         }
       }
     }
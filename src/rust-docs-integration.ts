@@ -10,9 +10,24 @@ import {
 	SymbolDefinition,
 } from "./types.js";
 import rustHttpClient from "./utils/rust-http-client.js";
+import { extractHtmlTables, extractMainContent, resolveRelativeLinks } from "./utils/html-content.js";
+import { formatMarkdownTable } from "./search-utils.js";
 import { McpLogger } from './logger.js'
+import { cachedRepoResource, repoCacheKey } from "./utils/repo-cache.js";
 
 const turndownInstance = new turndown();
+// Drop scripts, styles and inline SVGs (e.g. docs.rs icons), which would otherwise leak into the markdown
+turndownInstance.remove((node) =>
+	["script", "style", "noscript", "svg", "template"].includes(node.nodeName.toLowerCase()),
+);
+// Turndown flattens tables into their cells' text, losing parameter tables, so render them as markdown tables
+turndownInstance.addRule("table", {
+	filter: "table",
+	replacement: (content, node) => {
+		const [table] = extractHtmlTables((node as unknown as { outerHTML: string }).outerHTML);
+		return table ? `\n\n${formatMarkdownTable(table)}\n\n` : content;
+	},
+});
 
 export class RustDocsHandler {
   private logger: McpLogger;
@@ -69,6 +84,31 @@ export class RustDocsHandler {
     }
   }
 
+  /**
+   * Resolve the version to use in docs.rs paths: the requested one, or the crate's latest version
+   * from crates.io, as some docs.rs paths (such as source files) 404 without a concrete version.
+   * Falls back to docs.rs's "latest" alias when crates.io can't be reached.
+   */
+  private async resolveVersion(crateName: string, version?: string): Promise<string> {
+    if (version) {
+      return version;
+    }
+
+    // Cached for the repository cache TTL, so a new release is picked up once it expires
+    try {
+      return await cachedRepoResource(repoCacheKey(`crates.io/${crateName}`, "", "latest-version"), async () => {
+        const latest = (await this.getCrateDetails(crateName)).latestVersion;
+        if (!latest) {
+          throw new Error("crates.io lists no versions");
+        }
+        return latest;
+      });
+    } catch (error) {
+      this.logger.debug(`could not resolve the latest version of ${crateName}`, { error });
+    }
+    return "latest";
+  }
+
   /**
    * Get detailed information about a crate from crates.io
    */
@@ -76,10 +116,13 @@ export class RustDocsHandler {
     name: string;
     description?: string;
     versions: CrateVersion[];
+    latestVersion?: string;
     downloads: number;
     homepage?: string;
     repository?: string;
     documentation?: string;
+    keywords?: string[];
+    license?: string;
   }> {
     try {
       this.logger.info(`getting crate details for: ${crateName}`);
@@ -98,11 +141,15 @@ export class RustDocsHandler {
           homepage?: string;
           repository?: string;
           documentation?: string;
+          keywords?: string[];
+          max_version: string;
+          max_stable_version?: string;
         };
         versions: Array<{
           num: string;
           yanked: boolean;
           created_at: string;
+          license?: string;
         }>;
       };
 
@@ -113,10 +160,15 @@ export class RustDocsHandler {
         homepage: data.crate.homepage,
         repository: data.crate.repository,
         documentation: data.crate.documentation,
+        keywords: data.crate.keywords,
+        license: data.versions[0]?.license,
+        // Prereleases are only the latest when there's no stable release
+        latestVersion: data.crate.max_stable_version || data.crate.max_version,
         versions: data.versions.map((v) => ({
           version: v.num,
           isYanked: v.yanked,
           releaseDate: v.created_at,
+          license: v.license,
         })),
       };
     } catch (error) {
@@ -128,20 +180,63 @@ export class RustDocsHandler {
   }
 
   /**
-   * Get documentation for a specific crate from docs.rs
+   * Get the dependencies of a crate version from crates.io
+   */
+  async getCrateDependencies(
+    crateName: string,
+    version: string,
+  ): Promise<Array<{ name: string; req: string; kind: string; optional: boolean }>> {
+    try {
+      this.logger.info(`getting dependencies for crate: ${crateName} version ${version}`);
+
+      const response = await rustHttpClient.cratesIoFetch(`crates/${crateName}/${version}/dependencies`);
+
+      if (response.contentType !== "json") {
+        throw new Error("Expected JSON response but got text");
+      }
+
+      const data = response.data as {
+        dependencies: Array<{
+          crate_id: string;
+          req: string;
+          kind: string;
+          optional: boolean;
+        }>;
+      };
+
+      return data.dependencies.map((dep) => ({
+        name: dep.crate_id,
+        req: dep.req,
+        kind: dep.kind,
+        optional: dep.optional,
+      }));
+    } catch (error) {
+      this.logger.error(`error getting dependencies for crate: ${crateName}`, { error });
+      throw new Error(
+        `failed to get dependencies for crate ${crateName}: ${(error as Error).message}`,
+      );
+    }
+  }
+
+  /**
+   * Get documentation for a specific crate from docs.rs, optionally as built for a platform target
+   * (e.g. x86_64-pc-windows-msvc) rather than the crate's default one
    */
   async getCrateDocumentation(
     crateName: string,
     version?: string,
+    target?: string,
   ): Promise<string> {
     try {
       this.logger.info(
-        `getting documentation for crate: ${crateName}${version ? ` version ${version}` : ""}`,
+        `getting documentation for crate: ${crateName}${version ? ` version ${version}` : ""}${target ? ` on ${target}` : ""}`,
       );
 
-      const path = version
-        ? `crate/${crateName}/${version}`
-        : `crate/${crateName}/latest`;
+      // target-redirect sends us to the crate root of the target's build, whichever version it is
+      const versionPath = await this.resolveVersion(crateName, version);
+      const path = target
+        ? `crate/${crateName}/${versionPath}/target-redirect/${target}/${crateName.replace(/-/g, "_")}/`
+        : `crate/${crateName}/${versionPath}`;
 
       const response = await rustHttpClient.docsRsFetch(path);
 
@@ -149,7 +244,10 @@ export class RustDocsHandler {
         throw new Error("Expected HTML response but got JSON");
       }
 
-      return turndownInstance.turndown(response.data);
+      // Resolve links against the URL the page was served from, as docs.rs links to other items
+      // relative to it and redirects crate/<name>/<version> to the crate root (<name>/<version>/<name>/)
+      const html = resolveRelativeLinks(response.data, response.url);
+      return turndownInstance.turndown(extractMainContent(html));
     } catch (error) {
       this.logger.error(`error getting documentation for crate: ${crateName}`, {
         error,
@@ -171,7 +269,7 @@ export class RustDocsHandler {
     try {
       this.logger.info(`Getting type info for ${path} in crate: ${crateName}`);
 
-      const versionPath = version || "latest";
+      const versionPath = await this.resolveVersion(crateName, version);
       const fullPath = `${crateName}/${versionPath}/${crateName}/${path}`;
 
       const response = await rustHttpClient.docsRsFetch(fullPath);
@@ -226,7 +324,7 @@ export class RustDocsHandler {
     try {
       this.logger.info(`Getting feature flags for crate: ${crateName}`);
 
-      const versionPath = version || "latest";
+      const versionPath = await this.resolveVersion(crateName, version);
       const response = await rustHttpClient.docsRsFetch(
         `/crate/${crateName}/${versionPath}/features`,
       );
@@ -259,6 +357,80 @@ export class RustDocsHandler {
     }
   }
 
+  /**
+   * Get the build targets docs.rs lists for a crate version
+   */
+  async getSupportedTargets(
+    crateName: string,
+    version?: string,
+  ): Promise<string[]> {
+    try {
+      this.logger.info(`Getting supported targets for crate: ${crateName}`);
+
+      const versionPath = await this.resolveVersion(crateName, version);
+      const response = await rustHttpClient.docsRsFetch(
+        `/crate/${crateName}/${versionPath}`,
+      );
+
+      if (response.contentType !== "text") {
+        throw new Error("Expected HTML response but got JSON");
+      }
+
+      // The platform menu links to /crate/<name>/<version>/target-redirect/<target>/...
+      const targets = new Set<string>();
+      for (const match of response.data.matchAll(/target-redirect\/([a-z0-9_]+(?:-[a-z0-9_.]+){1,3})\//g)) {
+        targets.add(match[1]);
+      }
+
+      return Array.from(targets);
+    } catch (error) {
+      this.logger.error(`Error getting supported targets for crate: ${crateName}`, {
+        error,
+      });
+      return [];
+    }
+  }
+
+  /**
+   * Get the public items a crate version exports, from the docs.rs "All items" page
+   */
+  async getCrateItems(
+    crateName: string,
+    version?: string,
+  ): Promise<Array<{ name: string; kind: string }>> {
+    try {
+      this.logger.info(`Getting items for crate: ${crateName}`);
+
+      const versionPath = await this.resolveVersion(crateName, version);
+      const libraryName = crateName.replace(/-/g, "_");
+      const response = await rustHttpClient.docsRsFetch(
+        `${crateName}/${versionPath}/${libraryName}/all.html`,
+      );
+
+      if (response.contentType !== "text") {
+        throw new Error("Expected HTML response but got JSON");
+      }
+
+      // Each kind of item is listed under a heading such as <h3 id="structs">
+      const $ = cheerio.load(response.data);
+      const items: Array<{ name: string; kind: string }> = [];
+
+      $("h3[id]").each((_: number, heading: any) => {
+        const kind = ($(heading).attr("id") || "other").replace(/s$/, "");
+        $(heading).next("ul").find("li a").each((_: number, link: any) => {
+          items.push({ name: $(link).text().trim(), kind });
+        });
+      });
+
+      return items;
+    } catch (error) {
+      this.logger.error(`Error getting items for crate: ${crateName}`, {
+        error,
+      });
+      throw new Error(`Failed to get crate items: ${(error as Error).message}`);
+    }
+  }
+
   /**
    * Get available versions for a crate from crates.io
    */
@@ -308,7 +480,7 @@ export class RustDocsHandler {
     try {
       this.logger.info(`Getting source code for ${path} in crate: ${crateName}`);
 
-      const versionPath = version || "latest";
+      const versionPath = await this.resolveVersion(crateName, version);
       const response = await rustHttpClient.docsRsFetch(
         `/crate/${crateName}/${versionPath}/src/${path}`,
       );
@@ -342,7 +514,7 @@ export class RustDocsHandler {
       );
 
       try {
-        const versionPath = version || "latest";
+        const versionPath = await this.resolveVersion(crateName, version);
         const response = await rustHttpClient.docsRsFetch(
           `/${crateName}/${versionPath}/${crateName}/`,
           {
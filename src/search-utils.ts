@@ -1,4 +1,8 @@
 import { McpLogger } from './logger.js'
+import { PackageSearchLanguage, PackageSearchResult } from './package-search.js'
+import { LANGUAGE_NAMES, SectionCategory, detectLanguage, getLocaleKeywords, parseLocales } from './locale-keywords.js'
+import { compareVersions } from './dependency-versions.js'
+import { cachedParse } from './utils/parse-cache.js'
 
 export interface DocResult {
   description?: string
@@ -6,11 +10,56 @@ export interface DocResult {
   example?: string
   error?: string
   searchResults?: SearchResults
+  packages?: PackageSearchResult[] // Registry matches from search_packages
+  metadata?: PackageMetadata // Structured package details when describe is called with format "json"
+  options?: MarkdownTable[] // Configuration option and parameter tables from the README
+  readme?: string // The package's README verbatim, when describe is called with includeRaw
+  dependencyTree?: DependencyTreeNode // Transitive dependencies from get_package_dependencies
+  signals?: BadgeSignal[] // What the README's badges say about CI, coverage, downloads and upkeep
   suggestInstall?: boolean // Flag to indicate if we should suggest package installation
 }
 
+// Registry metadata for a package, returned as-is rather than rendered to markdown
+export interface PackageMetadata {
+  name: string
+  version?: string
+  description?: string
+  license?: string
+  homepage?: string
+  repository?: string
+  documentation?: string // Hosted documentation site, where the registry records one
+  keywords?: string[]
+  dependencies?: Record<string, string>
+  deprecated?: string // Deprecation or yank notice for this version
+}
+
+// A package in a dependency tree, with the requirement its parent declares and the version it resolves to
+export interface DependencyTreeNode {
+  name: string
+  requirement?: string // As written in the parent's metadata, e.g. ^1.2.0 or >=2.5,<4
+  version?: string
+  dependencies?: DependencyTreeNode[]
+  note?: string // Why the node wasn't expanded: a cycle, a repeat, the depth or node limit, or an error
+}
+
+// Output format for describe tools: rendered markdown sections or structured metadata
+export type DocFormat = "markdown" | "json"
+
+export const isDocFormat = (value: unknown): value is DocFormat => {
+  return value === "markdown" || value === "json"
+}
+
+// Where a tool call may look for documentation: "auto" tries the local toolchain and
+// installed packages before the network, "local" and "network" only use one of them
+export type DocSource = "auto" | "local" | "network"
+
+export const isDocSource = (value: unknown): value is DocSource => {
+  return value === "auto" || value === "local" || value === "network"
+}
+
 export interface SearchResults {
-  results: SearchResult[]
+  results: SearchResult[] // Empty in the json format, which gives hits instead
+  hits?: SearchHit[]
   totalResults: number
   error?: string
   suggestInstall?: boolean
@@ -22,14 +71,55 @@ export interface SearchResult {
   context?: string // Make context optional to save space
   score: number
   type?: string // Type of the section (function, class, etc.)
+  matches?: MatchSpan[] // Where the query's terms appear in the context
+  highlight?: string // The first matching line, with the matched terms in bold
+}
+
+// A search result in the json format: the verbatim text around a match and where it sits, for
+// clients that render results themselves rather than showing the markdown snippets
+export interface SearchHit {
+  section: string // The section's heading, or the nearest heading above the match
+  type?: string
+  symbol?: string
+  score: number // Lower is better
+  context: string // The lines around the first match, as they appear in the documentation
+  offset: number // Where context starts in the section (in the document, for documentation without sections)
+  matches: MatchSpan[] // Where the query's terms appear in context
+}
+
+// Where a query term matched, as offsets in code points (not UTF-16 units) so they're correct for any text
+export interface MatchSpan {
+  start: number
+  end: number // Exclusive
 }
 
 export interface SearchDocArgs {
   package: string
   query: string
-  language: "go" | "python" | "npm" | "swift" | "rust"
+  language: "go" | "python" | "npm" | "swift" | "rust" | "php" | "java" | "dotnet"
   fuzzy?: boolean
   projectPath?: string
+  symbol?: string // Restrict the search to a type, module or other symbol within the package
+  source?: DocSource
+  contextSize?: number // Lines of context after each match, clamped to MIN_CONTEXT_SIZE..MAX_CONTEXT_SIZE
+  format?: DocFormat // Results with markdown snippets (default), or json hits with offsets
+  ranking?: SearchRankingOptions // Weights ranking the matching sections, over DEFAULT_SEARCH_RANKING
+}
+
+// Bounds of a search result's context, so it's neither a fragment nor most of the document
+export const MIN_CONTEXT_SIZE = 2
+export const MAX_CONTEXT_SIZE = 50
+export const DEFAULT_CONTEXT_SIZE = 10
+
+/**
+ * Clamp a requested context size to MIN_CONTEXT_SIZE..MAX_CONTEXT_SIZE lines, using the default
+ * when it's missing or not a number
+ */
+export function clampContextSize(size?: number): number {
+  if (size === undefined || !Number.isFinite(size)) {
+    return DEFAULT_CONTEXT_SIZE
+  }
+  return Math.min(MAX_CONTEXT_SIZE, Math.max(MIN_CONTEXT_SIZE, Math.round(size)))
 }
 
 export const isSearchDocArgs = (args: unknown): args is SearchDocArgs => {
@@ -38,194 +128,2009 @@ export const isSearchDocArgs = (args: unknown): args is SearchDocArgs => {
     args !== null &&
     typeof (args as SearchDocArgs).package === "string" &&
     typeof (args as SearchDocArgs).query === "string" &&
-    ["go", "python", "npm", "swift", "rust"].includes((args as SearchDocArgs).language) &&
+    ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"].includes((args as SearchDocArgs).language) &&
     (typeof (args as SearchDocArgs).fuzzy === "boolean" ||
       (args as SearchDocArgs).fuzzy === undefined) &&
     (typeof (args as SearchDocArgs).projectPath === "string" ||
-      (args as SearchDocArgs).projectPath === undefined)
+      (args as SearchDocArgs).projectPath === undefined) &&
+    (typeof (args as SearchDocArgs).symbol === "string" ||
+      (args as SearchDocArgs).symbol === undefined) &&
+    (isDocSource((args as SearchDocArgs).source) ||
+      (args as SearchDocArgs).source === undefined) &&
+    (typeof (args as SearchDocArgs).contextSize === "number" ||
+      (args as SearchDocArgs).contextSize === undefined) &&
+    (isDocFormat((args as SearchDocArgs).format) ||
+      (args as SearchDocArgs).format === undefined) &&
+    (isSearchRankingOptions((args as SearchDocArgs).ranking) ||
+      (args as SearchDocArgs).ranking === undefined)
+  )
+}
+
+export interface SearchPackagesArgs {
+  query: string
+  language: PackageSearchLanguage
+  limit?: number
+  page?: number // 1-based page of limit results
+  projectPath?: string
+}
+
+export const isSearchPackagesArgs = (args: unknown): args is SearchPackagesArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as SearchPackagesArgs).query === "string" &&
+    ["go", "python", "npm", "rust", "php", "java", "dotnet"].includes((args as SearchPackagesArgs).language) &&
+    (typeof (args as SearchPackagesArgs).limit === "number" ||
+      (args as SearchPackagesArgs).limit === undefined) &&
+    (typeof (args as SearchPackagesArgs).page === "number" ||
+      (args as SearchPackagesArgs).page === undefined) &&
+    (typeof (args as SearchPackagesArgs).projectPath === "string" ||
+      (args as SearchPackagesArgs).projectPath === undefined)
   )
 }
 
 export interface GoDocArgs {
   package: string
+  version?: string // Module version such as v0.17.0; package@version also works
+  includePrerelease?: boolean // Without a version, read the newest version even if it's a prerelease
   symbol?: string
   projectPath?: string
+  format?: DocFormat
+  source?: DocSource
+  raw?: boolean // Return go doc's output as is, without splitting it into sections
+  includeRaw?: boolean // Return the README verbatim as well as the summary
+}
+
+export interface PythonDocArgs {
+  package: string
+  version?: string // Read from PyPI, as the installed copy may be any version
+  includePrerelease?: boolean // Without a version, read the newest version even if it's a prerelease
+  symbol?: string
+  projectPath?: string
+  format?: DocFormat
+  source?: DocSource
+  raw?: boolean // Return pydoc's output as is, without splitting it into sections
+  includeApiReference?: boolean // Add the API reference from the package's Sphinx documentation
+  includeRaw?: boolean // Return the README verbatim as well as the summary
+}
+
+export interface NpmDocArgs {
+  package: string
+  version?: string
+  projectPath?: string
+  section?: string
+  maxLength?: number
+  query?: string
+}
+
+export interface SwiftDocArgs {
+  package: string
+  symbol?: string
+  projectPath?: string
+  format?: DocFormat
+  source?: DocSource
+  includeRaw?: boolean // Return the README verbatim as well as the summary
+}
+
+export const isGoDocArgs = (args: unknown): args is GoDocArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as GoDocArgs).package === "string" &&
+    (typeof (args as GoDocArgs).version === "string" ||
+      (args as GoDocArgs).version === undefined) &&
+    (typeof (args as GoDocArgs).symbol === "string" ||
+      (args as GoDocArgs).symbol === undefined) &&
+    (typeof (args as GoDocArgs).projectPath === "string" ||
+      (args as GoDocArgs).projectPath === undefined) &&
+    (isDocFormat((args as GoDocArgs).format) ||
+      (args as GoDocArgs).format === undefined) &&
+    (isDocSource((args as GoDocArgs).source) ||
+      (args as GoDocArgs).source === undefined) &&
+    (typeof (args as GoDocArgs).raw === "boolean" ||
+      (args as GoDocArgs).raw === undefined)
+  )
+}
+
+export const isSwiftDocArgs = (args: unknown): args is SwiftDocArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as SwiftDocArgs).package === "string" &&
+    (typeof (args as SwiftDocArgs).symbol === "string" ||
+      (args as SwiftDocArgs).symbol === undefined) &&
+    (typeof (args as SwiftDocArgs).projectPath === "string" ||
+      (args as SwiftDocArgs).projectPath === undefined) &&
+    (isDocFormat((args as SwiftDocArgs).format) ||
+      (args as SwiftDocArgs).format === undefined) &&
+    (isDocSource((args as SwiftDocArgs).source) ||
+      (args as SwiftDocArgs).source === undefined)
+  )
+}
+
+export const isPythonDocArgs = (args: unknown): args is PythonDocArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as PythonDocArgs).package === "string" &&
+    (typeof (args as PythonDocArgs).version === "string" ||
+      (args as PythonDocArgs).version === undefined) &&
+    (typeof (args as PythonDocArgs).symbol === "string" ||
+      (args as PythonDocArgs).symbol === undefined) &&
+    (typeof (args as PythonDocArgs).projectPath === "string" ||
+      (args as PythonDocArgs).projectPath === undefined) &&
+    (isDocFormat((args as PythonDocArgs).format) ||
+      (args as PythonDocArgs).format === undefined) &&
+    (isDocSource((args as PythonDocArgs).source) ||
+      (args as PythonDocArgs).source === undefined) &&
+    (typeof (args as PythonDocArgs).raw === "boolean" ||
+      (args as PythonDocArgs).raw === undefined) &&
+    (typeof (args as PythonDocArgs).includeApiReference === "boolean" ||
+      (args as PythonDocArgs).includeApiReference === undefined)
+  )
+}
+
+export const isNpmDocArgs = (args: unknown): args is NpmDocArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as NpmDocArgs).package === "string" &&
+    (typeof (args as NpmDocArgs).version === "string" ||
+      (args as NpmDocArgs).version === undefined) &&
+    (typeof (args as NpmDocArgs).projectPath === "string" ||
+      (args as NpmDocArgs).projectPath === undefined) &&
+    (typeof (args as NpmDocArgs).section === "string" ||
+      (args as NpmDocArgs).section === undefined) &&
+    (typeof (args as NpmDocArgs).maxLength === "number" ||
+      (args as NpmDocArgs).maxLength === undefined) &&
+    (typeof (args as NpmDocArgs).query === "string" ||
+      (args as NpmDocArgs).query === undefined)
+  )
+}
+
+const isStringArray = (value: unknown): value is string[] => {
+  return Array.isArray(value) && value.every(item => typeof item === "string")
+}
+
+export interface PackageDocArgs {
+  package: string
+  language: "go" | "python" | "npm" | "swift" | "rust"
+  version?: string
+  includePrerelease?: boolean // Without a version, read the newest version even if it's a prerelease
+  projectPath?: string
+  parent?: string // Document the version of the package this parent package (name or name@version) uses
+  section?: string
+  maxLength?: number
+  query?: string
+  include?: string[]
+  exclude?: string[]
+  minLevel?: number
+  stripHeadingEmoji?: boolean // Remove emoji from section headings, e.g. "🚀 Getting Started" becomes "Getting Started"
+}
+
+export const isPackageDocArgs = (args: unknown): args is PackageDocArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as PackageDocArgs).package === "string" &&
+    ["go", "python", "npm", "swift", "rust"].includes((args as PackageDocArgs).language) &&
+    (typeof (args as PackageDocArgs).version === "string" ||
+      (args as PackageDocArgs).version === undefined) &&
+    (typeof (args as PackageDocArgs).projectPath === "string" ||
+      (args as PackageDocArgs).projectPath === undefined) &&
+    (typeof (args as PackageDocArgs).parent === "string" ||
+      (args as PackageDocArgs).parent === undefined) &&
+    (typeof (args as PackageDocArgs).section === "string" ||
+      (args as PackageDocArgs).section === undefined) &&
+    (typeof (args as PackageDocArgs).maxLength === "number" ||
+      (args as PackageDocArgs).maxLength === undefined) &&
+    (typeof (args as PackageDocArgs).query === "string" ||
+      (args as PackageDocArgs).query === undefined) &&
+    (isStringArray((args as PackageDocArgs).include) ||
+      (args as PackageDocArgs).include === undefined) &&
+    (isStringArray((args as PackageDocArgs).exclude) ||
+      (args as PackageDocArgs).exclude === undefined) &&
+    (typeof (args as PackageDocArgs).minLevel === "number" ||
+      (args as PackageDocArgs).minLevel === undefined) &&
+    (typeof (args as PackageDocArgs).stripHeadingEmoji === "boolean" ||
+      (args as PackageDocArgs).stripHeadingEmoji === undefined)
+  )
+}
+
+export interface CompareVersionsArgs {
+  package: string
+  language: "python" | "npm" | "rust" | "php" | "java" | "dotnet"
+  fromVersion: string
+  toVersion: string
+  projectPath?: string
+}
+
+export const isCompareVersionsArgs = (args: unknown): args is CompareVersionsArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as CompareVersionsArgs).package === "string" &&
+    ["python", "npm", "rust", "php", "java", "dotnet"].includes((args as CompareVersionsArgs).language) &&
+    typeof (args as CompareVersionsArgs).fromVersion === "string" &&
+    typeof (args as CompareVersionsArgs).toVersion === "string" &&
+    (typeof (args as CompareVersionsArgs).projectPath === "string" ||
+      (args as CompareVersionsArgs).projectPath === undefined)
+  )
+}
+
+export interface ApiDiffArgs {
+  package: string
+  language: "go" | "npm" | "rust"
+  fromVersion: string
+  toVersion: string
+}
+
+export const isApiDiffArgs = (args: unknown): args is ApiDiffArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as ApiDiffArgs).package === "string" &&
+    ["go", "npm", "rust"].includes((args as ApiDiffArgs).language) &&
+    typeof (args as ApiDiffArgs).fromVersion === "string" &&
+    typeof (args as ApiDiffArgs).toVersion === "string"
+  )
+}
+
+export interface SymbolListArgs {
+  package: string
+  language: "go" | "python" | "npm" | "rust"
+  version?: string // npm and Rust only; Go and Python list the locally installed version
+  projectPath?: string // Where Go and Python look for the installed package
+}
+
+export const isSymbolListArgs = (args: unknown): args is SymbolListArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as SymbolListArgs).package === "string" &&
+    ["go", "python", "npm", "rust"].includes((args as SymbolListArgs).language) &&
+    (typeof (args as SymbolListArgs).version === "string" ||
+      (args as SymbolListArgs).version === undefined) &&
+    (typeof (args as SymbolListArgs).projectPath === "string" ||
+      (args as SymbolListArgs).projectPath === undefined)
+  )
+}
+
+export interface ProjectDependenciesArgs {
+  projectPath: string
+  includeDev?: boolean
+}
+
+export const isProjectDependenciesArgs = (args: unknown): args is ProjectDependenciesArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as ProjectDependenciesArgs).projectPath === "string" &&
+    (typeof (args as ProjectDependenciesArgs).includeDev === "boolean" ||
+      (args as ProjectDependenciesArgs).includeDev === undefined)
+  )
+}
+
+export interface ChangelogArgs {
+  package: string
+  language: "go" | "python" | "npm" | "swift" | "rust" | "php" | "java" | "dotnet"
+  version?: string
+  projectPath?: string
+  maxLength?: number
+}
+
+export const isChangelogArgs = (args: unknown): args is ChangelogArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as ChangelogArgs).package === "string" &&
+    ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"].includes((args as ChangelogArgs).language) &&
+    (typeof (args as ChangelogArgs).version === "string" ||
+      (args as ChangelogArgs).version === undefined) &&
+    (typeof (args as ChangelogArgs).projectPath === "string" ||
+      (args as ChangelogArgs).projectPath === undefined) &&
+    (typeof (args as ChangelogArgs).maxLength === "number" ||
+      (args as ChangelogArgs).maxLength === undefined)
+  )
+}
+
+export interface MigrationGuideArgs {
+  package: string
+  language: "go" | "python" | "npm" | "swift" | "rust" | "php" | "java" | "dotnet"
+  fromVersion?: string
+  toVersion?: string
+  projectPath?: string
+  maxLength?: number
+}
+
+export const isMigrationGuideArgs = (args: unknown): args is MigrationGuideArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as MigrationGuideArgs).package === "string" &&
+    ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"].includes((args as MigrationGuideArgs).language) &&
+    (typeof (args as MigrationGuideArgs).fromVersion === "string" ||
+      (args as MigrationGuideArgs).fromVersion === undefined) &&
+    (typeof (args as MigrationGuideArgs).toVersion === "string" ||
+      (args as MigrationGuideArgs).toVersion === undefined) &&
+    (typeof (args as MigrationGuideArgs).projectPath === "string" ||
+      (args as MigrationGuideArgs).projectPath === undefined) &&
+    (typeof (args as MigrationGuideArgs).maxLength === "number" ||
+      (args as MigrationGuideArgs).maxLength === undefined)
+  )
+}
+
+export interface PackageDependenciesArgs {
+  package: string
+  language: "npm" | "python" | "rust"
+  version?: string
+  depth?: number
+  maxNodes?: number
+  projectPath?: string
+}
+
+export const isPackageDependenciesArgs = (args: unknown): args is PackageDependenciesArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as PackageDependenciesArgs).package === "string" &&
+    ["npm", "python", "rust"].includes((args as PackageDependenciesArgs).language) &&
+    (typeof (args as PackageDependenciesArgs).version === "string" ||
+      (args as PackageDependenciesArgs).version === undefined) &&
+    (typeof (args as PackageDependenciesArgs).depth === "number" ||
+      (args as PackageDependenciesArgs).depth === undefined) &&
+    (typeof (args as PackageDependenciesArgs).maxNodes === "number" ||
+      (args as PackageDependenciesArgs).maxNodes === undefined) &&
+    (typeof (args as PackageDependenciesArgs).projectPath === "string" ||
+      (args as PackageDependenciesArgs).projectPath === undefined)
+  )
+}
+
+export interface SchemaArgs {
+  package: string
+  language: "go" | "python" | "npm" | "swift" | "rust" | "php" | "java" | "dotnet"
+  path?: string // A schema file in the repository, instead of looking for one
+  projectPath?: string
+  maxLength?: number
+}
+
+export const isSchemaArgs = (args: unknown): args is SchemaArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as SchemaArgs).package === "string" &&
+    ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"].includes((args as SchemaArgs).language) &&
+    (typeof (args as SchemaArgs).path === "string" ||
+      (args as SchemaArgs).path === undefined) &&
+    (typeof (args as SchemaArgs).projectPath === "string" ||
+      (args as SchemaArgs).projectPath === undefined) &&
+    (typeof (args as SchemaArgs).maxLength === "number" ||
+      (args as SchemaArgs).maxLength === undefined)
+  )
+}
+
+export interface ConfigDocArgs {
+  package: string
+  language: "go" | "python" | "npm" | "swift" | "rust" | "php" | "java" | "dotnet"
+  projectPath?: string
+}
+
+export const isConfigDocArgs = (args: unknown): args is ConfigDocArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as ConfigDocArgs).package === "string" &&
+    ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"].includes((args as ConfigDocArgs).language) &&
+    (typeof (args as ConfigDocArgs).projectPath === "string" ||
+      (args as ConfigDocArgs).projectPath === undefined)
+  )
+}
+
+export interface ExamplesArgs {
+  package: string
+  language: "go" | "python" | "npm" | "swift" | "rust" | "php" | "java" | "dotnet"
+  projectPath?: string
+  maxExamples?: number
+}
+
+export const isExamplesArgs = (args: unknown): args is ExamplesArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as ExamplesArgs).package === "string" &&
+    ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"].includes((args as ExamplesArgs).language) &&
+    (typeof (args as ExamplesArgs).projectPath === "string" ||
+      (args as ExamplesArgs).projectPath === undefined) &&
+    (typeof (args as ExamplesArgs).maxExamples === "number" ||
+      (args as ExamplesArgs).maxExamples === undefined)
+  )
+}
+
+export interface CompatibilityArgs {
+  package: string
+  language: "go" | "python" | "npm" | "swift" | "rust" | "php" | "java" | "dotnet"
+  projectPath?: string
+}
+
+export const isCompatibilityArgs = (args: unknown): args is CompatibilityArgs => {
+  return (
+    typeof args === "object" &&
+    args !== null &&
+    typeof (args as CompatibilityArgs).package === "string" &&
+    ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"].includes((args as CompatibilityArgs).language) &&
+    (typeof (args as CompatibilityArgs).projectPath === "string" ||
+      (args as CompatibilityArgs).projectPath === undefined)
+  )
+}
+
+// Weights of the signals ranking a documentation section against a search query
+export interface SearchRankingOptions {
+  title?: number // Boost when the query appears in the section's heading
+  position?: number // Boost for matches near the start of the section
+  frequency?: number // Weight of the query's frequency, relative to the section's length
+  proximity?: number // Boost for the terms of a multi-term query appearing close together
+}
+
+export const DEFAULT_SEARCH_RANKING: Required<SearchRankingOptions> = {
+  title: 2,
+  position: 1,
+  frequency: 1,
+  proximity: 1,
+}
+
+export const isSearchRankingOptions = (options: unknown): options is SearchRankingOptions => {
+  return (
+    typeof options === "object" &&
+    options !== null &&
+    Object.entries(options).every(([signal, weight]) =>
+      signal in DEFAULT_SEARCH_RANKING && typeof weight === "number" && Number.isFinite(weight) && weight >= 0
+    )
+  )
+}
+
+/**
+ * Parse a search query into alternatives, each a list of lowercased terms that must all appear.
+ * Space separated terms are ANDed, OR (in capitals) separates alternatives, and "quoted phrases"
+ * are matched as a single term, so `"connection pool" timeout OR retry` is parsed as
+ * [["connection pool", "timeout"], ["retry"]].
+ */
+export function parseSearchQuery(query: string): string[][] {
+  const alternatives: string[][] = [[]]
+  for (const [, phrase, word] of query.matchAll(/"([^"]*)"?|(\S+)/g)) {
+    if (word === "OR") {
+      alternatives.push([])
+    } else {
+      const term = (phrase ?? word).toLowerCase().replace(/\s+/g, " ").trim()
+      if (term) alternatives[alternatives.length - 1].push(term)
+    }
+  }
+  return alternatives.filter(terms => terms.length > 0)
+}
+
+// A GFM table from markdown, with inline formatting left in the cells
+export interface MarkdownTable {
+  headers: string[]
+  rows: string[][]
+}
+
+// First column headings of tables documenting options or parameters
+const OPTION_TABLE_HEADER_PATTERN = /^(options?|names?|parameters?|params?|property|properties|props?|keys?|settings?|flags?|arguments?|args?|fields?|attributes?)$/i
+
+// Headings of sections documenting which versions of runtimes, frameworks or platforms a package supports
+const COMPATIBILITY_HEADING_PATTERN = /compatib|supported (versions?|platforms?|runtimes?|environments?|frameworks?|browsers?)|(version|platform|browser|runtime) (support|matrix)|requirements|prerequisites/i
+
+// Table headers that make a table a compatibility matrix wherever it appears
+const COMPATIBILITY_TABLE_HEADER_PATTERN = /compatib|supported|support/i
+
+// Sentences stating compatibility, e.g. "Works with React 16, 17 and 18" or "Requires Node.js >= 18"
+const COMPATIBILITY_STATEMENT_PATTERN = /\b(works with|compatible with|supports?|requires?|tested (on|with|against))\b.*\d/i
+
+// A section documenting a package's compatibility, with any tables in it parsed
+export interface CompatibilitySection {
+  title: string
+  content: string
+  level?: number // Heading depth in the README
+  tables: MarkdownTable[]
+}
+
+// A fenced code block, with the language from its info string (e.g. "go" for ```go)
+export interface CodeBlock {
+  language?: string
+  code: string
+}
+
+// Fence languages used for each ecosystem's code, for picking out examples in the package's own language
+const ECOSYSTEM_CODE_LANGUAGES: Record<string, string[]> = {
+  go: ["go", "golang"],
+  python: ["python", "py", "python3", "pycon"],
+  npm: ["js", "javascript", "jsx", "mjs", "cjs", "ts", "typescript", "tsx"],
+  swift: ["swift"],
+  rust: ["rust", "rs"],
+  php: ["php"],
+  java: ["java", "kotlin", "kt", "groovy", "scala"],
+  dotnet: ["csharp", "cs", "c#", "fsharp", "fs", "vb"],
 }
 
-export interface PythonDocArgs {
-  package: string
-  symbol?: string
-  projectPath?: string
-}
+// A Go method declaration, with its receiver's type name captured (e.g. Client for "func (c *Client) Do(")
+const GO_METHOD_PATTERN = /^func\s+\((?:\w+\s+)?\*?(\w+)(?:\[[^\]]*\])?\)\s*(\w+)/
+
+// A code example from documentation, labelled with what it demonstrates
+export interface LabelledExample {
+  label: string
+  language?: string
+  code: string
+  heading?: string // The heading of the section the example is in
+}
+
+// Headings of sections written to show how a package is used, whose examples are preferred
+const EXAMPLE_HEADING_PATTERN = /example|usage|getting started|quick ?start|tutorial|how to/i
+
+// Lines that open a function declaration, with the function's name captured as "name". Methods
+// have no leading keyword, so they're only accepted when followed by a body.
+const SIGNATURE_START_PATTERNS: Array<{ pattern: RegExp; method?: boolean; typedParameters?: boolean }> = [
+  // TypeScript/JavaScript functions, including `export default function` and generators
+  { pattern: /^\s*(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:async\s+)?function\s*\*?\s*(?<name>\w+)?\s*(?:<[^>]*>)?\s*\(/ },
+  // Arrow functions assigned to (exported) consts, whose parameters may start on the next line
+  { pattern: /^\s*(?:export\s+)?(?:const|let|var)\s+(?<name>\w+)\s*(?::[^=]+)?=\s*(?:async\s*)?(?:<[^>]*>\s*)?(?:\(|$)/ },
+  // Kotlin functions, including extension functions
+  { pattern: /^\s*(?:(?:public|private|internal|protected|override|suspend|inline|open|abstract|operator|infix|tailrec)\s+)*fun\s+(?:<[^>]*>\s*)?(?:[\w.<>, ?*]+\.)?(?<name>\w+)\s*\(/ },
+  // Go, Rust, Swift and Python
+  { pattern: /^\s*func\s+(?:\([^)]*\)\s*)?(?<name>\w+)\s*(?:\[[^\]]*\])?\s*\(/ },
+  { pattern: /^\s*(?:pub(?:\([^)]*\))?\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?(?:extern\s+"[^"]*"\s+)?fn\s+(?<name>\w+)\s*(?:<[^>]*>)?\s*\(/ },
+  { pattern: /^\s*(?:(?:public|private|fileprivate|internal|open|static|class|override|mutating)\s+)*func\s+(?<name>\w+)\s*(?:<[^>]*>)?\s*\(/ },
+  { pattern: /^\s*(?:async\s+)?def\s+(?<name>\w+)\s*\(/ },
+  // C/C++ free functions and methods: a return type (possibly templated, namespaced, pointer or
+  // reference) before the name, e.g. `std::vector<int> foo(const Bar& b)` or `int add(int a, int b);`
+  {
+    pattern: /^\s*(?:template\s*<[^>]*>\s*)?(?:(?:static|inline|extern|virtual|constexpr|explicit|friend)\s+)*(?:const\s+)?(?!(?:return|else|new|delete|throw|case|await|yield|typeof|export|import|let|var)\b)[A-Za-z_][\w:]*(?:<[^;()]*>)?(?:\s*[*&]+\s*|\s+)(?:[\w:]+::)?(?<name>~?[A-Za-z_]\w*)\s*\(/,
+    typedParameters: true,
+  },
+  // Class methods: `name(args): ReturnType {`, optionally with modifiers
+  {
+    pattern: /^\s*(?:(?:public|private|protected|static|async|readonly|override|abstract|get|set)\s+)*(?<name>[A-Za-z_$][\w$]*)\s*(?:<[^>]*>)?\s*\(/,
+    method: true,
+  },
+]
+
+// Keywords that look like method names to the class method pattern
+const SIGNATURE_NON_NAMES = new Set(['if', 'for', 'while', 'switch', 'catch', 'return', 'function', 'new', 'typeof', 'await', 'super', 'constructor'])
+
+// Wrapped signatures longer than this are assumed not to be signatures
+const SIGNATURE_MAX_LINES = 15
+
+// Headings that introduce documentation for a tool's configuration
+const CONFIG_HEADING_PATTERN = /\b(config|configuration|configuring|options|settings)\b/i
+
+// Headings that introduce a migration or upgrade guide, e.g. "Migrating from v3", "Upgrade Guide" or "Breaking Changes"
+const MIGRATION_HEADING_PATTERN = /\b(migrat(e|es|ing|ion)|upgrad(e|es|ing)|breaking[\s-]+changes?)\b/i
+
+// Changelog sub-headings and list items that mark a breaking change
+const BREAKING_HEADING_PATTERN = /\bbreaking\b|\bremoved\b|\bincompatib/i
+const BREAKING_LINE_PATTERN = /\bBREAKING\b|\[breaking\]|⚠️|💥/
+
+// Common configuration file names such as .eslintrc.json, prettier.config.js or pyproject.toml
+const CONFIG_FILE_PATTERN = /(?:^|[\s`'"(])((?:\.[\w-]+rc(?:\.(?:json|ya?ml|js|cjs|mjs|toml))?)|(?:[\w.-]+\.config\.(?:js|cjs|mjs|ts|json))|(?:[\w.-]+\.(?:toml|ya?ml|ini|cfg)))(?=$|[\s`'"),:])/gm
+
+// Which README sections describe keeps: "consumer" focuses on usage and API, "contributor"
+// keeps development sections such as contributing and architecture, "full" keeps everything
+export type RelevanceProfile = "consumer" | "contributor" | "full"
+
+export const isRelevanceProfile = (value: unknown): value is RelevanceProfile => {
+  return value === "consumer" || value === "contributor" || value === "full"
+}
+
+// Section heading keywords the contributor profile keeps rather than skips
+const CONTRIBUTOR_KEYWORDS = [
+  'contributing', 'contributor', 'architecture', 'development', 'developing', 'building',
+  'build from source', 'design', 'internals', 'test', 'roadmap', 'changelog', 'release'
+]
+
+// An image referenced from markdown or inline HTML
+export interface ImageRef {
+  alt: string
+  url: string
+  isBadge: boolean
+}
+
+// Hosts and paths used for README status badges rather than content images
+const BADGE_URL_PATTERN = /shields\.io|badgen\.net|badge\.fury\.io|travis-ci\.(?:org|com)|circleci\.com|ci\.appveyor\.com|codecov\.io|coveralls\.io|snyk\.io\/test|david-dm\.org|nodei\.co|packagephobia|bundlephobia\.com\/api|deepscan\.io|codeclimate\.com|sonarcloud\.io\/api|\/actions\/workflows\/|\/workflows\/[^/]+\/badge\.svg|badge\.svg|\/badges?\//i
+
+// What a README badge says about a package's upkeep, e.g. which CI service builds it
+export interface BadgeSignal {
+  kind: 'ci' | 'coverage' | 'downloads' | 'version' | 'license' | 'maintenance' | 'security'
+  source: string // The service behind the badge, e.g. GitHub Actions or Codecov
+  detail?: string // What the badge URL itself says, e.g. the workflow file or a static badge's message
+  url: string // Where the badge links, or the badge image when it isn't a link
+}
+
+// Badge services by the URLs of their images (or, failing that, the pages they link to). Earlier
+// rules win, so shields.io paths for a service come before the generic static badge rule.
+const BADGE_SIGNAL_RULES: Array<{ kind: BadgeSignal['kind']; source: string; pattern: RegExp; detail?: (match: RegExpMatchArray) => string }> = [
+  { kind: 'ci', source: 'GitHub Actions', pattern: /github\.com\/[^/]+\/[^/]+\/actions\/workflows\/([^/?#\s]+)\/badge\.svg/i, detail: match => match[1] },
+  { kind: 'ci', source: 'GitHub Actions', pattern: /shields\.io\/github\/(?:actions\/)?workflow\/status\/[^/]+\/[^/]+\/([^/?#\s]+)/i, detail: match => decodeBadgeText(match[1]) },
+  { kind: 'ci', source: 'GitHub Actions', pattern: /github\.com\/[^/]+\/[^/]+\/workflows\/([^/]+)\/badge\.svg/i, detail: match => decodeBadgeText(match[1]) },
+  { kind: 'ci', source: 'Travis CI', pattern: /travis-ci\.(?:org|com)|shields\.io\/travis\//i },
+  { kind: 'ci', source: 'CircleCI', pattern: /circleci\.com|shields\.io\/circleci\//i },
+  { kind: 'ci', source: 'AppVeyor', pattern: /ci\.appveyor\.com|shields\.io\/appveyor\/(?:build|ci|tests)\//i },
+  { kind: 'ci', source: 'Azure Pipelines', pattern: /dev\.azure\.com\/.*_apis\/build\/status|visualstudio\.com\/.*_apis\/build\/status/i },
+  { kind: 'ci', source: 'GitLab CI', pattern: /\/badges\/[^/]+\/pipeline\.svg|shields\.io\/gitlab\/pipeline/i },
+  { kind: 'ci', source: 'Buildkite', pattern: /badge\.buildkite\.com/i },
+  { kind: 'coverage', source: 'Codecov', pattern: /codecov\.io|shields\.io\/codecov\//i },
+  { kind: 'coverage', source: 'Coveralls', pattern: /coveralls\.io|shields\.io\/coveralls(?:github)?\//i },
+  { kind: 'coverage', source: 'Code Climate', pattern: /codeclimate\.com\/.*coverage|shields\.io\/codeclimate\/coverage/i },
+  { kind: 'coverage', source: 'SonarCloud', pattern: /sonarcloud\.io\/api\/project_badges\/measure\?[^\s]*metric=coverage/i },
+  { kind: 'coverage', source: 'GitLab', pattern: /\/badges\/[^/]+\/coverage\.svg/i },
+  { kind: 'downloads', source: 'npm', pattern: /shields\.io\/npm\/(dm|dw|dy|dt|d18m)\//i, detail: match => DOWNLOAD_PERIODS[match[1].toLowerCase()] },
+  { kind: 'downloads', source: 'npm', pattern: /nodei\.co\/npm|npm-stat\.com|npmcharts\.com/i },
+  { kind: 'downloads', source: 'PyPI', pattern: /shields\.io\/pypi\/(dm|dw|dd)\//i, detail: match => DOWNLOAD_PERIODS[match[1].toLowerCase()] },
+  { kind: 'downloads', source: 'PyPI (pepy)', pattern: /pepy\.tech/i },
+  { kind: 'downloads', source: 'crates.io', pattern: /shields\.io\/crates\/(d|dr|dv)\//i, detail: match => DOWNLOAD_PERIODS[`crates-${match[1].toLowerCase()}`] },
+  { kind: 'downloads', source: 'Packagist', pattern: /shields\.io\/packagist\/(dm|dd|dt)\//i, detail: match => DOWNLOAD_PERIODS[match[1].toLowerCase()] },
+  { kind: 'downloads', source: 'NuGet', pattern: /shields\.io\/nuget\/dt\//i, detail: () => 'total' },
+  { kind: 'downloads', source: 'GitHub releases', pattern: /shields\.io\/github\/downloads\//i },
+  { kind: 'version', source: 'badge.fury.io', pattern: /badge\.fury\.io/i },
+  { kind: 'license', source: 'license badge', pattern: /shields\.io\/(?:github|npm|pypi|crates|packagist|hexpm)\/l(?:icense)?\//i },
+  { kind: 'version', source: 'registry', pattern: /shields\.io\/(?:(?:npm|pypi|crates|packagist|nuget|gem|maven-central|hexpm)\/v|github\/v\/(?:release|tag)|github\/release)\//i },
+  { kind: 'maintenance', source: 'last commit', pattern: /shields\.io\/github\/last-commit\//i },
+  { kind: 'maintenance', source: 'commit activity', pattern: /shields\.io\/github\/commit-activity\//i },
+  { kind: 'maintenance', source: 'maintenance badge', pattern: /shields\.io\/maintenance\/(yes|no)\/(\d{4})/i, detail: match => match[1].toLowerCase() === 'yes' ? `maintained in ${match[2]}` : `unmaintained since ${match[2]}` },
+  { kind: 'maintenance', source: 'repostatus.org', pattern: /repostatus\.org\/badges\/[^/]+\/(\w+)\.svg/i, detail: match => match[1] },
+  { kind: 'maintenance', source: 'lifecycle badge', pattern: /lifecycle-(experimental|stable|maturing|deprecated|superseded|archived|dormant|questioning)/i, detail: match => match[1] },
+  { kind: 'security', source: 'Snyk', pattern: /snyk\.io\/test/i },
+  { kind: 'security', source: 'OpenSSF Best Practices', pattern: /bestpractices\.(?:coreinfrastructure\.org|dev)/i },
+  { kind: 'security', source: 'OpenSSF Scorecard', pattern: /securityscorecards\.dev|api\.scorecard\.dev/i },
+]
+
+/**
+ * Decode the percent-encoding in part of a badge URL, leaving it as written when it's malformed
+ */
+function decodeBadgeText(text: string): string {
+  try {
+    return decodeURIComponent(text)
+  } catch {
+    return text
+  }
+}
+
+// What each registry's download badge counts, by its shields.io path
+const DOWNLOAD_PERIODS: Record<string, string> = {
+  dd: 'daily', dw: 'weekly', dm: 'monthly', dy: 'yearly', dt: 'total', d18m: 'last 18 months',
+  'crates-d': 'total', 'crates-dr': 'recent', 'crates-dv': 'latest version',
+}
+
+// Static badge labels, e.g. shields.io/badge/coverage-95%25-green, by what they report
+const STATIC_BADGE_LABELS: Array<[BadgeSignal['kind'], RegExp]> = [
+  ['ci', /^(?:build|ci|tests?|checks?|pipeline)$/i],
+  ['coverage', /^(?:coverage|codecov)$/i],
+  ['downloads', /^downloads?$/i],
+  ['version', /^(?:version|release|npm|pypi|crates\.io)$/i],
+  ['license', /^licen[cs]e$/i],
+  ['maintenance', /^(?:maintained|maintenance|status|project status|stability)$/i],
+]
+
+const SIGNAL_LABELS: Record<BadgeSignal['kind'], string> = {
+  ci: 'CI',
+  coverage: 'Coverage',
+  downloads: 'Downloads',
+  version: 'Version',
+  license: 'License',
+  maintenance: 'Maintenance',
+  security: 'Security',
+}
+
+// A titled section of full package documentation
+export interface DocSection {
+  title: string
+  content: string
+  level?: number // Markdown heading depth, 0 for the overview before the first heading
+}
+
+// A markdown section with its nested subsections
+export interface DocSectionNode extends DocSection {
+  level: number
+  children: DocSectionNode[]
+}
+
+// Per-request tuning of which documentation sections are returned. Keywords are matched
+// case-insensitively against section headings, and a matching section brings its subsections.
+export interface SectionFilterOptions {
+  include?: string[] // Only keep sections matching one of these (plus the overview)
+  exclude?: string[] // Always drop sections matching one of these, even if also included
+  minLevel?: number // Headings shallower than this (e.g. a "# Title" when 2) are kept regardless of include
+}
+
+/**
+ * Truncate text to at most maxLength characters, marking it as truncated. Characters are counted
+ * as code points, so an emoji or other surrogate pair is never split in half, and the cut is moved
+ * back to a word boundary when there's one nearby (CJK text often has none).
+ */
+export function truncateText(text: string, maxLength: number): string {
+  const characters = Array.from(text)
+  if (characters.length <= maxLength) {
+    return text
+  }
+
+  let truncated = characters.slice(0, maxLength).join('')
+  const lastSpace = truncated.search(/\s\S*$/)
+  if (lastSpace > truncated.length * 0.8) {
+    truncated = truncated.slice(0, lastSpace)
+  }
+
+  return truncated.trimEnd() + "... (truncated)"
+}
+
+/**
+ * Join sections, dropping whole trailing sections until the result fits in maxLength characters,
+ * so no section is returned cut short. A note says how many were omitted. Only a first section
+ * that is longer than maxLength on its own is cut, with truncateText.
+ */
+export function truncateSections(sections: string[], maxLength: number, separator = "\n\n"): string {
+  const length = (text: string) => Array.from(text).length
+  const joined = sections.join(separator)
+  if (length(joined) <= maxLength) {
+    return joined
+  }
+
+  const kept: string[] = []
+  let used = 0
+  for (const section of sections) {
+    const added = length(section) + (kept.length > 0 ? length(separator) : 0)
+    if (used + added > maxLength) break
+    kept.push(section)
+    used += added
+  }
+
+  const omitted = sections.length - Math.max(kept.length, 1)
+  const content = kept.length > 0 ? kept.join(separator).trimEnd() : truncateText(sections[0], maxLength)
+  return omitted > 0
+    ? `${content}\n\n_${omitted} more section${omitted === 1 ? "" : "s"} omitted to stay within ${maxLength} characters_`
+    : content
+}
+
+/**
+ * Truncate markdown to at most maxLength characters by dropping whole trailing sections (see
+ * truncateSections). Headings inside code blocks, such as shell comments, don't start a section.
+ */
+export function truncateMarkdown(markdown: string, maxLength: number): string {
+  const sections: string[] = []
+  let current: string[] = []
+  let inFence = false
+
+  for (const line of markdown.split("\n")) {
+    if (/^\s*(```|~~~)/.test(line)) {
+      inFence = !inFence
+    } else if (!inFence && /^#+\s/.test(line) && current.length > 0) {
+      sections.push(current.join("\n"))
+      current = []
+    }
+    current.push(line)
+  }
+  sections.push(current.join("\n"))
+
+  return truncateSections(sections, maxLength, "\n")
+}
+
+/**
+ * Check whether a heading (e.g. "Migrating from v2 to v3") is relevant when upgrading from a
+ * version: it names no version, or names one newer than fromVersion's major version
+ */
+function isAfterVersion(heading: string, fromVersion?: string): boolean {
+  const versions = Array.from(heading.matchAll(/(?:^|[^\w.])v?(\d+(?:\.\d+)*)(?![\w.])/g), match => match[1])
+  if (!fromVersion || versions.length === 0) return true
+
+  // Guides are written per major version, so only those naming a later major apply
+  const fromMajor = Number(fromVersion.replace(/^v/, '').split('.')[0])
+  return versions.some(version => Number(version.split('.')[0]) > fromMajor)
+}
+
+// A whole emoji: a keycap (1️⃣), a flag (two regional indicators) or a pictograph with its skin tone,
+// variation selector and tag modifiers, joined by zero width joiners into sequences such as 👩‍💻.
+// ©, ® and ™ are pictographs too, but are kept as they're part of names rather than decoration.
+const EMOJI_PATTERN = /[0-9#*]\uFE0F?\u20E3|\p{Regional_Indicator}{2}|(?![\u00A9\u00AE\u2122])\p{Extended_Pictographic}(?:\p{Emoji_Modifier}|\uFE0F|[\u{E0020}-\u{E007F}])*(?:\u200D\p{Extended_Pictographic}(?:\p{Emoji_Modifier}|\uFE0F)*)*/gu
+
+// GitHub emoji shortcodes such as :rocket:, as written on their own in headings
+const EMOJI_SHORTCODE_PATTERN = /(^|\s):[a-z0-9_+-]+:(?=\s|$)/g
+
+/**
+ * Remove emoji and emoji shortcodes from text such as a heading, keeping the words around them,
+ * e.g. "🚀 Getting Started" becomes "Getting Started". Text that's only emoji is returned as is.
+ */
+export function stripEmoji(text: string): string {
+  const stripped = text
+    .replace(EMOJI_PATTERN, ' ')
+    .replace(EMOJI_SHORTCODE_PATTERN, '$1')
+    .replace(/[\uFE0F\u200D]/g, '')
+    .replace(/\s+/g, ' ')
+    .trim()
+  return stripped || text.trim()
+}
+
+/**
+ * Build the anchor GitHub gives a heading: lowercased, with emoji and punctuation dropped and each
+ * space made a hyphen. The space after an emoji is kept, so "🚀 Getting Started!" becomes
+ * "-getting-started"; strip the emoji first for "getting-started".
+ */
+export function slugifyHeading(heading: string): string {
+  return heading
+    .trim()
+    .toLowerCase()
+    .replace(/[^\p{L}\p{M}\p{N}\p{Pc} -]/gu, '')
+    .replace(/ /g, '-')
+}
+
+/**
+ * Render a documentation section as markdown under a heading of its original level, so an h3
+ * subsection stays ### rather than being flattened. The overview and sections whose level isn't
+ * known are rendered as ##.
+ */
+export function formatDocSection(section: DocSection): string {
+  const heading = section.level ? '#'.repeat(section.level) : '##'
+  return `${heading} ${section.title}\n\n${section.content}`.trim()
+}
+
+/**
+ * Render badge signals as a compact "Signals" block, one line per kind with each badge linked,
+ * e.g. "- CI: [GitHub Actions (ci.yml)](https://...)". Returns undefined when there are none.
+ */
+export function formatSignals(signals: BadgeSignal[]): string | undefined {
+  if (signals.length === 0) return undefined
+
+  const lines = (Object.keys(SIGNAL_LABELS) as Array<BadgeSignal['kind']>)
+    .map(kind => {
+      const badges = signals
+        .filter(signal => signal.kind === kind)
+        .map(signal => `[${signal.source}${signal.detail ? ` (${signal.detail})` : ''}](${signal.url})`)
+      return badges.length > 0 ? `- ${SIGNAL_LABELS[kind]}: ${badges.join(', ')}` : undefined
+    })
+    .filter(Boolean)
+  return `## Signals\n\n${lines.join('\n')}`
+}
+
+/**
+ * Render a table as markdown, with columns padded to line up and pipes in cells escaped
+ */
+export function formatMarkdownTable(table: MarkdownTable): string {
+  const escape = (cell: string) => cell.replace(/\|/g, '\\|')
+  const rows = [table.headers, ...table.rows].map(row => row.map(escape))
+  const widths = table.headers.map((_, column) => Math.max(3, ...rows.map(row => (row[column] || '').length)))
+  const formatRow = (row: string[]) => `| ${widths.map((width, column) => (row[column] || '').padEnd(width)).join(' | ')} |`
+
+  return [
+    formatRow(rows[0]),
+    `| ${widths.map(width => '-'.repeat(width)).join(' | ')} |`,
+    ...rows.slice(1).map(formatRow),
+  ].join('\n')
+}
+
+/**
+ * Wrap the spans of text matched by a search in markers (markdown bold by default). Spans are in code
+ * points, as returned by SearchUtils.findMatchSpans.
+ */
+export function highlightMatches(text: string, spans: MatchSpan[], marker: string = '**'): string {
+  const characters = Array.from(text)
+  let highlighted = ''
+  let offset = 0
+  for (const span of spans) {
+    highlighted += characters.slice(offset, span.start).join('') + marker + characters.slice(span.start, span.end).join('') + marker
+    offset = span.end
+  }
+  return highlighted + characters.slice(offset).join('')
+}
+
+/**
+ * Find the parenthesis closing the first one opened in code, or -1 when it isn't closed
+ */
+function closingParenIndex(code: string): number {
+  let depth = 0
+  for (let i = code.indexOf('('); i >= 0 && i < code.length; i++) {
+    if (code[i] === '(') depth++
+    else if (code[i] === ')' && --depth === 0) return i
+  }
+  return -1
+}
+
+export class SearchUtils {
+  private logger: McpLogger
+  private locales: string[]
+
+  constructor(logger: McpLogger) {
+    this.logger = logger.child('SearchUtils')
+    // Additional README languages whose section headings should be recognised, e.g. "es,zh"
+    this.locales = parseLocales(process.env.PACKAGE_DOCS_LOCALES)
+  }
+
+  /**
+   * Check whether a lowercased heading matches the configured localised keywords for a category
+   */
+  private matchesLocaleHeading(heading: string, category: SectionCategory): boolean {
+    return getLocaleKeywords(category, this.locales).some(keyword => heading.includes(keyword))
+  }
+
+  /**
+   * Split markdown into heading-delimited sections, reusing the split of a document already read
+   * by the tool call, as the describe and search paths run several extractors over the same README
+   */
+  public splitMarkdownSections(markdown: string): string[] {
+    return [...cachedParse('sections', markdown, () => markdown.split(/(?=^#+\s+)/m))]
+  }
+
+  /**
+   * Simple fuzzy matching algorithm
+   */
+  public fuzzyMatch(text: string, pattern: string): boolean {
+    const textLower = text.toLowerCase()
+    const patternLower = pattern.toLowerCase()
+
+    let textIndex = 0
+    let patternIndex = 0
+
+    while (textIndex < text.length && patternIndex < pattern.length) {
+      if (textLower[textIndex] === patternLower[patternIndex]) {
+        patternIndex++
+      }
+      textIndex++
+    }
+
+    return patternIndex === pattern.length
+  }
+
+  /**
+   * Rank a fuzzy match of a pattern in text, where lower is better like every search score: 0 when the
+   * text contains the pattern, rising towards 1 as its characters are spread further apart. Returns
+   * undefined when the text doesn't contain the pattern's characters in order.
+   */
+  public fuzzyRank(text: string, pattern: string): number | undefined {
+    const textLower = text.toLowerCase()
+    const patternLower = pattern.toLowerCase()
+    if (!patternLower || textLower.includes(patternLower)) {
+      return patternLower ? 0 : undefined
+    }
+
+    // Find the shortest stretch of text containing the pattern's characters in order
+    let shortest = Infinity
+    for (let start = textLower.indexOf(patternLower[0]); start !== -1; start = textLower.indexOf(patternLower[0], start + 1)) {
+      let patternIndex = 1
+      let end = start + 1
+      while (end < textLower.length && patternIndex < patternLower.length) {
+        if (textLower[end] === patternLower[patternIndex]) patternIndex++
+        end++
+      }
+      if (patternIndex < patternLower.length) break
+      shortest = Math.min(shortest, end - start)
+    }
+
+    return shortest === Infinity ? undefined : 1 - patternLower.length / shortest
+  }
+
+  /**
+   * Extract the lines around the first match of any of a query's terms in a section: contextSize lines after
+   * (clamped, 10 by default) and half as many before, or the lines after the heading when the query doesn't
+   * match any one line (e.g. a fuzzy match). Omitted lines are marked with an ellipsis, and a long matching
+   * line is cut to the words around the match.
+   */
+  public extractContextAroundMatch(
+    content: string,
+    query: string,
+    contextSize: number = DEFAULT_CONTEXT_SIZE,
+    maxLineLength: number = 200
+  ): string {
+    const lines = content.split('\n')
+    const terms = parseSearchQuery(query).flat()
+    const matchLineIndex = lines.findIndex(line => terms.some(term => line.toLowerCase().includes(term)))
+    const after = clampContextSize(contextSize)
+    const before = Math.ceil(after / 2)
+
+    if (matchLineIndex === -1) {
+      const contextLines = lines.slice(1, 1 + before + after)
+      if (lines.length > 1 + before + after) contextLines.push('...')
+      return contextLines.join('\n')
+    }
+
+    const contextStart = Math.max(0, matchLineIndex - before)
+    const contextEnd = Math.min(lines.length, matchLineIndex + after)
+    const contextLines = lines.slice(contextStart, contextEnd)
+
+    const matchLine = lines[matchLineIndex]
+    if (matchLine.length > maxLineLength) {
+      // Centre a window on the match, then widen its ends out to the nearest word boundaries
+      const needle = terms.find(term => matchLine.toLowerCase().includes(term)) || ''
+      const matchStart = matchLine.toLowerCase().indexOf(needle)
+      let start = Math.max(0, matchStart - Math.floor((maxLineLength - needle.length) / 2))
+      let end = Math.min(matchLine.length, start + maxLineLength)
+      start = Math.max(0, end - maxLineLength)
+      while (start > 0 && /\S/.test(matchLine[start - 1])) start--
+      while (end < matchLine.length && /\S/.test(matchLine[end])) end++
+      contextLines[matchLineIndex - contextStart] =
+        (start > 0 ? '... ' : '') + matchLine.slice(start, end).trim() + (end < matchLine.length ? ' ...' : '')
+    }
+
+    if (contextStart > 0) contextLines.unshift('...')
+    if (contextEnd < lines.length) contextLines.push('...')
+    return contextLines.join('\n')
+  }
+
+  /**
+   * Locate a match for a json search hit: the lines around the first line matching the query at or
+   * after fromLine, verbatim, with their offset in the content and the query's matches within them.
+   * Offsets are in code points, like findMatchSpans.
+   */
+  public locateMatch(content: string, query: string, contextSize: number = DEFAULT_CONTEXT_SIZE, fromLine: number = 0): { context: string; offset: number; matches: MatchSpan[] } {
+    const lines = content.split('\n')
+    const terms = parseSearchQuery(query).flat()
+    const found = lines.findIndex((line, index) => index >= fromLine && terms.some(term => line.toLowerCase().includes(term)))
+    const matchLineIndex = found === -1 ? Math.min(fromLine, lines.length - 1) : found
+    const after = clampContextSize(contextSize)
+    const contextStart = Math.max(0, matchLineIndex - Math.ceil(after / 2))
+    const context = lines.slice(contextStart, Math.min(lines.length, matchLineIndex + after)).join('\n')
+
+    return {
+      context,
+      offset: contextStart > 0 ? Array.from(lines.slice(0, contextStart).join('\n')).length + 1 : 0,
+      matches: this.findMatchSpans(context, query),
+    }
+  }
+
+  /**
+   * Find where the terms of a search query appear in text, case insensitively, as sorted code point spans
+   * with overlapping matches of different terms merged
+   */
+  public findMatchSpans(text: string, query: string): MatchSpan[] {
+    const spans: MatchSpan[] = []
+    for (const term of new Set(parseSearchQuery(query).flat())) {
+      const pattern = new RegExp(term.replace(/[.*+?^${}()|[\]\\]/g, '\\$&').replace(/ /g, '\\s+'), 'giu')
+      for (const match of text.matchAll(pattern)) {
+        const start = Array.from(text.slice(0, match.index)).length
+        spans.push({ start, end: start + Array.from(match[0]).length })
+      }
+    }
+
+    spans.sort((a, b) => a.start - b.start)
+    const merged: MatchSpan[] = []
+    for (const span of spans) {
+      const last = merged[merged.length - 1]
+      if (last && span.start <= last.end) {
+        last.end = Math.max(last.end, span.end)
+      } else {
+        merged.push({ ...span })
+      }
+    }
+    return merged
+  }
+
+  /**
+   * Whether content matches a search query, i.e. contains every term of one of its alternatives
+   * (see parseSearchQuery)
+   */
+  public matchesQuery(content: string, query: string): boolean {
+    const text = content.toLowerCase()
+    return parseSearchQuery(query).some(terms => terms.every(term => text.includes(term)))
+  }
+
+  /**
+   * Score how well a documentation section matches a query, where lower is better (between 0 and 1, like
+   * Fuse.js scores). Matches in the heading and near the start count for more, and the number of matches
+   * is normalised by the section's length, so a long section mentioning the query in passing many times
+   * doesn't outrank a short one about it. For a multi-term query, the best matching alternative counts,
+   * scaled by the share of its terms found, with a boost for terms that appear close together.
+   */
+  public scoreSectionMatch(content: string, query: string, options: SearchRankingOptions = {}): number {
+    const weights = { ...DEFAULT_SEARCH_RANKING, ...options }
+    const text = content.toLowerCase()
+    const heading = text.split('\n', 1)[0]
+    const words = Math.max(1, (text.match(/\S+/g) || []).length)
+
+    let best = 0
+    for (const terms of parseSearchQuery(query)) {
+      const positions = terms.map(term => {
+        const found: number[] = []
+        for (let index = text.indexOf(term); index !== -1; index = text.indexOf(term, index + term.length)) {
+          found.push(index)
+        }
+        return found
+      })
+      const matched = positions.filter(found => found.length > 0)
+      if (matched.length === 0) continue
+
+      const first = Math.min(...matched.map(found => found[0]))
+      const occurrences = matched.reduce((total, found) => total + found.length, 0)
+      // Dampened term frequency per 100 words, so repetition has diminishing returns
+      const frequency = Math.log1p((occurrences * 100) / words)
+      const position = 1 - first / text.length
+      const title = terms.filter(term => heading.includes(term)).length / terms.length
+
+      let relevance = weights.title * title + weights.position * position + weights.frequency * frequency
+      if (terms.length > 1 && matched.length === terms.length) {
+        relevance += weights.proximity / (1 + this.shortestSpan(positions) / 100)
+      }
+      best = Math.max(best, relevance * (matched.length / terms.length))
+    }
+
+    return 1 / (1 + best)
+  }
+
+  /**
+   * Get the length of the shortest stretch of text containing one occurrence of every term, given the
+   * (sorted) positions of each term's occurrences
+   */
+  private shortestSpan(positions: number[][]): number {
+    const occurrences = positions
+      .flatMap((found, term) => found.map(index => ({ index, term })))
+      .sort((a, b) => a.index - b.index)
+
+    // Slide a window over the occurrences, shrinking it from the left while it still holds every term
+    const counts = new Array(positions.length).fill(0)
+    let covered = 0
+    let shortest = Infinity
+    let left = 0
+    for (const occurrence of occurrences) {
+      if (counts[occurrence.term]++ === 0) covered++
+      while (covered === positions.length) {
+        shortest = Math.min(shortest, occurrence.index - occurrences[left].index)
+        if (--counts[occurrences[left].term] === 0) covered--
+        left++
+      }
+    }
+    return shortest
+  }
+
+  /**
+   * Extract symbol from text based on language, falling back to the first function signature in the text
+   */
+  public extractSymbol(text: string, language: string): string | undefined {
+    return this.extractSymbolFromFirstLine(text, language) || this.extractFunctionSignatures(text)[0]?.name
+  }
+
+  private extractSymbolFromFirstLine(text: string, language: string): string | undefined {
+    const firstLine = text.split('\n')[0]
+    switch (language) {
+      case "go": {
+        const methodMatch = firstLine.match(GO_METHOD_PATTERN)
+        if (methodMatch) return `${methodMatch[1]}.${methodMatch[2]}`
+        const goMatch = firstLine.match(/^(func|type|var|const)\s+(\w+)/)
+        return goMatch?.[2]
+      }
+      case "python": {
+        const pyMatch = firstLine.match(/^(class|def)\s+(\w+)/)
+        return pyMatch?.[2]
+      }
+      case "npm": {
+        // Extract symbol from markdown headings or code blocks
+        const npmMatch = firstLine.match(/^#+\s*(?:`([^`]+)`|(\w+))/)
+        return npmMatch?.[1] || npmMatch?.[2]
+      }
+      case "swift": {
+        const swiftMatch = firstLine.match(/^(class|struct|enum|protocol|func|var|let)\s+(\w+)/)
+        return swiftMatch?.[2]
+      }
+      case "rust": {
+        const rustMatch = firstLine.match(/^(pub\s+)?(struct|enum|trait|impl|fn|mod|type)\s+(\w+)/)
+        return rustMatch?.[3]
+      }
+      default:
+        return undefined
+    }
+  }
+
+  /**
+   * Extract function signatures from code, or from the code blocks of markdown. Signatures whose
+   * parameter lists wrap across lines are joined up to the opening brace (or arrow, or expression
+   * body) before matching, so each is returned on a single line.
+   */
+  public extractFunctionSignatures(text: string): Array<{ name: string; signature: string }> {
+    return [...cachedParse('signatures', text, () => this.parseFunctionSignatures(text))]
+  }
+
+  private parseFunctionSignatures(text: string): Array<{ name: string; signature: string }> {
+    const fences = text.match(/```[^\n]*\n[\s\S]*?```/g)
+    const code = fences ? fences.map(fence => fence.replace(/^```[^\n]*\n|```$/g, '')).join('\n') : text
+    const lines = code.split('\n')
+    const signatures: Array<{ name: string; signature: string }> = []
+
+    for (let i = 0; i < lines.length; i++) {
+      const start = SIGNATURE_START_PATTERNS
+        .map(({ pattern, ...options }) => ({ match: lines[i].match(pattern), ...options }))
+        .find(({ match }) => match && !SIGNATURE_NON_NAMES.has(match.groups?.name || ''))
+      if (!start?.match) continue
+
+      // Join following lines until the parameter list closes and the body or terminator is reached
+      let joined = ''
+      let depth = 0
+      let complete = false
+      let end = i
+      for (; end < lines.length && end < i + SIGNATURE_MAX_LINES; end++) {
+        for (const char of lines[end]) {
+          if (char === '(') depth++
+          else if (char === ')') depth--
+        }
+        joined += ' ' + lines[end].trim()
+        if (depth <= 0 && /\)[^()]*(\{|=>|=|;|:)\s*(\S.*)?$/.test(joined)) {
+          complete = true
+          break
+        }
+      }
+      if (!complete) continue
+
+      // Drop the body after the parameter list, including Kotlin expression bodies, keeping the
+      // arrow of arrow functions (braces within the parameters, e.g. `options = {}`, are kept)
+      let signature = joined.replace(/\s+/g, ' ').replace(/\(\s+/g, '(').replace(/\s+\)/g, ')').replace(/,\s*\)/g, ')').trim()
+      const close = closingParenIndex(signature)
+      signature = signature.slice(0, close + 1) + signature.slice(close + 1).replace(/\s*\{.*$/, '')
+      signature = signature.replace(/(\)[^()=]*)\s=\s.*$/, '$1').replace(/;$/, '')
+
+      // A method's body follows its parameters (and return type), otherwise it's a call such as
+      // `foo(bar);` or `expect(a).toBe(b)`. Its parameters are names, unlike the strings and
+      // callbacks passed to calls such as `it('works', () => {` or `useEffect(() => {`
+      if (start.method) {
+        const joinedClose = closingParenIndex(joined)
+        const hasBody = joinedClose >= 0 && /^\s*(?::[^{};=]+)?\{/.test(joined.slice(joinedClose + 1))
+        if (!hasBody || /['"`]|=>|\bfunction\b/.test(joined.slice(joined.indexOf('(') + 1, joinedClose))) continue
+      }
+
+      // C/C++ parameters each have a type and a name (or are `void`), unlike constructor calls such as `std::vector<int> v(10);`
+      if (start.typedParameters) {
+        const parameters = signature.slice(signature.indexOf('(') + 1, signature.lastIndexOf(')')).split(',').map(p => p.trim())
+        const typed = parameters.every(p => !p || p === 'void' || p === '...' || /^[A-Za-z_][\w:<>,]*[\s*&]+.*\w/.test(p))
+        if (!typed) continue
+      }
+
+      signatures.push({ name: start.match.groups?.name || 'default', signature })
+      i = end
+    }
+
+    return signatures
+  }
+
+  /**
+   * Parse go doc output into a section per top-level declaration, each followed by its indented doc comment.
+   * Declarations spanning several lines (struct and interface bodies, const and var blocks) are kept whole,
+   * the CONSTANTS/VARIABLES/FUNCTIONS/TYPES headings of go doc -all are dropped, and methods are sections
+   * of their own whose signatures are also listed in their receiver type's section.
+   */
+  public parseGoDoc(doc: string): Array<{ content: string; type: string }> {
+    const sections: Array<{ content: string; type: string }> = []
+    const methodsByType = new Map<string, string[]>()
+    let currentLines: string[] = []
+    let currentType = 'description'
+    // Brackets left open by a multi-line declaration, whose lines start in the first column too
+    let depth = 0
+
+    const flush = () => {
+      const content = currentLines.join('\n').trim()
+      if (content) sections.push({ content, type: currentType })
+      currentLines = []
+    }
+
+    for (const line of doc.split('\n')) {
+      if (depth > 0) {
+        currentLines.push(line)
+        depth = Math.max(0, depth + this.goBracketBalance(line))
+        continue
+      }
+
+      const declaration = line.match(/^(func|type|var|const)\b/)
+      if (declaration) {
+        flush()
+        currentLines = [line]
+        depth = Math.max(0, this.goBracketBalance(line))
+
+        const receiver = line.match(GO_METHOD_PATTERN)
+        if (receiver) {
+          currentType = 'method'
+          methodsByType.set(receiver[1], [...(methodsByType.get(receiver[1]) || []), line.trim()])
+        } else {
+          currentType = declaration[1] === 'func' ? 'function' : declaration[1] === 'type' ? 'type' : 'variable'
+        }
+      } else if (/^[A-Z][A-Z ]+$/.test(line)) {
+        // A go doc -all heading ends the section before it but isn't part of the next one
+        flush()
+        currentType = 'description'
+      } else {
+        currentLines.push(line)
+      }
+    }
+    flush()
+
+    // List each type's method set in its section, so searching for a type finds what it can do
+    for (const section of sections) {
+      const typeName = section.type === 'type' ? section.content.match(/^type\s+(\w+)/)?.[1] : undefined
+      const methods = typeName ? methodsByType.get(typeName) : undefined
+      if (methods) {
+        section.content += '\n\nMethods:\n' + methods.map(method => `    ${method}`).join('\n')
+      }
+    }
+
+    return sections
+  }
+
+  /**
+   * Count the brackets a line of Go opens less those it closes, ignoring any in strings and comments
+   */
+  private goBracketBalance(line: string): number {
+    const code = line
+      .replace(/"(?:[^"\\]|\\.)*"|`[^`]*`|'(?:[^'\\]|\\.)*'/g, '')
+      .replace(/\/\/.*$/, '')
+    return (code.match(/[({[]/g)?.length ?? 0) - (code.match(/[)}\]]/g)?.length ?? 0)
+  }
+
+  /**
+   * Split `go doc -all` output into its overview and CONSTANTS/VARIABLES/FUNCTIONS/TYPES sections
+   */
+  public parseGoDocAll(doc: string): DocSection[] {
+    return this.splitOnUppercaseHeadings(doc, 'Overview')
+  }
+
+  /**
+   * Split pydoc output into its NAME/DESCRIPTION/CLASSES/FUNCTIONS/DATA sections
+   */
+  public parsePythonDocAll(doc: string): DocSection[] {
+    return this.splitOnUppercaseHeadings(doc, 'Overview')
+  }
+
+  /**
+   * Split markdown into sections titled by their headings, with any text before the first heading as the overview.
+   * Sections are returned flat in document order; each records its heading level so nesting can be recovered.
+   */
+  public parseMarkdownDocSections(markdown: string): DocSection[] {
+    const sections: DocSection[] = []
+
+    for (const section of this.splitMarkdownSections(markdown)) {
+      if (!section.trim()) continue
+
+      const headingMatch = section.match(/^(#+)\s+(.*)/)
+      const heading = headingMatch?.[2].trim()
+      const title = heading || 'Overview'
+      const level = headingMatch ? headingMatch[1].length : 0
+      const content = headingMatch ? section.split('\n').slice(1).join('\n').trim() : section.trim()
+
+      sections.push({ title, content, level })
+    }
+
+    // Headings with no text of their own are kept when they introduce subsections, e.g. "## API" followed by "### get"
+    return sections.filter((section, i) =>
+      section.content || (sections[i + 1]?.level ?? 0) > (section.level ?? 0)
+    )
+  }
+
+  /**
+   * Split markdown into a tree of sections, with each heading's subsections as its children
+   */
+  public parseMarkdownSectionTree(markdown: string): DocSectionNode[] {
+    const roots: DocSectionNode[] = []
+    const stack: DocSectionNode[] = []
+
+    for (const section of this.parseMarkdownDocSections(markdown)) {
+      const node: DocSectionNode = { title: section.title, content: section.content, level: section.level ?? 0, children: [] }
+
+      while (stack.length > 0 && stack[stack.length - 1].level >= node.level) {
+        stack.pop()
+      }
+
+      if (stack.length > 0) {
+        stack[stack.length - 1].children.push(node)
+      } else {
+        roots.push(node)
+      }
+      stack.push(node)
+    }
+
+    return roots
+  }
+
+  /**
+   * Filter documentation sections by heading keywords. Exclusions are authoritative, and a
+   * section's subsections are kept or dropped along with it.
+   */
+  public filterDocSections(sections: DocSection[], options: SectionFilterOptions): DocSection[] {
+    const include = (options.include || []).map(keyword => keyword.toLowerCase())
+    const exclude = (options.exclude || []).map(keyword => keyword.toLowerCase())
+    if (include.length === 0 && exclude.length === 0) {
+      return sections
+    }
+
+    const matches = (title: string, keywords: string[]) => keywords.some(keyword => title.toLowerCase().includes(keyword))
+    const filtered: DocSection[] = []
+
+    // Enclosing sections and whether their subsections follow them; undefined leaves each subsection to be checked
+    const ancestors: Array<{ level: number; kept?: boolean }> = []
+
+    for (const section of sections) {
+      // The text before the first heading isn't a parent of the sections that follow
+      if (section.title === 'Overview' && !section.level) {
+        if (!matches(section.title, exclude)) filtered.push(section)
+        continue
+      }
+
+      // Sections from plain text docs have no level, so each is treated as top level
+      const level = section.level ?? 1
+      while (ancestors.length > 0 && ancestors[ancestors.length - 1].level >= level) {
+        ancestors.pop()
+      }
+      const inherited = ancestors.length > 0 ? ancestors[ancestors.length - 1].kept : undefined
+
+      let kept: boolean | undefined
+      if (matches(section.title, exclude)) {
+        kept = false
+      } else if (inherited !== undefined) {
+        kept = inherited
+      } else if (include.length === 0 || matches(section.title, include)) {
+        kept = true
+      } else if (options.minLevel !== undefined && level < options.minLevel) {
+        filtered.push(section)
+      }
+
+      ancestors.push({ level, kept })
+      if (kept) filtered.push(section)
+    }
+
+    return filtered
+  }
+
+  /**
+   * Get a section's content together with the content of its nested subsections, which follow it in
+   * the flat section list with deeper heading levels. Returns the index after the last subsection.
+   */
+  private aggregateSection(sections: DocSection[], index: number): { section: DocSection; end: number } {
+    const parent = sections[index]
+    const parts = parent.content ? [parent.content] : []
+    let end = index + 1
+
+    if (parent.level !== undefined) {
+      while (end < sections.length && (sections[end].level ?? 0) > parent.level) {
+        const child = sections[end]
+        parts.push(formatDocSection(child))
+        end++
+      }
+    }
+
+    return { section: { ...parent, content: parts.join('\n\n') }, end }
+  }
+
+  /**
+   * Extract the documentation for a tool's configuration from a README: the configuration
+   * sections, the code examples within them and any configuration file names mentioned
+   */
+  public extractConfigDocs(markdown: string): {
+    sections: DocSection[]
+    examples: string[]
+    files: string[]
+  } {
+    // Configuration sections include their subsections, e.g. "## Configuration" with "### Options"
+    const allSections = this.parseMarkdownDocSections(markdown)
+    const sections: DocSection[] = []
+    for (let i = 0; i < allSections.length; i++) {
+      if (allSections[i].title !== 'Overview' && CONFIG_HEADING_PATTERN.test(allSections[i].title)) {
+        const { section, end } = this.aggregateSection(allSections, i)
+        if (section.content) sections.push(section)
+        i = end - 1
+      }
+    }
+
+    const examples: string[] = []
+    for (const section of sections) {
+      examples.push(...(section.content.match(/```[\s\S]*?```/g) || []))
+    }
+
+    // Look for file names across the whole README, as they're often introduced before the options table
+    const files = new Set<string>()
+    for (const match of markdown.matchAll(CONFIG_FILE_PATTERN)) {
+      files.add(match[1])
+    }
+
+    return { sections, examples, files: Array.from(files) }
+  }
+
+  /**
+   * Extract the migration and upgrade guide sections of a README or migration guide, with their
+   * subsections. With fromVersion, sections whose headings only name versions up to it (such as
+   * "Migrating from v2 to v3" when upgrading from 3.1) are left out, as they've already been done.
+   */
+  public extractMigrationSections(markdown: string, fromVersion?: string): DocSection[] {
+    const allSections = this.parseMarkdownDocSections(markdown)
+    const sections: DocSection[] = []
+    for (let i = 0; i < allSections.length; i++) {
+      if (allSections[i].title !== 'Overview' && MIGRATION_HEADING_PATTERN.test(allSections[i].title)) {
+        const { section, end } = this.aggregateSection(allSections, i)
+        if (section.content && isAfterVersion(section.title, fromVersion)) sections.push(section)
+        i = end - 1
+      }
+    }
+    return sections
+  }
+
+  /**
+   * Extract the breaking changes from a changelog for each version after fromVersion, up to and
+   * including toVersion: sub-sections headed Breaking Changes (or Removed), and list items marked
+   * BREAKING. Versions are taken from the changelog's headings, newest first as written.
+   */
+  public extractBreakingChanges(changelog: string, fromVersion?: string, toVersion?: string): Array<{ version: string; content: string }> {
+    const entries: Array<{ version: string; lines: string[] }> = []
+    let entryLevel = 0
+    for (const line of changelog.split('\n')) {
+      const heading = line.match(/^(#+)\s+(.*)/)
+      const version = heading?.[2].match(/(?:^|[^\w.])v?(\d+\.\d+(?:\.\d+)?(?:-[\w.]+)?)/)?.[1]
+      if (heading && version && (entries.length === 0 || heading[1].length <= entryLevel)) {
+        entries.push({ version, lines: [] })
+        entryLevel = heading[1].length
+      } else if (entries.length > 0) {
+        entries[entries.length - 1].lines.push(line)
+      }
+    }
+
+    const breaking: Array<{ version: string; content: string }> = []
+    for (const entry of entries) {
+      if (fromVersion && compareVersions(entry.version, fromVersion) <= 0) continue
+      if (toVersion && compareVersions(entry.version, toVersion) > 0) continue
+
+      const kept: string[] = []
+      let inBreakingSection = false
+      for (const line of entry.lines) {
+        if (/^#+\s/.test(line)) {
+          inBreakingSection = BREAKING_HEADING_PATTERN.test(line)
+          if (inBreakingSection) kept.push(line)
+        } else if (inBreakingSection || BREAKING_LINE_PATTERN.test(line)) {
+          kept.push(line)
+        }
+      }
 
-export interface NpmDocArgs {
-  package: string
-  version?: string
-  projectPath?: string
-  section?: string
-  maxLength?: number
-  query?: string
-}
+      const content = kept.join('\n').trim()
+      if (content) breaking.push({ version: entry.version, content })
+    }
+    return breaking
+  }
 
-export interface SwiftDocArgs {
-  package: string
-  symbol?: string
-  projectPath?: string
-}
+  /**
+   * Extract the fenced code blocks from markdown with their languages. When an ecosystem is given
+   * (e.g. "python"), only the blocks in one of its languages are returned.
+   */
+  public extractCodeBlocks(content: string, ecosystem?: string): CodeBlock[] {
+    // The blocks are parsed once per tool call, whichever languages are asked for
+    const blocks = cachedParse('codeBlocks', content, () => this.parseCodeBlocks(content))
+    if (!ecosystem) {
+      return [...blocks]
+    }
+    const languages = ECOSYSTEM_CODE_LANGUAGES[ecosystem] || [ecosystem]
+    return blocks.filter(block => block.language && languages.includes(block.language))
+  }
 
-export const isGoDocArgs = (args: unknown): args is GoDocArgs => {
-  return (
-    typeof args === "object" &&
-    args !== null &&
-    typeof (args as GoDocArgs).package === "string" &&
-    (typeof (args as GoDocArgs).symbol === "string" ||
-      (args as GoDocArgs).symbol === undefined) &&
-    (typeof (args as GoDocArgs).projectPath === "string" ||
-      (args as GoDocArgs).projectPath === undefined)
-  )
-}
+  private parseCodeBlocks(content: string): CodeBlock[] {
+    const blocks: CodeBlock[] = []
+    const lines = content.split('\n')
 
-export const isSwiftDocArgs = (args: unknown): args is SwiftDocArgs => {
-  return (
-    typeof args === "object" &&
-    args !== null &&
-    typeof (args as SwiftDocArgs).package === "string" &&
-    (typeof (args as SwiftDocArgs).symbol === "string" ||
-      (args as SwiftDocArgs).symbol === undefined) &&
-    (typeof (args as SwiftDocArgs).projectPath === "string" ||
-      (args as SwiftDocArgs).projectPath === undefined)
-  )
-}
+    for (let i = 0; i < lines.length; i++) {
+      const fence = lines[i].match(/^\s*(```+|~~~+)\s*([^\s`{]*)/)
+      if (!fence) continue
 
-export const isPythonDocArgs = (args: unknown): args is PythonDocArgs => {
-  return (
-    typeof args === "object" &&
-    args !== null &&
-    typeof (args as PythonDocArgs).package === "string" &&
-    (typeof (args as PythonDocArgs).symbol === "string" ||
-      (args as PythonDocArgs).symbol === undefined) &&
-    (typeof (args as PythonDocArgs).projectPath === "string" ||
-      (args as PythonDocArgs).projectPath === undefined)
-  )
-}
+      // The closing fence must use the same character and be at least as long
+      const code: string[] = []
+      while (i + 1 < lines.length && !lines[i + 1].trim().startsWith(fence[1])) {
+        code.push(lines[++i])
+      }
+      i++
 
-export const isNpmDocArgs = (args: unknown): args is NpmDocArgs => {
-  return (
-    typeof args === "object" &&
-    args !== null &&
-    typeof (args as NpmDocArgs).package === "string" &&
-    (typeof (args as NpmDocArgs).version === "string" ||
-      (args as NpmDocArgs).version === undefined) &&
-    (typeof (args as NpmDocArgs).projectPath === "string" ||
-      (args as NpmDocArgs).projectPath === undefined) &&
-    (typeof (args as NpmDocArgs).section === "string" ||
-      (args as NpmDocArgs).section === undefined) &&
-    (typeof (args as NpmDocArgs).maxLength === "number" ||
-      (args as NpmDocArgs).maxLength === undefined) &&
-    (typeof (args as NpmDocArgs).query === "string" ||
-      (args as NpmDocArgs).query === undefined)
-  )
-}
+      blocks.push({ language: fence[2].toLowerCase() || undefined, code: code.join('\n') })
+    }
 
-export class SearchUtils {
-  private logger: McpLogger
+    return blocks
+  }
 
-  constructor(logger: McpLogger) {
-    this.logger = logger.child('SearchUtils')
+  /**
+   * Render a code block as markdown, keeping its language for syntax highlighting
+   */
+  public formatCodeBlock(block: CodeBlock): string {
+    return `\`\`\`${block.language || ''}\n${block.code}\n\`\`\``
   }
 
   /**
-   * Simple fuzzy matching algorithm
+   * Extract the GFM tables from markdown: a header row, a delimiter row (e.g. | --- | :-: |) and
+   * the body rows up to the first line that isn't part of the table. Tables in code blocks are ignored.
    */
-  public fuzzyMatch(text: string, pattern: string): boolean {
-    const textLower = text.toLowerCase()
-    const patternLower = pattern.toLowerCase()
+  public extractTables(content: string): MarkdownTable[] {
+    const tables: MarkdownTable[] = []
+    const lines = content.split('\n')
+
+    // Split on unescaped pipes, dropping the optional leading and trailing ones
+    const splitRow = (line: string) => line
+      .trim()
+      .replace(/^\|/, '')
+      .replace(/(?<!\\)\|$/, '')
+      .split(/(?<!\\)\|/)
+      .map(cell => cell.trim().replace(/\\\|/g, '|'))
+
+    const isDelimiterRow = (line: string) => /^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$/.test(line) && line.includes('-')
+
+    let inCodeBlock = false
+    for (let i = 0; i < lines.length; i++) {
+      if (lines[i].trim().startsWith('```')) {
+        inCodeBlock = !inCodeBlock
+        continue
+      }
+      if (inCodeBlock || !lines[i].includes('|') || i + 1 >= lines.length || !isDelimiterRow(lines[i + 1])) {
+        continue
+      }
 
-    let textIndex = 0
-    let patternIndex = 0
+      const headers = splitRow(lines[i])
+      // The delimiter row must have a column for each header
+      if (splitRow(lines[i + 1]).length !== headers.length) {
+        continue
+      }
 
-    while (textIndex < text.length && patternIndex < pattern.length) {
-      if (textLower[textIndex] === patternLower[patternIndex]) {
-        patternIndex++
+      const rows: string[][] = []
+      i += 2
+      while (i < lines.length && lines[i].includes('|') && lines[i].trim() !== '') {
+        const cells = splitRow(lines[i])
+        // Short rows are padded and long rows cut to the header's width, as in GFM
+        rows.push(headers.map((_, column) => cells[column] ?? ''))
+        i++
       }
-      textIndex++
+      i--
+
+      tables.push({ headers, rows })
     }
 
-    return patternIndex === pattern.length
+    return tables
   }
 
   /**
-   * Extract symbol from text based on language
+   * Extract the tables documenting options or parameters, identified by their first column heading
+   * (e.g. "Option", "Name", "Parameter") alongside at least one other column such as a description
    */
-  public extractSymbol(text: string, language: string): string | undefined {
-    const firstLine = text.split('\n')[0]
-    switch (language) {
-      case "go": {
-        const goMatch = firstLine.match(/^(func|type|var|const)\s+(\w+)/)
-        return goMatch?.[2]
+  public extractOptionTables(content: string): MarkdownTable[] {
+    return this.extractTables(content).filter(table =>
+      table.headers.length >= 2 &&
+      table.rows.length > 0 &&
+      OPTION_TABLE_HEADER_PATTERN.test(table.headers[0].replace(/[*_`]/g, '').trim())
+    )
+  }
+
+  /**
+   * Extract a package's compatibility information from its README: sections whose heading is about
+   * compatibility or requirements (with their tables parsed), tables elsewhere whose headers mention
+   * support or compatibility, and sentences such as "Works with React 16, 17 and 18"
+   */
+  public extractCompatibility(markdown: string): { sections: CompatibilitySection[]; statements: string[] } {
+    const sections: CompatibilitySection[] = []
+    const statements = new Set<string>()
+
+    for (const section of this.parseMarkdownDocSections(markdown)) {
+      const tables = this.extractTables(section.content)
+      if (COMPATIBILITY_HEADING_PATTERN.test(section.title)) {
+        sections.push({ title: section.title, content: section.content, level: section.level, tables })
+        continue
       }
-      case "python": {
-        const pyMatch = firstLine.match(/^(class|def)\s+(\w+)/)
-        return pyMatch?.[2]
+
+      const matrices = tables.filter(table => table.headers.some(header => COMPATIBILITY_TABLE_HEADER_PATTERN.test(header)))
+      if (matrices.length > 0) {
+        sections.push({ title: section.title, content: '', level: section.level, tables: matrices })
       }
-      case "npm": {
-        // Extract symbol from markdown headings or code blocks
-        const npmMatch = firstLine.match(/^#+\s*(?:`([^`]+)`|(\w+))/)
-        return npmMatch?.[1] || npmMatch?.[2]
+
+      // Statements in prose only, not in code
+      const prose = section.content.replace(/```[\s\S]*?```/g, '')
+      for (const sentence of prose.split(/(?<=[.!?])\s+|\n+/)) {
+        if (COMPATIBILITY_STATEMENT_PATTERN.test(sentence) && sentence.length <= 300) {
+          statements.add(sentence.replace(/^[-*+]\s+/, '').trim())
+        }
       }
-      case "swift": {
-        const swiftMatch = firstLine.match(/^(class|struct|enum|protocol|func|var|let)\s+(\w+)/)
-        return swiftMatch?.[2]
+    }
+
+    return { sections, statements: Array.from(statements) }
+  }
+
+  /**
+   * Extract the code blocks from markdown, each labelled with what it demonstrates: the sentence
+   * introducing it (e.g. "To handle errors, catch the rejection:"), or otherwise its nearest heading
+   */
+  public extractLabelledExamples(markdown: string): LabelledExample[] {
+    const examples: LabelledExample[] = []
+    const lines = markdown.split('\n')
+
+    let heading = ''
+    let sentence = '' // The last line of prose since the previous heading or code block
+
+    // Labels are plain text, without markdown emphasis, links or a trailing colon
+    const clean = (text: string) => text
+      .replace(/!?\[([^\]]*)\]\([^)]*\)/g, '$1')
+      .replace(/[*_`]/g, '')
+      .replace(/[:.]\s*$/, '')
+      .trim()
+
+    for (let i = 0; i < lines.length; i++) {
+      const line = lines[i]
+
+      const headingMatch = line.match(/^#+\s+(.*)/)
+      if (headingMatch) {
+        heading = clean(headingMatch[1])
+        sentence = ''
+        continue
       }
-      case "rust": {
-        const rustMatch = firstLine.match(/^(pub\s+)?(struct|enum|trait|impl|fn|mod|type)\s+(\w+)/)
-        return rustMatch?.[3]
+
+      const fence = line.match(/^\s*(```+|~~~+)\s*([\w+#-]*)/)
+      if (fence) {
+        const code: string[] = []
+        while (i + 1 < lines.length && !lines[i + 1].trim().startsWith(fence[1])) {
+          code.push(lines[++i])
+        }
+        i++
+
+        // Only the prose's final sentence, as a paragraph often ends with the example's introduction
+        const lastSentence = sentence.split(/(?<=[.!?])\s+/).pop() || ''
+        examples.push({
+          label: clean(lastSentence) || heading || `Example ${examples.length + 1}`,
+          language: fence[2] || undefined,
+          code: code.join('\n'),
+          heading: heading || undefined,
+        })
+        sentence = ''
+        continue
       }
-      default:
-        return undefined
+
+      if (line.trim() && !line.trim().startsWith('<') && !line.trim().startsWith('|')) {
+        sentence = line.trim().replace(/^[-*>]\s+/, '')
+      }
+    }
+
+    return examples
+  }
+
+  /**
+   * Pick up to max examples, preferring those in example and usage sections over the rest (such as
+   * installation commands and configuration snippets), and keeping the picked ones in document order
+   */
+  public selectExamples(examples: LabelledExample[], max: number): LabelledExample[] {
+    if (examples.length <= max) {
+      return examples
     }
+
+    const preferred = examples.filter(example => EXAMPLE_HEADING_PATTERN.test(example.heading || ''))
+    const others = examples.filter(example => !EXAMPLE_HEADING_PATTERN.test(example.heading || ''))
+    const picked = new Set([...preferred, ...others].slice(0, max))
+    return examples.filter(example => picked.has(example))
   }
 
   /**
-   * Parse Go documentation into sections
+   * Extract images from markdown, including inline HTML <img> tags, flagging status badges.
+   * Badges are left out unless includeBadges is set.
    */
-  public parseGoDoc(doc: string): Array<{ content: string; type: string }> {
-    const sections: Array<{ content: string; type: string }> = []
-    let currentSection = ''
-    let currentType = 'description'
+  public extractImages(content: string, includeBadges: boolean = false): ImageRef[] {
+    const images: ImageRef[] = []
+    const seen = new Set<string>()
 
-    const lines = doc.split('\n')
-    for (const line of lines) {
-      if (line.startsWith('func ')) {
-        if (currentSection) {
-          sections.push({ content: currentSection.trim(), type: currentType })
+    const add = (alt: string, url: string) => {
+      if (!url || seen.has(url)) return
+      seen.add(url)
+
+      const isBadge = BADGE_URL_PATTERN.test(url)
+      if (isBadge && !includeBadges) return
+
+      images.push({ alt: alt.trim(), url, isBadge })
+    }
+
+    // Markdown images, optionally with a title: ![alt](url "title")
+    for (const match of content.matchAll(/!\[([^\]]*)\]\(\s*<?([^\s)>]+)>?(?:\s+["'][^"']*["'])?\s*\)/g)) {
+      add(match[1], match[2])
+    }
+
+    // Reference style images: ![alt][ref] with [ref]: url elsewhere in the document
+    const references = new Map<string, string>()
+    for (const match of content.matchAll(/^\s*\[([^\]]+)\]:\s*<?(\S+?)>?(?:\s+["'(].*)?$/gm)) {
+      references.set(match[1].toLowerCase(), match[2])
+    }
+    for (const match of content.matchAll(/!\[([^\]]*)\]\[([^\]]*)\]/g)) {
+      const url = references.get((match[2] || match[1]).toLowerCase())
+      if (url) add(match[1], url)
+    }
+
+    // HTML images, which READMEs often use to control sizing
+    for (const match of content.matchAll(/<img\b[^>]*>/gi)) {
+      const src = match[0].match(/\bsrc\s*=\s*["']([^"']+)["']/i)
+      const alt = match[0].match(/\balt\s*=\s*["']([^"']*)["']/i)
+      if (src) add(alt ? alt[1] : "", src[1])
+    }
+
+    return images
+  }
+
+  /**
+   * Infer what a README's badges say about a package's upkeep: which CI service builds it, which
+   * service measures its coverage, where its download counts come from and so on. Only the badge
+   * URLs are read, so the status shown by a live badge isn't known, but a static badge's message is.
+   */
+  public extractBadgeSignals(markdown: string): BadgeSignal[] {
+    // The pages badges link to, by image URL: [![alt](image)](link), [![alt][image]][link] and <a href><img src></a>
+    const references = new Map<string, string>()
+    for (const match of markdown.matchAll(/^\s*\[([^\]]+)\]:\s*<?(\S+?)>?(?:\s+["'(].*)?$/gm)) {
+      references.set(match[1].toLowerCase(), match[2])
+    }
+    const resolve = (inline: string | undefined, reference: string | undefined) =>
+      inline ?? (reference !== undefined ? references.get(reference.toLowerCase()) : undefined)
+    const links = new Map<string, string>()
+    const linkedImage = /\[!\[([^\]]*)\](?:\(\s*<?([^\s)>]+)>?[^)]*\)|\[([^\]]*)\])\](?:\(\s*<?([^\s)>]+)>?[^)]*\)|\[([^\]]*)\])/g
+    for (const match of markdown.matchAll(linkedImage)) {
+      const image = resolve(match[2], match[3] || match[1])
+      const link = resolve(match[4], match[5])
+      if (image && link) links.set(image, link)
+    }
+    for (const match of markdown.matchAll(/<a\b[^>]*\bhref\s*=\s*["']([^"']+)["'][^>]*>\s*<img\b[^>]*\bsrc\s*=\s*["']([^"']+)["']/gi)) {
+      links.set(match[2], match[1])
+    }
+
+    const signals: BadgeSignal[] = []
+    const seen = new Set<string>()
+    for (const image of this.extractImages(markdown, true)) {
+      const link = links.get(image.url)
+      const url = link || image.url
+      const target = `${image.url} ${link || ''}`
+
+      let signal: BadgeSignal | undefined
+      for (const rule of BADGE_SIGNAL_RULES) {
+        const match = target.match(rule.pattern)
+        if (match) {
+          signal = { kind: rule.kind, source: rule.source, detail: rule.detail?.(match), url }
+          break
         }
-        currentSection = line
-        currentType = 'function'
-      } else if (line.startsWith('type ')) {
-        if (currentSection) {
-          sections.push({ content: currentSection.trim(), type: currentType })
+      }
+
+      // Static badges carry their own label and message, e.g. /badge/coverage-95%25-green
+      const staticBadge = image.url.match(/shields\.io\/badge\/([^?#\s]+)/i)?.[1]
+      if (!signal && staticBadge) {
+        const [label, message] = staticBadge
+          .replace(/--/g, '\u0000').replace(/__/g, '\u0001')
+          .split('-')
+          .map(part => decodeBadgeText(part.replace(/\u0000/g, '-').replace(/_/g, ' ').replace(/\u0001/g, '_')).trim())
+        const kind = STATIC_BADGE_LABELS.find(([, pattern]) => pattern.test(label))?.[0]
+        if (kind && message) signal = { kind, source: 'static badge', detail: message, url }
+      }
+
+      if (!signal) continue
+      const key = `${signal.kind}|${signal.source}|${signal.detail || ''}`
+      if (seen.has(key)) continue
+      seen.add(key)
+      signals.push(signal)
+    }
+
+    return signals
+  }
+
+  /**
+   * Get a note naming the documentation's language when it isn't English, so clients can decide to translate it
+   */
+  public languageNote(content: string): string | undefined {
+    const language = detectLanguage(content)
+    if (!language || language === 'en') return undefined
+
+    return `Documentation language: ${LANGUAGE_NAMES[language]}`
+  }
+
+  /**
+   * Extract the entry for a version from a changelog, including its sub-headings (e.g. "### Added").
+   * Matches headings such as "## 1.2.0", "## [v1.2.0] - 2024-01-01" or "# Version 1.2.0".
+   */
+  public extractVersionSection(changelog: string, version: string): string | undefined {
+    const escaped = version.replace(/^v/, '').replace(/[.*+?^${}()|[\]\\]/g, '\\$&')
+    const versionPattern = new RegExp(`(^|[^\\d.])v?${escaped}($|[^\\d.])`, 'i')
+    const lines = changelog.split('\n')
+
+    let start = -1
+    let level = 0
+    for (let i = 0; i < lines.length; i++) {
+      const heading = lines[i].match(/^(#+)\s+(.*)/)
+      if (!heading) continue
+
+      if (start === -1) {
+        if (versionPattern.test(heading[2])) {
+          start = i
+          level = heading[1].length
         }
-        currentSection = line
-        currentType = 'type'
-      } else if (line.startsWith('var ') || line.startsWith('const ')) {
-        if (currentSection) {
-          sections.push({ content: currentSection.trim(), type: currentType })
+      } else if (heading[1].length <= level) {
+        return lines.slice(start, i).join('\n').trim()
+      }
+    }
+
+    return start === -1 ? undefined : lines.slice(start).join('\n').trim()
+  }
+
+  /**
+   * Split plain text documentation on unindented all-caps heading lines, as used by go doc and pydoc
+   */
+  private splitOnUppercaseHeadings(doc: string, firstTitle: string): DocSection[] {
+    const sections: DocSection[] = []
+    let title = firstTitle
+    let lines: string[] = []
+
+    for (const line of doc.split('\n')) {
+      if (/^[A-Z][A-Z ]+$/.test(line.trim()) && !line.startsWith(' ')) {
+        if (lines.join('\n').trim()) {
+          sections.push({ title, content: lines.join('\n').trim() })
         }
-        currentSection = line
-        currentType = 'variable'
+        title = line.trim().charAt(0) + line.trim().slice(1).toLowerCase()
+        lines = []
       } else {
-        currentSection += '\n' + line
+        lines.push(line)
       }
     }
 
-    if (currentSection) {
-      sections.push({ content: currentSection.trim(), type: currentType })
+    if (lines.join('\n').trim()) {
+      sections.push({ title, content: lines.join('\n').trim() })
     }
 
     return sections
   }
 
+  /**
+   * Narrow full documentation to the requested section and/or the blocks matching a query.
+   * If nothing matches, all sections are returned along with an error explaining why.
+   */
+  public selectDocumentation(
+    sections: DocSection[],
+    options: { section?: string; query?: string }
+  ): { sections: DocSection[]; error?: string } {
+    const { section, query } = options
+    let selected = sections
+
+    if (section) {
+      // A matching section includes its subsections; those aren't matched again separately.
+      // Emoji are ignored and slugs are compared too, so both "getting-started" and GitHub's anchor
+      // "-getting-started" find "🚀 Getting Started!".
+      const wanted = stripEmoji(section).toLowerCase()
+      const wantedSlug = slugifyHeading(section)
+      const matching: DocSection[] = []
+      for (let i = 0; i < sections.length; i++) {
+        const title = stripEmoji(sections[i].title)
+        const slugs = [slugifyHeading(sections[i].title), slugifyHeading(title)]
+        if (title.toLowerCase().includes(wanted) || (wantedSlug && slugs.some(slug => slug.includes(wantedSlug)))) {
+          const { section: aggregated, end } = this.aggregateSection(sections, i)
+          matching.push(aggregated)
+          i = end - 1
+        }
+      }
+      if (matching.length === 0) {
+        return { sections, error: `Section '${section}' not found in documentation` }
+      }
+      selected = matching
+    }
+
+    if (query?.trim()) {
+      const wanted = query.toLowerCase()
+      const matching: DocSection[] = []
+
+      for (const docSection of selected) {
+        // A block starts at an unindented line following a blank line, which keeps a
+        // declaration together with its doc comment and a markdown paragraph together.
+        // Brackets and code fences are tracked so multi-line declarations and code
+        // examples aren't split.
+        const blocks: string[] = []
+        let depth = 0
+        let inFence = false
+        let previousBlank = true
+        for (const line of docSection.content.split('\n')) {
+          if (blocks.length === 0 || (!inFence && depth === 0 && previousBlank && line.trim() && !/^\s/.test(line))) {
+            blocks.push(line)
+          } else {
+            blocks[blocks.length - 1] += '\n' + line
+          }
+
+          if (line.trim().startsWith('```')) {
+            inFence = !inFence
+          } else if (!inFence) {
+            depth = Math.max(0, depth + (line.match(/[{(]/g) || []).length - (line.match(/[})]/g) || []).length)
+          }
+          previousBlank = !line.trim()
+        }
+
+        const matchingBlocks = blocks.filter(block => block.toLowerCase().includes(wanted))
+        if (matchingBlocks.length > 0) {
+          matching.push({ title: docSection.title, content: matchingBlocks.map(b => b.trim()).join('\n\n') })
+        }
+      }
+
+      if (matching.length === 0) {
+        return { sections: selected, error: `No matches found for '${query}' in documentation` }
+      }
+      selected = matching
+    }
+
+    return { sections: selected }
+  }
+
   /**
    * Parse Python documentation into sections
    */
@@ -328,7 +2233,7 @@ export class SearchUtils {
 
     // Parse README into sections
     if (data.readme) {
-      const readmeSections = data.readme.split(/(?=^#+ )/m)
+      const readmeSections = this.splitMarkdownSections(data.readme)
       for (const section of readmeSections) {
         const lines = section.split('\n')
         const heading = lines[0]
@@ -363,6 +2268,10 @@ export class SearchUtils {
           else if (lowerHeading.includes('method') || lowerHeading.includes('function')) type = 'api'
           else if (lowerHeading.includes('quick start')) type = 'quickstart'
           else if (lowerHeading.includes('getting started')) type = 'quickstart'
+          else if (this.matchesLocaleHeading(lowerHeading, 'install')) type = 'installation'
+          else if (this.matchesLocaleHeading(lowerHeading, 'example')) type = 'example'
+          else if (this.matchesLocaleHeading(lowerHeading, 'config')) type = 'configuration'
+          else if (this.matchesLocaleHeading(lowerHeading, 'usage') || this.matchesLocaleHeading(lowerHeading, 'api')) type = 'usage'
 
           sections.push({
             content: `${heading}\n${content}`,
@@ -395,7 +2304,7 @@ export class SearchUtils {
     }
 
     // Split the readme into sections based on headings
-    const sections = readme.split(/(?=^#+\s+)/m)
+    const sections = this.splitMarkdownSections(readme)
 
     // Process each section
     for (const section of sections) {
@@ -434,7 +2343,8 @@ export class SearchUtils {
       if (
         heading.includes('usage') ||
         heading.includes('getting started') ||
-        heading.includes('quick start')
+        heading.includes('quick start') ||
+        this.matchesLocaleHeading(heading, 'usage')
       ) {
         result.usage = content
       }
@@ -447,7 +2357,8 @@ export class SearchUtils {
         heading.includes('request config') ||
         heading.includes('response schema') ||
         heading.includes('config defaults') ||
-        heading.includes('interceptors')
+        heading.includes('interceptors') ||
+        this.matchesLocaleHeading(heading, 'api')
       ) {
         // If we already have API content, append this section
         if (result.api) {
@@ -458,7 +2369,8 @@ export class SearchUtils {
       }
       else if (
         heading.includes('example') ||
-        heading.includes('demo')
+        heading.includes('demo') ||
+        this.matchesLocaleHeading(heading, 'example')
       ) {
         // If we already have examples content, append this section
         if (result.examples) {
@@ -470,7 +2382,8 @@ export class SearchUtils {
       else if (
         heading.includes('config') ||
         heading.includes('option') ||
-        heading.includes('setting')
+        heading.includes('setting') ||
+        this.matchesLocaleHeading(heading, 'config')
       ) {
         result.configuration = content
       }
@@ -519,8 +2432,12 @@ export class SearchUtils {
   /**
    * Extract only the most relevant content from a README for coding purposes
    */
-  public extractRelevantContent(readme: string): string {
-    this.logger.debug("Extracting relevant content from README")
+  public extractRelevantContent(readme: string, profile: RelevanceProfile = "consumer"): string {
+    this.logger.debug(`Extracting relevant content from README for the ${profile} profile`)
+
+    if (profile === "full") {
+      return readme
+    }
 
     // First, remove all badge links and reference-style links
     const cleanedReadme = readme
@@ -528,7 +2445,7 @@ export class SearchUtils {
       .replace(/\[[^\]]*\]:\s*https?:\/\/[^\s]+/g, '')    // Remove reference links
 
     // Split the readme into sections
-    const sections = cleanedReadme.split(/(?=^#+ )/m)
+    const sections = this.splitMarkdownSections(cleanedReadme)
     this.logger.debug(`Found ${sections.length} sections in README`)
 
     // Always include the first code example if it exists
@@ -554,41 +2471,62 @@ export class SearchUtils {
     }
 
     // Define keywords for sections we want to keep
-    const usefulKeywords = [
+    let usefulKeywords = [
       'install', 'usage', 'api', 'example', 'quick start', 'getting started',
       'guide', 'method', 'function', 'config', 'option', 'feature', 'overview',
       'basic', 'tutorial', 'how to'
     ]
 
     // Define keywords for sections we want to skip
-    const skipKeywords = [
+    let skipKeywords = [
       'sponsor', 'author', 'contributor', 'license', 'changelog', 'people',
       'community', 'triager', 'tc ', 'committee', 'security', 'test',
       'contributing', 'badge', 'build status', 'coverage', 'donate',
       'acknowledgement', 'credit', 'support', 'backers', 'funding'
     ]
 
+    // Recognise section headings in any configured README languages
+    const localeCategories: SectionCategory[] = ['install', 'usage', 'example', 'api', 'config']
+    usefulKeywords = [...usefulKeywords, ...localeCategories.flatMap(category => getLocaleKeywords(category, this.locales))]
+
+    if (profile === "contributor") {
+      usefulKeywords = [...usefulKeywords, ...CONTRIBUTOR_KEYWORDS]
+      skipKeywords = skipKeywords.filter(keyword =>
+        !CONTRIBUTOR_KEYWORDS.some(contributorKeyword => contributorKeyword.includes(keyword) || keyword.includes(contributorKeyword))
+      )
+    }
+
+    // Find the sections that are likely not useful for coding, at any heading level. The skip is
+    // authoritative: subsections of a skipped section (e.g. "### MIT" under "## License") are skipped
+    // too, and the fallbacks below never bring them back.
+    const skipped = new Set<number>()
+    let skipLevel = 0
+    for (let i = 0; i < sections.length; i++) {
+      const headingMatch = sections[i].match(/^(#+)\s+(.*)/)
+      if (!headingMatch) continue
+
+      const level = headingMatch[1].length
+      if (skipLevel && level > skipLevel) {
+        skipped.add(i)
+        continue
+      }
+
+      const heading = headingMatch[2].toLowerCase()
+      skipLevel = skipKeywords.some(keyword => heading.includes(keyword)) ? level : 0
+      if (skipLevel) {
+        skipped.add(i)
+        this.logger.debug(`Skipping section: ${heading}`)
+      }
+    }
+
     // Process each section with a heading
     for (let i = 0; i < sections.length; i++) {
       const section = sections[i]
-      if (!section.startsWith('#')) continue
+      if (!section.startsWith('#') || skipped.has(i)) continue
 
       const lines = section.split('\n')
       const heading = lines[0].toLowerCase()
 
-      // Skip sections that are likely not useful for coding
-      let shouldSkip = false
-      for (const keyword of skipKeywords) {
-        if (heading.includes(keyword)) {
-          shouldSkip = true
-          break
-        }
-      }
-      if (shouldSkip) {
-        this.logger.debug(`Skipping section: ${heading}`)
-        continue
-      }
-
       // Include sections that are likely useful for coding
       let shouldInclude = false
       for (const keyword of usefulKeywords) {
@@ -623,7 +2561,7 @@ export class SearchUtils {
       // Include any section with a code example
       for (let i = 0; i < sections.length; i++) {
         const section = sections[i]
-        if (!section.startsWith('#')) continue
+        if (!section.startsWith('#') || skipped.has(i)) continue
 
         if (section.includes('```') && !relevantSections.includes(section)) {
           relevantSections.push(section)
@@ -634,7 +2572,7 @@ export class SearchUtils {
       // If still no sections, include the first few sections regardless
       if (relevantSections.length <= 1) {
         for (let i = 0; i < Math.min(3, sections.length); i++) {
-          if (sections[i].startsWith('#') && !relevantSections.includes(sections[i])) {
+          if (sections[i].startsWith('#') && !skipped.has(i) && !relevantSections.includes(sections[i])) {
             relevantSections.push(sections[i])
             this.logger.debug(`Added section ${i} as fallback`)
           }
@@ -642,8 +2580,12 @@ export class SearchUtils {
       }
     }
 
-    // If we still don't have any code examples, add the first one we found
-    if (!hasIncludedCodeExample && firstCodeExample) {
+    // If we still don't have any code examples, add the first one we found outside the skipped sections
+    if (
+      !hasIncludedCodeExample &&
+      firstCodeExample &&
+      !sections.some((section, i) => skipped.has(i) && section.includes(firstCodeExample[0]))
+    ) {
       relevantSections.push(`## Code Example\n\n${firstCodeExample[0]}`)
       this.logger.debug("Added first code example")
     }
@@ -652,7 +2594,7 @@ export class SearchUtils {
     if (relevantSections.length === 0) {
       this.logger.debug("No relevant sections found, returning truncated README")
       // Return the first 2000 characters of the README
-      return readme.substring(0, 2000) + "... (truncated)"
+      return truncateText(readme, 2000)
     }
 
     // Join the relevant sections
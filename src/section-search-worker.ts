@@ -0,0 +1,15 @@
+// Searches runs of a document's sections for SectionSearchPool, off the main thread
+import { parentPort } from "worker_threads"
+import { McpLogger } from "./logger.js"
+import { SearchUtils } from "./search-utils.js"
+import { SectionSearchTask, searchSections } from "./section-search.js"
+
+const searchUtils = new SearchUtils(new McpLogger("SearchWorker", true))
+
+parentPort?.on("message", (run: SectionSearchTask) => {
+  try {
+    parentPort?.postMessage({ matches: searchSections(searchUtils, run) })
+  } catch (error) {
+    parentPort?.postMessage({ error: error instanceof Error ? error.message : String(error) })
+  }
+})
@@ -0,0 +1,231 @@
+import { Worker } from "worker_threads"
+import { availableParallelism } from "os"
+import { McpLogger } from "./logger.js"
+import { SearchRankingOptions, SearchUtils } from "./search-utils.js"
+
+// Most search workers started by default, leaving a core for the server itself
+const DEFAULT_MAX_SEARCH_WORKERS = 4
+
+// Documents with fewer sections are searched on the main thread: they take a few milliseconds
+// either way, and copying them to workers costs about as much as it saves. bench/section-search.js
+// times both on the machine it runs on.
+const PARALLEL_SEARCH_MIN_SECTIONS = 200
+
+export interface SearchableSection {
+  content: string
+  type: string
+}
+
+export interface SectionSearchTask {
+  sections: SearchableSection[]
+  query: string
+  language: string
+  contextSize: number
+  // Fuse.js scores of the sections, for fuzzy searches. Each is averaged with the section's weighted
+  // score; without them only the sections matching the query are kept.
+  fuzzyScores?: number[]
+  ranking?: SearchRankingOptions // Weights of the weighted score, over DEFAULT_SEARCH_RANKING
+}
+
+export interface SectionMatch {
+  symbol?: string
+  match: string
+  context: string
+  score: number
+  type: string
+  source: string // Where the json format locates the match, removed from the results
+}
+
+/**
+ * Get the number of search workers from PACKAGE_DOCS_SEARCH_WORKERS, 0 searching every document on
+ * the main thread. Falls back to one per core after the first, up to 4, when unset or invalid.
+ */
+export function getSearchWorkers(value = process.env.PACKAGE_DOCS_SEARCH_WORKERS): number {
+  const workers = Number(value)
+  if (value !== undefined && value.trim() !== "" && Number.isInteger(workers) && workers >= 0) {
+    return workers
+  }
+  return Math.max(0, Math.min(DEFAULT_MAX_SEARCH_WORKERS, availableParallelism() - 1))
+}
+
+/**
+ * Describe each section matching the query: its symbol, heading, the lines around the match and a
+ * code example. Matches are returned in the order of the sections.
+ */
+export function searchSections(searchUtils: SearchUtils, task: SectionSearchTask): SectionMatch[] {
+  const { sections, query, language, contextSize, fuzzyScores, ranking } = task
+  const matches: SectionMatch[] = []
+
+  sections.forEach((section, index) => {
+    if (!fuzzyScores && !searchUtils.matchesQuery(section.content, query)) {
+      return
+    }
+
+    // Fuse.js doesn't weigh where the match is, so average in the section's weighted score
+    const weighted = searchUtils.scoreSectionMatch(section.content, query, ranking)
+    const score = fuzzyScores ? (fuzzyScores[index] + weighted) / 2 : weighted
+
+    // Extract more context around the match
+    const contextLines = searchUtils.extractContextAroundMatch(section.content, query, contextSize).split("\n")
+
+    // Include code examples in the context if present, preferring one in the package's language
+    const codeExample = searchUtils.extractCodeBlocks(section.content, language)[0] ||
+      searchUtils.extractCodeBlocks(section.content)[0]
+    if (codeExample && !contextLines.some(line => line.includes("```"))) {
+      contextLines.push("") // Add a blank line
+      contextLines.push("Code example:")
+      contextLines.push(searchUtils.formatCodeBlock(codeExample))
+    }
+
+    matches.push({
+      symbol: searchUtils.extractSymbol(section.content, language),
+      match: section.content.split("\n", 1)[0],
+      context: contextLines.join("\n"),
+      score,
+      type: section.type,
+      source: section.content
+    })
+  })
+
+  return matches
+}
+
+/**
+ * Searches the sections of large documents on a bounded pool of worker threads. A document is split
+ * into one run of sections per worker and the matches joined in order, so the results are those of
+ * searchSections. Workers are started as they're needed and don't keep the process alive; searches
+ * wait for a free worker, and a run whose worker fails is searched on the main thread instead.
+ */
+export class SectionSearchPool {
+  private workers: Worker[] = []
+  private idle: Worker[] = []
+  private waiting: Array<(worker: Worker | undefined) => void> = []
+  private logger: McpLogger
+
+  constructor(
+    private readonly searchUtils: SearchUtils,
+    logger: McpLogger,
+    private readonly size: number = getSearchWorkers(),
+    private readonly minSections: number = PARALLEL_SEARCH_MIN_SECTIONS
+  ) {
+    this.logger = logger.child("SectionSearch")
+  }
+
+  async search(task: SectionSearchTask): Promise<SectionMatch[]> {
+    if (this.size === 0 || task.sections.length < this.minSections) {
+      return searchSections(this.searchUtils, task)
+    }
+
+    const runLength = Math.ceil(task.sections.length / this.size)
+    const runs: SectionSearchTask[] = []
+    for (let start = 0; start < task.sections.length; start += runLength) {
+      runs.push({
+        ...task,
+        sections: task.sections.slice(start, start + runLength),
+        fuzzyScores: task.fuzzyScores?.slice(start, start + runLength)
+      })
+    }
+
+    const results = await Promise.all(runs.map(run => this.searchRun(run)))
+    return results.flat()
+  }
+
+  /**
+   * Stop the pool's workers, e.g. when the server shuts down
+   */
+  async close(): Promise<void> {
+    const workers = this.workers
+    this.workers = []
+    this.idle = []
+    await Promise.all(workers.map(worker => worker.terminate()))
+  }
+
+  private async searchRun(run: SectionSearchTask): Promise<SectionMatch[]> {
+    const worker = await this.acquire()
+    if (!worker) {
+      return searchSections(this.searchUtils, run)
+    }
+
+    try {
+      const matches = await this.post(worker, run)
+      this.release(worker)
+      return matches
+    } catch (error) {
+      this.logger.warn("Search worker failed, searching on the main thread:", error)
+      this.discard(worker)
+      return searchSections(this.searchUtils, run)
+    }
+  }
+
+  private acquire(): Promise<Worker | undefined> {
+    const worker = this.idle.pop()
+    if (worker) {
+      return Promise.resolve(worker)
+    }
+    if (this.workers.length < this.size) {
+      return Promise.resolve(this.spawn())
+    }
+    return new Promise(resolve => this.waiting.push(resolve))
+  }
+
+  private release(worker: Worker): void {
+    const next = this.waiting.shift()
+    if (next) {
+      next(worker)
+    } else {
+      worker.unref()
+      this.idle.push(worker)
+    }
+  }
+
+  private discard(worker: Worker): void {
+    this.workers = this.workers.filter(other => other !== worker)
+    void worker.terminate()
+    // Whoever was waiting for the worker gets a new one, or searches on the main thread
+    this.waiting.shift()?.(this.spawn())
+  }
+
+  private spawn(): Worker | undefined {
+    try {
+      const worker = new Worker(new URL("./section-search-worker.js", import.meta.url))
+      this.workers.push(worker)
+      return worker
+    } catch (error) {
+      this.logger.warn("Couldn't start a search worker:", error)
+      return undefined
+    }
+  }
+
+  private post(worker: Worker, run: SectionSearchTask): Promise<SectionMatch[]> {
+    return new Promise((resolve, reject) => {
+      const onMessage = (message: { matches?: SectionMatch[]; error?: string }) => {
+        cleanup()
+        if (message.matches) {
+          resolve(message.matches)
+        } else {
+          reject(new Error(message.error))
+        }
+      }
+      const onError = (error: Error) => {
+        cleanup()
+        reject(error)
+      }
+      const onExit = (code: number) => {
+        cleanup()
+        reject(new Error(`Search worker exited with code ${code}`))
+      }
+      const cleanup = () => {
+        worker.off("message", onMessage)
+        worker.off("error", onError)
+        worker.off("exit", onExit)
+      }
+
+      // Busy workers keep the process alive until their search is done
+      worker.ref()
+      worker.on("message", onMessage)
+      worker.on("error", onError)
+      worker.on("exit", onExit)
+      worker.postMessage(run)
+    })
+  }
+}
@@ -1,5 +1,40 @@
 import TypeScriptLspClient from './lsp/typescript-lsp-client.js'
 
+// Properties shared by several tools' input schemas
+
+const SOURCE_PROPERTY = {
+  type: "string",
+  enum: ["auto", "local", "network"],
+  description: "Where to look for documentation: \"auto\" (default) tries installed packages and local tools before the network, \"local\" and \"network\" only use one"
+}
+
+// Output format of the describe tools
+const DESCRIBE_FORMAT_PROPERTY = {
+  type: "string",
+  enum: ["markdown", "json"],
+  description: "Output format: 'markdown' (default) for rendered documentation, or 'json' for structured package metadata",
+  default: "markdown"
+}
+
+// The describe tools' option to return the whole README as well as the summary
+const RAW_README_PROPERTIES = {
+  includeRaw: {
+    type: "boolean",
+    description: "Also return the package's full README, verbatim, in a separate readme field after the summary",
+    default: false
+  },
+  rawMaxLength: {
+    type: "number",
+    description: "Optional maximum length of the raw README returned with includeRaw (default 20000)"
+  }
+}
+
+const INCLUDE_PRERELEASE_PROPERTY = {
+  type: "boolean",
+  description: "When no version is given, use the newest version even if it's a prerelease (default: false, the latest stable release)",
+  default: false
+}
+
 /**
  * Get tool definitions for the package docs server
  */
@@ -18,11 +53,11 @@ export function getToolDefinitions(lspEnabled: boolean, lspClient: TypeScriptLsp
           },
           query: {
             type: "string",
-            description: "Search query"
+            description: "Search query. Terms must all match; use OR between alternatives and \"quotes\" for exact phrases"
           },
           language: {
             type: "string",
-            enum: ["go", "python", "npm", "swift", "rust"],
+            enum: ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"],
             description: "Package language/ecosystem"
           },
           fuzzy: {
@@ -32,12 +67,74 @@ export function getToolDefinitions(lspEnabled: boolean, lspClient: TypeScriptLsp
           },
           projectPath: {
             type: "string",
-            description: "Optional path to project directory for local .npmrc files"
+            description: "Optional path to project directory for local .npmrc files and Python virtualenvs"
+          },
+          symbol: {
+            type: "string",
+            description: "Optional type, module or other symbol to search within (e.g. Client)"
+          },
+          source: SOURCE_PROPERTY,
+          contextSize: {
+            type: "number",
+            description: "Lines of context to include after each match, with half as many before (clamped to 2-50)",
+            minimum: 2,
+            maximum: 50,
+            default: 10
+          },
+          format: {
+            type: "string",
+            enum: ["markdown", "json"],
+            description: "Result format: 'markdown' (default) for snippets with highlighted matches, or 'json' for hits giving each match's section, score, verbatim context and offsets",
+            default: "markdown"
+          },
+          ranking: {
+            type: "object",
+            description: "Optional weights of the signals ranking the matching sections, each 0 or more (0 ignores it)",
+            properties: {
+              title: { type: "number", minimum: 0, description: "Boost for the query appearing in a section's heading", default: 2 },
+              position: { type: "number", minimum: 0, description: "Boost for matches near the start of a section", default: 1 },
+              frequency: { type: "number", minimum: 0, description: "Weight of how often the query appears, relative to the section's length", default: 1 },
+              proximity: { type: "number", minimum: 0, description: "Boost for the terms of a multi-term query appearing close together", default: 1 }
+            },
+            additionalProperties: false
           }
         },
         required: ["package", "query", "language"]
       }
     },
+    {
+      name: "search_packages",
+      description: "Search a package registry for packages matching a term, to find the right package before looking up its documentation. Results include download counts or, for Go, how many packages import each one",
+      inputSchema: {
+        type: "object",
+        properties: {
+          query: {
+            type: "string",
+            description: "Package name or keywords to search for"
+          },
+          language: {
+            type: "string",
+            enum: ["go", "python", "npm", "rust", "php", "java", "dotnet"],
+            description: "Package language/ecosystem"
+          },
+          limit: {
+            type: "number",
+            description: "Maximum number of packages to return (1-50)",
+            default: 10
+          },
+          page: {
+            type: "number",
+            description: "Page of results to return, each page holding limit packages",
+            default: 1
+          },
+          projectPath: {
+            type: "string",
+            description: "Optional path to project directory for local .npmrc files"
+          }
+        },
+        required: ["query", "language"]
+      }
+    },
     {
       name: "describe_go_package",
       description: "Get a brief description of a Go package",
@@ -46,7 +143,11 @@ export function getToolDefinitions(lspEnabled: boolean, lspClient: TypeScriptLsp
         properties: {
           package: {
             type: "string",
-            description: "Full package import path (e.g. encoding/json)",
+            description: "Full package import path (e.g. encoding/json), optionally with a version (e.g. golang.org/x/net@v0.17.0)",
+          },
+          version: {
+            type: "string",
+            description: "Optional module version (e.g. v0.17.0); defaults to the local or latest version",
           },
           symbol: {
             type: "string",
@@ -56,7 +157,16 @@ export function getToolDefinitions(lspEnabled: boolean, lspClient: TypeScriptLsp
           projectPath: {
             type: "string",
             description: "Optional path to project directory for local .npmrc files"
-          }
+          },
+          format: DESCRIBE_FORMAT_PROPERTY,
+          ...RAW_README_PROPERTIES,
+          raw: {
+            type: "boolean",
+            description: "Return go doc's output unmodified instead of splitting it into sections, for when the processed output is wrong",
+            default: false
+          },
+          includePrerelease: INCLUDE_PRERELEASE_PROPERTY,
+          source: SOURCE_PROPERTY
         },
         required: ["package"],
       },
@@ -76,6 +186,24 @@ export function getToolDefinitions(lspEnabled: boolean, lspClient: TypeScriptLsp
             description:
               "Optional crate version",
           },
+          target: {
+            type: "string",
+            description: "Optional platform target to show the documentation for (e.g. x86_64-pc-windows-msvc), for crates whose API differs by platform",
+          },
+          features: {
+            type: "array",
+            items: { type: "string" },
+            description: "Optional feature flags you need. Reports whether docs.rs documented the crate with them, as items gated behind other features are missing from its build",
+          },
+          format: DESCRIBE_FORMAT_PROPERTY,
+          ...RAW_README_PROPERTIES,
+          raw: {
+            type: "boolean",
+            description: "Return the crate's docs.rs page, converted to markdown, without summarising it, for when the processed output is wrong",
+            default: false
+          },
+          includePrerelease: INCLUDE_PRERELEASE_PROPERTY,
+          source: SOURCE_PROPERTY
         },
         required: ["package"],
       },
@@ -90,6 +218,10 @@ export function getToolDefinitions(lspEnabled: boolean, lspClient: TypeScriptLsp
             type: "string",
             description: "Package name (e.g. requests)",
           },
+          version: {
+            type: "string",
+            description: "Optional package version, read from PyPI (defaults to the installed or latest version)",
+          },
           symbol: {
             type: "string",
             description:
@@ -97,8 +229,22 @@ export function getToolDefinitions(lspEnabled: boolean, lspClient: TypeScriptLsp
           },
           projectPath: {
             type: "string",
-            description: "Optional path to project directory for local .npmrc files"
-          }
+            description: "Optional path to project directory, whose virtualenv (.venv, venv, or its Poetry or Pipenv environment) is used to find installed packages"
+          },
+          format: DESCRIBE_FORMAT_PROPERTY,
+          ...RAW_README_PROPERTIES,
+          raw: {
+            type: "boolean",
+            description: "Return pydoc's output for installed packages unmodified instead of splitting it into sections, for when the processed output is wrong",
+            default: false
+          },
+          includeApiReference: {
+            type: "boolean",
+            description: "Also read the API reference from the package's Sphinx documentation (e.g. on Read the Docs), when its PyPI project URLs link to it. Takes a few more requests",
+            default: false
+          },
+          includePrerelease: INCLUDE_PRERELEASE_PROPERTY,
+          source: SOURCE_PROPERTY
         },
         required: ["package"],
       },
@@ -120,7 +266,16 @@ export function getToolDefinitions(lspEnabled: boolean, lspClient: TypeScriptLsp
           projectPath: {
             type: "string",
             description: "Optional path to project directory for local .npmrc files"
-          }
+          },
+          profile: {
+            type: "string",
+            enum: ["consumer", "contributor", "full"],
+            description: "Optional README relevance profile: 'consumer' (usage and API), 'contributor' (also contributing, architecture and development sections) or 'full' (everything)"
+          },
+          format: DESCRIBE_FORMAT_PROPERTY,
+          ...RAW_README_PROPERTIES,
+          includePrerelease: INCLUDE_PRERELEASE_PROPERTY,
+          source: SOURCE_PROPERTY
         },
         required: ["package"],
       },
@@ -142,7 +297,73 @@ export function getToolDefinitions(lspEnabled: boolean, lspClient: TypeScriptLsp
           projectPath: {
             type: "string",
             description: "Optional path to project directory for Package.swift file"
-          }
+          },
+          format: DESCRIBE_FORMAT_PROPERTY,
+          ...RAW_README_PROPERTIES,
+          source: SOURCE_PROPERTY
+        },
+        required: ["package"],
+      },
+    },
+    {
+      name: "describe_php_package",
+      description: "Get a brief description of a PHP (Composer) package",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "Composer package name in vendor/package form (e.g. monolog/monolog)",
+          },
+          version: {
+            type: "string",
+            description: "Optional package version",
+          },
+          format: DESCRIBE_FORMAT_PROPERTY,
+          ...RAW_README_PROPERTIES,
+          source: SOURCE_PROPERTY
+        },
+        required: ["package"],
+      },
+    },
+    {
+      name: "describe_java_package",
+      description: "Get a brief description of a Java (Maven Central) package",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "Maven coordinates in groupId:artifactId form (e.g. com.google.guava:guava)",
+          },
+          version: {
+            type: "string",
+            description: "Optional artifact version",
+          },
+          format: DESCRIBE_FORMAT_PROPERTY,
+          ...RAW_README_PROPERTIES,
+          source: SOURCE_PROPERTY
+        },
+        required: ["package"],
+      },
+    },
+    {
+      name: "describe_dotnet_package",
+      description: "Get a brief description of a .NET (NuGet) package",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "NuGet package ID, case-insensitive (e.g. Newtonsoft.Json)",
+          },
+          version: {
+            type: "string",
+            description: "Optional package version",
+          },
+          format: DESCRIBE_FORMAT_PROPERTY,
+          ...RAW_README_PROPERTIES,
+          source: SOURCE_PROPERTY
         },
         required: ["package"],
       },
@@ -171,7 +392,7 @@ export function getToolDefinitions(lspEnabled: boolean, lspClient: TypeScriptLsp
           },
           maxLength: {
             type: "number",
-            description: "Optional maximum length of the returned documentation"
+            description: "Optional maximum length of the returned documentation. Whole trailing sections are dropped to fit, with a note of how many"
           },
           query: {
             type: "string",
@@ -181,6 +402,376 @@ export function getToolDefinitions(lspEnabled: boolean, lspClient: TypeScriptLsp
         required: ["package"],
       },
     },
+    {
+      name: "get_package_doc",
+      description: "Get full documentation for a package, optionally narrowed to a section or search query",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "Package name, import path or Swift package URL (e.g. encoding/json, requests, axios)",
+          },
+          language: {
+            type: "string",
+            enum: ["go", "python", "npm", "swift", "rust"],
+            description: "Package language/ecosystem",
+          },
+          version: {
+            type: "string",
+            description: "Optional package version",
+          },
+          includePrerelease: INCLUDE_PRERELEASE_PROPERTY,
+          projectPath: {
+            type: "string",
+            description: "Optional path to project directory (module root for Go, local .npmrc files for NPM)"
+          },
+          parent: {
+            type: "string",
+            description: "Optional package (name or name@version) that depends on this one; documents the version the parent uses, from the project's lockfile or the parent's pinned requirements"
+          },
+          section: {
+            type: "string",
+            description: "Optional section to retrieve (e.g. 'functions', 'types', 'installation', 'api')"
+          },
+          maxLength: {
+            type: "number",
+            description: "Optional maximum length of the returned documentation. Whole trailing sections are dropped to fit, with a note of how many"
+          },
+          query: {
+            type: "string",
+            description: "Optional search query to filter documentation content"
+          },
+          include: {
+            type: "array",
+            items: { type: "string" },
+            description: "Optional heading keywords; only matching sections (and their subsections) are returned, e.g. [\"configuration\", \"migration\"]"
+          },
+          exclude: {
+            type: "array",
+            items: { type: "string" },
+            description: "Optional heading keywords for sections to drop, e.g. [\"benchmarks\"]. Takes precedence over include"
+          },
+          minLevel: {
+            type: "number",
+            description: "Optional heading level; markdown sections with shallower headings are kept even when they don't match include"
+          },
+          stripHeadingEmoji: {
+            type: "boolean",
+            description: "Optional; remove emoji and emoji shortcodes from section headings, e.g. '🚀 Getting Started' becomes 'Getting Started' (default: false)"
+          }
+        },
+        required: ["package", "language"],
+      },
+    },
+    {
+      name: "get_config_docs",
+      description: "Get the documentation for a package's configuration file format and options, with example config snippets",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "Package name (e.g. eslint)",
+          },
+          language: {
+            type: "string",
+            enum: ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"],
+            description: "Package language/ecosystem",
+          },
+          projectPath: {
+            type: "string",
+            description: "Optional path to project directory for local .npmrc files"
+          }
+        },
+        required: ["package", "language"],
+      },
+    },
+    {
+      name: "get_package_examples",
+      description: "Get the code examples from a package's README, each labelled with the operation it demonstrates (e.g. \"Creating a client\")",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "Package name, import path or Swift package URL",
+          },
+          language: {
+            type: "string",
+            enum: ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"],
+            description: "Package language/ecosystem",
+          },
+          projectPath: {
+            type: "string",
+            description: "Optional path to project directory for local .npmrc files"
+          },
+          maxExamples: {
+            type: "number",
+            description: "Optional maximum number of examples to return, preferring those in example and usage sections (default: every example)"
+          }
+        },
+        required: ["package", "language"],
+      },
+    },
+    {
+      name: "get_compatibility",
+      description: "Get the versions of runtimes, frameworks and platforms a package supports (e.g. \"works with React 16/17/18\"), from the compatibility tables and requirements in its README",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "Package name, import path or Swift package URL",
+          },
+          language: {
+            type: "string",
+            enum: ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"],
+            description: "Package language/ecosystem",
+          },
+          projectPath: {
+            type: "string",
+            description: "Optional path to project directory for local .npmrc files"
+          }
+        },
+        required: ["package", "language"],
+      },
+    },
+    {
+      name: "compare_versions",
+      description: "Compare two versions of a package, listing dependencies added, removed or changed and licence or deprecation changes",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "Package name (e.g. axios)",
+          },
+          language: {
+            type: "string",
+            enum: ["python", "npm", "rust", "php", "java", "dotnet"],
+            description: "Package language/ecosystem",
+          },
+          fromVersion: {
+            type: "string",
+            description: "Version currently in use",
+          },
+          toVersion: {
+            type: "string",
+            description: "Version to compare against",
+          },
+          projectPath: {
+            type: "string",
+            description: "Optional path to project directory for local .npmrc files"
+          }
+        },
+        required: ["package", "language", "fromVersion", "toVersion"],
+      },
+    },
+    {
+      name: "compare_api",
+      description: "Show what changed in a package's public API between two versions: added, removed and changed exported symbols",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "Package name or Go import path",
+          },
+          language: {
+            type: "string",
+            enum: ["go", "npm", "rust"],
+            description: "Package language/ecosystem",
+          },
+          fromVersion: {
+            type: "string",
+            description: "The version to compare from",
+          },
+          toVersion: {
+            type: "string",
+            description: "The version to compare to",
+          },
+        },
+        required: ["package", "language", "fromVersion", "toVersion"],
+      },
+    },
+    {
+      name: "list_package_symbols",
+      description: "List a package's exported symbols (functions, types, classes, constants), grouped by kind, to find one to look up with a describe tool's symbol argument",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "Package name or Go import path",
+          },
+          language: {
+            type: "string",
+            enum: ["go", "python", "npm", "rust"],
+            description: "Package language/ecosystem. Go and Python packages must be installed locally",
+          },
+          version: {
+            type: "string",
+            description: "Optional version for npm and Rust packages (defaults to the latest)",
+          },
+          projectPath: {
+            type: "string",
+            description: "Optional path to the project whose installed Go or Python packages to list from",
+          },
+        },
+        required: ["package", "language"],
+      },
+    },
+    {
+      name: "describe_project_dependencies",
+      description: "Summarise a project's direct dependencies from its manifests (package.json, go.mod, requirements.txt, pyproject.toml, Cargo.toml), with the current version, a one-line description and a docs link for each, grouped by ecosystem",
+      inputSchema: {
+        type: "object",
+        properties: {
+          projectPath: {
+            type: "string",
+            description: "Path to the project. Manifests in the project and the directories directly below it (monorepo packages) are read",
+          },
+          includeDev: {
+            type: "boolean",
+            description: "Include dev dependencies (default: false)",
+          },
+        },
+        required: ["projectPath"],
+      },
+    },
+    {
+      name: "get_package_changelog",
+      description: "Get a package's changelog from its repository, optionally only the entry for a specific version",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "Package name, import path or Swift package URL",
+          },
+          language: {
+            type: "string",
+            enum: ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"],
+            description: "Package language/ecosystem",
+          },
+          version: {
+            type: "string",
+            description: "Optional version whose changelog entry to return",
+          },
+          projectPath: {
+            type: "string",
+            description: "Optional path to project directory for local .npmrc files"
+          },
+          maxLength: {
+            type: "number",
+            description: "Optional maximum length of the returned changelog. Whole trailing entries are dropped to fit"
+          }
+        },
+        required: ["package", "language"],
+      },
+    },
+    {
+      name: "get_migration_guide",
+      description: "Get only the guidance for upgrading a package: migration sections of its README, its migration guide file and the breaking changes listed in its changelog since a version",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "Package name, import path or Swift package URL",
+          },
+          language: {
+            type: "string",
+            enum: ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"],
+            description: "Package language/ecosystem",
+          },
+          fromVersion: {
+            type: "string",
+            description: "Optional version being upgraded from. Only breaking changes and migration sections after it are returned",
+          },
+          toVersion: {
+            type: "string",
+            description: "Optional version being upgraded to. Breaking changes in later versions are left out",
+          },
+          projectPath: {
+            type: "string",
+            description: "Optional path to project directory for local .npmrc files"
+          },
+          maxLength: {
+            type: "number",
+            description: "Optional maximum length of the returned guide. Whole trailing sections are dropped to fit"
+          }
+        },
+        required: ["package", "language"],
+      },
+    },
+    {
+      name: "get_package_dependencies",
+      description: "Get a package's transitive dependencies as a tree, with the requirement each parent declares and the version it resolves to",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "Package name",
+          },
+          language: {
+            type: "string",
+            enum: ["npm", "python", "rust"],
+            description: "Package language/ecosystem",
+          },
+          version: {
+            type: "string",
+            description: "Optional version or version range of the package (defaults to the latest release)",
+          },
+          depth: {
+            type: "number",
+            description: "Optional number of dependency levels to list (default 2, at most 10)",
+          },
+          maxNodes: {
+            type: "number",
+            description: "Optional maximum number of packages in the tree (default 100, at most 500)",
+          },
+          projectPath: {
+            type: "string",
+            description: "Optional path to project directory for local .npmrc files"
+          }
+        },
+        required: ["package", "language"],
+      },
+    },
+    {
+      name: "get_schema",
+      description: "Get the OpenAPI spec or JSON Schema a package ships in its repository, such as openapi.yaml or schema.json",
+      inputSchema: {
+        type: "object",
+        properties: {
+          package: {
+            type: "string",
+            description: "Package name, import path or Swift package URL",
+          },
+          language: {
+            type: "string",
+            enum: ["go", "python", "npm", "swift", "rust", "php", "java", "dotnet"],
+            description: "Package language/ecosystem",
+          },
+          path: {
+            type: "string",
+            description: "Optional path of a schema file in the repository, instead of looking for one",
+          },
+          projectPath: {
+            type: "string",
+            description: "Optional path to project directory for local .npmrc files"
+          },
+          maxLength: {
+            type: "number",
+            description: "Optional maximum length of the returned schema (default 50000)"
+          }
+        },
+        required: ["package", "language"],
+      },
+    },
   ]
 
   // Add legacy tools for backward compatibility
@@ -193,7 +784,11 @@ export function getToolDefinitions(lspEnabled: boolean, lspClient: TypeScriptLsp
         properties: {
           package: {
             type: "string",
-            description: "Full package import path (e.g. encoding/json)",
+            description: "Full package import path (e.g. encoding/json), optionally with a version (e.g. golang.org/x/net@v0.17.0)",
+          },
+          version: {
+            type: "string",
+            description: "Optional module version (e.g. v0.17.0); defaults to the local or latest version",
           },
           symbol: {
             type: "string",
@@ -225,7 +820,7 @@ export function getToolDefinitions(lspEnabled: boolean, lspClient: TypeScriptLsp
           },
           projectPath: {
             type: "string",
-            description: "Optional path to project directory for local .npmrc files"
+            description: "Optional path to project directory, whose virtualenv (.venv, venv, or its Poetry or Pipenv environment) is used to find installed packages"
           }
         },
         required: ["package"],
@@ -40,6 +40,7 @@ export interface CrateVersion {
 	version: string;
 	isYanked: boolean;
 	releaseDate?: string;
+	license?: string;
 }
 
 export interface SymbolDefinition {
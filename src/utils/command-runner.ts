@@ -0,0 +1,202 @@
+import { spawn } from 'child_process';
+import { existsSync, realpathSync } from 'fs';
+import { basename, delimiter, dirname, isAbsolute, join, resolve, sep } from 'path';
+
+// Output kept from each of a command's stdout and stderr by default, enough for go doc -all on large modules
+const DEFAULT_MAX_OUTPUT_BYTES = 10 * 1024 * 1024;
+
+/**
+ * Get the output cap from PACKAGE_DOCS_MAX_COMMAND_OUTPUT, in bytes, falling back to the default when unset or invalid
+ */
+export function getMaxCommandOutput(value = process.env.PACKAGE_DOCS_MAX_COMMAND_OUTPUT): number {
+  const limit = Number(value);
+  return Number.isInteger(limit) && limit > 0 ? limit : DEFAULT_MAX_OUTPUT_BYTES;
+}
+
+// The executables the server runs by default: Go, Python and its environment managers, and the Rust, Swift and npm toolchains
+const DEFAULT_ALLOWED_COMMANDS = ['go', 'pip', 'python', 'python3', 'poetry', 'pipenv', 'cargo', 'rustup', 'swift', 'npm'];
+
+/**
+ * Get the allowed commands from PACKAGE_DOCS_ALLOWED_COMMANDS (comma separated executable names),
+ * falling back to the default allowlist when unset
+ */
+export function getAllowedCommands(value = process.env.PACKAGE_DOCS_ALLOWED_COMMANDS): string[] {
+  if (value === undefined || value.trim() === '') {
+    return DEFAULT_ALLOWED_COMMANDS;
+  }
+  return value.split(',').map(command => command.trim()).filter(Boolean);
+}
+
+/**
+ * Get the directories commands given as a path may run from: those listed in
+ * PACKAGE_DOCS_ALLOWED_COMMAND_DIRS (separated like PATH), and the bin directory of the
+ * virtualenv or conda environment the server itself was started in
+ */
+export function getAllowedCommandDirectories(
+  value = process.env.PACKAGE_DOCS_ALLOWED_COMMAND_DIRS,
+  environments = [process.env.VIRTUAL_ENV, process.env.CONDA_PREFIX]
+): string[] {
+  const listed = (value ?? '').split(delimiter).map(directory => directory.trim()).filter(Boolean);
+  const binDir = process.platform === 'win32' ? 'Scripts' : 'bin';
+  const active = environments.filter((env): env is string => !!env).map(env => join(env, binDir));
+  return [...listed, ...active];
+}
+
+let allowedCommands = new Set(getAllowedCommands());
+let allowedDirectories = getAllowedCommandDirectories();
+
+/**
+ * Restrict runCommand to the given executables, and commands given as a path to the given
+ * directories (and the directories below them)
+ */
+export function setAllowedCommands(commands: string[], directories: string[] = getAllowedCommandDirectories()): void {
+  allowedCommands = new Set(commands);
+  allowedDirectories = directories;
+}
+
+// The name a command is allowed by: its file name, without the .exe extension on Windows
+function commandName(command: string): string {
+  return basename(command).replace(/\.exe$/i, '');
+}
+
+// A path with its symlinked directories resolved, so ../ and links can't step outside an allowed directory
+function realDirectory(path: string): string | undefined {
+  try {
+    return realpathSync(path);
+  } catch {
+    return undefined;
+  }
+}
+
+/**
+ * Check whether runCommand may run a command. A bare name (go, python3) must be on the allowlist
+ * and is found on the server's PATH. A path, such as a project's .venv/bin/python, must also be
+ * in an allowed directory, as a tool call's projectPath decides where it points.
+ */
+export function isCommandAllowed(command: string): boolean {
+  if (!allowedCommands.has(commandName(command))) {
+    return false;
+  }
+  if (!isAbsolute(command) && !/[\\/]/.test(command)) {
+    return true;
+  }
+
+  const directory = realDirectory(dirname(resolve(command)));
+  return directory !== undefined && allowedDirectories.some(allowed => {
+    const root = realDirectory(resolve(allowed));
+    return root !== undefined && (directory === root || directory.startsWith(`${root}${sep}`));
+  });
+}
+
+// How to run an external command such as go, python or swift
+export interface CommandOptions {
+  args: string[];
+  cwd?: string; // Working directory, e.g. a tool call's projectPath, instead of the server's
+  env?: Record<string, string | undefined>; // Set on top of the server's environment; undefined unsets a variable
+  timeout?: number;
+  maxOutputBytes?: number; // Cap on each of stdout and stderr, getMaxCommandOutput() by default
+}
+
+export interface CommandResult {
+  stdout: string;
+  stderr: string;
+  truncated: boolean; // Whether the output reached the cap, so the command was stopped early
+}
+
+// A failed command, with whatever output it produced before failing
+export class CommandError extends Error {
+  constructor(
+    message: string,
+    readonly code: number | string | null,
+    readonly stdout: string,
+    readonly stderr: string
+  ) {
+    super(message);
+    this.name = 'CommandError';
+  }
+}
+
+// A command whose executable isn't installed or isn't on the PATH, as opposed to one that ran and failed,
+// so callers can report "go not found, using pkg.go.dev" rather than a command error
+export class ToolNotInstalledError extends Error {
+  constructor(readonly command: string) {
+    super(`${command} is not installed or not on the PATH`);
+    this.name = 'ToolNotInstalledError';
+  }
+}
+
+// A command outside the allowlist, which is refused without being run
+export class CommandNotAllowedError extends Error {
+  constructor(readonly command: string) {
+    super(`${command} is not an allowed command (see PACKAGE_DOCS_ALLOWED_COMMANDS and PACKAGE_DOCS_ALLOWED_COMMAND_DIRS)`);
+    this.name = 'CommandNotAllowedError';
+  }
+}
+
+/**
+ * Run a command directly rather than through a shell, so its arguments are never interpreted,
+ * in the given working directory and with the given variables added to the server's environment.
+ * Output beyond the cap isn't kept: the command is stopped and what was captured is returned with
+ * a note that it was truncated, rather than failing or holding all of it in memory.
+ * Only commands on the allowlist are run.
+ */
+export function runCommand(command: string, options: CommandOptions): Promise<CommandResult> {
+  const { args, cwd, env, timeout, maxOutputBytes = getMaxCommandOutput() } = options;
+
+  return new Promise((resolve, reject) => {
+    if (!isCommandAllowed(command)) {
+      reject(new CommandNotAllowedError(command));
+      return;
+    }
+
+    // spawn reports a missing working directory as ENOENT too, which would read as a missing executable
+    if (cwd && !existsSync(cwd)) {
+      reject(new CommandError(`Command failed: working directory ${cwd} does not exist`, 'ENOENT', '', ''));
+      return;
+    }
+
+    const child = spawn(command, args, {
+      cwd,
+      env: env ? { ...process.env, ...env } : undefined,
+      timeout,
+    });
+
+    const output = { stdout: [] as Buffer[], stderr: [] as Buffer[] };
+    const sizes = { stdout: 0, stderr: 0 };
+    let truncated = false;
+
+    const collect = (stream: 'stdout' | 'stderr') => (chunk: Buffer) => {
+      const remaining = maxOutputBytes - sizes[stream];
+      if (remaining <= 0) return;
+      output[stream].push(remaining < chunk.length ? chunk.subarray(0, remaining) : chunk);
+      sizes[stream] += Math.min(chunk.length, remaining);
+      if (chunk.length >= remaining && !truncated) {
+        truncated = true;
+        child.kill();
+      }
+    };
+    child.stdout.on('data', collect('stdout'));
+    child.stderr.on('data', collect('stderr'));
+
+    child.on('error', error => {
+      reject((error as NodeJS.ErrnoException).code === 'ENOENT' ? new ToolNotInstalledError(command) : error);
+    });
+    child.on('close', (code, signal) => {
+      const marker = truncated ? `\n\n[Output truncated at ${maxOutputBytes} bytes]` : '';
+      const stdout = Buffer.concat(output.stdout).toString('utf8') + (sizes.stdout >= maxOutputBytes ? marker : '');
+      const stderr = Buffer.concat(output.stderr).toString('utf8') + (sizes.stderr >= maxOutputBytes ? marker : '');
+
+      // A command stopped for producing too much output still produced what was captured
+      if (truncated || code === 0) {
+        resolve({ stdout, stderr, truncated });
+        return;
+      }
+
+      const commandLine = [command, ...args].join(' ');
+      const reason = signal
+        ? `was stopped by ${signal}${timeout ? ` (timeout ${timeout}ms)` : ''}`
+        : `exited with code ${code}`;
+      reject(new CommandError(`Command failed: ${commandLine} ${reason}${stderr ? `\n${stderr.trim()}` : ''}`, code ?? signal, stdout, stderr));
+    });
+  });
+}
@@ -0,0 +1,52 @@
+// Tool calls run at once by default; more are queued until one finishes
+const DEFAULT_MAX_CONCURRENT_CALLS = 4;
+
+/**
+ * Get the concurrency limit from PACKAGE_DOCS_MAX_CONCURRENT_CALLS, falling back to the default when unset or invalid
+ */
+export function getMaxConcurrentCalls(value = process.env.PACKAGE_DOCS_MAX_CONCURRENT_CALLS): number {
+  const limit = Number(value);
+  return Number.isInteger(limit) && limit > 0 ? limit : DEFAULT_MAX_CONCURRENT_CALLS;
+}
+
+/**
+ * Limits how many tasks run at once, starting queued tasks in the order they arrived as others finish.
+ * Each tool call can spawn subprocesses and several HTTP requests, so a burst of calls is spread out.
+ */
+export class ConcurrencyGate {
+  private active = 0;
+  private queue: Array<() => void> = [];
+
+  constructor(private readonly limit: number = getMaxConcurrentCalls()) {}
+
+  /**
+   * Run a task once there's capacity for it
+   */
+  async run<T>(task: () => Promise<T>): Promise<T> {
+    if (this.active >= this.limit) {
+      await new Promise<void>(resolve => this.queue.push(resolve));
+    } else {
+      this.active++;
+    }
+
+    try {
+      return await task();
+    } finally {
+      // Hand the slot straight to the next queued task, so new arrivals can't overtake it
+      const next = this.queue.shift();
+      if (next) {
+        next();
+      } else {
+        this.active--;
+      }
+    }
+  }
+
+  get running(): number {
+    return this.active;
+  }
+
+  get waiting(): number {
+    return this.queue.length;
+  }
+}
@@ -0,0 +1,105 @@
+/**
+ * Get the Authorization header for GitHub requests from GITHUB_TOKEN (or GH_TOKEN, as the gh CLI
+ * uses), which raises the API rate limit from 60 to 5000 requests an hour and gives access to
+ * private repositories. Empty when neither is set.
+ */
+export function getGitHubAuthHeaders(value = process.env.GITHUB_TOKEN || process.env.GH_TOKEN): Record<string, string> {
+  const token = value?.trim();
+  return token ? { Authorization: `Bearer ${token}` } : {};
+}
+
+// A dependency installed from a git repository rather than a registry
+export interface GitPackageSpec {
+  repositoryUrl: string; // https URL of the repository, without a .git suffix
+  ref?: string; // Branch, tag or commit after the # in the spec
+}
+
+/**
+ * Parse an npm dependency spec that points at a git repository, such as
+ * git+https://github.com/owner/repo.git#v1.2.0, git@github.com:owner/repo.git, github:owner/repo
+ * or the owner/repo shorthand. Returns undefined for registry package names.
+ */
+export function parseGitPackageSpec(spec: string): GitPackageSpec | undefined {
+  const [location, ref] = spec.trim().split('#', 2);
+  const withRef = (repositoryUrl: string): GitPackageSpec => ({
+    repositoryUrl: repositoryUrl.replace(/\.git$/, '').replace(/\/$/, ''),
+    ref: ref || undefined,
+  });
+
+  const hosted = location.match(/^(github|gitlab|bitbucket):([\w.-]+\/[\w.-]+)$/);
+  if (hosted) {
+    const host = hosted[1] === 'bitbucket' ? 'bitbucket.org' : `${hosted[1]}.com`;
+    return withRef(`https://${host}/${hosted[2]}`);
+  }
+
+  // scp-like ssh URLs, e.g. git@github.com:owner/repo.git
+  const scp = location.match(/^(?:git\+ssh:\/\/)?[\w.-]+@([\w.-]+):(?!\d+\/)([\w.-]+\/[\w.-]+)$/);
+  if (scp) {
+    return withRef(`https://${scp[1]}/${scp[2]}`);
+  }
+
+  const url = location.match(/^(?:git\+)?(?:https?|ssh|git):\/\/(?:[\w.-]+@)?([\w.-]+)(?::\d+)?\/([\w.-]+\/[\w.-]+)/);
+  if (url && (location.startsWith('git') || location.startsWith('ssh') || location.endsWith('.git') || ref)) {
+    return withRef(`https://${url[1]}/${url[2]}`);
+  }
+
+  // npm treats owner/repo as a GitHub shorthand; scoped packages start with @
+  const shorthand = location.match(/^([\w-][\w.-]*\/[\w.-]+)$/);
+  if (shorthand) {
+    return withRef(`https://github.com/${shorthand[1]}`);
+  }
+
+  return undefined;
+}
+
+// A package's source repository, normalised from whichever URL form its registry records
+export interface RepositoryRef {
+  host: string; // e.g. github.com
+  owner: string;
+  repo: string;
+  url: string; // https://host/owner/repo
+}
+
+/**
+ * Normalise a repository URL as found in package metadata: https and git URLs (with or without
+ * git+ and .git), scp-like ssh URLs, links to a path within the repository (e.g. /tree/main/pkg)
+ * and npm shorthands such as github:owner/repo
+ */
+export function parseRepositoryUrl(value: string): RepositoryRef | undefined {
+  const toRef = (host: string, owner: string, repo: string): RepositoryRef | undefined => {
+    const name = repo.replace(/\.git$/, '');
+    return owner && name
+      ? { host: host.toLowerCase(), owner, repo: name, url: `https://${host.toLowerCase()}/${owner}/${name}` }
+      : undefined;
+  };
+
+  const trimmed = value.trim();
+  const url = trimmed.match(/^(?:git\+)?(?:https?|git|ssh):\/\/(?:[^@/]+@)?([^/:]+)(?::\d+)?\/([^/]+)\/([^/#?]+)/);
+  if (url) {
+    return toRef(url[1], url[2], url[3]);
+  }
+
+  const spec = parseGitPackageSpec(trimmed);
+  const specUrl = spec?.repositoryUrl.match(/^https:\/\/([^/]+)\/([^/]+)\/([^/]+)/);
+  return specUrl ? toRef(specUrl[1], specUrl[2], specUrl[3]) : undefined;
+}
+
+/**
+ * Get the repository for a Go import path. Vanity paths for the Go project and gopkg.in are mapped
+ * to their GitHub repositories; other paths are assumed to start with host/owner/repo.
+ */
+export function getGoRepositoryUrl(importPath: string): RepositoryRef | undefined {
+  const parts = importPath.replace(/^https?:\/\//, '').split('/');
+
+  if (parts[0] === 'golang.org' && parts[1] === 'x' && parts[2]) {
+    return parseRepositoryUrl(`https://github.com/golang/${parts[2]}`);
+  }
+  if (parts[0] === 'gopkg.in' && parts[1]) {
+    // gopkg.in/pkg.v3 is github.com/go-pkg/pkg, and gopkg.in/user/pkg.v3 is github.com/user/pkg
+    return parts[2]
+      ? parseRepositoryUrl(`https://github.com/${parts[1]}/${parts[2].replace(/\.v\d+$/, '')}`)
+      : parseRepositoryUrl(`https://github.com/go-${parts[1].replace(/\.v\d+$/, '')}/${parts[1].replace(/\.v\d+$/, '')}`);
+  }
+
+  return parts.length >= 3 ? parseRepositoryUrl(`https://${parts.slice(0, 3).join('/')}`) : undefined;
+}
@@ -0,0 +1,336 @@
+import * as cheerio from 'cheerio';
+import { MarkdownTable } from '../search-utils.js';
+
+type CheerioSelection = ReturnType<cheerio.CheerioAPI>;
+
+// Containers of the main content on common documentation sites, tried in order as the fast path
+export const DEFAULT_MAIN_CONTENT_SELECTORS = [
+  'main',
+  '[role="main"]',
+  '#main-content',
+  '[role="document"]',
+  'article',
+  '.Documentation-content', // pkg.go.dev
+  '.rustdoc', // docs.rs
+  '.markdown-body', // GitHub
+  '.theme-doc-markdown', // Docusaurus
+  '.VPDoc', // VitePress
+  '.md-content', // MkDocs Material
+  '[itemprop="articleBody"]', // Sphinx
+  '#content',
+  '.content',
+];
+
+// Elements that never hold documentation text
+const NON_CONTENT_SELECTORS = 'script, style, noscript, svg, template, nav, header, footer, aside';
+
+// Containers with less text than this are too small to be the main content
+const MIN_CONTENT_LENGTH = 200;
+
+/**
+ * Get the main content selectors, from PACKAGE_DOCS_CONTENT_SELECTORS (a comma separated list)
+ * followed by the defaults
+ */
+export function getMainContentSelectors(value = process.env.PACKAGE_DOCS_CONTENT_SELECTORS): string[] {
+  const custom = (value || '').split(',').map(selector => selector.trim()).filter(Boolean);
+  return [...custom, ...DEFAULT_MAIN_CONTENT_SELECTORS];
+}
+
+/**
+ * Score an element readability-style: its text length, discounted by the share of that text in links,
+ * so navigation menus and link lists score low however long they are
+ */
+function scoreContent(element: CheerioSelection): number {
+  const textLength = element.text().replace(/\s+/g, ' ').trim().length;
+  if (textLength < MIN_CONTENT_LENGTH) {
+    return 0;
+  }
+
+  const linkLength = element.find('a').text().replace(/\s+/g, ' ').trim().length;
+  const paragraphs = element.find('p, pre, li').length;
+  return textLength * (1 - linkLength / textLength) + paragraphs * 25;
+}
+
+/**
+ * Extract the HTML of a documentation page's main content, without scripts, styles or navigation.
+ * The first of the selectors matching a container with enough text wins; otherwise the densest
+ * candidate element by text-to-link ratio is picked, and failing that the whole body is returned.
+ */
+export function extractMainContent(html: string, selectors: string[] = getMainContentSelectors()): string {
+  const $ = cheerio.load(html);
+  $(NON_CONTENT_SELECTORS).remove();
+
+  for (const selector of selectors) {
+    try {
+      const element = $(selector).first();
+      if (element.length > 0 && element.text().trim().length >= MIN_CONTENT_LENGTH) {
+        return element.html() || '';
+      }
+    } catch {
+      // Skip invalid custom selectors
+    }
+  }
+
+  // Start from the densest candidate, then narrow down to the innermost container that still holds
+  // most of its content, as an ancestor always has at least the text of its descendants
+  let best: CheerioSelection | undefined;
+  let bestScore = 0;
+  for (const node of $('div, section, td').toArray()) {
+    const score = scoreContent($(node));
+    if (score > bestScore) {
+      best = $(node);
+      bestScore = score;
+    }
+  }
+
+  while (best) {
+    const child = best.children('div, section, article').toArray()
+      .map(node => ({ element: $(node), score: scoreContent($(node)) }))
+      .find(candidate => candidate.score >= bestScore * 0.8);
+    if (!child) break;
+    best = child.element;
+    bestScore = child.score;
+  }
+
+  return best?.html() || $('body').html() || html;
+}
+
+/**
+ * Resolve the relative links and image sources in an HTML page against the URL it was fetched from
+ * (or its <base href>), so they still work once the content is returned out of context
+ */
+export function resolveRelativeLinks(html: string, pageUrl: string): string {
+  const $ = cheerio.load(html);
+
+  let baseUrl = pageUrl;
+  const baseHref = $('base[href]').attr('href');
+  if (baseHref) {
+    try {
+      baseUrl = new URL(baseHref, pageUrl).toString();
+    } catch {
+      // Keep the page URL when the base is invalid
+    }
+  }
+
+  for (const [selector, attribute] of [['a[href]', 'href'], ['img[src]', 'src']]) {
+    $(selector).each((_, element) => {
+      const value = $(element).attr(attribute);
+      // Leave absolute URLs alone, along with schemes such as mailto: and javascript:
+      if (!value || /^[a-z][a-z\d+.-]*:/i.test(value)) {
+        return;
+      }
+      try {
+        $(element).attr(attribute, new URL(value, baseUrl).toString());
+      } catch {
+        // Leave unparseable links as they are
+      }
+    });
+  }
+
+  return $.html();
+}
+
+// A code block or inline code span from an HTML page
+export interface HtmlCodeBlock {
+  language?: string; // From a language-* or lang-* class, as used by most highlighters
+  code: string;
+  inline: boolean; // A <code> span in running text rather than a <pre> block
+}
+
+/**
+ * Extract the code from an HTML page, optionally only within the elements matching a selector.
+ * A <pre> block is extracted once, including any <code> inside it; only <code> outside a <pre> is inline.
+ */
+export function extractHtmlCodeBlocks(html: string, within?: string): HtmlCodeBlock[] {
+  const $ = cheerio.load(html);
+  const scope = within ? $(within) : $.root();
+  const languageOf = (element: CheerioSelection) =>
+    ((element.attr('class') || '') + ' ' + (element.find('code').attr('class') || ''))
+      .match(/\b(?:language|lang)-([\w+#-]+)/)?.[1];
+
+  const blocks: HtmlCodeBlock[] = [];
+  scope.find('pre').addBack('pre').each((_, node) => {
+    const element = $(node);
+    blocks.push({ language: languageOf(element), code: element.text().replace(/\n$/, ''), inline: false });
+  });
+  scope.find('code').addBack('code').filter((_, node) => $(node).closest('pre').length === 0).each((_, node) => {
+    const element = $(node);
+    blocks.push({ language: languageOf(element), code: element.text(), inline: true });
+  });
+
+  return blocks;
+}
+
+// A Python API object documented by Sphinx autodoc, e.g. a class, function or method
+export interface SphinxApiEntry {
+  kind: string; // The object type from the dl's classes: "class", "function", "method", "attribute", ...
+  name: string; // Fully qualified name from the signature's id, e.g. "requests.Session.get"
+  signature: string;
+  description?: string;
+}
+
+/**
+ * Whether an HTML page was generated by Sphinx, from its generator meta tag or markup
+ */
+export function isSphinxPage(html: string): boolean {
+  const $ = cheerio.load(html);
+  return /sphinx/i.test($('meta[name="generator"]').attr('content') || '') ||
+    $('dl.py, .sphinxsidebar, div[itemprop="articleBody"]').length > 0;
+}
+
+/**
+ * Extract the API objects documented on a Sphinx page from its autodoc blocks
+ * (<dl class="py function"> and similar), including methods nested within classes
+ */
+export function extractSphinxApi(html: string): SphinxApiEntry[] {
+  const $ = cheerio.load(html);
+  const entries: SphinxApiEntry[] = [];
+
+  $('dl.py').each((_, node) => {
+    const element = $(node);
+    const kind = (element.attr('class') || '').split(/\s+/).find(name => name && name !== 'py') || 'object';
+
+    const signatureElement = element.children('dt').first().clone();
+    // Drop the ¶ permalink and [source] links from the signature text
+    signatureElement.find('a.headerlink, .viewcode-link, a.reference.internal').remove();
+    const signature = signatureElement.text().replace(/\s+/g, ' ').replace(/\s*([(),[\]])\s*/g, '$1').replace(/,/g, ', ').trim();
+    if (!signature) {
+      return;
+    }
+
+    const id = element.children('dt').first().attr('id');
+    const description = element.children('dd').first().children('p').first().text().replace(/\s+/g, ' ').trim();
+    entries.push({
+      kind,
+      name: id || signature.replace(/^\w+\s+/, '').split('(')[0],
+      signature,
+      description: description || undefined,
+    });
+  });
+
+  return entries;
+}
+
+/**
+ * Find the pages an HTML page links to whose URL or link text matches a pattern, as absolute URLs
+ * without fragments. Links within the same page are skipped.
+ */
+export function findLinkedPages(html: string, pageUrl: string, pattern: RegExp): string[] {
+  const $ = cheerio.load(html);
+  const pages = new Set<string>();
+
+  $('a[href]').each((_, link) => {
+    const href = $(link).attr('href') || '';
+    if (href.startsWith('#') || !pattern.test(`${href} ${$(link).text()}`)) {
+      return;
+    }
+    try {
+      const page = new URL(href, pageUrl).toString().split('#')[0];
+      if (page !== pageUrl.split('#')[0]) {
+        pages.add(page);
+      }
+    } catch {
+      // Skip unparseable links
+    }
+  });
+
+  return Array.from(pages);
+}
+
+/**
+ * Extract the tables from an HTML page. Headers come from the <thead> row, or otherwise a first row
+ * of <th> cells; tables without either get numbered column headers. Nested tables are skipped.
+ */
+export function extractHtmlTables(html: string): MarkdownTable[] {
+  const $ = cheerio.load(html);
+  const tables: MarkdownTable[] = [];
+  const cellTexts = (row: CheerioSelection) =>
+    row.children('th, td').toArray().map(cell => $(cell).text().replace(/\s+/g, ' ').trim());
+
+  $('table').filter((_, table) => $(table).parents('table').length === 0).each((_, table) => {
+    // Rows of this table only, not of tables nested in its cells
+    const rows = $(table).find('tr').filter((_, row) => $(row).closest('table').is(table)).toArray();
+    if (rows.length === 0) {
+      return;
+    }
+
+    const firstRow = $(rows[0]);
+    const hasHeader = firstRow.parent().is('thead') || (firstRow.children('th').length > 0 && firstRow.children('td').length === 0);
+    const bodyRows = (hasHeader ? rows.slice(1) : rows).map(row => cellTexts($(row)));
+    const width = Math.max(hasHeader ? firstRow.children('th, td').length : 0, ...bodyRows.map(row => row.length));
+    if (width === 0) {
+      return;
+    }
+
+    const headers = hasHeader
+      ? cellTexts(firstRow)
+      : Array.from({ length: width }, (_, column) => `Column ${column + 1}`);
+    tables.push({
+      headers: Array.from({ length: width }, (_, column) => headers[column] ?? ''),
+      rows: bodyRows.map(row => Array.from({ length: width }, (_, column) => row[column] ?? '')),
+    });
+  });
+
+  return tables;
+}
+
+// The parts of a pkg.go.dev package page that describe the package
+export interface PkgGoDevDocs {
+  overview?: string; // The package comment, with code blocks fenced
+  functions: string[]; // Package level function signatures, e.g. func Get(url string) (resp *Response, err error)
+  types: string[]; // Type declarations, e.g. type Client struct
+}
+
+/**
+ * Extract the overview, functions and types from a pkg.go.dev package page, for the standard library
+ * and subpackages as well as modules. Signatures come from the rendered declarations rather than the
+ * headings, as those only hold the name.
+ */
+export function extractPkgGoDevDocs(html: string): PkgGoDevDocs {
+  const $ = cheerio.load(html);
+
+  // The overview section has the Documentation-overview class, and contains the #pkg-overview heading
+  let overviewSection = $('section.Documentation-overview').first();
+  if (overviewSection.length === 0) {
+    overviewSection = $('#pkg-overview').closest('section');
+  }
+  const overview = overviewSection.find('p, pre, h3, h4')
+    // Examples are collapsed into <details> and extracted separately; the section's own heading is dropped
+    .filter((_, node) => $(node).closest('details').length === 0 && !$(node).is('.Documentation-overviewHeader, #pkg-overview'))
+    .toArray()
+    .map(node => {
+      const element = $(node);
+      if (element.is('pre')) {
+        return '```go\n' + element.text().replace(/\n$/, '') + '\n```';
+      }
+      // Keep the overview's links (to other packages and the declarations below) as markdown links
+      element.find('a[href]').each((_, link) => {
+        const anchor = $(link);
+        const label = anchor.text().replace(/¶/g, '').trim();
+        if (label) {
+          anchor.text(`[${label}](${anchor.attr('href')})`);
+        }
+      });
+      const text = element.text().replace(/¶/g, '').replace(/\s+/g, ' ').trim();
+      return element.is('h3, h4') ? `### ${text}` : text;
+    })
+    .filter(Boolean)
+    .join('\n\n');
+
+  const functions = new Set<string>();
+  const types = new Set<string>();
+  $('.Documentation-declaration pre').each((_, node) => {
+    const declaration = $(node).text().split('\n')[0].replace(/\s*[{(]\s*$/, '').trim();
+    if (/^func \w/.test(declaration)) {
+      functions.add(declaration);
+    } else if (/^type \w/.test(declaration)) {
+      types.add(declaration);
+    }
+  });
+
+  return {
+    overview: overview || undefined,
+    functions: Array.from(functions),
+    types: Array.from(types),
+  };
+}
@@ -0,0 +1,155 @@
+import axios, { AxiosAdapter, AxiosInstance, AxiosResponse, InternalAxiosRequestConfig } from 'axios';
+import { createHash } from 'crypto';
+
+// Responses larger than this aren't cached, so a few large pages can't crowd out the rest
+const MAX_CACHED_BODY_LENGTH = 5 * 1024 * 1024;
+
+// A cached response, fresh until expires and revalidated with its validators after that
+interface HttpCacheEntry {
+  response: Pick<AxiosResponse, 'data' | 'status' | 'statusText' | 'headers'>;
+  expires: number;
+  etag?: string;
+  lastModified?: string;
+  bytes: number;
+}
+
+const httpCache = new Map<string, HttpCacheEntry>();
+let httpCacheBytes = 0;
+
+/**
+ * Get the most the HTTP cache may hold, in bytes, from PACKAGE_DOCS_HTTP_CACHE_MB (megabytes of
+ * response bodies). The cache is off unless it's set to a positive number.
+ */
+export function getHttpCacheBytes(value = process.env.PACKAGE_DOCS_HTTP_CACHE_MB): number {
+  const megabytes = Number(value);
+  return value !== undefined && value !== '' && Number.isFinite(megabytes) && megabytes > 0
+    ? Math.floor(megabytes * 1024 * 1024)
+    : 0;
+}
+
+/**
+ * Get how long a response may be reused without revalidating, in milliseconds, from its
+ * Cache-Control max-age (or s-maxage) or Expires header. Returns undefined when it mustn't be
+ * stored (no-store, Vary: *), and 0 when it has to be revalidated on every use.
+ */
+export function getFreshnessLifetime(headers: Record<string, unknown>, now = Date.now()): number | undefined {
+  const cacheControl = String(headers['cache-control'] ?? '').toLowerCase();
+  if (/\bno-store\b/.test(cacheControl) || String(headers['vary'] ?? '').trim() === '*') {
+    return undefined;
+  }
+  if (/\bno-cache\b/.test(cacheControl)) {
+    return 0;
+  }
+
+  const maxAge = cacheControl.match(/\bs-maxage\s*=\s*(\d+)/) || cacheControl.match(/\bmax-age\s*=\s*(\d+)/);
+  if (maxAge) {
+    return Number(maxAge[1]) * 1000;
+  }
+
+  const expires = headers['expires'] ? Date.parse(String(headers['expires'])) : NaN;
+  return Number.isNaN(expires) ? 0 : Math.max(expires - now, 0);
+}
+
+/**
+ * Build the cache key of a request from its URL and headers. Credentials are hashed rather than
+ * kept, and responses for one token are never served to a request with another.
+ */
+function httpCacheKey(config: InternalAxiosRequestConfig): string {
+  const headers = Object.entries(config.headers?.toJSON?.() ?? {})
+    .map(([name, value]) => [name.toLowerCase(), String(value)])
+    .map(([name, value]) => [name, name === 'authorization' ? createHash('sha256').update(value).digest('hex') : value])
+    .sort(([a], [b]) => a.localeCompare(b));
+  return `${axios.getUri(config)}\n${JSON.stringify(headers)}`;
+}
+
+function bodyLength(data: unknown): number {
+  if (typeof data === 'string') return Buffer.byteLength(data);
+  if (Buffer.isBuffer(data)) return data.length;
+  if (data instanceof ArrayBuffer) return data.byteLength;
+  return MAX_CACHED_BODY_LENGTH + 1;
+}
+
+function deleteEntry(key: string): void {
+  const entry = httpCache.get(key);
+  if (entry) {
+    httpCacheBytes -= entry.bytes;
+    httpCache.delete(key);
+  }
+}
+
+/**
+ * Wrap an axios adapter with a cache of GET responses that honours Cache-Control, Expires, ETag and
+ * Last-Modified: fresh responses are served without a request, and stale ones with validators are
+ * revalidated with If-None-Match or If-Modified-Since so an unchanged resource isn't downloaded again.
+ * Raw responses are cached before axios transforms them, so every response type is reused as fetched.
+ * The least recently used responses are dropped once the bodies held come to more than maxBytes.
+ */
+export function createCachingAdapter(adapter: AxiosAdapter, maxBytes = getHttpCacheBytes()): AxiosAdapter {
+  return async (config: InternalAxiosRequestConfig): Promise<AxiosResponse> => {
+    if (maxBytes === 0 || (config.method ?? 'get').toLowerCase() !== 'get') {
+      return adapter(config);
+    }
+
+    const key = httpCacheKey(config);
+    const entry = httpCache.get(key);
+    const now = Date.now();
+    if (entry && entry.expires > now) {
+      // Move the entry to the end, so the least recently used responses are dropped first
+      httpCache.delete(key);
+      httpCache.set(key, entry);
+      return { ...entry.response, config, request: undefined };
+    }
+
+    let request = config;
+    if (entry && (entry.etag || entry.lastModified)) {
+      request = { ...config, headers: config.headers.concat(), validateStatus: status => status === 304 || (config.validateStatus?.(status) ?? (status >= 200 && status < 300)) };
+      if (entry.etag) request.headers.set('If-None-Match', entry.etag);
+      if (entry.lastModified) request.headers.set('If-Modified-Since', entry.lastModified);
+    }
+
+    const response = await adapter(request);
+    const cached = response.status === 304 && entry
+      ? { ...entry.response, headers: { ...entry.response.headers, ...response.headers } }
+      : response;
+
+    const lifetime = getFreshnessLifetime(cached.headers as Record<string, unknown>, now);
+    const etag = cached.headers['etag'] as string | undefined;
+    const lastModified = cached.headers['last-modified'] as string | undefined;
+    const bytes = bodyLength(cached.data);
+    deleteEntry(key);
+    if (cached.status === 200 && lifetime !== undefined && (lifetime > 0 || etag || lastModified) && bytes <= Math.min(MAX_CACHED_BODY_LENGTH, maxBytes)) {
+      httpCache.set(key, {
+        response: { data: cached.data, status: cached.status, statusText: cached.statusText, headers: cached.headers },
+        expires: now + lifetime,
+        etag,
+        lastModified,
+        bytes,
+      });
+      httpCacheBytes += bytes;
+      while (httpCacheBytes > maxBytes) {
+        deleteEntry(httpCache.keys().next().value as string);
+      }
+    }
+
+    return { ...cached, config, request: response.request };
+  };
+}
+
+/**
+ * Cache the GET responses of an axios instance (the default instance unless another is given),
+ * when PACKAGE_DOCS_HTTP_CACHE_MB enables the cache
+ */
+export function installHttpCache(instance: AxiosInstance = axios, maxBytes = getHttpCacheBytes()): void {
+  if (maxBytes === 0) {
+    return;
+  }
+  instance.defaults.adapter = createCachingAdapter(axios.getAdapter(instance.defaults.adapter), maxBytes);
+}
+
+/**
+ * Forget every cached HTTP response
+ */
+export function clearHttpCache(): void {
+  httpCache.clear();
+  httpCacheBytes = 0;
+}
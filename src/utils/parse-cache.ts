@@ -0,0 +1,42 @@
+import { AsyncLocalStorage } from 'async_hooks';
+import { createHash } from 'crypto';
+
+// What a tool call has parsed so far: for each kind of parse, the result for each document by its hash
+type ParseCache = Map<string, Map<string, unknown>>;
+
+const parseCacheStorage = new AsyncLocalStorage<ParseCache>();
+
+/**
+ * Run a tool call with its own parse cache. A describe or search runs several extractors over the
+ * same README, so each document is split into sections, code blocks and signatures once per call.
+ * The cache goes when the call ends, so a long-running server doesn't hold on to every README.
+ */
+export function withParseCache<T>(fn: () => Promise<T>): Promise<T> {
+  return parseCacheStorage.run(new Map(), fn);
+}
+
+/**
+ * Parse a document, or reuse the result of parsing it earlier in the same tool call. Outside a
+ * tool call the document is always parsed. Callers mustn't modify the result they're given.
+ */
+export function cachedParse<T>(kind: string, content: string, parse: () => T): T {
+  const cache = parseCacheStorage.getStore();
+  if (!cache) {
+    return parse();
+  }
+
+  let results = cache.get(kind);
+  if (!results) {
+    results = new Map();
+    cache.set(kind, results);
+  }
+  // Documents are keyed by their hash rather than held whole as keys
+  const key = createHash('sha1').update(content).digest('hex');
+  if (results.has(key)) {
+    return results.get(key) as T;
+  }
+
+  const result = parse();
+  results.set(key, result);
+  return result;
+}
@@ -0,0 +1,88 @@
+// How long fetched repository files, listings, tags and metadata are reused, in seconds
+const DEFAULT_REPO_CACHE_TTL = 600;
+
+// Most resources kept at once; the least recently used are dropped first
+const DEFAULT_REPO_CACHE_ENTRIES = 500;
+
+interface CacheEntry {
+  expires: number;
+  value: Promise<unknown>;
+}
+
+const repoCache = new Map<string, CacheEntry>();
+
+/**
+ * Get the repository cache TTL from PACKAGE_DOCS_REPO_CACHE_TTL (seconds, 0 disables caching),
+ * falling back to the default when unset or invalid
+ */
+export function getRepoCacheTtl(value = process.env.PACKAGE_DOCS_REPO_CACHE_TTL): number {
+  const seconds = Number(value);
+  return value !== undefined && value !== '' && Number.isFinite(seconds) && seconds >= 0
+    ? seconds
+    : DEFAULT_REPO_CACHE_TTL;
+}
+
+/**
+ * Get the most resources the repository cache keeps from PACKAGE_DOCS_REPO_CACHE_ENTRIES, falling
+ * back to the default when unset or invalid
+ */
+export function getRepoCacheEntries(value = process.env.PACKAGE_DOCS_REPO_CACHE_ENTRIES): number {
+  const entries = Number(value);
+  return Number.isInteger(entries) && entries > 0 ? entries : DEFAULT_REPO_CACHE_ENTRIES;
+}
+
+/**
+ * Build the cache key of a repository resource. The ref is always part of the key, so a file read
+ * at a tag is never served for a branch (or another tag); resources without a ref use an empty one.
+ * @param repository The repository as host/owner/repo, e.g. github.com/expressjs/express
+ * @param resource The kind of resource, e.g. "file", "dir", "tags" or "metadata"
+ */
+export function repoCacheKey(repository: string, ref: string, resource: string, path = ''): string {
+  return `${repository.toLowerCase()}@${ref}:${resource}:${path}`;
+}
+
+/**
+ * Get a repository resource from the cache, or load and cache it. Concurrent requests for the same
+ * key share one load, and failed loads aren't cached so they're retried next time. Beyond
+ * maxEntries, the least recently used resources are dropped.
+ */
+export function cachedRepoResource<T>(
+  key: string,
+  load: () => Promise<T>,
+  ttl = getRepoCacheTtl(),
+  maxEntries = getRepoCacheEntries()
+): Promise<T> {
+  if (ttl === 0) {
+    return load();
+  }
+
+  const entry = repoCache.get(key);
+  if (entry) {
+    // Reinserted so the Map's order runs from least to most recently used
+    repoCache.delete(key);
+    if (entry.expires > Date.now()) {
+      repoCache.set(key, entry);
+      return entry.value as Promise<T>;
+    }
+  }
+
+  const value = load();
+  repoCache.set(key, { expires: Date.now() + ttl * 1000, value });
+  for (const oldest of repoCache.keys()) {
+    if (repoCache.size <= maxEntries) break;
+    repoCache.delete(oldest);
+  }
+  value.catch(() => {
+    if (repoCache.get(key)?.value === value) {
+      repoCache.delete(key);
+    }
+  });
+  return value;
+}
+
+/**
+ * Forget every cached repository resource
+ */
+export function clearRepoCache(): void {
+  repoCache.clear();
+}
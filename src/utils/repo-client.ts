@@ -0,0 +1,482 @@
+import axios from 'axios';
+import { McpLogger } from '../logger.js';
+import { RepositoryRef, getGitHubAuthHeaders, parseRepositoryUrl } from './github-client.js';
+import { cachedRepoResource, repoCacheKey } from './repo-cache.js';
+
+// README file names, in order of preference
+const README_FILE_NAMES = ['README.md', 'readme.md', 'Readme.md', 'README.rst', 'README.txt', 'README'];
+
+// Below this many remaining GitHub API requests, the rest are spread out until the limit resets
+const GITHUB_RATE_LIMIT_LOW = 10;
+const MAX_RATE_LIMIT_DELAY_MS = 5000;
+
+/**
+ * A host's API rate limit has been (or is about to be) used up, so requests fail until it resets
+ */
+export class RateLimitError extends Error {
+  constructor(readonly host: string, readonly resetsAt: Date, hint?: string) {
+    super(`${host} rate limit nearly exhausted, resets at ${resetsAt.toISOString()}${hint ? `. ${hint}` : ''}`);
+    this.name = 'RateLimitError';
+  }
+}
+
+/**
+ * A host rejected the configured token, so every authenticated request fails until it's fixed
+ */
+export class BadCredentialsError extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = 'BadCredentialsError';
+  }
+}
+
+// Failures that no other branch, path or request would avoid, so are passed on to the caller
+function isFatalRepoError(error: unknown): boolean {
+  return error instanceof RateLimitError || error instanceof BadCredentialsError;
+}
+
+// Suggested when GitHub's unauthenticated rate limit runs out
+const GITHUB_TOKEN_HINT = 'Set GITHUB_TOKEN to raise the limit from 60 to 5000 requests an hour';
+
+// The GitHub API rate limit as of the last response, by Authorization header (empty when
+// anonymous), shared by every client as the anonymous limit applies per IP and a token's per token
+const gitHubRateLimits = new Map<string, { remaining?: number; reset?: Date }>();
+
+function gitHubRateLimitFor(authorization = ''): { remaining?: number; reset?: Date } {
+  let rateLimit = gitHubRateLimits.get(authorization);
+  if (!rateLimit) {
+    rateLimit = {};
+    gitHubRateLimits.set(authorization, rateLimit);
+  }
+  return rateLimit;
+}
+
+/**
+ * Record the GitHub API rate limit from a response's X-RateLimit-Remaining and X-RateLimit-Reset headers
+ */
+function recordGitHubRateLimit(authorization: string | undefined, headers: Record<string, unknown> | undefined): void {
+  const rateLimit = gitHubRateLimitFor(authorization);
+  const remaining = Number(headers?.['x-ratelimit-remaining']);
+  const reset = Number(headers?.['x-ratelimit-reset']);
+  if (headers?.['x-ratelimit-remaining'] !== undefined && Number.isFinite(remaining)) {
+    rateLimit.remaining = remaining;
+  }
+  if (headers?.['x-ratelimit-reset'] !== undefined && Number.isFinite(reset)) {
+    rateLimit.reset = new Date(reset * 1000); // Seconds since the epoch
+  }
+}
+
+/**
+ * Get the GitHub API rate limit as of the last response made with the current GITHUB_TOKEN (or
+ * anonymously without one), if any has been seen
+ */
+export function getGitHubRateLimit(authorization = getGitHubAuthHeaders().Authorization): { remaining?: number; reset?: Date } {
+  return { ...gitHubRateLimitFor(authorization) };
+}
+
+// A file read from a repository
+export interface RepoFile {
+  path: string;
+  content: string;
+}
+
+// An entry in a repository directory listing
+export interface RepoEntry {
+  name: string;
+  path: string;
+  type: 'file' | 'dir';
+}
+
+// Repository details from the host's API
+export interface RepoMetadata {
+  description?: string;
+  defaultBranch?: string;
+  homepage?: string;
+  license?: string;
+  stars?: number;
+  topics?: string[];
+  archived?: boolean;
+}
+
+/**
+ * Read access to a hosted repository, so handlers can fetch READMEs, changelogs and examples the
+ * same way whichever host the package lives on
+ */
+export interface RepoClient {
+  readonly repository: RepositoryRef;
+
+  /**
+   * Get the repository's README, at the given ref or otherwise from main or master
+   */
+  getReadme(ref?: string): Promise<string | undefined>;
+
+  /**
+   * Get the first of the given files that exists, at the given ref or otherwise trying the common
+   * default branches
+   */
+  getFile(paths: string | string[], ref?: string): Promise<RepoFile | undefined>;
+
+  /**
+   * List a directory's entries, at the repository root by default
+   */
+  listDir(path?: string, ref?: string): Promise<RepoEntry[]>;
+
+  /**
+   * List the repository's tag names, newest first as returned by the host
+   */
+  getTags(): Promise<string[]>;
+
+  getMetadata(): Promise<RepoMetadata | undefined>;
+}
+
+/**
+ * The behaviour shared by every host: trying candidate files and branches in turn, caching what's
+ * fetched (shared with the other clients for the same repository), and treating failed requests as
+ * missing content rather than errors, except for a RateLimitError or BadCredentialsError
+ */
+abstract class BaseRepoClient implements RepoClient {
+  constructor(readonly repository: RepositoryRef, protected readonly logger: McpLogger) {}
+
+  private cached<T>(ref: string, resource: string, path: string, load: () => Promise<T>): Promise<T> {
+    const { host, owner, repo } = this.repository;
+    return cachedRepoResource(repoCacheKey(`${host}/${owner}/${repo}`, ref, resource, path), load);
+  }
+
+  /**
+   * Fetch a file's raw content, throwing when it doesn't exist
+   */
+  protected abstract fetchRaw(path: string, ref: string): Promise<string>;
+
+  protected abstract fetchDir(path: string, ref: string): Promise<RepoEntry[]>;
+
+  protected abstract fetchTags(): Promise<string[]>;
+
+  protected abstract fetchMetadata(): Promise<RepoMetadata>;
+
+  async getReadme(ref?: string): Promise<string | undefined> {
+    return (await this.getFile(README_FILE_NAMES, ref))?.content;
+  }
+
+  async getFile(paths: string | string[], ref?: string): Promise<RepoFile | undefined> {
+    for (const path of Array.isArray(paths) ? paths : [paths]) {
+      for (const branch of ref ? [ref] : ['main', 'master']) {
+        try {
+          this.logger.debug(`Fetching ${path} from ${this.repository.url} at ${branch}`);
+          const filePath = path.replace(/^\/+/, '');
+          const content = await this.cached(branch, 'file', filePath, () => this.fetchRaw(filePath, branch));
+          if (content) {
+            return { path, content };
+          }
+        } catch {
+          // Try the next branch
+        }
+      }
+    }
+
+    return undefined;
+  }
+
+  async listDir(path = '', ref?: string): Promise<RepoEntry[]> {
+    for (const branch of ref ? [ref] : ['main', 'master']) {
+      try {
+        const dirPath = path.replace(/^\/+|\/+$/g, '');
+        return await this.cached(branch, 'dir', dirPath, () => this.fetchDir(dirPath, branch));
+      } catch (error) {
+        // Try the next branch, unless no more requests can be made
+        if (isFatalRepoError(error)) throw error;
+      }
+    }
+
+    this.logger.debug(`Could not list ${path || '/'} in ${this.repository.url}`);
+    return [];
+  }
+
+  async getTags(): Promise<string[]> {
+    try {
+      return await this.cached('', 'tags', '', () => this.fetchTags());
+    } catch (error) {
+      if (isFatalRepoError(error)) throw error;
+      this.logger.debug(`Error fetching tags for ${this.repository.url}: ${error}`);
+      return [];
+    }
+  }
+
+  async getMetadata(): Promise<RepoMetadata | undefined> {
+    try {
+      return await this.cached('', 'metadata', '', () => this.fetchMetadata());
+    } catch (error) {
+      if (isFatalRepoError(error)) throw error;
+      this.logger.debug(`Error fetching metadata for ${this.repository.url}: ${error}`);
+      return undefined;
+    }
+  }
+}
+
+/**
+ * GitHub, reading files from raw.githubusercontent.com (which isn't rate limited like the API)
+ */
+export class GitHubRepoClient extends BaseRepoClient {
+  private get apiUrl(): string {
+    return `https://api.github.com/repos/${this.repository.owner}/${this.repository.repo}`;
+  }
+
+  /**
+   * Make a GitHub API request, authenticated with GITHUB_TOKEN when it's set, slowing down when few
+   * requests remain before the rate limit resets and failing with a RateLimitError, rather than the
+   * API's bare 403, once it's exhausted
+   */
+  private async apiGet(url: string, params?: Record<string, unknown>) {
+    const headers = getGitHubAuthHeaders();
+    const hint = headers.Authorization ? undefined : GITHUB_TOKEN_HINT;
+    const rateLimit = gitHubRateLimitFor(headers.Authorization);
+    const { remaining, reset } = rateLimit;
+    if (remaining !== undefined && reset && reset.getTime() > Date.now()) {
+      if (remaining <= 0) {
+        throw new RateLimitError('GitHub', reset, hint);
+      }
+      if (remaining < GITHUB_RATE_LIMIT_LOW) {
+        const delay = Math.min(MAX_RATE_LIMIT_DELAY_MS, (reset.getTime() - Date.now()) / (remaining + 1));
+        this.logger.debug(`GitHub rate limit low (${remaining} remaining), waiting ${Math.round(delay)}ms`);
+        await new Promise(resolve => setTimeout(resolve, delay));
+      }
+    }
+
+    try {
+      const response = await axios.get(url, { params, headers });
+      recordGitHubRateLimit(headers.Authorization, response.headers);
+      return response;
+    } catch (error) {
+      if (axios.isAxiosError(error) && error.response) {
+        recordGitHubRateLimit(headers.Authorization, error.response.headers);
+        const status = error.response.status;
+        // GitHub explains primary and secondary rate limits in the error body's message, e.g.
+        // {"message": "API rate limit exceeded for 1.2.3.4. (But here's the good news: ...)"}
+        const message = String((error.response.data as { message?: unknown } | undefined)?.message ?? '');
+        if ((status === 403 || status === 429) && (rateLimit.remaining === 0 || /rate limit/i.test(message))) {
+          const retryAfter = Number(error.response.headers?.['retry-after']);
+          const resetsAt = rateLimit.reset ?? new Date(Date.now() + (Number.isFinite(retryAfter) ? retryAfter : 60) * 1000);
+          throw new RateLimitError('GitHub', resetsAt, hint);
+        }
+        if (status === 401) {
+          throw new BadCredentialsError('GitHub rejected the token in GITHUB_TOKEN (401 Bad credentials)');
+        }
+      }
+      throw error;
+    }
+  }
+
+  protected async fetchRaw(path: string, ref: string): Promise<string> {
+    const { owner, repo } = this.repository;
+    const response = await axios.get(`https://raw.githubusercontent.com/${owner}/${repo}/${ref}/${path}`, {
+      responseType: 'text',
+      headers: getGitHubAuthHeaders(),
+    });
+    return String(response.data);
+  }
+
+  protected async fetchDir(path: string, ref: string): Promise<RepoEntry[]> {
+    const response = await this.apiGet(`${this.apiUrl}/contents/${path}`, { ref });
+    return (Array.isArray(response.data) ? response.data : []).map((entry: { name: string; path: string; type: string }) => ({
+      name: entry.name,
+      path: entry.path,
+      type: entry.type === 'dir' ? 'dir' : 'file',
+    }));
+  }
+
+  protected async fetchTags(): Promise<string[]> {
+    const response = await this.apiGet(`${this.apiUrl}/tags`, { per_page: 100 });
+    return (response.data || []).map((tag: { name: string }) => tag.name);
+  }
+
+  protected async fetchMetadata(): Promise<RepoMetadata> {
+    const { data } = await this.apiGet(this.apiUrl);
+    return {
+      description: data.description || undefined,
+      defaultBranch: data.default_branch,
+      homepage: data.homepage || undefined,
+      license: data.license?.spdx_id || undefined,
+      stars: data.stargazers_count,
+      topics: data.topics,
+      archived: data.archived,
+    };
+  }
+}
+
+/**
+ * GitLab, whose API addresses projects by their URL encoded path
+ */
+export class GitLabRepoClient extends BaseRepoClient {
+  private get apiUrl(): string {
+    const projectPath = `${this.repository.owner}/${this.repository.repo}`;
+    return `https://${this.repository.host}/api/v4/projects/${encodeURIComponent(projectPath)}`;
+  }
+
+  protected async fetchRaw(path: string, ref: string): Promise<string> {
+    const response = await axios.get(`${this.apiUrl}/repository/files/${encodeURIComponent(path)}/raw`, {
+      params: { ref },
+      responseType: 'text',
+    });
+    return String(response.data);
+  }
+
+  protected async fetchDir(path: string, ref: string): Promise<RepoEntry[]> {
+    const response = await axios.get(`${this.apiUrl}/repository/tree`, { params: { path, ref, per_page: 100 } });
+    return (response.data || []).map((entry: { name: string; path: string; type: string }) => ({
+      name: entry.name,
+      path: entry.path,
+      type: entry.type === 'tree' ? 'dir' : 'file',
+    }));
+  }
+
+  protected async fetchTags(): Promise<string[]> {
+    const response = await axios.get(`${this.apiUrl}/repository/tags`, { params: { per_page: 100 } });
+    return (response.data || []).map((tag: { name: string }) => tag.name);
+  }
+
+  protected async fetchMetadata(): Promise<RepoMetadata> {
+    const { data } = await axios.get(this.apiUrl);
+    return {
+      description: data.description || undefined,
+      defaultBranch: data.default_branch,
+      stars: data.star_count,
+      topics: data.topics,
+      archived: data.archived,
+    };
+  }
+}
+
+/**
+ * Bitbucket Cloud, whose src endpoint returns a file's content or a directory's listing
+ */
+export class BitbucketRepoClient extends BaseRepoClient {
+  private get apiUrl(): string {
+    return `https://api.bitbucket.org/2.0/repositories/${this.repository.owner}/${this.repository.repo}`;
+  }
+
+  protected async fetchRaw(path: string, ref: string): Promise<string> {
+    const response = await axios.get(`${this.apiUrl}/src/${encodeURIComponent(ref)}/${path}`, { responseType: 'text' });
+    return String(response.data);
+  }
+
+  protected async fetchDir(path: string, ref: string): Promise<RepoEntry[]> {
+    const response = await axios.get(`${this.apiUrl}/src/${encodeURIComponent(ref)}/${path}`, { params: { pagelen: 100 } });
+    return (response.data?.values || []).map((entry: { path: string; type: string }) => ({
+      name: entry.path.split('/').pop() || entry.path,
+      path: entry.path,
+      type: entry.type === 'commit_directory' ? 'dir' : 'file',
+    }));
+  }
+
+  protected async fetchTags(): Promise<string[]> {
+    const response = await axios.get(`${this.apiUrl}/refs/tags`, { params: { pagelen: 100, sort: '-target.date' } });
+    return (response.data?.values || []).map((tag: { name: string }) => tag.name);
+  }
+
+  protected async fetchMetadata(): Promise<RepoMetadata> {
+    const { data } = await axios.get(this.apiUrl);
+    return {
+      description: data.description || undefined,
+      defaultBranch: data.mainbranch?.name,
+      homepage: data.website || undefined,
+    };
+  }
+}
+
+/**
+ * The raw file URL layouts plain Git hosts use, in the order they're tried
+ */
+const GENERIC_RAW_LAYOUTS: Array<(url: string, ref: string, path: string) => string> = [
+  (url, ref, path) => `${url}/raw/branch/${ref}/${path}`, // Gitea and Forgejo
+  (url, ref, path) => `${url}/-/raw/${ref}/${path}`, // GitLab
+  (url, ref, path) => `${url}/raw/${ref}/${path}`, // Gogs and older Gitea
+  (url, ref, path) => `${url}/plain/${path}?h=${encodeURIComponent(ref)}`, // cgit
+];
+
+// The layout each plain Git host last served a file from, so later files are only fetched from it
+const genericHostLayouts = new Map<string, number>();
+
+/**
+ * Any other Git host (Gitea, Forgejo, Gogs, cgit or a self-hosted forge), whose API isn't known, so
+ * files are fetched over plain HTTP from the raw file URL layouts those hosts use. Each layout is
+ * only probed until one serves a file, after which the host's other files are fetched from it.
+ * Directory listings, tags and metadata aren't available.
+ */
+export class GenericGitRepoClient extends BaseRepoClient {
+  protected async fetchRaw(path: string, ref: string): Promise<string> {
+    const { host, url } = this.repository;
+    const known = genericHostLayouts.get(host);
+    const layouts = known === undefined ? GENERIC_RAW_LAYOUTS.map((_, index) => index) : [known];
+
+    for (const layout of layouts) {
+      try {
+        const response = await axios.get(GENERIC_RAW_LAYOUTS[layout](url, ref, path), { responseType: 'text' });
+        // Hosts that don't recognise the layout often answer with an HTML page (or a login form)
+        // rather than a 404, which mustn't be mistaken for the file
+        const contentType = String(response.headers?.['content-type'] || '');
+        if (contentType.includes('text/html') && !/\.html?$/i.test(path)) continue;
+        genericHostLayouts.set(host, layout);
+        return String(response.data);
+      } catch {
+        // Try the next layout
+      }
+    }
+
+    throw new Error(`${path} not found at ${url}`);
+  }
+
+  protected async fetchDir(): Promise<RepoEntry[]> {
+    throw new Error(`Directory listings aren't supported for ${this.repository.host}`);
+  }
+
+  protected async fetchTags(): Promise<string[]> {
+    return [];
+  }
+
+  protected async fetchMetadata(): Promise<RepoMetadata> {
+    throw new Error(`Repository metadata isn't supported for ${this.repository.host}`);
+  }
+}
+
+export interface RepoClientOptions {
+  /**
+   * Read repositories on hosts without a known API over plain HTTP, probing their raw file
+   * layouts. Off by default, as every file then costs up to a request per layout; only worth it
+   * where packages are commonly hosted on such forges, as Swift packages are.
+   */
+  genericHosts?: boolean;
+}
+
+/**
+ * Create a client for a repository from a resolved repository or any URL form parseRepositoryUrl
+ * accepts. GitHub, GitLab (including self-hosted instances with gitlab in their host name) and
+ * Bitbucket are read through their APIs, and other hosts over plain HTTP when genericHosts is set.
+ * Returns undefined when no repository can be parsed from the URL or its host isn't supported.
+ */
+export function createRepoClient(
+  repository: RepositoryRef | string,
+  logger: McpLogger,
+  { genericHosts = false }: RepoClientOptions = {}
+): RepoClient | undefined {
+  const ref = typeof repository === 'string' ? parseRepositoryUrl(repository) : repository;
+  if (!ref) {
+    return undefined;
+  }
+
+  switch (ref.host) {
+    case 'github.com':
+      return new GitHubRepoClient(ref, logger);
+    case 'gitlab.com':
+      return new GitLabRepoClient(ref, logger);
+    case 'bitbucket.org':
+      return new BitbucketRepoClient(ref, logger);
+    default:
+      if (ref.host.split('.').includes('gitlab')) {
+        return new GitLabRepoClient(ref, logger);
+      }
+      if (genericHosts) {
+        return new GenericGitRepoClient(ref, logger);
+      }
+      logger.debug(`No client for repositories on ${ref.host}`);
+      return undefined;
+  }
+}
@@ -0,0 +1,107 @@
+import { AsyncLocalStorage } from 'async_hooks';
+import type { AxiosError, AxiosInstance, InternalAxiosRequestConfig } from 'axios';
+import { McpLogger } from '../logger.js';
+
+// Retries allowed for any one request, and across all the requests made by a single tool call
+const MAX_RETRIES_PER_REQUEST = 2;
+const DEFAULT_RETRY_BUDGET = 4;
+
+/**
+ * The retries remaining for a tool call. A describe makes several sub-requests (metadata, README,
+ * download stats), so without a shared budget a struggling registry could make one call take minutes.
+ */
+export class RetryBudget {
+  private remaining: number;
+
+  constructor(retries: number) {
+    this.remaining = retries;
+  }
+
+  /**
+   * Use one retry from the budget, returning false when it's exhausted
+   */
+  take(): boolean {
+    if (this.remaining <= 0) {
+      return false;
+    }
+    this.remaining--;
+    return true;
+  }
+
+  get exhausted(): boolean {
+    return this.remaining <= 0;
+  }
+}
+
+const retryBudgetStorage = new AsyncLocalStorage<RetryBudget>();
+
+/**
+ * Get the retry budget from PACKAGE_DOCS_RETRY_BUDGET, falling back to the default when unset or invalid
+ */
+export function getRetryBudgetSize(value = process.env.PACKAGE_DOCS_RETRY_BUDGET): number {
+  const retries = Number(value);
+  return value !== undefined && value !== '' && Number.isInteger(retries) && retries >= 0
+    ? retries
+    : DEFAULT_RETRY_BUDGET;
+}
+
+/**
+ * Run a tool call with its own retry budget, shared by every HTTP request made while it runs
+ */
+export function withRetryBudget<T>(fn: () => Promise<T>, retries = getRetryBudgetSize()): Promise<T> {
+  return retryBudgetStorage.run(new RetryBudget(retries), fn);
+}
+
+/**
+ * Get the retry budget of the tool call in progress, if any
+ */
+export function currentRetryBudget(): RetryBudget | undefined {
+  return retryBudgetStorage.getStore();
+}
+
+/**
+ * Whether a failed request is worth retrying: network errors, rate limiting and server errors
+ */
+export function isRetryableStatus(status: number | undefined): boolean {
+  return status === undefined || status === 429 || status >= 500;
+}
+
+/**
+ * Take a retry for a failed request, from the current tool call's budget when there is one
+ */
+export function takeRetry(attempt: number): boolean {
+  if (attempt >= MAX_RETRIES_PER_REQUEST) {
+    return false;
+  }
+  const budget = currentRetryBudget();
+  return budget ? budget.take() : true;
+}
+
+/**
+ * Wait before the given retry attempt, backing off exponentially from 250ms
+ */
+export function retryDelay(attempt: number): Promise<void> {
+  return new Promise(resolve => setTimeout(resolve, 250 * 2 ** attempt));
+}
+
+/**
+ * Retry an axios instance's failed requests, within the current tool call's retry budget
+ */
+export function installRetryInterceptor(instance: AxiosInstance, logger: McpLogger): void {
+  instance.interceptors.response.use(undefined, async (error: AxiosError) => {
+    const config = error.config as (InternalAxiosRequestConfig & { retryCount?: number }) | undefined;
+    if (!config || error.code === 'ERR_CANCELED' || !isRetryableStatus(error.response?.status)) {
+      throw error;
+    }
+
+    const attempt = config.retryCount ?? 0;
+    if (!takeRetry(attempt)) {
+      throw error;
+    }
+
+    config.retryCount = attempt + 1;
+    logger.debug(`Retrying ${config.url} (attempt ${config.retryCount}) after: ${error.message}`);
+    await retryDelay(attempt);
+    return instance.request(config);
+  });
+}
@@ -0,0 +1,177 @@
+// Characters rST allows for section title underlines (and overlines)
+const ADORNMENT_PATTERN = /^([=\-~^"'`#*+:._<>!$%&(),/;?@[\\\]{|}])\1{2,}\s*$/;
+
+// Directives whose content is prose worth keeping, rendered as a labelled blockquote
+const ADMONITIONS = ['note', 'warning', 'tip', 'important', 'caution', 'attention', 'danger', 'error', 'hint', 'seealso', 'deprecated', 'versionadded', 'versionchanged'];
+
+// Directives whose content is code, rendered as a fenced block in the directive's language
+const CODE_DIRECTIVES = ['code', 'code-block', 'sourcecode', 'highlight'];
+
+/**
+ * Check whether a PyPI description is reStructuredText. PyPI treats descriptions without a
+ * content type as rST, but many of those are really plain text or markdown, so they're only
+ * taken as rST when they have a title underline and no markdown headings or fences.
+ */
+export function isRestructuredText(contentType: string | null | undefined, text: string): boolean {
+  if (contentType) {
+    return contentType.toLowerCase().startsWith('text/x-rst');
+  }
+
+  const lines = text.split('\n');
+  const hasUnderline = lines.some((line, i) => i > 0 && isTitleUnderline(lines[i - 1], line));
+  return hasUnderline && !/^(#{1,6} |```)/m.test(text);
+}
+
+/**
+ * Convert reStructuredText to markdown: section titles become headings (levels assigned in the
+ * order their adornment styles first appear, as rST does), literal blocks and code directives become
+ * fenced code, admonitions become blockquotes, and inline markup and links are rewritten. Other
+ * directives, comments and substitution definitions are dropped.
+ */
+export function rstToMarkdown(rst: string): string {
+  const lines = rst.replace(/\r\n?/g, '\n').split('\n');
+  const output: string[] = [];
+  const headingStyles: string[] = [];
+
+  const headingLevel = (style: string): number => {
+    if (!headingStyles.includes(style)) headingStyles.push(style);
+    return Math.min(headingStyles.indexOf(style) + 1, 6);
+  };
+
+  let i = 0;
+  while (i < lines.length) {
+    const line = lines[i];
+
+    // Titles with an overline: ====\nTitle\n====
+    if (ADORNMENT_PATTERN.test(line) && lines[i + 1]?.trim() && lines[i + 2] === line && !ADORNMENT_PATTERN.test(lines[i + 1])) {
+      output.push(`${'#'.repeat(headingLevel(`over${line[0]}`))} ${convertInline(lines[i + 1].trim())}`, '');
+      i += 3;
+      continue;
+    }
+
+    // Titles with only an underline: Title\n-----
+    if (lines[i + 1] !== undefined && isTitleUnderline(line, lines[i + 1])) {
+      output.push(`${'#'.repeat(headingLevel(lines[i + 1][0]))} ${convertInline(line.trim())}`, '');
+      i += 2;
+      continue;
+    }
+
+    // Directives and comments, which own the indented block after them
+    const directive = line.match(/^(\s*)\.\.\s+(\|[^|]+\|\s+)?([\w-]+)::\s*(.*)$/);
+    if (directive || /^\s*\.\.(\s|$)/.test(line)) {
+      const indent = (directive?.[1] ?? line.match(/^\s*/)![0]).length;
+      const { block, next } = readIndentedBlock(lines, i + 1, indent);
+      // Substitution definitions (.. |badge| image:: ...) only matter where they're referenced
+      const name = directive && !directive[2] ? directive[3].toLowerCase() : undefined;
+      const argument = directive?.[4].trim() ?? '';
+
+      if (name && CODE_DIRECTIVES.includes(name)) {
+        // Directive options (:linenos: and the like) come before the code
+        const code = block.filter((blockLine, index) => !(index < 3 && /^:[\w-]+:/.test(blockLine.trim())));
+        output.push('```' + argument, ...trimBlankLines(code), '```', '');
+      } else if (name && ADMONITIONS.includes(name)) {
+        const label = name.charAt(0).toUpperCase() + name.slice(1);
+        const body = [argument, ...block].filter((bodyLine, index) => index > 0 || bodyLine);
+        output.push(...trimBlankLines(body).map((bodyLine, index) =>
+          `> ${index === 0 ? `**${label}:** ` : ''}${convertInline(bodyLine.trim())}`.trimEnd()
+        ), '');
+      } else if (name === 'image' || name === 'figure') {
+        output.push(`![](${argument})`, '');
+      }
+
+      i = next;
+      continue;
+    }
+
+    // Doctest blocks, which need no :: to be literal
+    if (/^\s*>>> /.test(line)) {
+      const block: string[] = [];
+      while (i < lines.length && lines[i].trim()) {
+        block.push(lines[i].trim());
+        i++;
+      }
+      output.push('```python', ...block, '```', '');
+      continue;
+    }
+
+    // Paragraphs ending in :: introduce an indented literal block
+    if (/::\s*$/.test(line) && !/^\s*\.\./.test(line)) {
+      const text = line.replace(/\s*::\s*$/, line.trim() === '::' ? '' : ':');
+      if (text.trim()) output.push(convertInline(text));
+
+      const indent = line.match(/^\s*/)![0].length;
+      const { block, next } = readIndentedBlock(lines, i + 1, indent);
+      if (block.some(blockLine => blockLine.trim())) {
+        output.push('', '```', ...trimBlankLines(block), '```');
+        i = next;
+      } else {
+        i++;
+      }
+      continue;
+    }
+
+    output.push(convertInline(line));
+    i++;
+  }
+
+  return output.join('\n').replace(/\n{3,}/g, '\n\n').trim();
+}
+
+/**
+ * Check whether a line underlines the title above it: a run of one adornment character at least
+ * as long as the title
+ */
+function isTitleUnderline(title: string, underline: string): boolean {
+  return (
+    title.trim().length > 0 &&
+    !/^\s/.test(title) &&
+    !ADORNMENT_PATTERN.test(title) &&
+    ADORNMENT_PATTERN.test(underline) &&
+    underline.trim().length >= title.trim().length
+  );
+}
+
+/**
+ * Read the lines indented deeper than a directive or paragraph, dedented, up to the first line that
+ * isn't. Blank lines within the block are kept.
+ */
+function readIndentedBlock(lines: string[], start: number, indent: number): { block: string[]; next: number } {
+  let end = start;
+  while (end < lines.length && (!lines[end].trim() || lines[end].match(/^\s*/)![0].length > indent)) {
+    end++;
+  }
+  // Trailing blank lines belong to whatever follows
+  while (end > start && !lines[end - 1].trim()) {
+    end--;
+  }
+
+  const block = lines.slice(start, end);
+  const dedent = Math.min(...block.filter(line => line.trim()).map(line => line.match(/^\s*/)![0].length));
+  return {
+    block: block.map(line => line.slice(Number.isFinite(dedent) ? dedent : 0)),
+    next: end,
+  };
+}
+
+/**
+ * Drop the blank lines at the start and end of a block
+ */
+function trimBlankLines(block: string[]): string[] {
+  let start = 0;
+  let end = block.length;
+  while (start < end && !block[start].trim()) start++;
+  while (end > start && !block[end - 1].trim()) end--;
+  return block.slice(start, end);
+}
+
+/**
+ * Rewrite rST inline markup as markdown: ``literals``, `links <url>`_, :role:`text` and
+ * `references`_. Emphasis and strong emphasis are the same in both.
+ */
+function convertInline(text: string): string {
+  return text
+    .replace(/``(.+?)``/g, '`$1`')
+    .replace(/`([^`<]+?)\s*<([^>]+)>`__?/g, '[$1]($2)')
+    .replace(/:[\w:+-]+:`~?([^`]+)`/g, '`$1`')
+    .replace(/`([^`]+)`__?/g, '$1');
+}
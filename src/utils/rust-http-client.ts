@@ -1,4 +1,5 @@
 import { logger } from '../logger.js';
+import { isRetryableStatus, retryDelay, takeRetry } from './retry-budget.js';
 
 interface RequestOptions {
 	method?: string;
@@ -11,12 +12,14 @@ type FetchResponse =
 			data: Record<string, unknown>;
 			status: number;
 			headers: Headers;
+			url: string; // Where the response came from, after any redirects
 			contentType: "json";
 	  }
 	| {
 			data: string;
 			status: number;
 			headers: Headers;
+			url: string;
 			contentType: "text";
 	  };
 
@@ -59,9 +62,11 @@ async function rustFetch(
 	baseURL: string,
 	path: string,
 	options: RequestOptions = {},
+	attempt = 0,
 ): Promise<FetchResponse> {
 	const { method = "GET", params, body } = options;
 	const url = buildUrl(baseURL, path, params);
+	let status: number | undefined;
 
 	try {
 		logger.debug(`Making request to ${url}`, { method, params });
@@ -83,6 +88,7 @@ async function rustFetch(
 			contentType: response.headers.get("content-type"),
 		});
 
+		status = response.status;
 		if (!response.ok) {
 			throw new Error(`HTTP error! status: ${response.status}`);
 		}
@@ -96,6 +102,7 @@ async function rustFetch(
 				data,
 				status: response.status,
 				headers: response.headers,
+				url: response.url || url,
 				contentType: "json" as const,
 			};
 		} else {
@@ -104,10 +111,18 @@ async function rustFetch(
 				data,
 				status: response.status,
 				headers: response.headers,
+				url: response.url || url,
 				contentType: "text" as const,
 			};
 		}
 	} catch (error) {
+		// Retry network errors, rate limiting and server errors within the tool call's retry budget
+		if (isRetryableStatus(status) && takeRetry(attempt)) {
+			logger.debug(`Retrying ${url} (attempt ${attempt + 1})`);
+			await retryDelay(attempt);
+			return rustFetch(baseURL, path, options, attempt + 1);
+		}
+
 		logger.error(`Error making request to ${url}`, { error });
 		throw error;
 	}
@@ -0,0 +1,110 @@
+/**
+ * Helpers for describing what changed between two versions of a package,
+ * based on the registry metadata for each version.
+ */
+import { PackageMetadata } from "./search-utils.js"
+
+export interface DependencyChange {
+  name: string
+  change: "added" | "removed" | "changed"
+  from?: string
+  to?: string
+}
+
+export interface MetadataChange {
+  field: string
+  from?: string
+  to?: string
+}
+
+/**
+ * Compare the dependencies of two versions
+ */
+export function diffDependencies(
+  from: Record<string, string> = {},
+  to: Record<string, string> = {}
+): DependencyChange[] {
+  const changes: DependencyChange[] = []
+
+  for (const [name, range] of Object.entries(to)) {
+    if (!(name in from)) {
+      changes.push({ name, change: "added", to: range })
+    } else if (from[name] !== range) {
+      changes.push({ name, change: "changed", from: from[name], to: range })
+    }
+  }
+
+  for (const [name, range] of Object.entries(from)) {
+    if (!(name in to)) {
+      changes.push({ name, change: "removed", from: range })
+    }
+  }
+
+  return changes.sort((a, b) => a.name.localeCompare(b.name))
+}
+
+/**
+ * Compare the descriptive metadata (licence, deprecation, links) of two versions
+ */
+export function diffMetadata(from: PackageMetadata, to: PackageMetadata): MetadataChange[] {
+  const fields: Array<[string, keyof PackageMetadata]> = [
+    ["Licence", "license"],
+    ["Deprecated", "deprecated"],
+    ["Description", "description"],
+    ["Homepage", "homepage"],
+    ["Repository", "repository"],
+  ]
+
+  const changes: MetadataChange[] = []
+  for (const [label, key] of fields) {
+    const fromValue = from[key] as string | undefined
+    const toValue = to[key] as string | undefined
+    if ((fromValue || "") !== (toValue || "")) {
+      changes.push({ field: label, from: fromValue, to: toValue })
+    }
+  }
+
+  return changes
+}
+
+// Keep table cells on one line and stop pipes from breaking the table
+function cell(value: string | undefined): string {
+  return value ? value.replace(/\|/g, "\\|").replace(/\s*\n\s*/g, " ") : "-"
+}
+
+/**
+ * Render the differences between two versions as markdown tables
+ */
+export function formatVersionComparison(from: PackageMetadata, to: PackageMetadata): string {
+  const dependencyChanges = diffDependencies(from.dependencies, to.dependencies)
+  const metadataChanges = diffMetadata(from, to)
+
+  let markdown = `## ${from.version || "?"} → ${to.version || "?"}\n\n`
+
+  if (to.deprecated) {
+    markdown += `> **Warning:** ${to.version} is deprecated: ${to.deprecated}\n\n`
+  }
+
+  markdown += "### Dependencies\n\n"
+  if (dependencyChanges.length === 0) {
+    markdown += "No dependency changes.\n\n"
+  } else {
+    markdown += "| Dependency | Change | From | To |\n| --- | --- | --- | --- |\n"
+    for (const change of dependencyChanges) {
+      markdown += `| ${cell(change.name)} | ${change.change} | ${cell(change.from)} | ${cell(change.to)} |\n`
+    }
+    markdown += "\n"
+  }
+
+  markdown += "### Metadata\n\n"
+  if (metadataChanges.length === 0) {
+    markdown += "No metadata changes.\n"
+  } else {
+    markdown += "| Field | From | To |\n| --- | --- | --- |\n"
+    for (const change of metadataChanges) {
+      markdown += `| ${change.field} | ${cell(change.from)} | ${cell(change.to)} |\n`
+    }
+  }
+
+  return markdown
+}
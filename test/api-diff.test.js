@@ -0,0 +1,184 @@
+import { test } from "node:test"
+import assert from "node:assert/strict"
+import { chmodSync, mkdtempSync, writeFileSync } from "fs"
+import { tmpdir } from "os"
+import { join } from "path"
+import { diffApiSymbols, formatApiDiff, formatSymbolList, parseGoApiSymbols, parseGoShortSymbols, parsePydocSymbols } from "../build/api-diff.js"
+import { PackageDocsServer } from "../build/package-docs-server.js"
+import { callTool } from "./helpers.js"
+
+const from = [
+  { name: "Get", kind: "func", signature: "func Get(url string) (*Response, error)" },
+  { name: "Post", kind: "func", signature: "func Post(url string, body io.Reader) (*Response, error)" },
+  { name: "Client", kind: "type", signature: "type Client struct {\n\tTimeout time.Duration\n}" },
+  { name: "Version", kind: "const" },
+  { name: "Head", kind: "func", signature: "func Head(url string) (*Response, error)" },
+]
+
+const to = [
+  { name: "Get", kind: "func", signature: "func Get(ctx context.Context, url string) (*Response, error)" },
+  // Only reformatted
+  { name: "Post", kind: "func", signature: "func Post(url string,  body io.Reader) ( *Response, error )" },
+  { name: "Client", kind: "type", signature: "type Client struct {\n\tTimeout time.Duration\n\tRetries int\n}" },
+  // No signature to compare
+  { name: "Version", kind: "const", signature: "const Version = \"2.0.0\"" },
+  { name: "Patch", kind: "func", signature: "func Patch(url string) (*Response, error)" },
+  // Same name, another kind
+  { name: "Head", kind: "var", signature: "var Head = Get" },
+]
+
+test("symbols are categorised as added, removed or changed", () => {
+  const diff = diffApiSymbols(from, to)
+
+  assert.deepEqual(diff.added.map(symbol => `${symbol.kind} ${symbol.name}`), ["var Head", "func Patch"])
+  assert.deepEqual(diff.removed.map(symbol => `${symbol.kind} ${symbol.name}`), ["func Head"])
+  assert.deepEqual(diff.changed.map(change => change.name), ["Client", "Get"])
+  assert.deepEqual(diff.changed[1], {
+    name: "Get",
+    kind: "func",
+    from: "func Get(url string) (*Response, error)",
+    to: "func Get(ctx context.Context, url string) (*Response, error)",
+  })
+})
+
+test("identical symbol sets have no changes", () => {
+  assert.deepEqual(diffApiSymbols(from, from), { added: [], removed: [], changed: [] })
+})
+
+test("an API diff is summarised before the symbol lists", () => {
+  const markdown = formatApiDiff(diffApiSymbols(from, to), "v1.0.0", "v2.0.0")
+
+  assert.match(markdown, /^## v1\.0\.0 → v2\.0\.0\n\nAdded 2, Removed 1, Changed 2\n/)
+  assert.match(markdown, /### Added\n\n- `Head` \(var\)\n- `Patch` \(func\)/)
+  assert.match(markdown, /#### Get \(func\)\n\n```diff\n- func Get\(url string\) \(\*Response, error\)\n\+ func Get\(ctx context\.Context, url string\) \(\*Response, error\)\n```/)
+  assert.match(markdown, /- type Client struct \{\n- \tTimeout time\.Duration\n- \}\n\+ type Client struct \{/)
+})
+
+test("go doc -all declarations are read as symbols, exported only", () => {
+  const doc = [
+    "package lite // import \"example.com/lite\"",
+    "",
+    "const (",
+    "\tDefaultTimeout = 30",
+    "\tinternalLimit  = 5",
+    ")",
+    "",
+    "func Get(url string) (*Response, error)",
+    "    Get fetches a URL.",
+    "",
+    "func helper()",
+    "",
+    "type Client struct {",
+    "\tTimeout time.Duration",
+    "}",
+    "",
+    "func (c *Client) Do(req *Request) (*Response, error)",
+    "",
+    "func (c *Client) do()",
+  ].join("\n")
+
+  assert.deepEqual(parseGoApiSymbols(doc), [
+    { name: "DefaultTimeout", kind: "const", signature: "DefaultTimeout = 30" },
+    { name: "Get", kind: "func", signature: "func Get(url string) (*Response, error)" },
+    { name: "Client", kind: "type", signature: "type Client struct {\n\tTimeout time.Duration\n}" },
+    { name: "Client.Do", kind: "method", signature: "func (c *Client) Do(req *Request) (*Response, error)" },
+  ])
+})
+
+const goDocShort = [
+  "const MethodGet = \"GET\"",
+  "var ErrNotSupported = &ProtocolError{...}",
+  "func Get(url string) (resp *Response, err error)",
+  "type Client struct{ ... }",
+  "type Header map[string][]string",
+  "    func NewRequest(method, url string, body io.Reader) (*Request, error)",
+  "func unexported()",
+].join("\n")
+
+test("go doc -short output lists each exported declaration, including indented constructors", () => {
+  assert.deepEqual(parseGoShortSymbols(goDocShort).map(symbol => `${symbol.kind} ${symbol.name}`), [
+    "const MethodGet",
+    "var ErrNotSupported",
+    "func Get",
+    "type Client",
+    "type Header",
+    "func NewRequest",
+  ])
+  assert.equal(parseGoShortSymbols(goDocShort)[2].signature, "func Get(url string) (resp *Response, err error)")
+})
+
+test("pydoc output lists the public classes, functions and data", () => {
+  const pydoc = [
+    "Help on package requests:",
+    "",
+    "NAME",
+    "    requests",
+    "",
+    "CLASSES",
+    "    builtins.object",
+    "        requests.Session",
+    "    ",
+    "    class Session(requests.sessions.SessionRedirectMixin)",
+    "     |  A Requests session.",
+    "    class _Private(builtins.object)",
+    "",
+    "FUNCTIONS",
+    "    get(url, params=None, **kwargs)",
+    "        Sends a GET request.",
+    "    _helper()",
+    "",
+    "DATA",
+    "    codes = <lookup 'status_codes'>",
+    "    __all__ = ['get']",
+  ].join("\n")
+
+  assert.deepEqual(parsePydocSymbols(pydoc), [
+    { name: "Session", kind: "class", signature: "class Session(requests.sessions.SessionRedirectMixin)" },
+    { name: "get", kind: "function", signature: "get(url, params=None, **kwargs)" },
+    { name: "codes", kind: "data", signature: "codes = <lookup 'status_codes'>" },
+  ])
+})
+
+test("symbol lists are grouped by kind in the order the kinds appear", () => {
+  assert.equal(formatSymbolList([
+    { name: "Get", kind: "func", signature: "func Get(url string)" },
+    { name: "Client", kind: "type", signature: "type Client struct {\n\tTimeout time.Duration\n}" },
+    { name: "Post", kind: "func", signature: "func Post(url string)" },
+    { name: "VERSION", kind: "const" },
+  ]), [
+    "### func (2)",
+    "",
+    "- `Get`: `func Get(url string)`",
+    "- `Post`: `func Post(url string)`",
+    "",
+    "### type (1)",
+    "",
+    "- `Client`: `type Client struct {`",
+    "",
+    "### const (1)",
+    "",
+    "- `VERSION`",
+  ].join("\n"))
+})
+
+test("list_package_symbols lists a Go package's symbols from go doc -short", async () => {
+  const bin = mkdtempSync(join(tmpdir(), "package-docs-go-"))
+  // A go on the PATH whose go doc -short prints the declarations above for net/http, and nothing for other packages
+  writeFileSync(join(bin, "go"), `#!/bin/sh\n[ "$3" = net/http ] && printf '%s\\n' '${goDocShort}'\nexit 0\n`)
+  chmodSync(join(bin, "go"), 0o755)
+  const path = process.env.PATH
+  process.env.PATH = bin
+
+  try {
+    const server = new PackageDocsServer()
+    const text = await callTool(server, "list_package_symbols", { package: "net/http", language: "go" })
+    assert.match(text, /6 exported symbols in net\/http/)
+    assert.match(text, /### func \(2\)/)
+    assert.match(text, /- `NewRequest`: `func NewRequest\(method, url string, body io\.Reader\) \(\*Request, error\)`/)
+
+    assert.match(await callTool(server, "list_package_symbols", { package: "net/missing", language: "go" }), /No exported symbols found for net\/missing/)
+    await assert.rejects(callTool(server, "list_package_symbols", { package: "net/http", language: "swift" }), /Invalid list_package_symbols arguments/)
+  } finally {
+    process.env.PATH = path
+  }
+})
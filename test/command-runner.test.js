@@ -0,0 +1,167 @@
+import { test } from 'node:test';
+import assert from 'node:assert/strict';
+import { chmodSync, mkdirSync, mkdtempSync, realpathSync, symlinkSync, writeFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { dirname, join } from 'path';
+import { CommandError, CommandNotAllowedError, isCommandAllowed, runCommand, setAllowedCommands, ToolNotInstalledError } from '../build/utils/command-runner.js';
+import { PackageDocsServer } from '../build/package-docs-server.js';
+import { callTool, notFound, restoreNetwork, stubGet } from './helpers.js';
+
+// The tests run node itself, as the one executable every environment running them has
+const nodeDir = dirname(process.execPath);
+
+test('bare names on the allowlist are run from the PATH', async () => {
+  setAllowedCommands(['node'], []);
+  const { stdout } = await runCommand('node', { args: ['-e', 'process.stdout.write("ok")'] });
+  assert.equal(stdout, 'ok');
+});
+
+test('names missing from the allowlist are refused without being run', async () => {
+  setAllowedCommands(['go'], []);
+  await assert.rejects(runCommand('node', { args: ['--version'] }), CommandNotAllowedError);
+});
+
+test('paths are only allowed inside an allowed directory', () => {
+  // A project can put anything named python3 in its .venv, so the name alone doesn't allow it
+  const project = mkdtempSync(join(tmpdir(), 'package-docs-project-'));
+  const binDir = join(project, '.venv', 'bin');
+  mkdirSync(binDir, { recursive: true });
+  symlinkSync(nodeDir, join(project, 'linked-bin'));
+
+  setAllowedCommands(['node'], []);
+  assert.equal(isCommandAllowed(join(binDir, 'node')), false);
+  assert.equal(isCommandAllowed(process.execPath), false);
+
+  setAllowedCommands(['node'], [project]);
+  assert.equal(isCommandAllowed(join(binDir, 'node')), true);
+  assert.equal(isCommandAllowed(join(project, '..', 'node')), false);
+  // A symlink inside the allowed directory can't reach a directory outside it
+  assert.equal(isCommandAllowed(join(project, 'linked-bin', 'node')), false);
+
+  setAllowedCommands(['node'], [nodeDir]);
+  assert.equal(isCommandAllowed(process.execPath), true);
+});
+
+test('an allowed directory only allows commands on the allowlist', () => {
+  setAllowedCommands(['go'], [nodeDir]);
+  assert.equal(isCommandAllowed(process.execPath), false);
+});
+
+test('output over the cap is truncated with a marker rather than failing the command', async () => {
+  setAllowedCommands(['node'], []);
+  // Write far more than the cap, in chunks, and exit with an error once done
+  const script = 'for (let i = 0; i < 100; i++) process.stdout.write("x".repeat(1024)); process.exitCode = 1;';
+  const result = await runCommand('node', { args: ['-e', script], maxOutputBytes: 4096 });
+
+  assert.equal(result.truncated, true);
+  assert.equal(result.stdout, `${'x'.repeat(4096)}\n\n[Output truncated at 4096 bytes]`);
+});
+
+test('output under the cap is returned whole', async () => {
+  setAllowedCommands(['node'], []);
+  const result = await runCommand('node', { args: ['-e', 'process.stdout.write("x".repeat(1000))'], maxOutputBytes: 4096 });
+
+  assert.equal(result.truncated, false);
+  assert.equal(result.stdout, 'x'.repeat(1000));
+});
+
+test('commands run in the given working directory', async () => {
+  setAllowedCommands(['node'], []);
+  const cwd = mkdtempSync(join(tmpdir(), 'package-docs-cwd-'));
+  const { stdout } = await runCommand('node', { args: ['-e', 'process.stdout.write(process.cwd())'], cwd });
+  assert.equal(stdout, realpathSync(cwd));
+});
+
+test('variables are set on top of the server\'s environment, and undefined ones unset', async () => {
+  setAllowedCommands(['node'], []);
+  process.env.PACKAGE_DOCS_TEST_UNSET = 'inherited';
+  try {
+    const script = 'process.stdout.write(JSON.stringify([process.env.GOFLAGS, process.env.PACKAGE_DOCS_TEST_UNSET, !!process.env.PATH]))';
+    const { stdout } = await runCommand('node', { args: ['-e', script], env: { GOFLAGS: '-mod=mod', PACKAGE_DOCS_TEST_UNSET: undefined } });
+    assert.deepEqual(JSON.parse(stdout), ['-mod=mod', null, true]);
+
+    // Without env the command gets the server's environment as is
+    const inherited = await runCommand('node', { args: ['-e', 'process.stdout.write(process.env.PACKAGE_DOCS_TEST_UNSET)'] });
+    assert.equal(inherited.stdout, 'inherited');
+  } finally {
+    delete process.env.PACKAGE_DOCS_TEST_UNSET;
+  }
+});
+
+test('a missing working directory is a command error, not a missing executable', async () => {
+  setAllowedCommands(['node'], []);
+  const cwd = join(tmpdir(), 'package-docs-missing-dir', 'nowhere');
+  const error = await runCommand('node', { args: ['--version'], cwd }).catch(error => error);
+  assert.ok(error instanceof CommandError);
+  assert.ok(!(error instanceof ToolNotInstalledError));
+  assert.equal(error.code, 'ENOENT');
+  assert.match(error.message, /working directory .*nowhere does not exist/);
+});
+
+test('go doc runs in the tool call\'s projectPath', { skip: process.platform === 'win32' }, async () => {
+  setAllowedCommands(['go'], []);
+  const projectPath = mkdtempSync(join(tmpdir(), 'package-docs-project-'));
+  const bin = mkdtempSync(join(tmpdir(), 'package-docs-go-'));
+  writeFileSync(join(bin, 'go'), '#!/bin/sh\necho "ran in $(pwd) with $*"\n');
+  chmodSync(join(bin, 'go'), 0o755);
+  const path = process.env.PATH;
+  process.env.PATH = bin;
+  try {
+    const result = JSON.parse(await callTool(new PackageDocsServer(), 'describe_go_package', { package: 'strings', projectPath, raw: true }));
+    assert.equal(result.usage.trim(), `ran in ${realpathSync(projectPath)} with doc strings`);
+  } finally {
+    process.env.PATH = path;
+  }
+});
+
+// Run with a PATH holding nothing, so no command can be found
+async function withoutTools(run) {
+  const path = process.env.PATH;
+  process.env.PATH = mkdtempSync(join(tmpdir(), 'package-docs-empty-bin-'));
+  try {
+    await run();
+  } finally {
+    process.env.PATH = path;
+  }
+}
+
+test('executables that aren\'t installed are reported as such', async () => {
+  setAllowedCommands(['go'], []);
+  await withoutTools(async () => {
+    const error = await runCommand('go', { args: ['version'] }).catch(error => error);
+    assert.ok(error instanceof ToolNotInstalledError);
+    assert.equal(error.command, 'go');
+    assert.equal(error.message, 'go is not installed or not on the PATH');
+  });
+});
+
+test('commands that run and fail are command errors, with their output', async () => {
+  setAllowedCommands(['node'], []);
+  const error = await runCommand('node', { args: ['-e', 'console.error("bad flag"); process.exit(3)'] }).catch(error => error);
+  assert.ok(error instanceof CommandError);
+  assert.ok(!(error instanceof ToolNotInstalledError));
+  assert.equal(error.code, 3);
+  assert.equal(error.stderr, 'bad flag\n');
+  assert.match(error.message, /exited with code 3\nbad flag$/);
+});
+
+test('a missing go is logged as a fallback and reported with how to install it', { skip: process.platform === 'win32' }, async () => {
+  setAllowedCommands(['go'], []);
+  stubGet(url => notFound(url));
+  const server = new PackageDocsServer();
+  const debug = [];
+  server['logger'] = { debug(message) { debug.push(message); }, info() {}, warn() {}, error() {} };
+
+  try {
+    await withoutTools(async () => {
+      await server['describeGoPackage']({ package: 'strings' });
+      assert.ok(debug.includes('go not found, using pkg.go.dev'), debug.join('\n'));
+
+      const full = await server['getGoPackageDocumentation']({ package: 'strings', language: 'go' });
+      assert.match(full.error, /go is not installed or not on the PATH\. Install Go to read full documentation, or use search_package_docs/);
+      assert.equal(full.suggestInstall, undefined);
+    });
+  } finally {
+    restoreNetwork();
+  }
+});
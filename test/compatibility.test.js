@@ -0,0 +1,83 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { SearchUtils } from '../build/search-utils.js'
+import { callTool, notFound, restoreNetwork, silentLogger, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+const searchUtils = new SearchUtils(silentLogger)
+
+const readme = [
+  '# widgets',
+  '',
+  'Works with React 16, 17 and 18.',
+  '',
+  '## Install',
+  '',
+  '```sh',
+  '# requires node 99',
+  'npm install widgets',
+  '```',
+  '',
+  '## Supported versions',
+  '',
+  '| Widgets | Node | React |',
+  '| --- | --- | --- |',
+  '| 3.x | >=18 | 18 |',
+  '| 2.x | >=14 | 16, 17 |',
+  '',
+  '## Browsers',
+  '',
+  '| Browser | Supported |',
+  '| --- | --- |',
+  '| Chrome | last 2 |',
+  '',
+  '## Requirements',
+  '',
+  'A DOM to render into.',
+].join('\n')
+
+test('compatibility comes from matching sections, support tables elsewhere and statements in prose', () => {
+  const { sections, statements } = searchUtils.extractCompatibility(readme)
+
+  assert.deepEqual(sections.map(section => section.title), ['Supported versions', 'Browsers', 'Requirements'])
+  assert.deepEqual(sections[0].tables, [{ headers: ['Widgets', 'Node', 'React'], rows: [['3.x', '>=18', '18'], ['2.x', '>=14', '16, 17']] }])
+  // Support tables outside a compatibility section are kept without the rest of their section
+  assert.equal(sections[1].content, '')
+  assert.deepEqual(sections[1].tables[0].headers, ['Browser', 'Supported'])
+  assert.deepEqual(sections[2].tables, [])
+  // Statements in code blocks are not prose
+  assert.deepEqual(statements, ['Works with React 16, 17 and 18.'])
+})
+
+test('get_compatibility renders the tables and statements from the README', async () => {
+  stubGet(url => {
+    if (url === 'https://registry.npmjs.org/widgets') {
+      return { data: { name: 'widgets', 'dist-tags': { latest: '3.0.0' }, versions: { '3.0.0': {} }, readme } }
+    }
+    notFound(url)
+  })
+
+  const server = new PackageDocsServer()
+  const text = await callTool(server, 'get_compatibility', { package: 'widgets', language: 'npm' })
+
+  assert.match(text, /Compatibility information from the widgets README/)
+  assert.match(text, /\| 2\.x +\| >=14 +\| 16, 17 \|/)
+  assert.match(text, /A DOM to render into\./)
+  assert.match(text, /## Stated compatibility\n\n- Works with React 16, 17 and 18\./)
+  assert.doesNotMatch(text, /requires node 99/)
+})
+
+test('get_compatibility reports READMEs without compatibility information', async () => {
+  stubGet(url => {
+    if (url === 'https://registry.npmjs.org/plain-widgets') {
+      return { data: { name: 'plain-widgets', 'dist-tags': { latest: '1.0.0' }, versions: { '1.0.0': {} }, readme: '# plain-widgets\n\n## Usage\n\nCall widget().' } }
+    }
+    notFound(url)
+  })
+
+  const server = new PackageDocsServer()
+  assert.match(await callTool(server, 'get_compatibility', { package: 'plain-widgets', language: 'npm' }), /No compatibility information found in the README for plain-widgets/)
+  await assert.rejects(callTool(server, 'get_compatibility', { package: 'plain-widgets', language: 'cobol' }), /Invalid get_compatibility arguments/)
+})
@@ -0,0 +1,74 @@
+import { test } from 'node:test';
+import assert from 'node:assert/strict';
+import { ConcurrencyGate, getMaxConcurrentCalls } from '../build/utils/concurrency-gate.js';
+
+// A task that runs until release() is called, recording when it starts
+function deferredTask(started, name) {
+  let release;
+  const finished = new Promise(resolve => { release = resolve; });
+  return {
+    run: async () => {
+      started.push(name);
+      await finished;
+      return name;
+    },
+    release: () => release(),
+  };
+}
+
+const tick = () => new Promise(resolve => setImmediate(resolve));
+
+test('no more than the limit run at once, and queued tasks start in order', async () => {
+  const gate = new ConcurrencyGate(2);
+  const started = [];
+  const tasks = ['a', 'b', 'c', 'd', 'e'].map(name => deferredTask(started, name));
+  const results = tasks.map(task => gate.run(task.run));
+
+  await tick();
+  assert.deepEqual(started, ['a', 'b']);
+  assert.equal(gate.running, 2);
+  assert.equal(gate.waiting, 3);
+
+  tasks[1].release();
+  await tick();
+  assert.deepEqual(started, ['a', 'b', 'c']);
+  assert.equal(gate.running, 2);
+
+  // A task arriving now waits behind those already queued
+  const late = deferredTask(started, 'late');
+  const lateResult = gate.run(late.run);
+  tasks[0].release();
+  await tick();
+  assert.deepEqual(started, ['a', 'b', 'c', 'd']);
+
+  for (const task of [...tasks, late]) task.release();
+  assert.deepEqual(await Promise.all([...results, lateResult]), ['a', 'b', 'c', 'd', 'e', 'late']);
+  assert.equal(gate.running, 0);
+  assert.equal(gate.waiting, 0);
+});
+
+test('a rejected task frees its slot for the next one', async () => {
+  const gate = new ConcurrencyGate(1);
+  const started = [];
+  const failing = gate.run(async () => {
+    started.push('failing');
+    throw new Error('registry unavailable');
+  });
+  const next = gate.run(async () => {
+    started.push('next');
+    return 'ok';
+  });
+
+  await assert.rejects(failing, /registry unavailable/);
+  assert.equal(await next, 'ok');
+  assert.deepEqual(started, ['failing', 'next']);
+  assert.equal(gate.running, 0);
+});
+
+test('the limit comes from PACKAGE_DOCS_MAX_CONCURRENT_CALLS when it is a positive integer', () => {
+  assert.equal(getMaxConcurrentCalls('8'), 8);
+  assert.equal(getMaxConcurrentCalls(undefined), 4);
+  assert.equal(getMaxConcurrentCalls('0'), 4);
+  assert.equal(getMaxConcurrentCalls('2.5'), 4);
+  assert.equal(getMaxConcurrentCalls('many'), 4);
+});
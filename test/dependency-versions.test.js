@@ -0,0 +1,179 @@
+import { afterEach, test } from 'node:test';
+import assert from 'node:assert/strict';
+import { mkdtempSync, writeFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { RECENT_NPM_VERSIONS, escapeGoModulePath, findCargoLockVersion, findGoModRequirement, findNpmLockVersion, findPythonLockVersion, latestVersion, maxSatisfyingVersion, pinnedVersion, recentNpmVersions, splitPackageSpec } from '../build/dependency-versions.js';
+import { PackageDocsServer } from '../build/package-docs-server.js';
+import { notFound, restoreNetwork, stubGet } from './helpers.js';
+
+afterEach(restoreNetwork);
+
+// A packument shaped like @types/node's, with thousands of versions published over the years
+function largePackument(count) {
+  const versions = {};
+  const time = { created: '2010-01-01T00:00:00.000Z', modified: '2026-01-01T00:00:00.000Z' };
+  for (let i = 0; i < count; i++) {
+    const version = `${Math.floor(i / 100)}.${i % 100}.0`;
+    versions[version] = { version };
+    time[version] = new Date(Date.UTC(2010, 0, 1) + i * 3600_000).toISOString();
+  }
+  const latest = `${Math.floor((count - 1) / 100)}.${(count - 1) % 100}.0`;
+  const next = `${Math.floor((count - 1) / 100) + 1}.0.0-beta.1`;
+  versions[next] = { version: next };
+  time[next] = '2026-02-01T00:00:00.000Z';
+  return { versions, time, 'dist-tags': { latest, legacy: '0.1.0' } };
+}
+
+test('only dist-tags and the most recent versions are considered', () => {
+  const packument = largePackument(5000);
+  const recent = recentNpmVersions(packument);
+
+  assert.ok(recent.length <= RECENT_NPM_VERSIONS + 2);
+  assert.ok(recent.includes('0.1.0'), 'dist-tags are always included');
+  assert.ok(recent.includes('50.0.0-beta.1'), 'the newest publish is included');
+  assert.ok(!recent.includes('10.0.0'), 'old untagged versions are left out');
+  assert.equal(latestVersion(recent), '49.99.0');
+  assert.equal(latestVersion(recent, true), '50.0.0-beta.1');
+});
+
+test('choosing a version from a large packument matches sorting every version', () => {
+  const packument = largePackument(20000);
+  const started = performance.now();
+  const latest = latestVersion(recentNpmVersions(packument));
+  const bounded = performance.now() - started;
+
+  const fullStarted = performance.now();
+  assert.equal(latestVersion(Object.keys(packument.versions)), latest);
+  const full = performance.now() - fullStarted;
+
+  // Reported rather than asserted, as timings vary between machines
+  console.log(`latest of 20000 versions: ${bounded.toFixed(1)}ms bounded, ${full.toFixed(1)}ms sorting every version`);
+});
+
+test('packuments without times fall back to the last listed versions', () => {
+  const versions = Object.fromEntries(Array.from({ length: 500 }, (_, i) => [`1.${i}.0`, {}]));
+  const recent = recentNpmVersions({ versions, 'dist-tags': { latest: '1.499.0' } });
+  assert.equal(recent.length, RECENT_NPM_VERSIONS);
+  assert.equal(maxSatisfyingVersion(recent, '^1.450.0', 'npm'), '1.499.0');
+});
+
+test('package specs are split at the last @, leaving a scope alone', () => {
+  assert.deepEqual(splitPackageSpec('express@4.18.2'), { name: 'express', version: '4.18.2' });
+  assert.deepEqual(splitPackageSpec('@types/node@20.0.0'), { name: '@types/node', version: '20.0.0' });
+  assert.deepEqual(splitPackageSpec('@types/node'), { name: '@types/node' });
+  assert.deepEqual(splitPackageSpec('github.com/spf13/cobra@v1.8.0'), { name: 'github.com/spf13/cobra', version: 'v1.8.0' });
+  assert.deepEqual(splitPackageSpec('express@'), { name: 'express', version: undefined });
+});
+
+test('only exact requirements pin a version', () => {
+  for (const [requirement, version] of [['1.2.3', '1.2.3'], ['=1.2.3', '1.2.3'], ['==2.31.0', '2.31.0'], ['v1.8.0', '1.8.0'], ['1.0.0-beta.1', '1.0.0-beta.1']]) {
+    assert.equal(pinnedVersion(requirement), version, requirement);
+  }
+  for (const requirement of ['^1.2.3', '~1.2', '>=1.2', '1.x', '*', '<2,>=1']) {
+    assert.equal(pinnedVersion(requirement), undefined, requirement);
+  }
+});
+
+test('npm lockfiles give the copy of a dependency the parent resolves', () => {
+  const lock = {
+    packages: {
+      '': { dependencies: { express: '^4.0.0', 'body-parser': '^2.0.0' } },
+      'node_modules/express': { version: '4.18.2' },
+      'node_modules/express/node_modules/body-parser': { version: '1.20.1' },
+      'node_modules/body-parser': { version: '2.0.0' },
+      'node_modules/debug': { version: '4.3.4' },
+      'node_modules/express/node_modules/send': { version: '0.18.0' },
+      'node_modules/express/node_modules/send/node_modules/ms': { version: '2.1.3' },
+      'node_modules/ms': { version: '2.0.0' },
+    },
+  };
+
+  assert.equal(findNpmLockVersion(lock, 'express', 'body-parser'), '1.20.1');
+  assert.equal(findNpmLockVersion(lock, 'express', 'debug'), '4.3.4');
+  // A nested parent resolves its own copy first, then its ancestors' copies
+  assert.equal(findNpmLockVersion(lock, 'send', 'ms'), '2.1.3');
+  assert.equal(findNpmLockVersion(lock, 'send', 'body-parser'), '1.20.1');
+  assert.equal(findNpmLockVersion(lock, 'missing', 'debug'), undefined);
+  assert.equal(findNpmLockVersion({ lockfileVersion: 1, dependencies: {} }, 'express', 'debug'), undefined);
+});
+
+test('Cargo and Python lockfiles give the locked version of a dependency', () => {
+  const cargoLock = [
+    '[[package]]', 'name = "app"', 'version = "0.1.0"', 'dependencies = [', ' "rand 0.8.5",', ' "serde",', ']',
+    '', '[[package]]', 'name = "rand"', 'version = "0.7.3"',
+    '', '[[package]]', 'name = "rand"', 'version = "0.8.5"',
+    '', '[[package]]', 'name = "serde"', 'version = "1.0.190"',
+  ].join('\n');
+  assert.equal(findCargoLockVersion(cargoLock, 'app', 'rand'), '0.8.5');
+  assert.equal(findCargoLockVersion(cargoLock, 'app', 'serde'), '1.0.190');
+  assert.equal(findCargoLockVersion(cargoLock, 'app', 'tokio'), undefined);
+
+  const poetryLock = '[[package]]\nname = "Charset_Normalizer"\nversion = "3.3.2"\n\n[[package]]\nname = "requests"\nversion = "2.31.0"\n';
+  assert.equal(findPythonLockVersion(poetryLock, 'charset-normalizer'), '3.3.2');
+  assert.equal(findPythonLockVersion(poetryLock, 'idna'), undefined);
+});
+
+test('go.mod requirements are matched to the longest module containing a package', () => {
+  const goMod = [
+    'module example.com/app',
+    '',
+    'require github.com/spf13/cobra v1.8.0',
+    '',
+    'require (',
+    '\tgolang.org/x/net v0.17.0 // indirect',
+    '\tgolang.org/x/net/http2 v0.1.0',
+    ')',
+    '',
+    'replace github.com/old/mod v1.0.0 => github.com/new/mod v2.0.0',
+  ].join('\n');
+
+  assert.deepEqual(findGoModRequirement(goMod, 'github.com/spf13/cobra/doc'), { module: 'github.com/spf13/cobra', version: 'v1.8.0' });
+  assert.deepEqual(findGoModRequirement(goMod, 'golang.org/x/net/http2/hpack'), { module: 'golang.org/x/net/http2', version: 'v0.1.0' });
+  assert.deepEqual(findGoModRequirement(goMod, 'golang.org/x/net/html'), { module: 'golang.org/x/net', version: 'v0.17.0' });
+  assert.equal(findGoModRequirement(goMod, 'github.com/old/mod'), undefined);
+  assert.equal(escapeGoModulePath('github.com/BurntSushi/toml'), 'github.com/!burnt!sushi/toml');
+});
+
+test('a transitive dependency is resolved from the project lockfile, then the parent\'s pinned requirements', async () => {
+  const projectPath = mkdtempSync(join(tmpdir(), 'package-docs-lock-'));
+  writeFileSync(join(projectPath, 'package-lock.json'), JSON.stringify({
+    packages: { 'node_modules/express': { version: '4.18.2' }, 'node_modules/express/node_modules/debug': { version: '2.6.8' } },
+  }));
+  const manifest = { name: 'express', version: '4.18.2', dependencies: { debug: '2.6.9', 'body-parser': '^1.20.0' } };
+  stubGet(url => {
+    if (url === 'https://registry.npmjs.org/express') {
+      return { data: { name: 'express', 'dist-tags': { latest: '4.18.2' }, versions: { '4.18.2': manifest } } };
+    }
+    if (url === 'https://registry.npmjs.org/express/4.18.2') {
+      return { data: manifest };
+    }
+    notFound(url);
+  });
+  const server = new PackageDocsServer();
+  const resolve = (parent, dependency, path) => server['resolveDependencyVersion']('npm', parent, dependency, path);
+
+  assert.equal(await resolve('express', 'debug', projectPath), '2.6.8');
+  // A parent version given explicitly isn't necessarily the installed one, so its metadata decides
+  assert.equal(await resolve('express@4.18.2', 'debug', projectPath), '2.6.9');
+  await assert.rejects(resolve('express@4.18.2', 'body-parser', projectPath), /requires body-parser \^1\.20\.0 rather than an exact version/);
+  await assert.rejects(resolve('express@4.18.2', 'left-pad', projectPath), /does not depend on left-pad/);
+  // Without a lockfile entry the latest version's requirements are used
+  assert.equal(await resolve('express', 'debug', mkdtempSync(join(tmpdir(), 'package-docs-lock-'))), '2.6.9');
+});
+
+test('a Go transitive dependency is resolved from the parent module\'s go.mod', async () => {
+  const urls = stubGet(url => {
+    if (url === 'https://proxy.golang.org/github.com/!burnt!sushi/toml/@latest') return { data: { Version: 'v1.3.2' } };
+    if (url === 'https://proxy.golang.org/github.com/!burnt!sushi/toml/@v/v1.3.2.mod') return { data: 'module github.com/BurntSushi/toml\n\nrequire golang.org/x/text v0.14.0\n' };
+    notFound(url);
+  });
+  const server = new PackageDocsServer();
+  const projectPath = mkdtempSync(join(tmpdir(), 'package-docs-go-'));
+
+  assert.equal(await server['resolveDependencyVersion']('go', 'github.com/BurntSushi/toml', 'golang.org/x/text/unicode', projectPath), 'v0.14.0');
+  assert.deepEqual(urls, [
+    'https://proxy.golang.org/github.com/!burnt!sushi/toml/@latest',
+    'https://proxy.golang.org/github.com/!burnt!sushi/toml/@v/v1.3.2.mod',
+  ]);
+});
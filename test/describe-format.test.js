@@ -0,0 +1,95 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { callTool, notFound, restoreNetwork, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+test('describe with format json returns the metadata of the PyPI release it read', async () => {
+  const urls = stubGet(url => {
+    if (url === 'https://pypi.org/pypi/requests-json/json') {
+      return {
+        data: {
+          info: {
+            name: 'requests-json',
+            version: '1.2.0',
+            summary: 'HTTP for humans',
+            license: 'Apache-2.0',
+            requires_dist: ['idna (<4,>=2.5)', "PySocks!=1.5.7; extra == 'socks'"],
+            project_urls: { Source: 'https://github.com/psf/requests' },
+          },
+        },
+      }
+    }
+    notFound(url)
+  })
+
+  const server = new PackageDocsServer()
+  const result = JSON.parse(await callTool(server, 'describe_python_package', { package: 'requests-json', format: 'json', source: 'network' }))
+
+  assert.deepEqual(urls.filter(url => url.startsWith('https://pypi.org/')), ['https://pypi.org/pypi/requests-json/json'])
+  assert.equal(result.metadata.name, 'requests-json')
+  assert.equal(result.metadata.version, '1.2.0')
+  assert.equal(result.metadata.repository, 'https://github.com/psf/requests')
+  assert.deepEqual(result.metadata.dependencies, { idna: '<4,>=2.5' })
+  assert.equal(result.usage, undefined)
+})
+
+test('describe with format json returns the metadata of the npm manifest it read', async () => {
+  const urls = stubGet(url => {
+    if (url === 'https://registry.npmjs.org/left-pad-json') {
+      return {
+        data: {
+          name: 'left-pad-json',
+          description: 'Pads strings',
+          'dist-tags': { latest: '1.3.0' },
+          versions: { '1.3.0': { name: 'left-pad-json', version: '1.3.0', license: 'WTFPL', dependencies: { tslib: '^2.0.0' } } },
+        },
+      }
+    }
+    notFound(url)
+  })
+
+  const server = new PackageDocsServer()
+  const result = JSON.parse(await callTool(server, 'describe_npm_package', { package: 'left-pad-json', format: 'json', source: 'network', includeTypes: false, includeExamples: false }))
+
+  assert.deepEqual(urls.filter(url => url.startsWith('https://registry.npmjs.org/')), ['https://registry.npmjs.org/left-pad-json'])
+  assert.equal(result.metadata.version, '1.3.0')
+  assert.deepEqual(result.metadata.dependencies, { tslib: '^2.0.0' })
+})
+
+test('describe without a format leaves the metadata out', async () => {
+  stubGet(url => {
+    if (url === 'https://pypi.org/pypi/plain-pkg/json') {
+      return { data: { info: { name: 'plain-pkg', version: '1.0.0', summary: 'Plain' } } }
+    }
+    notFound(url)
+  })
+
+  const server = new PackageDocsServer()
+  const result = JSON.parse(await callTool(server, 'describe_python_package', { package: 'plain-pkg', source: 'network' }))
+  assert.equal(result.metadata, undefined)
+  assert.match(result.description, /Plain/)
+})
+
+test('describe_npm_package returns the README option tables', async () => {
+  stubGet(url => {
+    if (url === 'https://registry.npmjs.org/tabled-opts') {
+      return {
+        data: {
+          name: 'tabled-opts',
+          description: 'Has options',
+          'dist-tags': { latest: '1.0.0' },
+          versions: { '1.0.0': {} },
+          readme: '# tabled-opts\n\n## Options\n\n| Option | Description |\n| --- | --- |\n| `depth` | How deep to go |\n',
+        },
+      }
+    }
+    notFound(url)
+  })
+
+  const server = new PackageDocsServer()
+  const result = JSON.parse(await callTool(server, 'describe_npm_package', { package: 'tabled-opts', format: 'json', source: 'network', includeTypes: false, includeExamples: false }))
+
+  assert.deepEqual(result.options, [{ headers: ['Option', 'Description'], rows: [['`depth`', 'How deep to go']] }])
+})
@@ -0,0 +1,45 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { callTool, restoreNetwork, stubFetch, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+// Queries with nothing to search for, once quotes and OR are set aside
+const EMPTY_QUERIES = ['', '   ', '""', 'OR']
+
+function stubNetwork() {
+  const urls = stubGet(url => assert.fail(`requested ${url}`))
+  const fetched = stubFetch(url => assert.fail(`fetched ${url}`))
+  return () => [...urls, ...fetched]
+}
+
+for (const language of ['go', 'python', 'npm', 'swift', 'rust', 'php', 'java', 'dotnet']) {
+  test(`search_package_docs rejects an empty query for ${language} without fetching anything`, async () => {
+    const requests = stubNetwork()
+    const server = new PackageDocsServer()
+
+    for (const query of EMPTY_QUERIES) {
+      await assert.rejects(
+        callTool(server, 'search_package_docs', { package: 'https://github.com/acme/widgets', language, query }),
+        /query is required: give one or more terms to search the documentation for/
+      )
+    }
+    assert.deepEqual(requests(), [])
+  })
+}
+
+for (const language of ['go', 'python', 'npm', 'rust', 'php', 'java', 'dotnet']) {
+  test(`search_packages rejects an empty query for ${language} without fetching anything`, async () => {
+    const requests = stubNetwork()
+    const server = new PackageDocsServer()
+
+    for (const query of ['', '   ']) {
+      await assert.rejects(
+        callTool(server, 'search_packages', { language, query }),
+        /query is required: give a package name or keywords to search for/
+      )
+    }
+    assert.deepEqual(requests(), [])
+  })
+}
@@ -0,0 +1,134 @@
+import { test } from 'node:test'
+import assert from 'node:assert/strict'
+import { SearchUtils } from '../build/search-utils.js'
+import { silentLogger } from './helpers.js'
+
+const searchUtils = new SearchUtils(silentLogger)
+
+const fence = (language, lines) => ['```' + language, ...lines, '```'].join('\n')
+
+test('wrapped TypeScript signatures are joined onto one line', () => {
+  const readme = fence('ts', [
+    'export async function createClient(',
+    '  url: string,',
+    '  options: ClientOptions = {},',
+    '): Promise<Client> {',
+    '  return new Client(url, options)',
+    '}',
+    '',
+    'export const retry = async <T>(',
+    '  task: () => Promise<T>,',
+    '  attempts: number',
+    '): Promise<T> => {',
+    '  return task()',
+    '}',
+    '',
+    'class Client {',
+    '  async request<T>(',
+    '    path: string,',
+    '    init?: RequestInit',
+    '  ): Promise<T> {',
+    '    return fetch(path, init)',
+    '  }',
+    '}',
+  ])
+
+  assert.deepEqual(searchUtils.extractFunctionSignatures(readme), [
+    { name: 'createClient', signature: 'export async function createClient(url: string, options: ClientOptions = {}): Promise<Client>' },
+    { name: 'retry', signature: 'export const retry = async <T>(task: () => Promise<T>, attempts: number): Promise<T> =>' },
+    { name: 'request', signature: 'async request<T>(path: string, init?: RequestInit): Promise<T>' },
+  ])
+})
+
+test('wrapped Kotlin signatures are joined, with expression bodies dropped', () => {
+  const readme = fence('kotlin', [
+    'suspend fun <T> HttpClient.fetch(',
+    '    url: String,',
+    '    headers: Map<String, String> = emptyMap()',
+    '): Response<T> {',
+    '    return get(url, headers)',
+    '}',
+    '',
+    'fun greet(name: String): String = "Hello, $name"',
+  ])
+
+  assert.deepEqual(searchUtils.extractFunctionSignatures(readme), [
+    { name: 'fetch', signature: 'suspend fun <T> HttpClient.fetch(url: String, headers: Map<String, String> = emptyMap()): Response<T>' },
+    { name: 'greet', signature: 'fun greet(name: String): String' },
+  ])
+})
+
+test('export default functions are found, named or not', () => {
+  const readme = fence('js', [
+    'export default function plugin(options) {',
+    '  return { name: "plugin" }',
+    '}',
+    '',
+    'export default function (config) {',
+    '  return config',
+    '}',
+  ])
+
+  assert.deepEqual(searchUtils.extractFunctionSignatures(readme), [
+    { name: 'plugin', signature: 'export default function plugin(options)' },
+    { name: 'default', signature: 'export default function (config)' },
+  ])
+})
+
+test('test, hook and timer calls with callbacks are not signatures', () => {
+  const readme = fence('tsx', [
+    "describe('client', () => {",
+    "  it('works', () => {",
+    '    expect(client.get()).toBe(true)',
+    '  })',
+    '',
+    "  test('times out', async function () {",
+    '    await client.get()',
+    '  })',
+    '})',
+    '',
+    'useEffect(() => {',
+    '  subscribe()',
+    '}, [])',
+    '',
+    'setTimeout(() => {',
+    '  done()',
+    '}, 100)',
+    '',
+    'app.get("/users", (req, res) => {',
+    '  res.json([])',
+    '})',
+  ])
+
+  assert.deepEqual(searchUtils.extractFunctionSignatures(readme), [])
+})
+
+test('C and C++ functions are found with templated, pointer and reference types', () => {
+  const readme = fence('cpp', [
+    'std::vector<int> foo(const Bar& b);',
+    'int add(int a, int b);',
+    'template <typename T>',
+    'const T& max(const T& a, const T& b) noexcept {',
+    '  return a < b ? b : a;',
+    '}',
+    'static char *copy_name(const char *name, size_t length);',
+  ])
+
+  assert.deepEqual(searchUtils.extractFunctionSignatures(readme), [
+    { name: 'foo', signature: 'std::vector<int> foo(const Bar& b)' },
+    { name: 'add', signature: 'int add(int a, int b)' },
+    { name: 'max', signature: 'const T& max(const T& a, const T& b) noexcept' },
+    { name: 'copy_name', signature: 'static char *copy_name(const char *name, size_t length)' },
+  ])
+})
+
+test('C++ variables constructed with arguments are not functions', () => {
+  const readme = fence('cpp', [
+    'std::vector<int> v(10);',
+    'std::string name("widget");',
+    'Client client(host, port);',
+    'return compute(a, b);',
+  ])
+
+  assert.deepEqual(searchUtils.extractFunctionSignatures(readme), [])
+})
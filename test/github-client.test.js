@@ -0,0 +1,128 @@
+import { afterEach, test } from 'node:test';
+import assert from 'node:assert/strict';
+import { getGoRepositoryUrl, parseGitPackageSpec, parseRepositoryUrl } from '../build/utils/github-client.js';
+import { clearRepoCache } from '../build/utils/repo-cache.js';
+import { PackageDocsServer } from '../build/package-docs-server.js';
+import { callTool, notFound, restoreNetwork, stubGet } from './helpers.js';
+
+afterEach(() => {
+  restoreNetwork();
+  clearRepoCache();
+});
+
+test('npm git dependency specs are parsed to the repository and ref', () => {
+  const specs = {
+    'git+https://github.com/owner/repo.git#v1.2.0': { repositoryUrl: 'https://github.com/owner/repo', ref: 'v1.2.0' },
+    'git+ssh://git@github.com/owner/repo.git': { repositoryUrl: 'https://github.com/owner/repo', ref: undefined },
+    'git@gitlab.com:group/repo.git#main': { repositoryUrl: 'https://gitlab.com/group/repo', ref: 'main' },
+    'git://github.com/owner/repo': { repositoryUrl: 'https://github.com/owner/repo', ref: undefined },
+    'https://github.com/owner/repo.git': { repositoryUrl: 'https://github.com/owner/repo', ref: undefined },
+    'https://github.com/owner/repo#abc123': { repositoryUrl: 'https://github.com/owner/repo', ref: 'abc123' },
+    'github:owner/repo#semver:^2': { repositoryUrl: 'https://github.com/owner/repo', ref: 'semver:^2' },
+    'gitlab:group/repo': { repositoryUrl: 'https://gitlab.com/group/repo', ref: undefined },
+    'bitbucket:team/repo': { repositoryUrl: 'https://bitbucket.org/team/repo', ref: undefined },
+    'owner/repo': { repositoryUrl: 'https://github.com/owner/repo', ref: undefined },
+  };
+
+  for (const [spec, expected] of Object.entries(specs)) {
+    assert.deepEqual(parseGitPackageSpec(spec), expected, spec);
+  }
+});
+
+test('registry package names are not git specs', () => {
+  for (const name of ['express', '@types/node', '@scope/pkg', 'lodash.merge', 'https://example.com/pkg.tgz']) {
+    assert.equal(parseGitPackageSpec(name), undefined, name);
+  }
+});
+
+test('describe_npm_package reads a git dependency from its repository, not the registry', async () => {
+  const urls = stubGet(url => {
+    if (url === 'https://raw.githubusercontent.com/acme/git-widgets/v3.0.0/package.json') {
+      return { data: JSON.stringify({ name: 'git-widgets', description: 'Widgets straight from git' }) };
+    }
+    if (url === 'https://raw.githubusercontent.com/acme/git-widgets/v3.0.0/README.md') {
+      return { data: '# git-widgets\n\n## Usage\n\nCall gitWidget() to make one.\n' };
+    }
+    notFound(url);
+  });
+
+  const server = new PackageDocsServer();
+  const text = await callTool(server, 'describe_npm_package', { package: 'git+https://github.com/acme/git-widgets.git#v3.0.0', includeTypes: false });
+
+  assert.match(text, /Widgets straight from git/);
+  assert.match(text, /Installed from git: https:\/\/github\.com\/acme\/git-widgets#v3\.0\.0 \(package name git-widgets\)/);
+  assert.match(text, /gitWidget\(\)/);
+  assert.ok(!urls.some(url => url.startsWith('https://registry.npmjs.org/')));
+});
+
+test('a git dependency without a README or package.json is reported as not found', async () => {
+  stubGet(url => notFound(url));
+
+  const server = new PackageDocsServer();
+  assert.match(await callTool(server, 'describe_npm_package', { package: 'github:acme/missing-widgets', includeTypes: false }), /Could not fetch documentation for https:\/\/github\.com\/acme\/missing-widgets/);
+});
+
+test('repository URLs in package metadata are normalised to host, owner and repo', () => {
+  const ref = { host: 'github.com', owner: 'owner', repo: 'repo', url: 'https://github.com/owner/repo' };
+  for (const url of [
+    'https://github.com/owner/repo',
+    'git+https://github.com/owner/repo.git',
+    'git://github.com/owner/repo.git',
+    'ssh://git@github.com/owner/repo.git',
+    'git@github.com:owner/repo.git',
+    'https://GitHub.com/owner/repo/tree/main/packages/core',
+    'https://github.com/owner/repo#readme',
+    'github:owner/repo',
+  ]) {
+    assert.deepEqual(parseRepositoryUrl(url), ref, url);
+  }
+
+  assert.equal(parseRepositoryUrl('gitlab:group/project').url, 'https://gitlab.com/group/project');
+  assert.equal(parseRepositoryUrl('https://example.com'), undefined);
+  assert.equal(parseRepositoryUrl('not a url'), undefined);
+});
+
+test('Go import paths map to their repositories, including vanity paths', () => {
+  const repositories = {
+    'github.com/gorilla/mux': 'https://github.com/gorilla/mux',
+    'github.com/aws/aws-sdk-go-v2/service/s3': 'https://github.com/aws/aws-sdk-go-v2',
+    'golang.org/x/net/http2': 'https://github.com/golang/net',
+    'gopkg.in/yaml.v3': 'https://github.com/go-yaml/yaml',
+    'gopkg.in/check.v1': 'https://github.com/go-check/check',
+    'gopkg.in/alecthomas/kingpin.v2': 'https://github.com/alecthomas/kingpin',
+  };
+
+  for (const [importPath, url] of Object.entries(repositories)) {
+    assert.equal(getGoRepositoryUrl(importPath)?.url, url, importPath);
+  }
+  // Standard library packages have no repository of their own
+  assert.equal(getGoRepositoryUrl('net/http'), undefined);
+});
+
+test('the canonical repository is resolved from each ecosystem\'s metadata', async () => {
+  stubGet(url => {
+    if (url === 'https://registry.npmjs.org/repo-widgets') {
+      return {
+        data: {
+          name: 'repo-widgets',
+          'dist-tags': { latest: '1.0.0' },
+          versions: { '1.0.0': { name: 'repo-widgets', version: '1.0.0', repository: { type: 'git', url: 'git+https://github.com/acme/widgets.git' } } },
+        },
+      };
+    }
+    if (url === 'https://pypi.org/pypi/repo-widgets/json') {
+      return { data: { info: { name: 'repo-widgets', version: '1.0.0', project_urls: { Homepage: 'https://widgets.example.com', Source: 'https://gitlab.com/acme/py-widgets' } } } };
+    }
+    notFound(url);
+  });
+
+  const server = new PackageDocsServer();
+  const resolve = (language, packageName) => server['resolveRepository'](language, packageName);
+
+  assert.equal((await resolve('npm', 'repo-widgets'))?.url, 'https://github.com/acme/widgets');
+  assert.equal((await resolve('python', 'repo-widgets'))?.url, 'https://gitlab.com/acme/py-widgets');
+  assert.equal((await resolve('go', 'golang.org/x/sync/errgroup'))?.url, 'https://github.com/golang/sync');
+  assert.equal((await resolve('swift', 'https://github.com/apple/swift-argument-parser.git'))?.url, 'https://github.com/apple/swift-argument-parser');
+  // Packages the registry doesn't know have no repository, rather than failing the tool call
+  assert.equal(await resolve('npm', 'unknown-widgets'), undefined);
+});
@@ -0,0 +1,79 @@
+import { afterEach, test } from "node:test"
+import assert from "node:assert/strict"
+import { spawnSync } from "child_process"
+import { chmodSync, mkdtempSync, writeFileSync } from "fs"
+import { tmpdir } from "os"
+import { join } from "path"
+import { PackageDocsServer } from "../build/package-docs-server.js"
+import { isGoStandardLibrary } from "../build/package-names.js"
+import { restoreNetwork, stubGet } from "./helpers.js"
+
+afterEach(restoreNetwork)
+
+const server = new PackageDocsServer()
+const goInstalled = !spawnSync("go", ["version"]).error
+
+test("standard library import paths are told apart from dotless module paths", () => {
+  assert.ok(isGoStandardLibrary("fmt"))
+  assert.ok(isGoStandardLibrary("net/http"))
+  assert.ok(!isGoStandardLibrary("mycorp/foo"))
+  assert.ok(!isGoStandardLibrary("github.com/spf13/cobra"))
+})
+
+test("fmt is documented by go doc without the network", { skip: !goInstalled && "go isn't installed" }, async () => {
+  const urls = stubGet(url => { throw new Error(`unexpected request for ${url}`) })
+
+  const result = await server["describeGoPackage"]({ package: "fmt" })
+  assert.ok(!result.error, result.error)
+  assert.match(`${result.description}\n${result.usage}`, /Printf/)
+  assert.deepEqual(urls, [])
+})
+
+test("fmt is read from pkg.go.dev when go isn't installed", async () => {
+  const path = process.env.PATH
+  process.env.PATH = ""
+  const urls = stubGet(url => {
+    if (url === "https://pkg.go.dev/api/packages/fmt") return { data: { Synopsis: "Package fmt implements formatted I/O." } }
+    throw new Error(`unexpected request for ${url}`)
+  })
+
+  try {
+    const result = await server["describeGoPackage"]({ package: "fmt" })
+    assert.match(result.description, /formatted I\/O/)
+    assert.deepEqual(urls, ["https://pkg.go.dev/api/packages/fmt"])
+  } finally {
+    process.env.PATH = path
+  }
+})
+
+// Put a go on the PATH whose go doc prints a marker, for the duration of a test
+async function withFakeGo(run) {
+  const bin = mkdtempSync(join(tmpdir(), "package-docs-go-"))
+  writeFileSync(join(bin, "go"), "#!/bin/sh\necho 'Package fmt, as go doc describes it.'\n")
+  chmodSync(join(bin, "go"), 0o755)
+  const path = process.env.PATH
+  process.env.PATH = bin
+  try {
+    await run()
+  } finally {
+    process.env.PATH = path
+  }
+}
+
+test("the network source goes straight to pkg.go.dev, even with go installed", async () => {
+  await withFakeGo(async () => {
+    const urls = stubGet(url => {
+      if (url === "https://pkg.go.dev/api/packages/fmt") return { data: { Synopsis: "Package fmt implements formatted I/O." } }
+      throw new Error(`unexpected request for ${url}`)
+    })
+
+    const network = await server["describeGoPackage"]({ package: "fmt", source: "network" })
+    assert.match(network.description, /formatted I\/O/)
+    assert.deepEqual(urls, ["https://pkg.go.dev/api/packages/fmt"])
+
+    const auto = await server["describeGoPackage"]({ package: "fmt" })
+    assert.match(auto.description, /as go doc describes it/)
+    assert.equal(urls.length, 1)
+  })
+})
+
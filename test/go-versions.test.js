@@ -0,0 +1,71 @@
+import { afterEach, test } from "node:test"
+import assert from "node:assert/strict"
+import { chmodSync, mkdtempSync, writeFileSync } from "fs"
+import { tmpdir } from "os"
+import { join } from "path"
+import { PackageDocsServer } from "../build/package-docs-server.js"
+import { notFound, restoreNetwork, stubGet } from "./helpers.js"
+
+afterEach(restoreNetwork)
+
+const server = new PackageDocsServer()
+
+// Put a go on the PATH that downloads golang.org/x/net to moduleDir, and whose go doc prints where
+// it ran and with which arguments, for the duration of a test
+async function withFakeGo(moduleDir, run) {
+  const bin = mkdtempSync(join(tmpdir(), "package-docs-go-"))
+  writeFileSync(join(bin, "go"), [
+    "#!/bin/sh",
+    "if [ \"$1\" = mod ]; then",
+    `  case "$4" in golang.org/x/net@*) echo '{"Dir": "${moduleDir}"}' ;; *) exit 1 ;; esac`,
+    "else",
+    "  echo \"ran in $(pwd) with $*\"",
+    "fi",
+    "",
+  ].join("\n"))
+  chmodSync(join(bin, "go"), 0o755)
+  const path = process.env.PATH
+  process.env.PATH = bin
+  try {
+    await run()
+  } finally {
+    process.env.PATH = path
+  }
+}
+
+test("a requested Go version is downloaded and documented from its module directory", async () => {
+  const moduleDir = mkdtempSync(join(tmpdir(), "package-docs-module-"))
+  const urls = stubGet(url => { throw new Error(`unexpected request for ${url}`) })
+
+  await withFakeGo(moduleDir, async () => {
+    const fromPath = await server["describeGoPackage"]({ package: "golang.org/x/net/http2@v0.17.0", raw: true })
+    assert.equal(fromPath.usage.trim(), `ran in ${moduleDir} with doc ./http2`)
+
+    // A separate version wins over none in the path, and a symbol is passed after the relative package
+    const withSymbol = await server["describeGoPackage"]({ package: "golang.org/x/net/http2", version: "v0.17.0", symbol: "Transport", raw: true })
+    assert.equal(withSymbol.usage.trim(), `ran in ${moduleDir} with doc ./http2 Transport`)
+  })
+  assert.deepEqual(urls, [])
+})
+
+test("a requested Go version is read from its pkg.go.dev page, not the latest version's API", async () => {
+  const urls = stubGet(url => {
+    if (url === "https://pkg.go.dev/golang.org/x/net/http2@v0.17.0") {
+      return { data: "<html><body><section class=\"Documentation-overview\"><p>Package http2 implements HTTP/2 as of v0.17.0.</p></section></body></html>" }
+    }
+    notFound(url)
+  })
+
+  const result = await server["describeGoPackage"]({ package: "golang.org/x/net/http2@v0.17.0", source: "network" })
+  assert.ok(urls.includes("https://pkg.go.dev/golang.org/x/net/http2@v0.17.0"))
+  assert.ok(!urls.some(url => url.includes("/api/packages/")))
+  assert.match(result.usage, /as of v0\.17\.0/)
+})
+
+test("a Go version that can't be downloaded is reported", async () => {
+  const moduleDir = mkdtempSync(join(tmpdir(), "package-docs-module-"))
+  await withFakeGo(moduleDir, async () => {
+    const result = await server["describeGoPackage"]({ package: "example.com/missing@v1.0.0", source: "local" })
+    assert.match(result.error, /example\.com\/missing/)
+  })
+})
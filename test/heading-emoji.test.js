@@ -0,0 +1,80 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { SearchUtils, slugifyHeading, stripEmoji } from '../build/search-utils.js'
+import { callTool, notFound, restoreNetwork, silentLogger, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+const searchUtils = new SearchUtils(silentLogger)
+
+const readme = [
+  '# rocket',
+  '',
+  'Launches things.',
+  '',
+  '## 🚀 Getting Started',
+  '',
+  'Install it with pip.',
+  '',
+  '## 👩‍💻 Contributing',
+  '',
+  'Open a pull request.',
+  '',
+  '## 🇬🇧 Localisation',
+  '',
+  'British English only.',
+  '',
+  '## :sparkles: Features',
+  '',
+  'It launches.',
+].join('\n')
+
+test('emoji, including multi-codepoint sequences, are stripped from headings', () => {
+  assert.equal(stripEmoji('🚀 Getting Started'), 'Getting Started')
+  assert.equal(stripEmoji('👩‍💻 Contributing'), 'Contributing')
+  assert.equal(stripEmoji('👍🏽 Thanks'), 'Thanks')
+  assert.equal(stripEmoji('🇬🇧 Localisation'), 'Localisation')
+  assert.equal(stripEmoji('1️⃣ Step one'), 'Step one')
+  assert.equal(stripEmoji(':sparkles: Features'), 'Features')
+  assert.equal(stripEmoji('Acme™ Setup'), 'Acme™ Setup')
+  assert.equal(stripEmoji('🚀'), '🚀')
+})
+
+test('slugs are the anchors GitHub gives headings', () => {
+  assert.equal(slugifyHeading('Getting Started!'), 'getting-started')
+  assert.equal(slugifyHeading('🚀 Getting Started'), '-getting-started')
+  assert.equal(slugifyHeading(stripEmoji('🚀 Getting Started')), 'getting-started')
+  assert.equal(slugifyHeading('snake_case & Co.'), 'snake_case--co')
+})
+
+test('headings are kept as written and emoji are ignored when selecting a section', () => {
+  const sections = searchUtils.parseMarkdownDocSections(readme)
+  assert.deepEqual(sections.map(section => section.title), ['rocket', '🚀 Getting Started', '👩‍💻 Contributing', '🇬🇧 Localisation', ':sparkles: Features'])
+
+  for (const wanted of ['getting started', 'getting-started', '-getting-started', '🚀 Getting Started']) {
+    const { sections: selected, error } = searchUtils.selectDocumentation(sections, { section: wanted })
+    assert.equal(error, undefined, wanted)
+    assert.deepEqual(selected.map(section => section.title), ['🚀 Getting Started'], wanted)
+  }
+})
+
+test('stripHeadingEmoji returns clean section headings', async () => {
+  stubGet(url => {
+    if (url === 'https://pypi.org/pypi/rocket/json') {
+      return { data: { info: { name: 'rocket', version: '1.0.0', description: readme, description_content_type: 'text/markdown' } } }
+    }
+    notFound(url)
+  })
+
+  const server = new PackageDocsServer()
+  const asWritten = await callTool(server, 'get_package_doc', { package: 'rocket', language: 'python' })
+  assert.match(asWritten, /^## 🚀 Getting Started$/m)
+
+  const stripped = await callTool(server, 'get_package_doc', { package: 'rocket', language: 'python', stripHeadingEmoji: true })
+  assert.match(stripped, /^## Getting Started$/m)
+  assert.match(stripped, /^## Contributing$/m)
+  assert.match(stripped, /^## Localisation$/m)
+  assert.match(stripped, /^## Features$/m)
+  assert.doesNotMatch(stripped, /🚀|👩|🇬🇧|:sparkles:/)
+})
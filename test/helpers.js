@@ -0,0 +1,60 @@
+// Stubs shared by the tests, so they never reach the network
+import axios from 'axios';
+import { Server } from '@modelcontextprotocol/sdk/server/index.js';
+import { CallToolRequestSchema } from '@modelcontextprotocol/sdk/types.js';
+
+const axiosGet = axios.get;
+const globalFetch = globalThis.fetch;
+
+// The tools/call handler of each MCP server, recorded as it's registered
+const toolHandlers = new WeakMap();
+const setRequestHandler = Server.prototype.setRequestHandler;
+Server.prototype.setRequestHandler = function (schema, handler) {
+  if (schema === CallToolRequestSchema) {
+    toolHandlers.set(this, handler);
+  }
+  return setRequestHandler.call(this, schema, handler);
+};
+
+export const silentLogger = { debug() {}, info() {}, warn() {}, error() {}, child() { return silentLogger; } };
+
+export function notFound(url) {
+  throw Object.assign(new Error(`Request failed with status code 404: ${url}`), { isAxiosError: true, response: { status: 404 } });
+}
+
+/**
+ * Answer axios GET requests with respond(url, config), returning the list of URLs requested
+ */
+export function stubGet(respond) {
+  const urls = [];
+  axios.get = async (url, config) => {
+    urls.push(url);
+    return respond(url, config);
+  };
+  return urls;
+}
+
+/**
+ * Answer fetch requests with the Response respond(url) returns, returning the list of URLs requested
+ */
+export function stubFetch(respond) {
+  const urls = [];
+  globalThis.fetch = async url => {
+    urls.push(String(url));
+    return respond(String(url));
+  };
+  return urls;
+}
+
+export function restoreNetwork() {
+  axios.get = axiosGet;
+  globalThis.fetch = globalFetch;
+}
+
+/**
+ * Call one of a PackageDocsServer's tools as an MCP client would, returning the text of the response
+ */
+export async function callTool(server, name, args) {
+  const response = await toolHandlers.get(server['server'])({ params: { name, arguments: args } });
+  return response.content[0].text;
+}
@@ -0,0 +1,68 @@
+import { afterEach, test } from 'node:test';
+import assert from 'node:assert/strict';
+import { NpmDocsEnhancer } from '../build/npm-docs-enhancer.js';
+import { notFound, restoreNetwork, silentLogger, stubGet } from './helpers.js';
+
+afterEach(restoreNetwork);
+
+const enhancer = new NpmDocsEnhancer(silentLogger);
+
+// A TypeDoc class page with a constructor, a method and a property
+function classPage(name) {
+  return `<html><head><meta name="generator" content="TypeDoc 0.25.0"></head><body>
+    <h1>Class ${name}</h1>
+    <div class="tsd-comment"><p>${name} talks to the service.</p></div>
+    <section class="tsd-member tsd-kind-constructor"><h3>constructor</h3>
+      <div class="tsd-signature">new ${name}(options: ${name}Options): ${name}</div></section>
+    <section class="tsd-member tsd-kind-method"><h3>send</h3>
+      <div class="tsd-signature">send(request: Request): Promise&lt;Response&gt;</div>
+      <div class="tsd-comment"><p>Send a request.</p></div></section>
+    <section class="tsd-member tsd-kind-property"><h3>timeout</h3>
+      <div class="tsd-signature">timeout: number</div></section>
+  </body></html>`;
+}
+
+// A TypeDoc index page linking to the given class pages
+function indexPage(classes) {
+  return `<html><head><meta name="generator" content="TypeDoc 0.25.0"></head><body>
+    <nav class="tsd-navigation">${classes.map(name => `<a href="classes/${name}.html">${name}</a>`).join('')}</nav>
+  </body></html>`;
+}
+
+test('classes and their methods are extracted from a TypeDoc page', () => {
+  const html = classPage('Client');
+  assert.equal(enhancer.detectApiDocGenerator(html), 'typedoc');
+
+  const [client] = enhancer.extractGeneratedApiDocs(html, 'typedoc');
+  assert.equal(client.name, 'Client');
+  assert.equal(client.type, 'class');
+  assert.deepEqual(client.methods.map(method => method.name), ['constructor', 'send']);
+  assert.equal(client.methods[1].description, 'Send a request.');
+  assert.deepEqual(client.properties, [{ name: 'timeout', type: 'number', description: undefined }]);
+});
+
+test('class pages linked from a TypeDoc site are fetched a few at a time, up to a cap', async () => {
+  const classes = Array.from({ length: 15 }, (_, i) => `Client${i}`);
+  const fetched = [];
+  let inFlight = 0;
+  let maxInFlight = 0;
+  stubGet(async url => {
+    if (url === 'https://docs.example.com/') {
+      return { data: indexPage(classes) };
+    }
+    const name = url.match(/^https:\/\/docs\.example\.com\/classes\/(\w+)\.html$/)?.[1];
+    if (!name) notFound(url);
+
+    fetched.push(name);
+    inFlight++;
+    maxInFlight = Math.max(maxInFlight, inFlight);
+    await new Promise(resolve => setTimeout(resolve, 5));
+    inFlight--;
+    return { data: classPage(name) };
+  });
+
+  const result = await enhancer.fetchHostedApiDocumentation('client', 'https://docs.example.com/');
+  assert.deepEqual(result.exports.map(item => item.name), classes.slice(0, 10));
+  assert.equal(fetched.length, 10);
+  assert.ok(maxInFlight > 1 && maxInFlight <= 3, `fetched ${maxInFlight} pages at once`);
+});
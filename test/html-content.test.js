@@ -0,0 +1,164 @@
+import { test } from 'node:test';
+import assert from 'node:assert/strict';
+import { DEFAULT_MAIN_CONTENT_SELECTORS, extractHtmlCodeBlocks, extractHtmlTables, extractMainContent, extractPkgGoDevDocs, getMainContentSelectors, resolveRelativeLinks } from '../build/utils/html-content.js';
+import { NpmDocsEnhancer } from '../build/npm-docs-enhancer.js';
+import { silentLogger } from './helpers.js';
+
+test('relative links resolve against the URL a page was served from', () => {
+  // docs.rs redirects crate/reqwest/latest to the crate root, which its links are relative to
+  const html = resolveRelativeLinks('<a href="struct.Client.html">Client</a><a href="#examples">Examples</a>', 'https://docs.rs/reqwest/latest/reqwest/');
+  assert.match(html, /href="https:\/\/docs\.rs\/reqwest\/latest\/reqwest\/struct\.Client\.html"/);
+  assert.match(html, /href="https:\/\/docs\.rs\/reqwest\/latest\/reqwest\/#examples"/);
+});
+
+test('pkg.go.dev overviews keep their links', () => {
+  const page = resolveRelativeLinks(
+    '<section class="Documentation-overview"><p>Parse with <a href="/net/url#Parse">url.Parse</a>, see <a href="#Client">Client</a>.</p></section>',
+    'https://pkg.go.dev/net/http'
+  );
+  assert.equal(
+    extractPkgGoDevDocs(page).overview,
+    'Parse with [url.Parse](https://pkg.go.dev/net/url#Parse), see [Client](https://pkg.go.dev/net/http#Client).'
+  );
+});
+
+// Markup that never holds documentation text, with text that must not reach the output
+const noise = [
+  '<script>window.analytics = "tracking";</script>',
+  '<style>.hero { color: red; }</style>',
+  '<noscript>Enable JavaScript to continue</noscript>',
+  '<svg viewBox="0 0 16 16"><title>copy icon</title><path d="M0 0h16v16H0z"/></svg>',
+  '<template><p>Template placeholder</p></template>',
+].join('');
+
+test('main content is extracted without scripts, styles, noscript or SVGs', () => {
+  const paragraph = '<p>Widgets are assembled from parts, each of which can be configured on its own before the widget is built.</p>';
+  const content = extractMainContent(`<html><head>${noise}</head><body><nav>Home</nav><main>${noise}${paragraph.repeat(3)}</main></body></html>`);
+
+  assert.match(content, /Widgets are assembled from parts/);
+  assert.doesNotMatch(content, /tracking|color: red|Enable JavaScript|copy icon|Template placeholder|<svg|<script|<style/);
+});
+
+test('HTML READMEs are converted to markdown without scripts, styles, noscript or SVGs', () => {
+  const markdown = new NpmDocsEnhancer(silentLogger).convertHtmlToMarkdown(`<h1>widgets</h1>${noise}<p>Make <strong>widgets</strong>.</p>`);
+
+  assert.match(markdown, /# widgets/);
+  assert.match(markdown, /Make \*\*widgets\*\*\./);
+  assert.doesNotMatch(markdown, /tracking|color: red|Enable JavaScript|copy icon|Template placeholder/);
+});
+
+// Enough text for a container to count as the main content
+const article = '<p>The client keeps connections open between requests, so repeated calls to the same host are fast.</p>'.repeat(3);
+
+test('custom main content selectors are tried before the defaults', () => {
+  assert.deepEqual(getMainContentSelectors(' .docs-body, #api ,'), ['.docs-body', '#api', ...DEFAULT_MAIN_CONTENT_SELECTORS]);
+  assert.deepEqual(getMainContentSelectors(undefined), DEFAULT_MAIN_CONTENT_SELECTORS);
+});
+
+test('main content is found by ARIA role and semantic containers', () => {
+  const sidebar = `<div class="sidebar"><p>${'Sidebar text that is long enough to count as content on its own, but is not the documentation. '.repeat(3)}</p></div>`;
+
+  assert.match(extractMainContent(`<body>${sidebar}<div role="main">${article}</div></body>`), /keeps connections open/);
+  assert.match(extractMainContent(`<body>${sidebar}<article>${article}</article></body>`), /keeps connections open/);
+  // A custom selector wins over the defaults, and invalid ones are skipped
+  assert.match(extractMainContent(`<body><main>${article}</main><div class="docs-body">${sidebar}</div></body>`, ['[[invalid', '.docs-body']), /Sidebar text/);
+});
+
+test('without a known container the densest element is the main content', () => {
+  // Text in links doesn't count towards an element's score, so the menu can't outweigh the article
+  const links = Array.from({ length: 5 }, (_, index) => `<li><a href="/page-${index}">Navigation link number ${index}</a></li>`).join('');
+  const html = `<body><div class="menu"><ul>${links}</ul></div><div class="wrapper"><div class="page">${article}</div><div class="ad">Ad</div></div></body>`;
+
+  const content = extractMainContent(html);
+  assert.match(content, /keeps connections open/);
+  assert.doesNotMatch(content, /Navigation link|>Ad</);
+});
+
+test('pages without enough text fall back to the whole body', () => {
+  assert.match(extractMainContent('<body><div><p>Short page.</p></div></body>'), /Short page\./);
+});
+
+test('code inside a pre block is extracted once, with the language of either element', () => {
+  const html = [
+    '<p>Call <code>get()</code> to fetch.</p>',
+    '<pre><code class="language-js">const res = await get(url)\n</code></pre>',
+    '<pre class="lang-python">print(get(url))</pre>',
+    '<pre><span>plain</span> <code>text</code></pre>',
+  ].join('');
+
+  assert.deepEqual(extractHtmlCodeBlocks(html), [
+    { language: 'js', code: 'const res = await get(url)', inline: false },
+    { language: 'python', code: 'print(get(url))', inline: false },
+    { language: undefined, code: 'plain text', inline: false },
+    { language: undefined, code: 'get()', inline: true },
+  ]);
+});
+
+test('code blocks can be limited to the elements matching a selector, including the blocks themselves', () => {
+  const html = '<pre>func main() {}</pre><pre class="Documentation-exampleCode">fmt.Println("example")</pre><div class="Documentation-exampleCode"><pre>fmt.Println("nested")</pre></div>';
+
+  assert.deepEqual(extractHtmlCodeBlocks(html, '.Documentation-exampleCode').map(block => block.code), ['fmt.Println("example")', 'fmt.Println("nested")']);
+});
+
+test('HTML tables take their headers from thead or a row of th cells', () => {
+  const html = [
+    '<table><thead><tr><th>Option</th><th>Default</th></tr></thead>',
+    '<tbody><tr><td><code>timeout</code></td><td>30\n  seconds</td></tr><tr><td>retries</td></tr></tbody></table>',
+    '<table><tr><th>Feature</th><th>Enabled</th></tr><tr><td>gzip</td><td>yes</td><td>extra</td></tr></table>',
+  ].join('');
+
+  assert.deepEqual(extractHtmlTables(html), [
+    { headers: ['Option', 'Default'], rows: [['timeout', '30 seconds'], ['retries', '']] },
+    // Rows wider than the header widen the table
+    { headers: ['Feature', 'Enabled', ''], rows: [['gzip', 'yes', 'extra']] },
+  ]);
+});
+
+test('HTML tables without headers get numbered columns, and nested tables are skipped', () => {
+  const html = '<table><tr><td>a</td><td><table><tr><td>nested</td></tr></table></td></tr><tr><td>b</td><td>c</td></tr></table><table></table>';
+
+  assert.deepEqual(extractHtmlTables(html), [
+    { headers: ['Column 1', 'Column 2'], rows: [['a', 'nested'], ['b', 'c']] },
+  ]);
+});
+
+// The overview and declarations of a pkg.go.dev page laid out like net/http's
+const netHttpPage = `<div class="Documentation-content">
+  <section class="Documentation-overview">
+    <h3 tabindex="-1" id="pkg-overview" class="Documentation-overviewHeader">Overview <a href="#pkg-overview">¶</a></h3>
+    <p>Package http provides HTTP client and server implementations.</p>
+    <pre>resp, err := http.Get("http://example.com/")
+</pre>
+    <h4 id="hdr-Clients_and_Transports">Clients and Transports <a href="#hdr-Clients_and_Transports">¶</a></h4>
+    <p>For control over HTTP client headers, create a Client.</p>
+    <details class="Documentation-exampleDetails"><summary>Example</summary><p>Example prose</p><pre>example()</pre></details>
+  </section>
+  <div class="Documentation-declaration"><pre>func Get(url <a href="/builtin#string">string</a>) (resp *Response, err error)</pre></div>
+  <div class="Documentation-declaration"><pre>func (c *Client) Do(req *Request) (*Response, error)</pre></div>
+  <div class="Documentation-declaration"><pre>type Client struct {
+	Timeout time.Duration
+}</pre></div>
+  <div class="Documentation-declaration"><pre>func Get(url string) (resp *Response, err error)</pre></div>
+  <div class="Documentation-declaration"><pre>const DefaultMaxHeaderBytes = 1 &lt;&lt; 20</pre></div>
+</div>`;
+
+test('pkg.go.dev overviews keep their sections and code, without the heading or examples', () => {
+  assert.equal(extractPkgGoDevDocs(netHttpPage).overview, [
+    'Package http provides HTTP client and server implementations.',
+    '```go\nresp, err := http.Get("http://example.com/")\n```',
+    '### Clients and Transports',
+    'For control over HTTP client headers, create a Client.',
+  ].join('\n\n'));
+});
+
+test('pkg.go.dev declarations give package functions and types, without methods', () => {
+  const docs = extractPkgGoDevDocs(netHttpPage);
+  assert.deepEqual(docs.functions, ['func Get(url string) (resp *Response, err error)']);
+  assert.deepEqual(docs.types, ['type Client struct']);
+});
+
+test('pkg.go.dev overviews are found from their heading on older layouts', () => {
+  const docs = extractPkgGoDevDocs('<section><h2 id="pkg-overview">Overview</h2><p>Package errgroup provides synchronization.</p></section>');
+  assert.equal(docs.overview, 'Package errgroup provides synchronization.');
+  assert.deepEqual(extractPkgGoDevDocs('<p>No documentation.</p>'), { overview: undefined, functions: [], types: [] });
+});
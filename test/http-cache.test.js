@@ -0,0 +1,82 @@
+import { after, before, beforeEach, test } from 'node:test';
+import assert from 'node:assert/strict';
+import { createServer } from 'http';
+import axios from 'axios';
+import { clearHttpCache, getHttpCacheBytes, installHttpCache } from '../build/utils/http-cache.js';
+
+// A stub server answering each path with the headers set for it, counting the requests it gets
+const routes = {
+  '/fresh': { 'cache-control': 'max-age=60' },
+  '/no-store': { 'cache-control': 'no-store' },
+  '/etag': { 'cache-control': 'no-cache', etag: '"v1"' },
+};
+const requests = [];
+const server = createServer((req, res) => {
+  requests.push({ path: req.url, ifNoneMatch: req.headers['if-none-match'] });
+  const headers = routes[req.url.replace(/\?.*/, '')];
+  if (headers.etag && req.headers['if-none-match'] === headers.etag) {
+    res.writeHead(304, headers).end();
+    return;
+  }
+  res.writeHead(200, { 'content-type': 'text/plain', ...headers }).end(`body of ${req.url}`);
+});
+let baseUrl;
+
+before(async () => {
+  await new Promise(resolve => server.listen(0, '127.0.0.1', resolve));
+  baseUrl = `http://127.0.0.1:${server.address().port}`;
+});
+after(() => server.close());
+beforeEach(() => {
+  requests.length = 0;
+  clearHttpCache();
+});
+
+function cachingClient(maxBytes = 1024 * 1024) {
+  const client = axios.create();
+  installHttpCache(client, maxBytes);
+  return client;
+}
+
+test('the cache is off unless a size is set', () => {
+  assert.equal(getHttpCacheBytes(undefined), 0);
+  assert.equal(getHttpCacheBytes('0'), 0);
+  assert.equal(getHttpCacheBytes('nonsense'), 0);
+  assert.equal(getHttpCacheBytes('2'), 2 * 1024 * 1024);
+});
+
+test('fresh responses are served from the cache', async () => {
+  const client = cachingClient();
+  const first = await client.get(`${baseUrl}/fresh`, { responseType: 'text' });
+  const second = await client.get(`${baseUrl}/fresh`, { responseType: 'text' });
+  assert.equal(second.data, first.data);
+  assert.equal(requests.length, 1);
+});
+
+test('no-store responses are fetched every time', async () => {
+  const client = cachingClient();
+  await client.get(`${baseUrl}/no-store`, { responseType: 'text' });
+  await client.get(`${baseUrl}/no-store`, { responseType: 'text' });
+  assert.equal(requests.length, 2);
+});
+
+test('stale responses are revalidated with their ETag', async () => {
+  const client = cachingClient();
+  await client.get(`${baseUrl}/etag`, { responseType: 'text' });
+  const revalidated = await client.get(`${baseUrl}/etag`, { responseType: 'text' });
+  assert.equal(revalidated.status, 200);
+  assert.equal(revalidated.data, 'body of /etag');
+  assert.deepEqual(requests.map(request => request.ifNoneMatch), [undefined, '"v1"']);
+});
+
+test('the least recently used responses are dropped to stay within the size', async () => {
+  // Each body is 18 bytes ("body of /fresh?n=1"), so only two fit
+  const client = cachingClient(40);
+  for (const n of [1, 2, 3]) await client.get(`${baseUrl}/fresh?n=${n}`, { responseType: 'text' });
+  requests.length = 0;
+
+  await client.get(`${baseUrl}/fresh?n=3`, { responseType: 'text' });
+  await client.get(`${baseUrl}/fresh?n=2`, { responseType: 'text' });
+  await client.get(`${baseUrl}/fresh?n=1`, { responseType: 'text' });
+  assert.deepEqual(requests.map(request => request.path), ['/fresh?n=1']);
+});
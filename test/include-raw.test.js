@@ -0,0 +1,101 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { callTool, notFound, restoreNetwork, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+const readme = [
+  '# fetcher',
+  '',
+  'Fetches things.',
+  '',
+  '## Usage',
+  '',
+  'Call `fetch_all()` to fetch everything.',
+  '',
+  '## Acknowledgements',
+  '',
+  'Thanks to everyone who reported a bug.',
+].join('\n')
+
+// PyPI's JSON for a project whose long description is the README above
+function stubPyPI() {
+  return stubGet(url => {
+    if (url === 'https://pypi.org/pypi/fetcher/json') {
+      return {
+        data: {
+          info: {
+            name: 'fetcher',
+            version: '1.0.0',
+            summary: 'Fetches things',
+            description: readme,
+            description_content_type: 'text/markdown',
+          },
+        },
+      }
+    }
+    notFound(url)
+  })
+}
+
+test('includeRaw returns the README the summary was made from, after the summary', async () => {
+  const urls = stubPyPI()
+
+  const server = new PackageDocsServer()
+  const result = JSON.parse(await callTool(server, 'describe_python_package', { package: 'fetcher', source: 'network', includeRaw: true }))
+
+  assert.deepEqual(urls, ['https://pypi.org/pypi/fetcher/json'])
+  assert.match(result.description, /Fetches things/)
+  assert.match(result.usage, /fetch_all/)
+  assert.equal(result.readme, readme)
+  assert.equal(Object.keys(result).at(-1), 'readme')
+})
+
+test('the raw README is truncated to rawMaxLength, marked as truncated', async () => {
+  stubPyPI()
+
+  const server = new PackageDocsServer()
+  const result = JSON.parse(await callTool(server, 'describe_python_package', { package: 'fetcher', source: 'network', includeRaw: true, rawMaxLength: 40 }))
+
+  assert.ok(readme.startsWith(result.readme.replace(/\.\.\. \(truncated\)$/, '')))
+  assert.match(result.readme, /\.\.\. \(truncated\)$/)
+  assert.match(result.usage, /fetch_all/)
+})
+
+test('the raw README follows the metadata of json results, and is left out without includeRaw', async () => {
+  stubPyPI()
+
+  const server = new PackageDocsServer()
+  const json = JSON.parse(await callTool(server, 'describe_python_package', { package: 'fetcher', source: 'network', includeRaw: true, format: 'json' }))
+  assert.equal(json.metadata.name, 'fetcher')
+  assert.equal(json.readme, readme)
+  assert.equal(Object.keys(json).at(-1), 'readme')
+
+  const plain = JSON.parse(await callTool(server, 'describe_python_package', { package: 'fetcher', source: 'network' }))
+  assert.equal(plain.readme, undefined)
+})
+
+test('npm packages return the README from the packument they were described from', async () => {
+  const urls = stubGet(url => {
+    if (url === 'https://registry.npmjs.org/fetcher-js') {
+      return {
+        data: {
+          name: 'fetcher-js',
+          description: 'Fetches things',
+          'dist-tags': { latest: '1.0.0' },
+          versions: { '1.0.0': { name: 'fetcher-js', version: '1.0.0' } },
+          readme,
+        },
+      }
+    }
+    notFound(url)
+  })
+
+  const server = new PackageDocsServer()
+  const result = JSON.parse(await callTool(server, 'describe_npm_package', { package: 'fetcher-js', source: 'network', includeRaw: true, includeTypes: false, includeExamples: false }))
+
+  assert.deepEqual(urls.filter(url => url.startsWith('https://registry.npmjs.org/')), ['https://registry.npmjs.org/fetcher-js'])
+  assert.equal(result.readme, readme)
+  assert.equal(Object.keys(result).at(-1), 'readme')
+})
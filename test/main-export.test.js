@@ -0,0 +1,57 @@
+import { afterEach, test } from 'node:test';
+import assert from 'node:assert/strict';
+import { NpmDocsEnhancer } from '../build/npm-docs-enhancer.js';
+import { notFound, restoreNetwork, silentLogger, stubGet } from './helpers.js';
+
+afterEach(restoreNetwork);
+
+const enhancer = new NpmDocsEnhancer(silentLogger);
+
+test('the entry file is resolved from exports, then module, then main', () => {
+  const entries = [
+    [{ exports: { '.': { require: './cjs/index.js', import: { types: './index.d.mts', default: './esm/index.mjs' } } } }, 'esm/index.mjs'],
+    [{ exports: { '.': './lib/main.js', './feature': './lib/feature.js' } }, 'lib/main.js'],
+    [{ exports: './lib/main.js', main: 'ignored.js' }, 'lib/main.js'],
+    // Subpath exports without a root entry leave the package's main to decide
+    [{ exports: { './feature': './feature.js' }, main: 'index.cjs' }, 'index.cjs'],
+    [{ module: 'dist/index.mjs', main: 'dist/index.cjs' }, 'dist/index.mjs'],
+    [{ main: './main.js' }, 'main.js'],
+    [{}, 'index.js'],
+  ];
+
+  for (const [manifest, entry] of entries) {
+    assert.equal(enhancer.resolveEntryPoint(manifest), entry, JSON.stringify(manifest));
+  }
+});
+
+test('default exports are the main export', () => {
+  assert.deepEqual(enhancer.findMainExport('export default function axios(config) {}', 'axios'), { name: 'axios', kind: 'function', style: 'default' });
+  assert.deepEqual(enhancer.findMainExport('const lib = { get() {} };\nexport default lib;', 'lib'), { name: 'lib', kind: 'object', style: 'default' });
+});
+
+test('CommonJS exports are the main export, keeping the kind of a merged declaration', () => {
+  assert.deepEqual(
+    enhancer.findMainExport('declare function got(url: string): Promise<unknown>;\ndeclare namespace got { const version: string; }\nexport = got;', 'got'),
+    { name: 'got', kind: 'function', style: 'commonjs' }
+  );
+  assert.deepEqual(enhancer.findMainExport('module.exports = class Queue {}', 'queue'), { name: 'Queue', kind: 'class', style: 'commonjs' });
+});
+
+test('without a default export, a named export after the package is the main export', () => {
+  const source = 'export function createStore() {}\nexport const helper = () => {};';
+  assert.deepEqual(enhancer.findMainExport(source, '@acme/create-store'), { name: 'createStore', kind: 'function', style: 'named' });
+  assert.equal(enhancer.findMainExport(source, 'unrelated'), undefined);
+});
+
+test('the entry file is fetched at the manifest version when there are no type definitions', async () => {
+  const urls = stubGet(url => notFound(url));
+
+  assert.equal(await enhancer.identifyMainExport('widgets', { version: '2.1.0', exports: { '.': { import: './esm/index.js' } } }), undefined);
+  assert.deepEqual(urls, ['https://unpkg.com/widgets@2.1.0/esm/index.js']);
+});
+
+test('main exports are described in one line', () => {
+  assert.equal(enhancer.formatMainExport({ name: 'axios', kind: 'function', style: 'default' }), 'Main export: `axios` (default export, function)');
+  assert.equal(enhancer.formatMainExport({ name: 'got', kind: 'function', style: 'commonjs' }), 'Main export: `got` (CommonJS module.exports, function)');
+  assert.equal(enhancer.formatMainExport({ name: 'createStore', kind: 'function', style: 'named' }), 'Main export: `createStore` (named export, function)');
+});
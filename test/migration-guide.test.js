@@ -0,0 +1,136 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { SearchUtils } from '../build/search-utils.js'
+import { clearRepoCache } from '../build/utils/repo-cache.js'
+import { callTool, notFound, restoreNetwork, silentLogger, stubGet } from './helpers.js'
+
+afterEach(() => {
+  restoreNetwork()
+  clearRepoCache()
+})
+
+const searchUtils = new SearchUtils(silentLogger)
+
+const readme = [
+  '# widgets',
+  '',
+  'Makes widgets.',
+  '',
+  '## Usage',
+  '',
+  'Call `widget()`.',
+  '',
+  '## Migrating from v2 to v3',
+  '',
+  'Options are now passed as an object.',
+  '',
+  '## Migrating from v3 to v4',
+  '',
+  '`render` returns a promise.',
+  '',
+  '### Renamed options',
+  '',
+  '`size` is now `scale`.',
+  '',
+  '## Licence',
+  '',
+  'MIT',
+].join('\n')
+
+const changelog = [
+  '# Changelog',
+  '',
+  '## 4.0.0',
+  '',
+  '### Breaking Changes',
+  '',
+  '- `render` returns a promise',
+  '',
+  '### Features',
+  '',
+  '- Added `scale`',
+  '',
+  '## 3.2.0',
+  '',
+  '- Faster rendering',
+  '- BREAKING: dropped Node 14',
+  '',
+  '## 3.1.0',
+  '',
+  '### Removed',
+  '',
+  '- `legacyRender`',
+].join('\n')
+
+test('migration sections are extracted with their subsections', () => {
+  const sections = searchUtils.extractMigrationSections(readme)
+  assert.deepEqual(sections.map(section => section.title), ['Migrating from v2 to v3', 'Migrating from v3 to v4'])
+  assert.match(sections[1].content, /`render` returns a promise\./)
+  assert.match(sections[1].content, /`size` is now `scale`\./)
+  assert.doesNotMatch(sections.map(section => section.content).join('\n'), /MIT|widget\(\)/)
+})
+
+test('migration sections for majors up to fromVersion are left out', () => {
+  assert.deepEqual(searchUtils.extractMigrationSections(readme, '3.1.0').map(section => section.title), ['Migrating from v3 to v4'])
+  assert.deepEqual(searchUtils.extractMigrationSections(readme, 'v4'), [])
+  // Headings naming no version always apply
+  assert.equal(searchUtils.extractMigrationSections('## Upgrade Guide\n\nReinstall.', '9.0.0').length, 1)
+})
+
+test('breaking changes are taken from each changelog entry in the version range', () => {
+  assert.deepEqual(searchUtils.extractBreakingChanges(changelog), [
+    { version: '4.0.0', content: '### Breaking Changes\n\n- `render` returns a promise' },
+    { version: '3.2.0', content: '- BREAKING: dropped Node 14' },
+    { version: '3.1.0', content: '### Removed\n\n- `legacyRender`' },
+  ])
+  assert.deepEqual(searchUtils.extractBreakingChanges(changelog, '3.1.0').map(entry => entry.version), ['4.0.0', '3.2.0'])
+  assert.deepEqual(searchUtils.extractBreakingChanges(changelog, '3.1.0', '3.2.0').map(entry => entry.version), ['3.2.0'])
+})
+
+const repository = { type: 'git', url: 'git+https://github.com/acme/widgets.git' }
+
+test('get_migration_guide combines the migration guide, README sections and breaking changes', async () => {
+  stubGet(url => {
+    if (url === 'https://registry.npmjs.org/widgets') {
+      return {
+        data: {
+          name: 'widgets',
+          'dist-tags': { latest: '4.0.0' },
+          versions: { '4.0.0': { name: 'widgets', version: '4.0.0', repository } },
+          repository,
+          readme,
+        },
+      }
+    }
+    if (url === 'https://raw.githubusercontent.com/acme/widgets/main/UPGRADING.md') {
+      return { data: '# Upgrading\n\nRun `npx widgets-codemod`.\n' }
+    }
+    if (url === 'https://raw.githubusercontent.com/acme/widgets/main/CHANGELOG.md') {
+      return { data: changelog }
+    }
+    notFound(url)
+  })
+
+  const text = await callTool(new PackageDocsServer(), 'get_migration_guide', { package: 'widgets', language: 'npm', fromVersion: '3.1.0' })
+
+  assert.match(text, /Migration guide for widgets since 3\.1\.0 \(from UPGRADING\.md, README, CHANGELOG\.md\)/)
+  assert.match(text, /# From UPGRADING\.md\n\n# Upgrading\n\nRun `npx widgets-codemod`\./)
+  assert.match(text, /# From the README\n\n## Migrating from v3 to v4/)
+  assert.doesNotMatch(text, /Migrating from v2 to v3/)
+  assert.match(text, /# Breaking changes from CHANGELOG\.md\n\n## 4\.0\.0\n\n### Breaking Changes/)
+  assert.doesNotMatch(text, /legacyRender/)
+})
+
+test('get_migration_guide reports packages without migration guidance', async () => {
+  stubGet(url => {
+    if (url === 'https://registry.npmjs.org/plain-widgets') {
+      return { data: { name: 'plain-widgets', 'dist-tags': { latest: '1.0.0' }, versions: { '1.0.0': {} }, readme: '# plain-widgets\n\n## Usage\n\nCall widget().' } }
+    }
+    notFound(url)
+  })
+
+  const server = new PackageDocsServer()
+  assert.match(await callTool(server, 'get_migration_guide', { package: 'plain-widgets', language: 'npm', fromVersion: '1.0.0' }), /No migration guidance found for plain-widgets since 1\.0\.0/)
+  await assert.rejects(callTool(server, 'get_migration_guide', { package: 'plain-widgets', language: 'npm', fromVersion: 1 }), /Invalid get_migration_guide arguments/)
+})
@@ -0,0 +1,79 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { callTool, notFound, restoreNetwork, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+// A packument whose README describe and search read
+function stubRegistry(name) {
+  return stubGet(url => {
+    if (url === `https://registry.npmjs.org/${name}`) {
+      return {
+        data: {
+          name,
+          description: 'Retries requests',
+          'dist-tags': { latest: '2.0.0' },
+          versions: { '1.0.0': { name, version: '1.0.0' }, '2.0.0': { name, version: '2.0.0' } },
+          readme: '# retrier\n\n## Usage\n\nCall retry() with a timeout.\n\n## Example\n\n```js\nretry(fn)\n```\n',
+        },
+      }
+    }
+    notFound(url)
+  })
+}
+
+// Count the README conversions of a server's npm handler
+function countConversions(server) {
+  const enhancer = server['npmDocsHandler']['enhancer']
+  const convert = enhancer.convertHtmlToMarkdown
+  let conversions = 0
+  enhancer.convertHtmlToMarkdown = function (...args) {
+    conversions++
+    return convert.apply(this, args)
+  }
+  return () => conversions
+}
+
+test('describing an npm package fetches and converts its README once', async () => {
+  const urls = stubRegistry('retrier-describe')
+  const server = new PackageDocsServer()
+  const conversions = countConversions(server)
+
+  assert.match(await callTool(server, 'describe_npm_package', { package: 'retrier-describe', source: 'network', includeTypes: false }), /Call retry\(\) with a timeout/)
+  assert.deepEqual(urls.filter(url => url.startsWith('https://registry.npmjs.org/retrier-describe')), ['https://registry.npmjs.org/retrier-describe'])
+  assert.equal(conversions(), 1)
+})
+
+test('searching an npm package fetches and converts its README once', async () => {
+  const urls = stubRegistry('retrier-search')
+  const server = new PackageDocsServer()
+  const conversions = countConversions(server)
+
+  assert.match(await callTool(server, 'search_package_docs', { package: 'retrier-search', language: 'npm', query: 'timeout', source: 'network', fuzzy: false }), /timeout/)
+  assert.deepEqual(urls, ['https://registry.npmjs.org/retrier-search'])
+  assert.equal(conversions(), 1)
+})
+
+test('comparing two versions of an npm package makes one registry request', async () => {
+  const urls = stubGet(url => {
+    if (url === 'https://registry.npmjs.org/retrier-compare') {
+      return {
+        data: {
+          name: 'retrier-compare',
+          'dist-tags': { latest: '2.0.0' },
+          versions: {
+            '1.0.0': { name: 'retrier-compare', version: '1.0.0', license: 'MIT', dependencies: { ms: '^1.0.0' } },
+            '2.0.0': { name: 'retrier-compare', version: '2.0.0', license: 'ISC', dependencies: { ms: '^2.0.0' } },
+          },
+        },
+      }
+    }
+    notFound(url)
+  })
+
+  const text = await callTool(new PackageDocsServer(), 'compare_versions', { package: 'retrier-compare', language: 'npm', fromVersion: '1.0.0', toVersion: 'latest' })
+  assert.match(text, /MIT/)
+  assert.match(text, /ISC/)
+  assert.deepEqual(urls, ['https://registry.npmjs.org/retrier-compare'])
+})
@@ -0,0 +1,90 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { callTool, notFound, restoreNetwork, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+// Answer registry requests for npm packages, given each one's dependencies at version 1.0.0
+function stubNpm(packages) {
+  return stubGet(url => {
+    const name = decodeURIComponent(url.replace('https://registry.npmjs.org/', ''))
+    if (!url.startsWith('https://registry.npmjs.org/') || !(name in packages)) notFound(url)
+    return {
+      data: {
+        name,
+        'dist-tags': { latest: '1.0.0' },
+        versions: { '1.0.0': { name, version: '1.0.0', dependencies: packages[name] } },
+      },
+    }
+  })
+}
+
+async function getTree(args) {
+  const server = new PackageDocsServer()
+  return JSON.parse(await callTool(server, 'get_package_dependencies', args))
+}
+
+test('a cycle is noted rather than walked again', async () => {
+  const urls = stubNpm({
+    app: { left: '^1.0.0' },
+    left: { right: '^1.0.0' },
+    right: { left: '^1.0.0' },
+  })
+
+  const { dependencyTree } = await getTree({ package: 'app', language: 'npm', depth: 10 })
+  const left = dependencyTree.dependencies[0]
+  const right = left.dependencies[0]
+  const cycle = right.dependencies[0]
+
+  assert.deepEqual([left.name, right.name, cycle.name], ['left', 'right', 'left'])
+  assert.match(cycle.note, /Cycle/)
+  assert.equal(cycle.dependencies, undefined)
+  assert.equal(urls.filter(url => url.endsWith('/left')).length, 1)
+})
+
+test('the tree stops at the node cap, noting the dependencies left out', async () => {
+  const dependencies = Object.fromEntries(Array.from({ length: 8 }, (_, i) => [`dep${i}`, '^1.0.0']))
+  stubNpm({ app: dependencies, ...Object.fromEntries(Object.keys(dependencies).map(name => [name, {}])) })
+
+  const result = await getTree({ package: 'app', language: 'npm', maxNodes: 5 })
+
+  assert.deepEqual(result.dependencyTree.dependencies.map(node => node.name), ['dep0', 'dep1', 'dep2', 'dep3'])
+  assert.match(result.dependencyTree.note, /4 more dependencies not listed, as the tree reached 5 packages/)
+  assert.match(result.description, /\(5 packages, depth 2\)\. Some dependencies were cut off/)
+})
+
+test('Python requirements for extras and other environments are left out, and the latest release is fetched once', async () => {
+  const urls = stubGet(url => {
+    if (url === 'https://pypi.org/pypi/fetcher/json') {
+      return {
+        data: {
+          info: {
+            name: 'fetcher',
+            version: '2.0.0',
+            requires_dist: [
+              'idna (<4,>=2.5)',
+              'typing-extensions>=4; python_version < "3.8"',
+              'colorama; platform_system == "Windows"',
+              'pysocks!=1.5.7,>=1.5.6; extra == "socks"',
+            ],
+          },
+          releases: { '1.0.0': [], '2.0.0': [] },
+        },
+      }
+    }
+    if (url === 'https://pypi.org/pypi/idna/json') {
+      return { data: { info: { name: 'idna', version: '4.0' }, releases: { '3.7': [], '4.0': [] } } }
+    }
+    if (url === 'https://pypi.org/pypi/idna/3.7/json') {
+      return { data: { info: { name: 'idna', version: '3.7' } } }
+    }
+    notFound(url)
+  })
+
+  const { dependencyTree } = await getTree({ package: 'fetcher', language: 'python' })
+
+  assert.equal(dependencyTree.version, '2.0.0')
+  assert.deepEqual(dependencyTree.dependencies, [{ name: 'idna', requirement: '<4,>=2.5', version: '3.7' }])
+  assert.deepEqual(urls, ['https://pypi.org/pypi/fetcher/json', 'https://pypi.org/pypi/idna/json', 'https://pypi.org/pypi/idna/3.7/json'])
+})
@@ -0,0 +1,94 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { SearchUtils } from '../build/search-utils.js'
+import { callTool, notFound, restoreNetwork, silentLogger, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+// A README with a code block in each of 12 sections, the last 4 of them under an Examples heading
+const readme = [
+  '# blocks',
+  ...Array.from({ length: 8 }, (_, i) => [`## Option ${i}`, '', `Set option ${i}:`, '', '```python', `blocks.configure(option${i}=True)`, '```', ''].join('\n')),
+  ...Array.from({ length: 4 }, (_, i) => [`## Example ${i}`, '', `Build example ${i}:`, '', '```python', `blocks.build(example${i})`, '```', ''].join('\n')),
+].join('\n')
+
+function stubPyPI() {
+  stubGet(url => {
+    if (url === 'https://pypi.org/pypi/blocks/json') {
+      return { data: { info: { name: 'blocks', version: '1.0.0', description: readme, description_content_type: 'text/markdown' } } }
+    }
+    notFound(url)
+  })
+}
+
+test('every example is returned when maxExamples is not given', async () => {
+  stubPyPI()
+
+  const server = new PackageDocsServer()
+  const text = await callTool(server, 'get_package_examples', { package: 'blocks', language: 'python' })
+
+  assert.match(text, /12 examples from the blocks README\n/)
+  assert.doesNotMatch(text, /omitted/)
+  assert.equal(text.match(/```python/g).length, 12)
+})
+
+test('maxExamples caps the examples, keeping those in example sections and noting the rest', async () => {
+  stubPyPI()
+
+  const server = new PackageDocsServer()
+  const text = await callTool(server, 'get_package_examples', { package: 'blocks', language: 'python', maxExamples: 6 })
+
+  assert.match(text, /6 examples from the blocks README \(6 more omitted; pass a larger maxExamples to see them\)/)
+  assert.equal(text.match(/```python/g).length, 6)
+  for (let i = 0; i < 4; i++) {
+    assert.match(text, new RegExp(`blocks\\.build\\(example${i}\\)`))
+  }
+  assert.match(text, /option0=True/)
+  assert.doesNotMatch(text, /option2=True/)
+})
+
+const labelledReadme = [
+  '# http-lite',
+  '',
+  '## Creating a client',
+  '',
+  '```js',
+  'const client = createClient()',
+  '```',
+  '',
+  '## Errors',
+  '',
+  'Requests reject on failure. To handle errors, **catch** the rejection:',
+  '',
+  '```js',
+  'client.get(url).catch(report)',
+  '```',
+  '',
+  '~~~',
+  'DEBUG=http-lite node app.js',
+  '~~~',
+].join('\n')
+
+test('each example is labelled by the sentence introducing it, or its heading', () => {
+  const examples = new SearchUtils(silentLogger).extractLabelledExamples(labelledReadme)
+
+  assert.deepEqual(examples, [
+    { label: 'Creating a client', language: 'js', code: 'const client = createClient()', heading: 'Creating a client' },
+    { label: 'To handle errors, catch the rejection', language: 'js', code: 'client.get(url).catch(report)', heading: 'Errors' },
+    { label: 'Errors', language: undefined, code: 'DEBUG=http-lite node app.js', heading: 'Errors' },
+  ])
+})
+
+test('get_package_examples shows each example under its label', async () => {
+  stubGet(url => {
+    if (url === 'https://pypi.org/pypi/http-lite/json') {
+      return { data: { info: { name: 'http-lite', version: '1.0.0', description: labelledReadme, description_content_type: 'text/markdown' } } }
+    }
+    notFound(url)
+  })
+
+  const text = await callTool(new PackageDocsServer(), 'get_package_examples', { package: 'http-lite', language: 'python' })
+  assert.match(text, /Creating a client[\s\S]*```js\nconst client = createClient\(\)\n```/)
+  assert.match(text, /To handle errors, catch the rejection[\s\S]*```js\nclient\.get\(url\)\.catch\(report\)\n```/)
+})
@@ -0,0 +1,75 @@
+import { afterEach, test } from "node:test"
+import assert from "node:assert/strict"
+import { normalizePackageArgs, normalizePackageName, normalizePyPIName, pypiJsonUrl } from "../build/package-names.js"
+import { PackageDocsServer } from "../build/package-docs-server.js"
+import { callTool, notFound, restoreNetwork, stubGet } from "./helpers.js"
+
+afterEach(restoreNetwork)
+
+test("PyPI names are normalised as PEP 503 describes", () => {
+  for (const name of ["My_Pkg", "my.pkg", "my--pkg", "my-pkg", " MY._-Pkg "]) {
+    assert.equal(normalizePyPIName(name), "my-pkg", name)
+  }
+  assert.equal(normalizePyPIName("Foo.Bar_Baz"), "foo-bar-baz")
+})
+
+test("PyPI URLs use the normalised name", () => {
+  assert.equal(pypiJsonUrl("My_Pkg"), "https://pypi.org/pypi/my-pkg/json")
+  assert.equal(pypiJsonUrl("my.pkg", "1.0.0rc1"), "https://pypi.org/pypi/my-pkg/1.0.0rc1/json")
+})
+
+test("a Python package is looked up on PyPI by its normalised name", async () => {
+  const urls = stubGet(url => {
+    if (url === "https://pypi.org/pypi/my-pkg/json") {
+      return { data: { info: { name: "my-pkg", version: "1.0.0", summary: "A normalised package" } } }
+    }
+    notFound(url)
+  })
+
+  const text = await callTool(new PackageDocsServer(), "describe_python_package", { package: "My_Pkg", source: "network" })
+  assert.match(text, /A normalised package/)
+  assert.ok(urls.every(url => !/My_Pkg/.test(url)), urls.join("\n"))
+})
+
+test("Go import paths keep their case, losing only the scheme and trailing slashes", () => {
+  assert.equal(normalizePackageName("go", "github.com/BurntSushi/toml/"), "github.com/BurntSushi/toml")
+  assert.equal(normalizePackageName("go", "https://GitHub.com/BurntSushi/toml//"), "github.com/BurntSushi/toml")
+  assert.equal(normalizePackageName("go", " net/http/ "), "net/http")
+})
+
+test("npm names are lowercased, except git specs", () => {
+  assert.equal(normalizePackageName("npm", "Express"), "express")
+  assert.equal(normalizePackageName("npm", "@Types/Node/"), "@types/node")
+  assert.equal(normalizePackageName("npm", "github:Acme/Widgets#Main"), "github:Acme/Widgets#Main")
+})
+
+test("Python names are only trimmed, leaving PyPI's normalisation to the registry URL", () => {
+  assert.equal(normalizePackageName("python", " Requests "), "Requests")
+  assert.equal(normalizePackageName("python", "my_pkg/"), "my_pkg")
+  assert.equal(pypiJsonUrl(normalizePackageName("python", "Requests")), "https://pypi.org/pypi/requests/json")
+  assert.equal(pypiJsonUrl(normalizePackageName("python", "my_pkg")), "https://pypi.org/pypi/my-pkg/json")
+})
+
+test("crates and Packagist names are lowercased", () => {
+  assert.equal(normalizePackageName("rust", "Serde_JSON/"), "serde_json")
+  assert.equal(normalizePackageName("php", " Monolog/Monolog "), "monolog/monolog")
+  assert.equal(normalizePackageName("java", " com.Google.guava:Guava "), "com.Google.guava:Guava")
+})
+
+test("tool arguments are normalised for the tool's ecosystem", () => {
+  assert.deepEqual(normalizePackageArgs("describe_npm_package", { package: "React", version: "18" }), { package: "react", version: "18" })
+  assert.deepEqual(normalizePackageArgs("search_package_docs", { package: "Serde", language: "rust" }), { package: "serde", language: "rust" })
+  assert.deepEqual(normalizePackageArgs("get_schema", { tool: "Describe" }), { tool: "Describe" })
+})
+
+test("a Python package named with other case is found on PyPI", async () => {
+  const urls = stubGet(url => {
+    if (url === "https://pypi.org/pypi/requests/json") {
+      return { data: { info: { name: "requests", version: "2.32.0", summary: "HTTP for Humans" } } }
+    }
+    notFound(url)
+  })
+
+  assert.match(await callTool(new PackageDocsServer(), "describe_python_package", { package: "Requests/", source: "network" }), /HTTP for Humans/)
+  assert.ok(urls.includes("https://pypi.org/pypi/requests/json"))
+})
@@ -0,0 +1,277 @@
+import { afterEach, test } from 'node:test';
+import assert from 'node:assert/strict';
+import { levenshteinDistance, PackageSearch } from '../build/package-search.js';
+import { PackageDocsServer } from '../build/package-docs-server.js';
+import { callTool, notFound, restoreNetwork, silentLogger, stubFetch, stubGet } from './helpers.js';
+
+afterEach(restoreNetwork);
+
+const packageSearch = new PackageSearch(silentLogger);
+
+// npm search results for the given package names, in the registry's order
+function npmResults(...names) {
+  return { data: { objects: names.map(name => ({ package: { name, version: '1.0.0' } })) } };
+}
+
+test('edit distance counts insertions, deletions and substitutions', () => {
+  assert.equal(levenshteinDistance('expres', 'express'), 1);
+  assert.equal(levenshteinDistance('lodahs', 'lodash'), 2);
+  assert.equal(levenshteinDistance('', 'abc'), 3);
+  assert.equal(levenshteinDistance('same', 'same'), 0);
+});
+
+test('suggestions are the three closest names other than the one requested', async () => {
+  const urls = stubGet(url => {
+    if (url === 'https://registry.npmjs.org/-/v1/search') {
+      return npmResults('expres', 'express-session', 'express', 'expresso', 'xpress', 'koa');
+    }
+    notFound(url);
+  });
+
+  // expresso and xpress are as close as each other, so keep the registry's order
+  assert.equal(await packageSearch.suggestAlternatives('npm', 'expres'), 'Did you mean: express, expresso, xpress?');
+  assert.deepEqual(urls, ['https://registry.npmjs.org/-/v1/search']);
+});
+
+test('no suggestion is made when the search finds nothing or fails', async () => {
+  stubGet(() => npmResults('left-pad'));
+  assert.equal(await packageSearch.suggestAlternatives('npm', 'left-pad'), undefined);
+
+  stubGet(url => notFound(url));
+  assert.equal(await packageSearch.suggestAlternatives('npm', 'left-pad'), undefined);
+});
+
+test('npm, python and php not-found errors suggest close names', async () => {
+  stubGet((url, config) => {
+    switch (url) {
+      case 'https://registry.npmjs.org/-/v1/search':
+        return npmResults('express');
+      case 'https://pypi.org/search/':
+        return {
+          data: '<span class="package-snippet__name">requests</span>\n<span class="package-snippet__version">2.31.0</span>\n<p class="package-snippet__description">HTTP for Humans.</p>',
+        };
+      case 'https://packagist.org/search.json':
+        assert.equal(config.params.q, 'monolog/monlog');
+        return { data: { results: [{ name: 'monolog/monolog' }] } };
+      default:
+        notFound(url);
+    }
+  });
+  const server = new PackageDocsServer();
+
+  const npm = JSON.parse(await callTool(server, 'describe_npm_package', { package: 'expres', source: 'network' }));
+  assert.equal(npm.error, 'Package expres not found. Did you mean: express? Try installing it with \'npm install expres\'');
+
+  const python = JSON.parse(await callTool(server, 'describe_python_package', { package: 'reqeusts', source: 'network' }));
+  assert.equal(python.error, 'Package reqeusts not found. Did you mean: requests? Try installing it with \'pip install reqeusts\'');
+
+  const php = JSON.parse(await callTool(server, 'describe_php_package', { package: 'monolog/monlog' }));
+  assert.match(php.error, /not found on Packagist\. Did you mean: monolog\/monolog\?/);
+});
+
+test('crates.io and Maven Central not-found errors suggest close names', async () => {
+  stubFetch(url => url.startsWith('https://crates.io/api/v1/crates?')
+    ? Response.json({ crates: [{ name: 'serde', max_version: '1.0.0' }] })
+    : new Response('', { status: 404 }));
+  stubGet((url, config) => {
+    if (url === 'https://search.maven.org/solrsearch/select') {
+      // The artifact lookup finds nothing, the keyword search finds the real artifact
+      const docs = config.params.q.startsWith('g:') ? [] : [{ g: 'com.google.guava', a: 'guava', latestVersion: '33.0.0-jre' }];
+      return { data: { response: { docs } } };
+    }
+    notFound(url);
+  });
+  const server = new PackageDocsServer();
+
+  const rust = JSON.parse(await callTool(server, 'describe_rust_package', { package: 'sedre', source: 'network' }));
+  assert.equal(rust.error, 'Crate sedre not found. Did you mean: serde? Try adding it to your Cargo.toml.');
+
+  const java = JSON.parse(await callTool(server, 'describe_java_package', { package: 'com.google.guava:guvaa' }));
+  assert.match(java.error, /not found on Maven Central\. Did you mean: com\.google\.guava:guava\?/);
+});
+
+test('failures other than a missing package don\'t look for suggestions', async () => {
+  const urls = stubGet(url => {
+    throw Object.assign(new Error(`Request failed with status code 500: ${url}`), { isAxiosError: true, response: { status: 500 } });
+  });
+
+  const python = JSON.parse(await callTool(new PackageDocsServer(), 'describe_python_package', { package: 'requests', source: 'network' }));
+  assert.doesNotMatch(python.error, /Did you mean/);
+  assert.ok(!urls.includes('https://pypi.org/search/'));
+});
+
+test('exact and prefix name matches are ranked first, keeping the registry order otherwise', () => {
+  const results = ['axios-retry', 'got', 'retry-axios', 'retry', 'p-retry', 'retryable'].map(name => ({ name }));
+  assert.deepEqual(packageSearch.rankResults('Retry', results).map(result => result.name), ['retry', 'retry-axios', 'retryable', 'axios-retry', 'p-retry', 'got']);
+});
+
+// Each registry's search response, naming a near match before the exact one, and the name the exact one is listed by
+const searchResponses = {
+  npm: ['https://registry.npmjs.org/-/v1/search', () => ({ data: { objects: [
+    { package: { name: 'fetch-retry', description: 'Retries fetch', version: '5.0.0' } },
+    { package: { name: 'retry', description: 'Retries things', version: '0.13.1' } },
+  ] } }), 'retry'],
+  python: ['https://pypi.org/search/', () => ({ data: [
+    '<span class="package-snippet__name">tenacity-retry</span> <span class="package-snippet__version">0.1</span> <p class="package-snippet__description">Retries fetch</p>',
+    '<span class="package-snippet__name">retry</span> <span class="package-snippet__version">0.9.2</span> <p class="package-snippet__description">Retries things</p>',
+  ].join('\n') }), 'retry'],
+  php: ['https://packagist.org/search.json', () => ({ data: { results: [
+    { name: 'guzzle/retry', description: 'Retries fetch' },
+    { name: 'retry', description: 'Retries things' },
+  ] } }), 'retry'],
+  java: ['https://search.maven.org/solrsearch/select', () => ({ data: { response: { docs: [
+    { g: 'io.github', a: 'fetch-retry', latestVersion: '1.0.0' },
+    { g: 'retry', a: 'retry', latestVersion: '2.0.0' },
+  ] } } }), 'retry:retry'],
+  dotnet: ['https://azuresearch-usnc.nuget.org/query', () => ({ data: { data: [
+    { id: 'Polly.Retry', description: 'Retries fetch', version: '8.0.0' },
+    { id: 'Retry', description: 'Retries things', version: '1.0.0' },
+  ] } }), 'Retry'],
+  go: ['https://pkg.go.dev/search', () => ({ data: [
+    '<div class="SearchSnippet"><span class="SearchSnippet-header-path">(github.com/acme/fetch-retry)</span><p class="SearchSnippet-synopsis" data-test-id="snippet-synopsis">Retries fetch</p></div>',
+    '<div class="SearchSnippet"><span class="SearchSnippet-header-path">(retry)</span><p class="SearchSnippet-synopsis">Retries <b>things</b></p></div>',
+  ].join('\n') }), 'retry'],
+};
+
+for (const [language, [searchUrl, respond, exactName]] of Object.entries(searchResponses)) {
+  test(`search_packages ranks ${language} packages named like the query first`, async () => {
+    const urls = stubGet((url, config) => {
+      if (url === searchUrl) return respond(config);
+      notFound(url);
+    });
+
+    const result = JSON.parse(await callTool(new PackageDocsServer(), 'search_packages', { query: 'retry', language }));
+    assert.deepEqual(urls, [searchUrl]);
+    assert.match(result.description, new RegExp(`^Found 2 ${language} packages matching "retry"`));
+    assert.equal(result.packages.length, 2);
+    assert.equal(result.packages[0].name, exactName);
+    // Maven Central's search results carry no description
+    if (language !== 'java') assert.equal(result.packages[0].description, 'Retries things');
+  });
+}
+
+test('search_packages ranks crates.io results', async () => {
+  const urls = stubFetch(() => Response.json({ crates: [
+    { name: 'backoff-retry', max_version: '0.1.0', description: 'Retries fetch' },
+    { name: 'retry', max_version: '2.0.0', description: 'Retries things' },
+  ] }));
+
+  const result = JSON.parse(await callTool(new PackageDocsServer(), 'search_packages', { query: 'retry', language: 'rust', limit: 5 }));
+  assert.deepEqual(result.packages.map(result => [result.name, result.version]), [['retry', '2.0.0'], ['backoff-retry', '0.1.0']]);
+  assert.equal(urls.length, 1);
+  assert.match(urls[0], /^https:\/\/crates\.io\/api\/v1\/crates\?q=retry&per_page=5/);
+});
+
+test('search_packages caps the limit and reports searches that find nothing', async () => {
+  const sizes = [];
+  stubGet((url, config) => {
+    sizes.push(config.params.size);
+    return { data: { objects: [] } };
+  });
+  const server = new PackageDocsServer();
+
+  const result = JSON.parse(await callTool(server, 'search_packages', { query: 'zzzz-nothing', language: 'npm', limit: 500 }));
+  assert.equal(result.error, 'No npm packages found matching "zzzz-nothing"');
+  assert.deepEqual(result.packages, []);
+  assert.deepEqual(sizes, [50]);
+
+  await assert.rejects(callTool(server, 'search_packages', { query: 'retry', language: 'cobol' }), /Invalid search_packages arguments/);
+});
+
+test('search_packages reports each registry\'s popularity signal', async () => {
+  stubGet(url => {
+    switch (url) {
+      case 'https://registry.npmjs.org/-/v1/search':
+        return { data: { objects: [{ package: { name: 'retry' }, downloads: { weekly: 1200, monthly: 5000 } }] } };
+      case 'https://packagist.org/search.json':
+        return { data: { results: [{ name: 'acme/retry', downloads: 300 }] } };
+      case 'https://azuresearch-usnc.nuget.org/query':
+        return { data: { data: [{ id: 'Retry', totalDownloads: 42 }] } };
+      case 'https://pkg.go.dev/search':
+        return {
+          data: [
+            '<div class="SearchSnippet"><span class="SearchSnippet-header-path">(github.com/acme/retry)</span>',
+            '<p class="SearchSnippet-synopsis">Retries</p><a>Imported by <strong>1,234</strong></a></div>',
+            '<div class="SearchSnippet"><span class="SearchSnippet-header-path">(github.com/acme/retry/v2)</span>',
+            '<p class="SearchSnippet-synopsis">Retries again</p></div>',
+          ].join('\n'),
+        };
+      default:
+        notFound(url);
+    }
+  });
+  stubFetch(() => Response.json({ crates: [
+    { name: 'retry', max_version: '2.0.0', recent_downloads: 900, downloads: 90000 },
+    { name: 'retry-old', max_version: '0.1.0', downloads: 10 },
+  ] }));
+  const server = new PackageDocsServer();
+  const search = async language => JSON.parse(await callTool(server, 'search_packages', { query: 'retry', language })).packages;
+
+  assert.deepEqual((await search('npm'))[0], { name: 'retry', downloads: 1200, downloadsPeriod: 'week' });
+  assert.deepEqual((await search('php'))[0], { name: 'acme/retry', downloads: 300, downloadsPeriod: 'all time' });
+  assert.deepEqual((await search('dotnet'))[0], { name: 'Retry', downloads: 42, downloadsPeriod: 'all time' });
+  assert.deepEqual((await search('rust')).map(crate => [crate.downloads, crate.downloadsPeriod]), [[900, '90 days'], [10, 'all time']]);
+  // A snippet without an imported-by count doesn't take the next one's
+  assert.deepEqual((await search('go')).map(result => result.importedBy), [1234, undefined]);
+});
+
+test('search_packages pages through each registry by limit', async () => {
+  const params = [];
+  stubGet((url, config) => {
+    params.push([url, config.params]);
+    return { data: { objects: [], results: [], data: [], response: { docs: [] } } };
+  });
+  const urls = stubFetch(() => Response.json({ crates: [] }));
+  const server = new PackageDocsServer();
+
+  for (const language of ['npm', 'php', 'java', 'dotnet', 'go', 'rust']) {
+    await callTool(server, 'search_packages', { query: 'retry', language, limit: 5, page: 3 });
+  }
+  assert.deepEqual(params.map(([url, { q, text, ...paging }]) => [url, paging]), [
+    ['https://registry.npmjs.org/-/v1/search', { size: 5, from: 10 }],
+    ['https://packagist.org/search.json', { per_page: 5, page: 3 }],
+    ['https://search.maven.org/solrsearch/select', { rows: 5, start: 10, wt: 'json' }],
+    ['https://azuresearch-usnc.nuget.org/query', { take: 5, skip: 10 }],
+    ['https://pkg.go.dev/search', { m: 'package', limit: 5, page: 3 }],
+  ]);
+  assert.match(urls[0], /[?&]per_page=5&page=3(&|$)/);
+});
+
+test('PyPI results are paged across its fixed size search pages', async () => {
+  // Each page of 20 results names its page and position
+  const pages = [];
+  stubGet((url, config) => {
+    pages.push(config.params.page);
+    const count = config.params.page < 3 ? 20 : 5;
+    return {
+      data: Array.from({ length: count }, (_, i) =>
+        `<span class="package-snippet__name">p${config.params.page}-${i}</span> <span class="package-snippet__version">1.0</span> <p class="package-snippet__description"></p>`
+      ).join('\n'),
+    };
+  });
+
+  // The second page of 15, results 16 to 30, spans the first and second search pages
+  const results = await packageSearch.searchPackages('python', 'retry', 15, undefined, 2);
+  assert.deepEqual(results.map(result => result.name), [
+    ...Array.from({ length: 5 }, (_, i) => `p1-${i + 15}`),
+    ...Array.from({ length: 10 }, (_, i) => `p2-${i}`),
+  ]);
+  assert.deepEqual(pages, [1, 2]);
+
+  // A short page is the last, however many results were asked for
+  pages.length = 0;
+  assert.equal((await packageSearch.searchPackages('python', 'retry', 50, undefined, 1)).length, 45);
+  assert.deepEqual(pages, [1, 2, 3]);
+});
+
+test('search_packages says when there may be more pages, and when there are none', async () => {
+  stubGet((url, config) => ({
+    data: { objects: config.params.from < 4 ? [{ package: { name: 'a' } }, { package: { name: 'b' } }] : [] },
+  }));
+  const server = new PackageDocsServer();
+  const search = async page => JSON.parse(await callTool(server, 'search_packages', { query: 'retry', language: 'npm', limit: 2, page }));
+
+  assert.equal((await search(1)).description, 'Found 2 npm packages matching "retry"; request page 2 for more');
+  assert.equal((await search(2)).description, 'Found 2 npm packages matching "retry" on page 2; request page 3 for more');
+  assert.equal((await search(3)).error, 'No more npm packages matching "retry" after page 2');
+});
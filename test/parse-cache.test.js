@@ -0,0 +1,79 @@
+import { test } from 'node:test';
+import assert from 'node:assert/strict';
+import { SearchUtils } from '../build/search-utils.js';
+import { cachedParse, withParseCache } from '../build/utils/parse-cache.js';
+import { silentLogger } from './helpers.js';
+
+const searchUtils = new SearchUtils(silentLogger);
+
+const readme = [
+  'Intro text',
+  '# Install',
+  '```sh',
+  'npm install pkg',
+  '```',
+  '#hashtag is not a heading',
+  '## Usage',
+  '```js',
+  'function createClient(options) {',
+  '  return new Client(options)',
+  '}',
+  '```',
+  '```python',
+  'def create_client(options):',
+  '    pass',
+  '```',
+].join('\n');
+
+test('a document is parsed once per tool call', async () => {
+  let parses = 0;
+  const parse = () => ++parses;
+
+  await withParseCache(async () => {
+    assert.equal(cachedParse('kind', 'document', parse), 1);
+    assert.equal(cachedParse('kind', 'document', parse), 1);
+    assert.equal(cachedParse('other kind', 'document', parse), 2);
+    assert.equal(cachedParse('kind', 'another document', parse), 3);
+  });
+  await withParseCache(async () => {
+    assert.equal(cachedParse('kind', 'document', parse), 4);
+  });
+  // Outside a tool call nothing is kept
+  assert.equal(cachedParse('kind', 'document', parse), 5);
+  assert.equal(cachedParse('kind', 'document', parse), 6);
+});
+
+test('the extractors return the same results from the cache', async () => {
+  const extract = () => ({
+    sections: searchUtils.splitMarkdownSections(readme),
+    docSections: searchUtils.parseMarkdownDocSections(readme),
+    codeBlocks: searchUtils.extractCodeBlocks(readme),
+    pythonBlocks: searchUtils.extractCodeBlocks(readme, 'python'),
+    javascriptBlocks: searchUtils.extractCodeBlocks(readme, 'npm'),
+    signatures: searchUtils.extractFunctionSignatures(readme),
+  });
+
+  const uncached = extract();
+  await withParseCache(async () => {
+    assert.deepEqual(extract(), uncached);
+    // The second run reads the cache
+    assert.deepEqual(extract(), uncached);
+  });
+
+  assert.deepEqual(uncached.sections.map(section => section.split('\n')[0]), ['Intro text', '# Install', '## Usage']);
+  assert.equal(uncached.codeBlocks.length, 3);
+  assert.deepEqual(uncached.pythonBlocks.map(block => block.language), ['python']);
+  assert.deepEqual(uncached.signatures.map(signature => signature.name), ['createClient', 'create_client']);
+});
+
+test('changing an extractor result leaves the cache alone', async () => {
+  await withParseCache(async () => {
+    searchUtils.splitMarkdownSections(readme).pop();
+    searchUtils.extractCodeBlocks(readme).pop();
+    searchUtils.extractFunctionSignatures(readme).pop();
+
+    assert.equal(searchUtils.splitMarkdownSections(readme).length, 3);
+    assert.equal(searchUtils.extractCodeBlocks(readme).length, 3);
+    assert.equal(searchUtils.extractFunctionSignatures(readme).length, 2);
+  });
+});
@@ -0,0 +1,117 @@
+import { afterEach, test } from "node:test"
+import assert from "node:assert/strict"
+import { extractNpmPlatforms, extractPythonPlatforms, formatPlatforms } from "../build/platform-utils.js"
+import { RustDocsHandler } from "../build/rust-docs-integration.js"
+import { PackageDocsServer } from "../build/package-docs-server.js"
+import { callTool, notFound, restoreNetwork, silentLogger, stubFetch, stubGet } from "./helpers.js"
+
+afterEach(restoreNetwork)
+
+test("npm platforms come from the os and cpu fields", () => {
+  assert.deepEqual(extractNpmPlatforms({ os: ["darwin", "linux"], cpu: ["arm64"] }), ["os: darwin, linux", "cpu: arm64"])
+  assert.deepEqual(extractNpmPlatforms({ os: ["!win32"] }), ["os: !win32"])
+  // os/cpu win over optional platform packages
+  assert.deepEqual(extractNpmPlatforms({ cpu: ["x64"], optionalDependencies: { "@esbuild/linux-arm64": "0.20.0" } }), ["cpu: x64"])
+})
+
+test("npm platforms fall back to the platform packages of optionalDependencies", () => {
+  const manifest = {
+    optionalDependencies: {
+      "@esbuild/darwin-arm64": "0.20.0",
+      "@esbuild/linux-x64": "0.20.0",
+      "@swc/core-linux-x64-musl": "1.4.0",
+      "@swc/core-win32-x64-msvc": "1.4.0",
+      "fsevents": "2.3.3",
+    },
+  }
+  assert.deepEqual(extractNpmPlatforms(manifest), ["darwin-arm64", "linux-x64", "linux-x64-musl", "win32-x64-msvc"])
+  assert.deepEqual(extractNpmPlatforms({}), [])
+})
+
+test("python platforms come from the wheel tags, grouped by operating system", () => {
+  const files = [
+    { filename: "numpy-1.26.4.tar.gz", packagetype: "sdist" },
+    { filename: "numpy-1.26.4-cp312-cp312-manylinux_2_17_x86_64.manylinux2014_x86_64.whl" },
+    { filename: "numpy-1.26.4-cp312-cp312-manylinux_2_17_aarch64.manylinux2014_aarch64.whl" },
+    { filename: "numpy-1.26.4-cp312-cp312-musllinux_1_1_x86_64.whl" },
+    { filename: "numpy-1.26.4-cp312-cp312-macosx_11_0_arm64.whl" },
+    { filename: "numpy-1.26.4-cp312-cp312-macosx_10_9_universal2.whl" },
+    { filename: "numpy-1.26.4-cp312-cp312-win_amd64.whl" },
+    { filename: "numpy-1.26.4-cp312-cp312-win32.whl" },
+  ]
+  assert.deepEqual(extractPythonPlatforms(files), [
+    "linux (arm64, x86_64)",
+    "linux-musl (x86_64)",
+    "macos (arm64, universal)",
+    "windows (x86, x86_64)",
+  ])
+})
+
+test("pure Python wheels are any platform, and sdists alone say nothing", () => {
+  assert.deepEqual(extractPythonPlatforms([{ filename: "requests-2.31.0-py3-none-any.whl" }]), ["any (pure Python)"])
+  assert.deepEqual(extractPythonPlatforms([{ filename: "requests-2.31.0.tar.gz" }]), [])
+  // Platform wheels make a pure one beside them redundant
+  assert.deepEqual(extractPythonPlatforms([
+    { filename: "pkg-1.0-py3-none-any.whl" },
+    { filename: "pkg-1.0-cp312-cp312-win_arm64.whl" },
+  ]), ["windows (arm64)"])
+})
+
+test("platforms are formatted as a single line, or left out when there are none", () => {
+  assert.equal(formatPlatforms(["linux (x86_64)", "macos (arm64)"]), "Platforms: linux (x86_64); macos (arm64)")
+  assert.equal(formatPlatforms([]), undefined)
+})
+
+test("crate targets are read from the docs.rs platform menu", async () => {
+  const html = [
+    "<ul class=\"pure-menu-list\">",
+    "<li><a href=\"/crate/krate/1.0.0/target-redirect/x86_64-unknown-linux-gnu/krate/\">x86_64-unknown-linux-gnu</a></li>",
+    "<li><a href=\"/crate/krate/1.0.0/target-redirect/x86_64-pc-windows-msvc/krate/\">x86_64-pc-windows-msvc</a></li>",
+    "<li><a href=\"/crate/krate/1.0.0/target-redirect/wasm32-unknown-unknown/krate/\">wasm32-unknown-unknown</a></li>",
+    "<li><a href=\"/crate/krate/1.0.0/target-redirect/x86_64-unknown-linux-gnu/krate/struct.Thing.html\">Thing</a></li>",
+    "</ul>",
+  ].join("\n")
+  const urls = stubFetch(() => new Response(html, { headers: { "content-type": "text/html" } }))
+
+  const handler = new RustDocsHandler(silentLogger)
+  assert.deepEqual(await handler.getSupportedTargets("krate", "1.0.0"), [
+    "x86_64-unknown-linux-gnu",
+    "x86_64-pc-windows-msvc",
+    "wasm32-unknown-unknown",
+  ])
+  assert.deepEqual(urls, ["https://docs.rs/crate/krate/1.0.0"])
+
+  stubFetch(() => new Response("", { status: 404 }))
+  assert.deepEqual(await handler.getSupportedTargets("krate", "1.0.0"), [])
+})
+
+test("described npm and python packages get a Platforms line", async () => {
+  stubGet(url => {
+    if (url === "https://registry.npmjs.org/native-widgets") {
+      return {
+        data: {
+          name: "native-widgets",
+          description: "Native widgets",
+          "dist-tags": { latest: "1.0.0" },
+          versions: { "1.0.0": { name: "native-widgets", version: "1.0.0", os: ["darwin", "linux"], cpu: ["x64", "arm64"] } },
+        },
+      }
+    }
+    if (url === "https://pypi.org/pypi/native-widgets/json") {
+      return {
+        data: {
+          info: { name: "native-widgets", version: "1.0.0", summary: "Native widgets" },
+          releases: { "1.0.0": [{ filename: "native_widgets-1.0.0-cp312-cp312-manylinux_2_17_x86_64.whl" }] },
+        },
+      }
+    }
+    notFound(url)
+  })
+
+  const server = new PackageDocsServer()
+  const npm = JSON.parse(await callTool(server, "describe_npm_package", { package: "native-widgets", source: "network", includeTypes: false, includeExamples: false }))
+  assert.match(npm.description, /\n\nPlatforms: os: darwin, linux; cpu: x64, arm64$/)
+
+  const python = JSON.parse(await callTool(server, "describe_python_package", { package: "native-widgets", source: "network" }))
+  assert.match(python.description, /^Native widgets\n\nPlatforms: linux \(x86_64\)/)
+})
@@ -0,0 +1,112 @@
+import { afterEach, test } from "node:test"
+import assert from "node:assert/strict"
+import { NpmDocsHandler } from "../build/npm-docs-integration.js"
+import { PackageDocsServer } from "../build/package-docs-server.js"
+import { notFound, restoreNetwork, stubFetch, stubGet } from "./helpers.js"
+
+afterEach(restoreNetwork)
+
+const server = new PackageDocsServer()
+
+const packument = {
+  name: "pkg",
+  "dist-tags": { latest: "1.0.0" },
+  versions: { "1.0.0": { version: "1.0.0" }, "2.0.0-beta.1": { version: "2.0.0-beta.1" } },
+  time: { "1.0.0": "2026-01-01T00:00:00Z", "2.0.0-beta.1": "2026-02-01T00:00:00Z" },
+}
+
+function describeNpm(args, installed = false) {
+  return new NpmDocsHandler().describeNpmPackage(
+    { package: "pkg", includeTypes: false, includeExamples: false, ...args },
+    () => ({ registry: "https://registry.example" }),
+    () => installed,
+    () => ({ description: "installed copy" })
+  )
+}
+
+test("npm reads the newest prerelease from the registry", async () => {
+  const urls = stubGet(url => {
+    if (url === "https://registry.example/pkg") return { data: packument }
+    if (url === "https://registry.example/pkg/2.0.0-beta.1") return { data: { ...packument.versions["2.0.0-beta.1"], description: "beta" } }
+    notFound(url)
+  })
+
+  const result = await describeNpm({ includePrerelease: true })
+  assert.ok(urls.includes("https://registry.example/pkg/2.0.0-beta.1"))
+  assert.match(result.description, /beta/)
+})
+
+test("npm leaves installed packages and the local source alone", async () => {
+  const urls = stubGet(notFound)
+
+  assert.equal((await describeNpm({ includePrerelease: true }, true)).description, "installed copy")
+  assert.ok((await describeNpm({ includePrerelease: true, source: "local" })).error)
+  assert.deepEqual(urls, [])
+})
+
+test("npm keeps an explicit version", async () => {
+  const urls = stubGet(url => url === "https://registry.example/pkg/1.0.0" ? { data: packument.versions["1.0.0"] } : notFound(url))
+
+  await describeNpm({ version: "1.0.0", includePrerelease: true })
+  assert.equal(urls[0], "https://registry.example/pkg/1.0.0")
+  assert.ok(!urls.includes("https://registry.example/pkg"))
+})
+
+test("Python reads the newest prerelease from PyPI", async () => {
+  const file = (version, yanked = false) => [{ filename: `pkg-${version}-py3-none-any.whl`, yanked }]
+  const releases = { "1.0": file("1.0"), "2.0b1": file("2.0b1"), "3.0a1": file("3.0a1", true) }
+  const urls = stubGet(url => {
+    if (url === "https://pypi.org/pypi/pkg/json") return { data: { info: { version: "1.0", summary: "stable" }, releases } }
+    if (url === "https://pypi.org/pypi/pkg/2.0b1/json") return { data: { info: { version: "2.0b1", summary: "beta" }, releases } }
+    notFound(url)
+  })
+
+  const result = await server["describePythonPackage"]({ package: "pkg", includePrerelease: true, source: "network" })
+  assert.ok(urls.includes("https://pypi.org/pypi/pkg/2.0b1/json"))
+  assert.match(result.description, /beta/)
+})
+
+test("Python doesn't resolve versions for the local source", async () => {
+  const urls = stubGet(notFound)
+
+  const result = await server["describePythonPackage"]({ package: "not-installed-anywhere-pkg", includePrerelease: true, source: "local" })
+  assert.ok(result.error)
+  assert.deepEqual(urls, [])
+})
+
+test("Rust reads the newest prerelease from docs.rs", async () => {
+  const urls = stubFetch(url => {
+    if (url.startsWith("https://crates.io/api/v1/crates/krate")) {
+      return Response.json({
+        crate: { name: "krate", downloads: 1, max_version: "1.0.0" },
+        versions: [{ num: "2.0.0-alpha.1", yanked: false }, { num: "1.0.0", yanked: false }],
+      })
+    }
+    if (url.startsWith("https://docs.rs/")) {
+      return new Response("<html><body><main>krate docs</main></body></html>", { headers: { "content-type": "text/html" } })
+    }
+    return new Response("", { status: 404 })
+  })
+  stubGet(notFound)
+
+  await server["describeRustPackage"]({ package: "krate", includePrerelease: true, source: "network" })
+  assert.ok(urls.some(url => url.startsWith("https://docs.rs/crate/krate/2.0.0-alpha.1")), urls.join("\n"))
+})
+
+test("Go reads the newest prerelease from pkg.go.dev", async () => {
+  const urls = stubGet(url => {
+    if (url === "https://proxy.golang.org/example.com/mod/@v/list") return { data: "v1.0.0\nv1.1.0-rc.1\n" }
+    if (url.startsWith("https://pkg.go.dev/example.com/mod@")) return { data: "<html></html>" }
+    notFound(url)
+  })
+
+  await server["describeGoPackage"]({ package: "example.com/mod", includePrerelease: true, source: "network" })
+  assert.ok(urls.includes("https://pkg.go.dev/example.com/mod@v1.1.0-rc.1"), urls.join("\n"))
+})
+
+test("Go standard library packages have no versions to resolve", async () => {
+  const urls = stubGet(notFound)
+
+  await server["describeGoPackage"]({ package: "fmt", includePrerelease: true, source: "network" })
+  assert.ok(!urls.some(url => url.includes("proxy.golang.org")))
+})
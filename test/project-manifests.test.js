@@ -0,0 +1,225 @@
+import { test } from "node:test"
+import assert from "node:assert/strict"
+import { chmodSync, mkdirSync, mkdtempSync, writeFileSync } from "fs"
+import { tmpdir } from "os"
+import { join } from "path"
+import { PackageDocsServer } from "../build/package-docs-server.js"
+import { findGoModRequirement } from "../build/dependency-versions.js"
+import { cargoDependencyNames, findGoModFiles, findNpmWorkspacePackage, formatSwiftConstraint, parseCargoToml, parseGoMod, parsePackageSwift } from "../build/project-manifests.js"
+import { callTool, notFound, restoreNetwork, stubGet } from "./helpers.js"
+
+const cargoToml = `
+[package]
+name = "app"
+
+[dependencies]
+serde = "1.0"
+local = { path = "../local" }
+shared = { workspace = true }
+
+[dependencies.tokio]
+version = "1.37"
+features = ["full"]
+
+[dev-dependencies]
+insta = "1"
+
+[build-dependencies]
+cc = "1.0"
+
+[target.'cfg(unix)'.dependencies]
+nix = { version = "0.28" }
+
+[target."cfg(windows)".dev-dependencies]
+windows-sys = "0.52"
+
+[workspace.dependencies]
+anyhow = "1"
+`
+
+test("Cargo.toml dependencies are read from every dependency table", () => {
+  assert.deepEqual(
+    parseCargoToml(cargoToml).map(({ name, requirement, dev }) => `${name} ${requirement}${dev ? " dev" : ""}`),
+    ["serde 1.0", "tokio 1.37", "insta 1 dev", "cc 1.0", "nix 0.28", "windows-sys 0.52 dev", "anyhow 1"]
+  )
+})
+
+test("installed crates include path and workspace dependencies", () => {
+  const names = cargoDependencyNames(cargoToml)
+  for (const name of ["serde", "local", "shared", "tokio", "cc", "nix", "anyhow"]) {
+    assert.ok(names.includes(name), name)
+  }
+})
+
+test("a go.work workspace's modules are found from any directory in it", async () => {
+  const root = mkdtempSync(join(tmpdir(), "go-work-"))
+  for (const module of ["api", "worker"]) {
+    mkdirSync(join(root, module, "internal"), { recursive: true })
+    writeFileSync(join(root, module, "go.mod"), `module example.com/${module}\n`)
+  }
+  writeFileSync(join(root, "go.work"), "go 1.22\n\nuse (\n\t./api\n\t./worker // the background jobs\n)\n")
+
+  assert.deepEqual(findGoModFiles(join(root, "api", "internal")), [join(root, "api", "go.mod"), join(root, "worker", "go.mod")])
+
+  // Packages of the workspace's other modules are local to the project
+  assert.ok(await new PackageDocsServer()["isGoPackageInstalledLocally"]("example.com/worker/jobs", join(root, "api")))
+})
+
+test("without a go.work, the nearest go.mod is used", () => {
+  const root = mkdtempSync(join(tmpdir(), "go-mod-"))
+  mkdirSync(join(root, "cmd", "tool"), { recursive: true })
+  writeFileSync(join(root, "go.mod"), "module example.com/tool\n")
+
+  assert.deepEqual(findGoModFiles(join(root, "cmd", "tool")), [join(root, "go.mod")])
+})
+
+test("go.mod requirements are read from single lines and require blocks, skipping indirect ones", () => {
+  const goMod = [
+    "module example.com/app",
+    "",
+    "go 1.22",
+    "",
+    "require github.com/spf13/cobra v1.8.0",
+    "",
+    "require (",
+    "\tgolang.org/x/sync v0.7.0",
+    "\tgithub.com/inconshreveable/mousetrap v1.1.0 // indirect",
+    ")",
+    "",
+    "exclude (",
+    "\tgolang.org/x/sync v0.6.0",
+    ")",
+    "",
+    "replace github.com/spf13/cobra v1.8.0 => ../cobra",
+  ].join("\n")
+
+  assert.deepEqual(parseGoMod(goMod), [
+    { name: "github.com/spf13/cobra", requirement: "v1.8.0" },
+    { name: "golang.org/x/sync", requirement: "v0.7.0" },
+  ])
+  assert.deepEqual(findGoModRequirement(goMod, "golang.org/x/sync/errgroup"), { module: "golang.org/x/sync", version: "v0.7.0" })
+  assert.deepEqual(findGoModRequirement(goMod, "github.com/inconshreveable/mousetrap"), { module: "github.com/inconshreveable/mousetrap", version: "v1.1.0" })
+})
+
+test("the dependencies of a project with a go.mod and a package.json are summarised per ecosystem", async () => {
+  const root = mkdtempSync(join(tmpdir(), "mixed-project-"))
+  writeFileSync(join(root, "go.mod"), "module example.com/app\n\ngo 1.22\n\nrequire (\n\tgithub.com/spf13/cobra v1.8.0\n\tgithub.com/spf13/pflag v1.0.5 // indirect\n)\n")
+  mkdirSync(join(root, "web"))
+  writeFileSync(join(root, "web", "package.json"), JSON.stringify({ dependencies: { express: "^4.19.0" }, devDependencies: { vitest: "^1.0.0" } }))
+
+  // A go whose go list describes cobra as the module cache would
+  const bin = mkdtempSync(join(tmpdir(), "package-docs-go-"))
+  const goPackage = { ImportPath: "github.com/spf13/cobra", Doc: "Package cobra is a commander for modern Go CLI interactions.", Module: { Path: "github.com/spf13/cobra", Version: "v1.8.0" } }
+  writeFileSync(join(bin, "go"), `#!/bin/sh\necho '${JSON.stringify(goPackage)}'\n`)
+  chmodSync(join(bin, "go"), 0o755)
+  const path = process.env.PATH
+  process.env.PATH = bin
+
+  const urls = stubGet(url => {
+    if (url === "https://registry.npmjs.org/express") {
+      return {
+        data: {
+          name: "express",
+          "dist-tags": { latest: "4.19.2" },
+          versions: { "4.19.2": { name: "express", version: "4.19.2", description: "Fast, unopinionated, minimalist web framework", homepage: "https://expressjs.com/" } },
+        },
+      }
+    }
+    notFound(url)
+  })
+
+  try {
+    const server = new PackageDocsServer()
+    const text = await callTool(server, "describe_project_dependencies", { projectPath: root })
+
+    assert.deepEqual(urls, ["https://registry.npmjs.org/express"])
+    assert.match(text, /2 direct dependencies declared in go\.mod, web\/package\.json/)
+    assert.match(text, /## npm \(1\)\n[^#]*\| express +\| \^4\.19\.0 +\| 4\.19\.2 +\| Fast, unopinionated, minimalist web framework +\| https:\/\/expressjs\.com\/ +\|/)
+    assert.match(text, /## go \(1\)\n[^#]*\| github\.com\/spf13\/cobra +\| v1\.8\.0 +\| v1\.8\.0 +\| Package cobra is a commander/)
+    assert.doesNotMatch(text, /pflag|vitest/)
+  } finally {
+    process.env.PATH = path
+    restoreNetwork()
+  }
+})
+
+test("every Package.swift dependency form is read with its constraint", () => {
+  const packageSwift = `
+// swift-tools-version:5.9
+import PackageDescription
+
+let package = Package(
+  name: "App",
+  dependencies: [
+    .package(url: "https://github.com/vapor/vapor.git", .upToNextMajor(from: "4.89.0")),
+    .package(url: "https://github.com/apple/swift-nio.git", branch: "main"),
+    .package(url: "https://github.com/apple/swift-log.git", from: "1.5.0"),
+    .package(url: "https://github.com/apple/swift-crypto.git", .upToNextMinor(from: "3.1.0")),
+    .package(url: "https://github.com/pointfreeco/swift-case-paths", exact: "1.2.2"),
+    .package(url: "https://github.com/apple/swift-collections", "1.0.0"..<"2.0.0"),
+    .package(url: "https://github.com/example/pinned.git", revision: "a1b2c3d"),
+    .package(name: "Legacy", url: "https://github.com/example/legacy.git", .branch("release")),
+    .package(id: "mona.LinkedList", from: "1.0.0"),
+    .package(path: "../LocalKit"),
+    // .package(url: "https://github.com/example/commented-out.git", from: "1.0.0"),
+  ]
+)
+`
+
+  assert.deepEqual(parsePackageSwift(packageSwift).map(({ url, name, constraint }) => [url, name, constraint?.kind, constraint?.value]), [
+    ["https://github.com/vapor/vapor.git", undefined, "upToNextMajor", "4.89.0"],
+    ["https://github.com/apple/swift-nio.git", undefined, "branch", "main"],
+    ["https://github.com/apple/swift-log.git", undefined, "from", "1.5.0"],
+    ["https://github.com/apple/swift-crypto.git", undefined, "upToNextMinor", "3.1.0"],
+    ["https://github.com/pointfreeco/swift-case-paths", undefined, "exact", "1.2.2"],
+    ["https://github.com/apple/swift-collections", undefined, "range", "1.0.0..<2.0.0"],
+    ["https://github.com/example/pinned.git", undefined, "revision", "a1b2c3d"],
+    ["https://github.com/example/legacy.git", "Legacy", "branch", "release"],
+    ["mona.LinkedList", undefined, "from", "1.0.0"],
+    ["../LocalKit", undefined, "path", "../LocalKit"],
+  ])
+  assert.equal(formatSwiftConstraint({ kind: "upToNextMajor", value: "4.89.0" }), "up to next major from 4.89.0")
+  assert.equal(formatSwiftConstraint({ kind: "branch", value: "main" }), "branch main")
+})
+
+// A monorepo whose workspaces are packages/*, except packages/legacy, plus tools/cli
+function createMonorepo() {
+  const root = mkdtempSync(join(tmpdir(), "monorepo-"))
+  writeFileSync(join(root, "package.json"), JSON.stringify({ name: "monorepo", private: true, workspaces: ["packages/*", "!packages/legacy", "tools/cli"] }))
+  const packages = {
+    "packages/ui": { name: "@acme/ui", version: "0.3.0", description: "Shared UI components" },
+    "packages/legacy": { name: "@acme/legacy", version: "0.1.0" },
+    "tools/cli": { name: "acme-cli", version: "1.0.0" },
+  }
+  for (const [path, manifest] of Object.entries(packages)) {
+    mkdirSync(join(root, path), { recursive: true })
+    writeFileSync(join(root, path, "package.json"), JSON.stringify(manifest))
+  }
+  writeFileSync(join(root, "packages", "ui", "README.md"), "# @acme/ui\n\nShared UI components.\n\n## Usage\n\nImport `Button` from `@acme/ui`.\n")
+  return root
+}
+
+test("workspace packages are found from the root package.json's workspaces globs", () => {
+  const root = createMonorepo()
+  assert.equal(findNpmWorkspacePackage(root, "@acme/ui"), join(root, "packages", "ui"))
+  assert.equal(findNpmWorkspacePackage(root, "acme-cli"), join(root, "tools", "cli"))
+  assert.equal(findNpmWorkspacePackage(root, "@acme/legacy"), undefined)
+  assert.equal(findNpmWorkspacePackage(root, "left-pad"), undefined)
+  assert.equal(findNpmWorkspacePackage(join(root, "packages"), "@acme/ui"), undefined)
+})
+
+test("a workspace package is documented from its local files, without the registry", async () => {
+  const root = createMonorepo()
+  const urls = stubGet(notFound)
+
+  try {
+    const server = new PackageDocsServer()
+    const text = await callTool(server, "describe_npm_package", { package: "@acme/ui", projectPath: root })
+
+    assert.match(text, /Shared UI components/)
+    assert.match(text, /Import `Button` from `@acme\/ui`/)
+    assert.deepEqual(urls, [])
+  } finally {
+    restoreNetwork()
+  }
+})
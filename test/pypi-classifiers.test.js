@@ -0,0 +1,86 @@
+import { afterEach, test } from "node:test"
+import assert from "node:assert/strict"
+import { extractPythonVersions, formatPyPICompatibility, groupClassifiers, recentReleases } from "../build/pypi-classifiers.js"
+import { PackageDocsServer } from "../build/package-docs-server.js"
+import { callTool, notFound, restoreNetwork, stubGet } from "./helpers.js"
+
+afterEach(restoreNetwork)
+
+const classifiers = [
+  "Programming Language :: Python :: 3",
+  "Programming Language :: Python :: 3.10",
+  "Programming Language :: Python :: 3.9",
+  "Programming Language :: Python :: 3.12",
+  "Programming Language :: Python :: Implementation :: CPython",
+  "Topic :: Internet :: WWW/HTTP",
+  "License :: OSI Approved :: Apache Software License",
+  "Development Status :: 5 - Production/Stable",
+  "Framework :: Django",
+  "Framework :: Django :: 4.2",
+  "Intended Audience :: Developers",
+]
+
+test("supported Python versions come from the version classifiers, in version order", () => {
+  assert.deepEqual(extractPythonVersions(classifiers), ["3.9", "3.10", "3.12"])
+  // Major versions only count when no minor versions are listed
+  assert.deepEqual(extractPythonVersions(["Programming Language :: Python :: 3", "Programming Language :: Python :: 2"]), ["2", "3"])
+  assert.deepEqual(extractPythonVersions(["Topic :: Utilities"]), [])
+})
+
+test("classifiers are grouped by category, without the Python ones", () => {
+  assert.deepEqual(groupClassifiers(classifiers), {
+    "Topic": ["Internet :: WWW/HTTP"],
+    "License": ["OSI Approved :: Apache Software License"],
+    "Development Status": ["5 - Production/Stable"],
+    "Framework": ["Django", "Django :: 4.2"],
+    "Intended Audience": ["Developers"],
+  })
+})
+
+test("recent releases are the newest with files that aren't all yanked", () => {
+  const releases = {
+    "1.0.0": [{ upload_time_iso_8601: "2023-01-02T10:00:00.000000Z" }],
+    "1.1.0": [{ upload_time: "2023-06-01T09:00:00" }],
+    "1.2.0": [{ upload_time_iso_8601: "2024-01-01T00:00:00Z", yanked: true }],
+    "2.0.0": [],
+    "1.3.0": [{ upload_time_iso_8601: "2024-03-01T00:00:00Z", yanked: true }, { upload_time_iso_8601: "2024-03-01T00:00:00Z" }],
+  }
+
+  assert.deepEqual(recentReleases(releases), [
+    { version: "1.3.0", date: "2024-03-01" },
+    { version: "1.1.0", date: "2023-06-01" },
+    { version: "1.0.0", date: "2023-01-02" },
+  ])
+  assert.deepEqual(recentReleases(releases, 1).map(release => release.version), ["1.3.0"])
+})
+
+test("PyPI compatibility is rendered with the most important classifiers first", () => {
+  const releases = Object.fromEntries(Array.from({ length: 12 }, (_, i) => [`1.${i}.0`, [{ upload_time_iso_8601: `2024-01-${String(i + 10)}T00:00:00Z` }]]))
+  const markdown = formatPyPICompatibility(classifiers, ">=3.9", releases)
+
+  assert.match(markdown, /^## Supported Python Versions\n\n3\.9, 3\.10, 3\.12 \(requires Python >=3\.9\)/)
+  assert.match(markdown, /## Classifiers\n\n- Development Status: 5 - Production\/Stable\n- License: OSI Approved :: Apache Software License\n- Framework: Django; Django :: 4\.2\n/)
+  assert.match(markdown, /## Recent Releases\n\n- 1\.11\.0 \(2024-01-21\)/)
+  assert.match(markdown, /12 releases in total$/)
+  assert.equal(formatPyPICompatibility([], ">=3.8"), "## Supported Python Versions\n\nRequires Python >=3.8")
+  assert.equal(formatPyPICompatibility([]), "")
+})
+
+test("describe_python_package includes the PyPI classifiers and releases", async () => {
+  stubGet(url => {
+    if (url === "https://pypi.org/pypi/classified/json") {
+      return {
+        data: {
+          info: { name: "classified", version: "1.0.0", summary: "Classified", classifiers, requires_python: ">=3.9" },
+          releases: { "1.0.0": [{ filename: "classified-1.0.0-py3-none-any.whl", upload_time_iso_8601: "2024-05-01T00:00:00Z" }] },
+        },
+      }
+    }
+    notFound(url)
+  })
+
+  const result = JSON.parse(await callTool(new PackageDocsServer(), "describe_python_package", { package: "classified", source: "network" }))
+  assert.match(result.description, /## Supported Python Versions\n\n3\.9, 3\.10, 3\.12/)
+  assert.match(result.description, /- Development Status: 5 - Production\/Stable/)
+  assert.match(result.description, /- 1\.0\.0 \(2024-05-01\)/)
+})
@@ -0,0 +1,40 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { callTool, notFound, restoreNetwork, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+// Describe a PyPI project with the given project_urls, returning the Links list of its description
+async function describeLinks(projectUrls) {
+  stubGet(url => {
+    if (url === 'https://pypi.org/pypi/linked/json') {
+      return { data: { info: { name: 'linked', version: '1.0.0', summary: 'Linked', project_urls: projectUrls } } }
+    }
+    notFound(url)
+  })
+
+  const server = new PackageDocsServer()
+  const result = JSON.parse(await callTool(server, 'describe_python_package', { package: 'linked', source: 'network' }))
+  return result.description.split('Links:\n')[1].split('\n\n')[0]
+}
+
+test('a code of conduct is not taken for the source code', async () => {
+  const links = await describeLinks({
+    'Code of Conduct': 'https://example.com/conduct',
+    'Source Code': 'https://github.com/example/linked',
+  })
+
+  assert.match(links, /^- Source: https:\/\/github\.com\/example\/linked$/m)
+  assert.match(links, /^- Code of Conduct: https:\/\/example\.com\/conduct$/m)
+})
+
+test('source links are matched on whole words', async () => {
+  const links = await describeLinks({
+    Repository: 'https://gitlab.com/example/linked',
+    Barcode: 'https://example.com/barcode',
+  })
+
+  assert.match(links, /^- Source: https:\/\/gitlab\.com\/example\/linked$/m)
+  assert.match(links, /^- Barcode: https:\/\/example\.com\/barcode$/m)
+})
@@ -0,0 +1,61 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { chmodSync, mkdirSync, mkdtempSync, writeFileSync } from 'fs'
+import { tmpdir } from 'os'
+import { join } from 'path'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { getAllowedCommands, setAllowedCommands } from '../build/utils/command-runner.js'
+import { callTool } from './helpers.js'
+
+const virtualEnv = process.env.VIRTUAL_ENV
+
+afterEach(() => {
+  setAllowedCommands(getAllowedCommands())
+  if (virtualEnv === undefined) delete process.env.VIRTUAL_ENV
+  else process.env.VIRTUAL_ENV = virtualEnv
+})
+
+// A virtualenv whose python3 prints pydoc output naming the environment it ran in
+function fakeEnvironment(envDir, marker) {
+  const binDir = join(envDir, 'bin')
+  mkdirSync(binDir, { recursive: true })
+  writeFileSync(join(binDir, 'python3'), [
+    '#!/bin/sh',
+    'echo FUNCTIONS',
+    `echo '    ${marker}()'`,
+    'echo DATA',
+    'echo "    environment = \'$VIRTUAL_ENV\'"',
+    '',
+  ].join('\n'))
+  chmodSync(join(binDir, 'python3'), 0o755)
+  return binDir
+}
+
+test('python packages are read with the interpreter of the project\'s virtualenv', { skip: process.platform === 'win32' }, async () => {
+  const projectPath = mkdtempSync(join(tmpdir(), 'package-docs-venv-'))
+  const envDir = join(projectPath, '.venv')
+  setAllowedCommands(['python3', 'python'], [fakeEnvironment(envDir, 'from_project_venv')])
+
+  const text = await callTool(new PackageDocsServer(), 'list_package_symbols', { package: 'widgets', language: 'python', projectPath })
+  assert.match(text, /`from_project_venv`/)
+  // The interpreter runs with its environment activated
+  assert.match(text, new RegExp(`environment = '${envDir}'`))
+})
+
+test('an activated virtualenv is used when the project has none of its own', { skip: process.platform === 'win32' }, async () => {
+  const projectPath = mkdtempSync(join(tmpdir(), 'package-docs-project-'))
+  const envDir = mkdtempSync(join(tmpdir(), 'package-docs-active-env-'))
+  process.env.VIRTUAL_ENV = envDir
+  setAllowedCommands(['python3', 'python'], [fakeEnvironment(envDir, 'from_active_venv')])
+
+  assert.match(await callTool(new PackageDocsServer(), 'list_package_symbols', { package: 'widgets', language: 'python', projectPath }), /`from_active_venv`/)
+})
+
+test('a virtualenv interpreter outside the allowed directories is not run', { skip: process.platform === 'win32' }, async () => {
+  const projectPath = mkdtempSync(join(tmpdir(), 'package-docs-venv-'))
+  fakeEnvironment(join(projectPath, '.venv'), 'from_disallowed_venv')
+  // Only bare names on the PATH are allowed, so the project's interpreter is skipped
+  setAllowedCommands(['python3', 'python'], [])
+
+  assert.doesNotMatch(await callTool(new PackageDocsServer(), 'list_package_symbols', { package: 'widgets', language: 'python', projectPath }), /from_disallowed_venv/)
+})
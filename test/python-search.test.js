@@ -0,0 +1,106 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { chmodSync, mkdirSync, mkdtempSync, writeFileSync } from 'fs'
+import { tmpdir } from 'os'
+import { join } from 'path'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { getAllowedCommands, setAllowedCommands } from '../build/utils/command-runner.js'
+import { callTool, notFound, restoreNetwork, stubGet } from './helpers.js'
+
+afterEach(() => {
+  restoreNetwork()
+  setAllowedCommands(getAllowedCommands())
+})
+
+const description = [
+  'widgets',
+  '=======',
+  '',
+  'Widgets for everyone.',
+  '',
+  'Configuration',
+  '-------------',
+  '',
+  'Set ``WIDGET_TIMEOUT`` to change how long a widget waits.',
+  '',
+  'Licence',
+  '-------',
+  '',
+  'MIT',
+].join('\n')
+
+function stubPyPI() {
+  return stubGet(url => {
+    if (url === 'https://pypi.org/pypi/widgets/json') {
+      return {
+        data: {
+          info: {
+            name: 'widgets',
+            version: '1.0.0',
+            summary: 'Widgets for everyone',
+            description,
+            description_content_type: 'text/x-rst',
+            project_urls: { Homepage: 'https://widgets.example.com' },
+          },
+        },
+      }
+    }
+    notFound(url)
+  })
+}
+
+// A project virtualenv whose python3 has the package installed, and prints its pydoc unless told to fail
+function fakeProject(pydoc) {
+  const projectPath = mkdtempSync(join(tmpdir(), 'package-docs-search-'))
+  const binDir = join(projectPath, '.venv', 'bin')
+  mkdirSync(binDir, { recursive: true })
+  writeFileSync(join(binDir, 'python3'), [
+    '#!/bin/sh',
+    'case "$2" in',
+    '  *find_spec*) echo True ;;',
+    pydoc ? `  *) printf '%s\\n' '${pydoc}' ;;` : '  *) echo "ImportError: broken" >&2; exit 1 ;;',
+    'esac',
+    '',
+  ].join('\n'))
+  chmodSync(join(binDir, 'python3'), 0o755)
+  setAllowedCommands(['python3', 'python'], [binDir])
+  return projectPath
+}
+
+test('searching a python package that isn\'t installed reads its PyPI description', async () => {
+  setAllowedCommands([], [])
+  const requested = stubPyPI()
+
+  const text = await callTool(new PackageDocsServer(), 'search_package_docs', { package: 'widgets', language: 'python', query: 'WIDGET_TIMEOUT', fuzzy: false })
+  assert.deepEqual(requested, ['https://pypi.org/pypi/widgets/json'])
+  // The reStructuredText description is converted and searched by section
+  assert.match(text, /`WIDGET_TIMEOUT`/)
+  assert.doesNotMatch(text, /``WIDGET_TIMEOUT``/)
+  assert.doesNotMatch(text, /MIT/)
+})
+
+test('search falls back to PyPI when pydoc fails for an installed package', { skip: process.platform === 'win32' }, async () => {
+  const projectPath = fakeProject()
+  stubPyPI()
+
+  const text = await callTool(new PackageDocsServer(), 'search_package_docs', { package: 'widgets', language: 'python', query: 'WIDGET_TIMEOUT', projectPath, fuzzy: false })
+  assert.match(text, /WIDGET_TIMEOUT/)
+  assert.doesNotMatch(text, /ImportError/)
+})
+
+test('pydoc is still preferred for an installed package', { skip: process.platform === 'win32' }, async () => {
+  const projectPath = fakeProject('FUNCTIONS\n    widget_timeout(seconds)\n        Set WIDGET_TIMEOUT locally.')
+  const requested = stubPyPI()
+
+  const text = await callTool(new PackageDocsServer(), 'search_package_docs', { package: 'widgets', language: 'python', query: 'WIDGET_TIMEOUT', projectPath, fuzzy: false })
+  assert.match(text, /Set WIDGET_TIMEOUT locally/)
+  assert.deepEqual(requested, [])
+})
+
+test('local python searches don\'t fall back to PyPI', async () => {
+  setAllowedCommands([], [])
+  const requested = stubPyPI()
+
+  await callTool(new PackageDocsServer(), 'search_package_docs', { package: 'widgets', language: 'python', query: 'WIDGET_TIMEOUT', source: 'local' }).catch(() => undefined)
+  assert.deepEqual(requested, [])
+})
@@ -0,0 +1,93 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { chmodSync, mkdirSync, mkdtempSync, writeFileSync } from 'fs'
+import { tmpdir } from 'os'
+import { join } from 'path'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { getAllowedCommands, setAllowedCommands } from '../build/utils/command-runner.js'
+import { callTool } from './helpers.js'
+
+afterEach(() => setAllowedCommands(getAllowedCommands()))
+
+// Output the handlers would otherwise split into sections, with lines they'd drop or move
+const goDoc = [
+  'package widgets // import "example.com/widgets"',
+  '',
+  'Package widgets makes widgets.',
+  '',
+  'func New(name string) *Widget',
+  'type Widget struct{ Name string }',
+  '    Example: widgets.New("a")',
+  '',
+].join('\n')
+const pydoc = [
+  'Help on package widgets:',
+  '',
+  'NAME',
+  '    widgets - Makes widgets.',
+  '',
+  'class Widget(builtins.object)',
+  ' |  Example: Widget("a")',
+  '',
+].join('\n')
+
+function writeExecutable(path, output) {
+  writeFileSync(path, `#!/bin/sh\nprintf '%s' '${output}'\n`)
+  chmodSync(path, 0o755)
+}
+
+test('raw returns go doc\'s output unmodified', { skip: process.platform === 'win32' }, async () => {
+  const projectPath = mkdtempSync(join(tmpdir(), 'package-docs-raw-go-'))
+  writeFileSync(join(projectPath, 'go.mod'), 'module example.com/app\n\nrequire example.com/widgets v1.0.0\n')
+  const bin = mkdtempSync(join(tmpdir(), 'package-docs-go-'))
+  writeExecutable(join(bin, 'go'), goDoc)
+  const path = process.env.PATH
+  process.env.PATH = bin
+  try {
+    const server = new PackageDocsServer()
+    const raw = JSON.parse(await callTool(server, 'describe_go_package', { package: 'example.com/widgets', projectPath, raw: true }))
+    assert.deepEqual(raw, { usage: goDoc })
+
+    const processed = JSON.parse(await callTool(server, 'describe_go_package', { package: 'example.com/widgets', projectPath }))
+    assert.notEqual(processed.usage, goDoc)
+  } finally {
+    process.env.PATH = path
+  }
+})
+
+test('raw returns pydoc\'s output unmodified', { skip: process.platform === 'win32' }, async () => {
+  const projectPath = mkdtempSync(join(tmpdir(), 'package-docs-raw-python-'))
+  const binDir = join(projectPath, '.venv', 'bin')
+  mkdirSync(binDir, { recursive: true })
+  // The same output answers the installed check, so it's printed whatever python3 is asked
+  writeFileSync(join(binDir, 'python3'), `#!/bin/sh\ncase "$2" in *find_spec*) echo True ;; *) printf '%s' '${pydoc}' ;; esac\n`)
+  chmodSync(join(binDir, 'python3'), 0o755)
+  setAllowedCommands(['python3', 'python'], [binDir])
+
+  const server = new PackageDocsServer()
+  const raw = JSON.parse(await callTool(server, 'describe_python_package', { package: 'widgets', projectPath, raw: true }))
+  assert.deepEqual(raw, { usage: pydoc })
+
+  const processed = JSON.parse(await callTool(server, 'describe_python_package', { package: 'widgets', projectPath }))
+  assert.notEqual(processed.usage, pydoc)
+})
+
+test('raw returns the docs.rs markdown without summarising it', async () => {
+  const documentation = '# Crate widgets\n\nMakes widgets.\n\n## Structs\n\n- [Widget](struct.Widget.html)\n'
+  const server = new PackageDocsServer()
+  Object.assign(server['rustDocsHandler'], {
+    getCrateDetails: async () => ({ name: 'widgets', description: 'Makes widgets', versions: [{ version: '1.0.0' }], latestVersion: '1.0.0', downloads: 1 }),
+    getCrateDocumentation: async () => documentation,
+    getSupportedTargets: async () => ['x86_64-unknown-linux-gnu'],
+  })
+
+  const raw = JSON.parse(await callTool(server, 'describe_rust_package', { package: 'widgets', source: 'network', raw: true }))
+  assert.deepEqual(raw, { description: 'Makes widgets', usage: documentation })
+
+  const processed = JSON.parse(await callTool(server, 'describe_rust_package', { package: 'widgets', source: 'network' }))
+  assert.match(processed.usage, /\[dependencies\]\nwidgets = "1\.0\.0"/)
+})
+
+test('raw must be a boolean', async () => {
+  await assert.rejects(callTool(new PackageDocsServer(), 'describe_go_package', { package: 'strings', raw: 'yes' }))
+})
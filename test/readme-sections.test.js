@@ -0,0 +1,340 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { SearchUtils } from '../build/search-utils.js'
+import { detectLanguage, parseLocales } from '../build/locale-keywords.js'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { callTool, notFound, restoreNetwork, silentLogger, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+const searchUtils = new SearchUtils(silentLogger)
+
+const readme = [
+  '# widgets',
+  '',
+  'Widgets for everyone.',
+  '',
+  '## Usage',
+  '',
+  'Call widget() to make one.',
+  '',
+  '## Architecture',
+  '',
+  // Long enough not to be kept as a short section with a simple heading
+  'Widgets are built by a pipeline of stages, each of which reads the widget so far and returns a new one. '.repeat(6).trim(),
+  '',
+  '## Contributing',
+  '',
+  'Open a pull request against main.',
+  '',
+  '## Sponsors',
+  '',
+  'Thanks to Acme for the coffee.',
+].join('\n')
+
+// The headings of the sections a profile keeps
+function headings(content) {
+  return [...content.matchAll(/^#+\s+(.*)$/gm)].map(match => match[1])
+}
+
+test('each relevance profile keeps a different set of README sections', () => {
+  const consumer = headings(searchUtils.extractRelevantContent(readme, 'consumer'))
+  const contributor = headings(searchUtils.extractRelevantContent(readme, 'contributor'))
+  const full = headings(searchUtils.extractRelevantContent(readme, 'full'))
+
+  assert.ok(consumer.includes('Usage'))
+  assert.ok(!consumer.includes('Architecture') && !consumer.includes('Contributing'))
+  assert.ok(contributor.includes('Usage') && contributor.includes('Architecture') && contributor.includes('Contributing'))
+  assert.ok(!contributor.includes('Sponsors'))
+  assert.deepEqual(full, ['widgets', 'Usage', 'Architecture', 'Contributing', 'Sponsors'])
+  assert.deepEqual(headings(searchUtils.extractRelevantContent(readme)), consumer)
+})
+
+test('describe_npm_package applies the requested profile to the README', async () => {
+  stubGet(url => {
+    if (url === 'https://registry.npmjs.org/profiled-widgets') {
+      return { data: { name: 'profiled-widgets', description: 'Widgets', 'dist-tags': { latest: '1.0.0' }, versions: { '1.0.0': {} }, readme } }
+    }
+    notFound(url)
+  })
+  const server = new PackageDocsServer()
+  const describe = profile => callTool(server, 'describe_npm_package', { package: 'profiled-widgets', source: 'network', includeTypes: false, profile })
+
+  assert.doesNotMatch(await describe('consumer'), /pull request against main/)
+  assert.match(await describe('contributor'), /pull request against main/)
+  assert.match(await describe('full'), /coffee/)
+})
+
+const configReadme = [
+  '# lintish',
+  '',
+  'Lints things. Settings are read from `.lintishrc.json` or `lintish.config.js` in the project root.',
+  '',
+  '## Install',
+  '',
+  'npm install lintish',
+  '',
+  '## Configuration',
+  '',
+  'Create a config file:',
+  '',
+  '```json',
+  '{ "rules": { "semi": "error" } }',
+  '```',
+  '',
+  '### Options',
+  '',
+  '| Option | Default |',
+  '| --- | --- |',
+  '| rules | {} |',
+  '',
+  '## License',
+  '',
+  'MIT',
+].join('\n')
+
+test('configuration sections, their examples and config file names are extracted', () => {
+  const { sections, examples, files } = searchUtils.extractConfigDocs(configReadme)
+
+  assert.deepEqual(sections.map(section => section.title), ['Configuration'])
+  assert.match(sections[0].content, /### Options/)
+  assert.match(sections[0].content, /\| rules \| \{\} \|/)
+  assert.deepEqual(examples, ['```json\n{ "rules": { "semi": "error" } }\n```'])
+  assert.deepEqual(files, ['.lintishrc.json', 'lintish.config.js'])
+})
+
+test('get_config_docs returns the config example from the README', async () => {
+  stubGet(url => {
+    if (url === 'https://pypi.org/pypi/lintish/json') {
+      return { data: { info: { name: 'lintish', version: '1.0.0', description: configReadme, description_content_type: 'text/markdown' } } }
+    }
+    notFound(url)
+  })
+  const server = new PackageDocsServer()
+
+  const result = JSON.parse(await callTool(server, 'get_config_docs', { package: 'lintish', language: 'python' }))
+  assert.equal(result.description, 'Configuration files: .lintishrc.json, lintish.config.js')
+  assert.equal(result.example, '```json\n{ "rules": { "semi": "error" } }\n```')
+  assert.doesNotMatch(result.usage, /MIT/)
+})
+
+test('get_config_docs says when a README documents no configuration', async () => {
+  stubGet(url => {
+    if (url === 'https://pypi.org/pypi/unconfigured/json') {
+      return { data: { info: { name: 'unconfigured', version: '1.0.0', description: '# unconfigured\n\n## Usage\n\nImport it.\n', description_content_type: 'text/markdown' } } }
+    }
+    notFound(url)
+  })
+
+  assert.match(await callTool(new PackageDocsServer(), 'get_config_docs', { package: 'unconfigured', language: 'python' }), /No configuration documentation found in the README for unconfigured/)
+})
+
+// Long enough not to be kept as a short section with a simple heading whatever its language
+const spanishProse = 'Cree un cliente con las opciones que necesite y llame a sus métodos para obtener los datos del servicio. '.repeat(6).trim()
+
+const spanishReadme = [
+  '# cliente',
+  '',
+  'Un cliente para el servicio.',
+  '',
+  '## Instalación',
+  '',
+  spanishProse,
+  '',
+  '## Uso',
+  '',
+  spanishProse,
+  '',
+  '## Ejemplo',
+  '',
+  'const cliente = crear()',
+  '',
+  '## Configuración',
+  '',
+  'Defina timeout en milisegundos.',
+  '',
+  '## Historia del proyecto',
+  '',
+  spanishProse,
+].join('\n')
+
+// A SearchUtils recognising the headings of the given PACKAGE_DOCS_LOCALES
+function localisedSearchUtils(locales) {
+  const previous = process.env.PACKAGE_DOCS_LOCALES
+  process.env.PACKAGE_DOCS_LOCALES = locales
+  try {
+    return new SearchUtils(silentLogger)
+  } finally {
+    if (previous === undefined) delete process.env.PACKAGE_DOCS_LOCALES
+    else process.env.PACKAGE_DOCS_LOCALES = previous
+  }
+}
+
+test('PACKAGE_DOCS_LOCALES keeps the locales with keyword sets, ignoring regions', () => {
+  assert.deepEqual(parseLocales('es, pt-BR,xx,ES'), ['es', 'pt'])
+  assert.deepEqual(parseLocales(undefined), [])
+})
+
+test('Spanish sections are kept once the Spanish locale is configured', () => {
+  const english = headings(searchUtils.extractRelevantContent(spanishReadme))
+  const spanish = headings(localisedSearchUtils('es').extractRelevantContent(spanishReadme))
+
+  assert.ok(!english.includes('Instalación') && !english.includes('Uso'))
+  assert.ok(spanish.includes('Instalación') && spanish.includes('Uso'))
+  assert.ok(!spanish.includes('Historia del proyecto'))
+})
+
+test('Spanish headings are categorised like English ones with the Spanish locale', () => {
+  const spanishUtils = localisedSearchUtils('es')
+  const sections = spanishUtils.extractDocSections(spanishReadme)
+  assert.match(sections.usage, /Cree un cliente/)
+  assert.match(sections.examples, /const cliente = crear\(\)/)
+  assert.match(sections.configuration, /timeout en milisegundos/)
+
+  const types = spanishUtils.parseNpmDoc({ readme: spanishReadme }).map(section => section.type)
+  assert.deepEqual(types, ['general', 'installation', 'usage', 'example', 'configuration', 'general'])
+  assert.equal(searchUtils.extractDocSections(spanishReadme).usage, '')
+})
+
+test('the predominant language of a README is detected from its prose', () => {
+  assert.equal(detectLanguage('This is a client for the service, and you can use it to fetch the data that you need.'), 'en')
+  assert.equal(detectLanguage(spanishReadme), 'es')
+  assert.equal(detectLanguage('Ce client est pour le service, et vous pouvez les utiliser avec une configuration qui est simple.'), 'fr')
+  assert.equal(detectLanguage('Der Client ist für den Dienst und die Konfiguration wird mit einer Datei geladen, die nicht groß ist.'), 'de')
+  assert.equal(detectLanguage('このライブラリはサービスのクライアントです。設定ファイルを読み込んで使います。'), 'ja')
+  assert.equal(detectLanguage('这个库是服务的客户端。使用配置文件来设置超时和重试次数以及其他选项。'), 'zh')
+  assert.equal(detectLanguage('이 라이브러리는 서비스의 클라이언트입니다. 설정 파일을 읽어서 사용합니다.'), 'ko')
+  assert.equal(detectLanguage('Эта библиотека является клиентом для сервиса и читает файл настроек.'), 'ru')
+})
+
+test('code, links and too little prose are left out of language detection', () => {
+  assert.equal(detectLanguage('Hola'), undefined)
+  const code = '```js\n' + 'const the = and; const is = to; const of = for;\n'.repeat(10) + '```'
+  assert.equal(detectLanguage(`${code}\n\n${spanishProse}`), 'es')
+})
+
+test('only READMEs not in English get a language note', () => {
+  assert.equal(searchUtils.languageNote(readme), undefined)
+  assert.equal(searchUtils.languageNote(spanishReadme), 'Documentation language: Spanish')
+  assert.equal(searchUtils.languageNote('Hola'), undefined)
+})
+
+test('describe notes the language of a Spanish README', async () => {
+  stubGet(url => {
+    const name = url.match(/^https:\/\/registry\.npmjs\.org\/(cliente|client)$/)?.[1]
+    if (!name) notFound(url)
+    return { data: { name, description: 'Un cliente', 'dist-tags': { latest: '1.0.0' }, versions: { '1.0.0': {} }, readme: name === 'cliente' ? spanishReadme : readme } }
+  })
+  const server = new PackageDocsServer()
+
+  assert.match(await callTool(server, 'describe_npm_package', { package: 'cliente', source: 'network', includeTypes: false }), /Documentation language: Spanish/)
+  assert.doesNotMatch(await callTool(server, 'describe_npm_package', { package: 'client', source: 'network', includeTypes: false }), /Documentation language/)
+})
+
+const nestedReadme = [
+  '# http-lite',
+  '',
+  'A small HTTP client.',
+  '',
+  '## API',
+  '',
+  'Every function returns a promise.',
+  '',
+  '### get(url)',
+  '',
+  'Fetch a URL.',
+  '',
+  '#### Options',
+  '',
+  'Pass timeout in milliseconds.',
+  '',
+  '### post(url, body)',
+  '',
+  'Send a body.',
+  '',
+  '## License',
+  '',
+  'MIT',
+].join('\n')
+
+test('markdown sections form a tree following their heading levels', () => {
+  const shape = nodes => nodes.map(node => ({ title: node.title, level: node.level, children: shape(node.children) }))
+
+  assert.deepEqual(shape(searchUtils.parseMarkdownSectionTree(nestedReadme)), [{
+    title: 'http-lite',
+    level: 1,
+    children: [
+      {
+        title: 'API',
+        level: 2,
+        children: [
+          { title: 'get(url)', level: 3, children: [{ title: 'Options', level: 4, children: [] }] },
+          { title: 'post(url, body)', level: 3, children: [] },
+        ],
+      },
+      { title: 'License', level: 2, children: [] },
+    ],
+  }])
+})
+
+test('a selected section keeps its nested subsections and ends at the next sibling', () => {
+  const { sections, error } = searchUtils.selectDocumentation(searchUtils.parseMarkdownDocSections(nestedReadme), { section: 'API' })
+
+  assert.equal(error, undefined)
+  assert.deepEqual(sections.map(section => section.title), ['API'])
+  assert.equal(sections[0].content, [
+    'Every function returns a promise.',
+    '### get(url)\n\nFetch a URL.',
+    '#### Options\n\nPass timeout in milliseconds.',
+    '### post(url, body)\n\nSend a body.',
+  ].join('\n\n'))
+
+  // A subsection can still be selected on its own
+  const options = searchUtils.selectDocumentation(searchUtils.parseMarkdownDocSections(nestedReadme), { section: 'get(url)' })
+  assert.match(options.sections[0].content, /Pass timeout/)
+  assert.doesNotMatch(options.sections[0].content, /Send a body/)
+})
+
+test('get_package_doc returns a section with its subsections at their own levels', async () => {
+  stubGet(url => {
+    if (url === 'https://pypi.org/pypi/http-lite/json') {
+      return { data: { info: { name: 'http-lite', version: '1.0.0', description: nestedReadme, description_content_type: 'text/markdown' } } }
+    }
+    notFound(url)
+  })
+
+  const text = await callTool(new PackageDocsServer(), 'get_package_doc', { package: 'http-lite', language: 'python', section: 'API' })
+  assert.match(text, /^## API$/m)
+  assert.match(text, /^### post\(url, body\)$/m)
+  assert.match(text, /^#### Options$/m)
+  assert.doesNotMatch(text, /MIT/)
+})
+
+test('get_package_doc lists the README\'s content images but not its badges', async () => {
+  const readme = [
+    '# diagrams',
+    '',
+    '![npm](https://img.shields.io/pypi/v/diagrams.svg)',
+    '',
+    'Draws diagrams.',
+    '',
+    '## Design',
+    '',
+    '![Architecture](https://example.com/architecture.png)',
+    '<img src="https://example.com/sequence.svg">',
+  ].join('\n')
+  stubGet(url => {
+    if (url === 'https://pypi.org/pypi/diagrams/json') {
+      return { data: { info: { name: 'diagrams', version: '1.0.0', description: readme, description_content_type: 'text/markdown' } } }
+    }
+    notFound(url)
+  })
+  const server = new PackageDocsServer()
+
+  const text = await callTool(server, 'get_package_doc', { package: 'diagrams', language: 'python' })
+  assert.match(text, /## Images\n\n- Architecture: https:\/\/example\.com\/architecture\.png\n- Image: https:\/\/example\.com\/sequence\.svg/)
+  assert.doesNotMatch(text.slice(text.indexOf('## Images')), /shields\.io/)
+
+  // Only the whole document lists its images
+  assert.doesNotMatch(await callTool(server, 'get_package_doc', { package: 'diagrams', language: 'python', section: 'Design' }), /## Images/)
+})
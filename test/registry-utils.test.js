@@ -0,0 +1,123 @@
+import { afterEach, test } from 'node:test';
+import assert from 'node:assert/strict';
+import { mkdtempSync, writeFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { RegistryUtils, expandEnvReferences, findRegistryToken, registryKey } from '../build/registry-utils.js';
+import { silentLogger } from './helpers.js';
+
+const home = process.env.HOME;
+afterEach(() => {
+  process.env.HOME = home;
+});
+
+// Load the registry configuration of a project whose only .npmrc has the given lines
+function loadProject(lines) {
+  process.env.HOME = mkdtempSync(join(tmpdir(), 'npmrc-home-'));
+  const project = mkdtempSync(join(tmpdir(), 'npmrc-project-'));
+  writeFileSync(join(project, '.npmrc'), lines.join('\n'));
+  return { registryUtils: new RegistryUtils(silentLogger), project };
+}
+
+test('registry URLs are keyed by host and path, whatever their scheme, case and trailing slashes', () => {
+  assert.equal(registryKey('https://Registry.Example.com'), '//registry.example.com/');
+  assert.equal(registryKey('http://registry.example.com/'), '//registry.example.com/');
+  assert.equal(registryKey('//registry.example.com/npm/repo//'), '//registry.example.com/npm/repo/');
+  assert.equal(registryKey('https://registry.example.com:8443/npm'), '//registry.example.com:8443/npm/');
+});
+
+test('a token is never used for a host that only starts with its registry host', () => {
+  const tokens = new Map([[registryKey('//registry.example.com/'), 'secret']]);
+  assert.equal(findRegistryToken('https://registry.example.com', tokens), 'secret');
+  assert.equal(findRegistryToken('https://registry.example.com.evil.net', tokens), undefined);
+  assert.equal(findRegistryToken('https://evil.net/registry.example.com/', tokens), undefined);
+});
+
+test('tokens match registries on whole path segments, the longest prefix winning', () => {
+  const tokens = new Map([
+    [registryKey('//npm.example.com/'), 'host'],
+    [registryKey('//npm.example.com/repo/'), 'repo'],
+  ]);
+  assert.equal(findRegistryToken('https://npm.example.com/repo', tokens), 'repo');
+  assert.equal(findRegistryToken('https://npm.example.com/repo/sub/', tokens), 'repo');
+  assert.equal(findRegistryToken('https://npm.example.com/repository', tokens), 'host');
+  assert.equal(findRegistryToken('https://npm.example.com/other', tokens), 'host');
+});
+
+test('tokens are matched to registries whichever line comes first', () => {
+  const tokenFirst = loadProject([
+    '//npm.example.com/:_authToken=example-token',
+    '@acme:registry=https://npm.example.com/',
+  ]);
+  const registryFirst = loadProject([
+    '@acme:registry=https://npm.example.com/',
+    '//npm.example.com/:_authToken=example-token',
+  ]);
+
+  for (const { registryUtils, project } of [tokenFirst, registryFirst]) {
+    assert.deepEqual(registryUtils.getRegistryConfigForPackage('@acme/widgets', project), {
+      registry: 'https://npm.example.com',
+      token: 'example-token',
+    });
+  }
+});
+
+test('a token for the default registry wins over a bare _authToken, and neither leaks to a lookalike host', () => {
+  const { registryUtils, project } = loadProject([
+    '_authToken=bare-token',
+    'registry=https://registry.example.com/',
+    '//registry.example.com/:_authToken=registry-token',
+    '@evil:registry=https://registry.example.com.evil.net/',
+  ]);
+
+  assert.deepEqual(registryUtils.getRegistryConfigForPackage('left-pad', project), {
+    registry: 'https://registry.example.com',
+    token: 'registry-token',
+  });
+  assert.deepEqual(registryUtils.getRegistryConfigForPackage('@evil/widgets', project), {
+    registry: 'https://registry.example.com.evil.net',
+    token: undefined,
+  });
+});
+
+test('a bare _authToken is used for the default registry when it has no token of its own', () => {
+  const { registryUtils, project } = loadProject([
+    'registry=https://registry.example.com/',
+    '_authToken=bare-token',
+  ]);
+
+  assert.equal(registryUtils.getRegistryConfigForPackage('left-pad', project).token, 'bare-token');
+});
+
+test('environment variable references in .npmrc values are expanded as npm expands them', () => {
+  const env = { NPM_TOKEN: 'secret', EMPTY: '' };
+  assert.equal(expandEnvReferences('${NPM_TOKEN}', env), 'secret');
+  assert.equal(expandEnvReferences('Bearer-${NPM_TOKEN}-suffix', env), 'Bearer-secret-suffix');
+  assert.equal(expandEnvReferences('${MISSING}', env), '');
+  assert.equal(expandEnvReferences('${MISSING?}', env), '');
+  assert.equal(expandEnvReferences('${MISSING:-fallback}', env), 'fallback');
+  assert.equal(expandEnvReferences('${EMPTY:-fallback}', env), 'fallback');
+  assert.equal(expandEnvReferences('${NPM_TOKEN:-fallback}', env), 'secret');
+  assert.equal(expandEnvReferences('\\${NPM_TOKEN}', env), '${NPM_TOKEN}');
+  assert.equal(expandEnvReferences('\\\\${NPM_TOKEN}', env), '\\secret');
+  assert.equal(expandEnvReferences('\\\\\\${NPM_TOKEN}', env), '\\${NPM_TOKEN}');
+});
+
+test('a token referencing an environment variable is sent as the variable\'s value', () => {
+  const token = process.env.PACKAGE_DOCS_TEST_NPM_TOKEN;
+  process.env.PACKAGE_DOCS_TEST_NPM_TOKEN = 'resolved-token';
+  try {
+    const { registryUtils, project } = loadProject([
+      '@acme:registry=https://npm.example.com/',
+      '//npm.example.com/:_authToken=${PACKAGE_DOCS_TEST_NPM_TOKEN}',
+      '//unset.example.com/:_authToken=${PACKAGE_DOCS_TEST_UNSET_TOKEN}',
+      '@unset:registry=https://unset.example.com/',
+    ]);
+
+    assert.equal(registryUtils.getRegistryConfigForPackage('@acme/widgets', project).token, 'resolved-token');
+    assert.equal(registryUtils.getRegistryConfigForPackage('@unset/widgets', project).token, undefined);
+  } finally {
+    if (token === undefined) delete process.env.PACKAGE_DOCS_TEST_NPM_TOKEN;
+    else process.env.PACKAGE_DOCS_TEST_NPM_TOKEN = token;
+  }
+});
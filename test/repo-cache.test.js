@@ -0,0 +1,128 @@
+import { afterEach, beforeEach, test } from 'node:test';
+import assert from 'node:assert/strict';
+import { cachedRepoResource, clearRepoCache, getRepoCacheEntries, repoCacheKey } from '../build/utils/repo-cache.js';
+import { createRepoClient } from '../build/utils/repo-client.js';
+import { PackageDocsServer } from '../build/package-docs-server.js';
+import { callTool, notFound, restoreNetwork, silentLogger as logger, stubGet } from './helpers.js';
+
+beforeEach(clearRepoCache);
+afterEach(restoreNetwork);
+
+// A loader counting how many times each resource is fetched
+function counter() {
+  const loads = {};
+  return {
+    loads,
+    load: key => () => {
+      loads[key] = (loads[key] ?? 0) + 1;
+      return Promise.resolve(`content of ${key}`);
+    },
+  };
+}
+
+test('a resource is fetched once and reused', async () => {
+  const { loads, load } = counter();
+  const key = repoCacheKey('github.com/Owner/Repo', 'main', 'file', 'README.md');
+
+  assert.equal(await cachedRepoResource(key, load('readme')), 'content of readme');
+  assert.equal(await cachedRepoResource(repoCacheKey('github.com/owner/repo', 'main', 'file', 'README.md'), load('readme')), 'content of readme');
+  assert.deepEqual(loads, { readme: 1 });
+
+  // Another ref is another resource
+  await cachedRepoResource(repoCacheKey('github.com/owner/repo', 'v1.0.0', 'file', 'README.md'), load('tagged'));
+  assert.deepEqual(loads, { readme: 1, tagged: 1 });
+});
+
+test('the least recently used resources are dropped beyond the limit', async () => {
+  const { loads, load } = counter();
+  const get = key => cachedRepoResource(key, load(key), 600, 2);
+
+  await get('a');
+  await get('b');
+  await get('a'); // a is now the most recently used
+  await get('c'); // Drops b
+  await get('a');
+  assert.deepEqual(loads, { a: 1, b: 1, c: 1 });
+
+  await get('b');
+  assert.deepEqual(loads, { a: 1, b: 2, c: 1 });
+});
+
+test('expired and failed resources are fetched again', async () => {
+  let loads = 0;
+  const failing = () => {
+    loads++;
+    return Promise.reject(new Error('unavailable'));
+  };
+  await assert.rejects(cachedRepoResource('failing', failing));
+  await assert.rejects(cachedRepoResource('failing', failing));
+  assert.equal(loads, 2);
+
+  const { loads: counts, load } = counter();
+  await cachedRepoResource('uncached', load('uncached'), 0);
+  await cachedRepoResource('uncached', load('uncached'), 0);
+  assert.deepEqual(counts, { uncached: 2 });
+});
+
+test('PACKAGE_DOCS_REPO_CACHE_ENTRIES sets the limit', () => {
+  assert.equal(getRepoCacheEntries('20'), 20);
+  assert.equal(getRepoCacheEntries('0'), 500);
+  assert.equal(getRepoCacheEntries(undefined), 500);
+});
+
+test('a second tool call for the same repository reuses its cached README and manifest', async () => {
+  const urls = stubGet(url => {
+    if (url === 'https://raw.githubusercontent.com/acme/cached-widgets/main/README.md') {
+      return { data: '# Cached widgets\n\n## Usage\n\nSet retry to the number of attempts.\n' };
+    }
+    if (url === 'https://raw.githubusercontent.com/acme/cached-widgets/main/Package.swift') {
+      return { data: 'let package = Package(name: "CachedWidgets")\n' };
+    }
+    notFound(url);
+  });
+  const server = new PackageDocsServer();
+  const swiftPackage = { package: 'https://github.com/acme/cached-widgets', source: 'network' };
+
+  assert.match(await callTool(server, 'describe_swift_package', swiftPackage), /number of attempts/);
+  assert.equal(urls.length, 2);
+
+  urls.length = 0;
+  assert.match(await callTool(server, 'search_package_docs', { ...swiftPackage, language: 'swift', query: 'retry' }), /number of attempts/);
+  assert.match(await callTool(server, 'describe_swift_package', swiftPackage), /number of attempts/);
+  assert.deepEqual(urls, []);
+});
+
+test('a tool call for another ref of a repository misses the cache', async () => {
+  const urls = stubGet(url => {
+    const file = url.match(/^https:\/\/raw\.githubusercontent\.com\/acme\/cached-widgets\/(v[\d.]+)\/(README\.md|package\.json)$/);
+    if (!file) notFound(url);
+    return file[2] === 'README.md'
+      ? { data: `# Cached widgets ${file[1]}\n` }
+      : { data: JSON.stringify({ name: 'cached-widgets', description: `Widgets ${file[1]}` }) };
+  });
+  const server = new PackageDocsServer();
+
+  assert.match(await callTool(server, 'describe_npm_package', { package: 'github:acme/cached-widgets#v1.0.0' }), /Widgets v1\.0\.0/);
+  assert.match(await callTool(server, 'describe_npm_package', { package: 'github:acme/cached-widgets#v1.0.0' }), /Widgets v1\.0\.0/);
+  assert.equal(urls.length, 2);
+
+  urls.length = 0;
+  assert.match(await callTool(server, 'describe_npm_package', { package: 'github:acme/cached-widgets#v2.0.0' }), /Widgets v2\.0\.0/);
+  assert.deepEqual(urls.sort(), [
+    'https://raw.githubusercontent.com/acme/cached-widgets/v2.0.0/README.md',
+    'https://raw.githubusercontent.com/acme/cached-widgets/v2.0.0/package.json',
+  ]);
+});
+
+test('tags fetched by one client are reused by the next client for the repository', async () => {
+  const urls = stubGet(url => {
+    if (url === 'https://api.github.com/repos/acme/cached-widgets/tags') {
+      return { data: [{ name: 'v2.0.0' }, { name: 'v1.0.0' }] };
+    }
+    notFound(url);
+  });
+
+  assert.deepEqual(await createRepoClient('https://github.com/acme/cached-widgets', logger).getTags(), ['v2.0.0', 'v1.0.0']);
+  assert.deepEqual(await createRepoClient('https://github.com/Acme/Cached-Widgets', logger).getTags(), ['v2.0.0', 'v1.0.0']);
+  assert.equal(urls.length, 1);
+});
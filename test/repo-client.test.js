@@ -0,0 +1,307 @@
+import { afterEach, test } from 'node:test';
+import assert from 'node:assert/strict';
+import { BadCredentialsError, createRepoClient, GenericGitRepoClient, getGitHubRateLimit, GitHubRepoClient, RateLimitError } from '../build/utils/repo-client.js';
+import { parseRepositoryUrl } from '../build/utils/github-client.js';
+import { clearRepoCache } from '../build/utils/repo-cache.js';
+import { PhpDocsHandler } from '../build/php-docs-integration.js';
+import { PackageDocsServer } from '../build/package-docs-server.js';
+import { notFound, restoreNetwork, silentLogger as logger, stubGet } from './helpers.js';
+
+const gitHubTokens = { GITHUB_TOKEN: process.env.GITHUB_TOKEN, GH_TOKEN: process.env.GH_TOKEN };
+
+afterEach(() => {
+  restoreNetwork();
+  clearRepoCache();
+  for (const [name, value] of Object.entries(gitHubTokens)) {
+    if (value === undefined) delete process.env[name];
+    else process.env[name] = value;
+  }
+});
+
+function setGitHubToken(token) {
+  delete process.env.GH_TOKEN;
+  if (token === undefined) delete process.env.GITHUB_TOKEN;
+  else process.env.GITHUB_TOKEN = token;
+}
+
+function secondsFromNow(seconds) {
+  return String(Math.floor(Date.now() / 1000) + seconds);
+}
+
+function gitHubClient(url) {
+  return new GitHubRepoClient(parseRepositoryUrl(url), logger);
+}
+
+test('hosts without a known API only get a client when plain HTTP hosts are opted into', () => {
+  assert.equal(createRepoClient('https://git.example.org/owner/repo', logger), undefined);
+  assert.ok(createRepoClient('https://git.example.org/owner/repo', logger, { genericHosts: true }) instanceof GenericGitRepoClient);
+  assert.ok(createRepoClient('https://github.com/owner/repo', logger));
+  assert.ok(createRepoClient('https://gitlab.example.org/owner/repo', logger));
+});
+
+test('a plain HTTP host is probed for its raw file layout once', async () => {
+  const urls = stubGet(url => {
+    if (!url.includes('/plain/') || url.includes('missing.md')) notFound(url);
+    return { data: `content of ${url}`, headers: { 'content-type': 'text/plain' } };
+  });
+  const client = createRepoClient('https://cgit.example.net/owner/repo', logger, { genericHosts: true });
+
+  const readme = await client.getFile('README.md', 'main');
+  assert.match(readme.content, /README\.md/);
+  assert.equal(urls.length, 4);
+
+  urls.length = 0;
+  await client.getFile('Package.swift', 'main');
+  assert.deepEqual(urls, ['https://cgit.example.net/owner/repo/plain/Package.swift?h=main']);
+
+  urls.length = 0;
+  assert.equal(await client.getFile('missing.md', 'main'), undefined);
+  assert.equal(urls.length, 1);
+});
+
+test('PHP packages read their README from GitLab as well as GitHub', async () => {
+  stubGet((url, config) => {
+    if (url === 'https://packagist.org/packages/acme/gitlab-readme.json') {
+      return {
+        data: {
+          package: {
+            name: 'acme/gitlab-readme',
+            repository: 'https://gitlab.com/acme/gitlab-readme',
+            versions: { '1.0.0': { name: 'acme/gitlab-readme', version: '1.0.0' } },
+          },
+        },
+      };
+    }
+    if (url === 'https://gitlab.com/api/v4/projects/acme%2Fgitlab-readme/repository/files/README.md/raw' && config.params.ref === 'main') {
+      return { data: '# Gitlab readme\n\n## Usage\n\nCall acme()\n' };
+    }
+    notFound(url);
+  });
+
+  const result = await new PhpDocsHandler(logger).describePhpPackage({ package: 'acme/gitlab-readme' });
+  assert.match(result.usage, /Call acme\(\)/);
+});
+
+test('changelogs and migration guides are read through the repository client', async () => {
+  const urls = stubGet(url => {
+    if (url === 'https://raw.githubusercontent.com/acme/changelog-client/master/CHANGES.md') {
+      return { data: '## 2.0.0\n\n- Breaking: renamed run\n' };
+    }
+    if (url === 'https://api.bitbucket.org/2.0/repositories/acme/guide-client/src/main/UPGRADING.md') {
+      return { data: '# Upgrading\n\nRename run to start.\n' };
+    }
+    notFound(url);
+  });
+  const server = new PackageDocsServer();
+
+  const { changelog, source } = await server['findChangelog']('npm', 'changelog-client', 'https://github.com/acme/changelog-client');
+  assert.equal(source, 'CHANGES.md');
+  assert.match(changelog, /renamed run/);
+  assert.ok(urls.includes('https://raw.githubusercontent.com/acme/changelog-client/main/CHANGELOG.md'));
+
+  const guide = await server['getMigrationGuide']({ package: 'https://bitbucket.org/acme/guide-client', language: 'swift' });
+  assert.match(guide.usage, /Rename run to start/);
+});
+
+test('GitHub getReadme falls back from main to master and through the README names', async () => {
+  const urls = stubGet(url => {
+    if (url === 'https://raw.githubusercontent.com/acme/widgets/master/readme.md') {
+      return { data: '# Widgets\n' };
+    }
+    notFound(url);
+  });
+
+  assert.equal(await gitHubClient('https://github.com/acme/widgets').getReadme(), '# Widgets\n');
+  assert.deepEqual(urls, [
+    'https://raw.githubusercontent.com/acme/widgets/main/README.md',
+    'https://raw.githubusercontent.com/acme/widgets/master/README.md',
+    'https://raw.githubusercontent.com/acme/widgets/main/readme.md',
+    'https://raw.githubusercontent.com/acme/widgets/master/readme.md',
+  ]);
+});
+
+test('GitHub getFile reads only the given ref and returns the path that exists', async () => {
+  const urls = stubGet(url => {
+    if (url === 'https://raw.githubusercontent.com/acme/widgets/v2.0.0/CHANGES.md') {
+      return { data: '## 2.0.0\n' };
+    }
+    notFound(url);
+  });
+  const client = gitHubClient('https://github.com/acme/widgets');
+
+  assert.deepEqual(await client.getFile(['CHANGELOG.md', '/CHANGES.md'], 'v2.0.0'), { path: '/CHANGES.md', content: '## 2.0.0\n' });
+  assert.deepEqual(urls, [
+    'https://raw.githubusercontent.com/acme/widgets/v2.0.0/CHANGELOG.md',
+    'https://raw.githubusercontent.com/acme/widgets/v2.0.0/CHANGES.md',
+  ]);
+  assert.equal(await client.getFile('MISSING.md', 'v2.0.0'), undefined);
+});
+
+test('GitHub listDir lists a directory through the contents API', async () => {
+  const requests = [];
+  stubGet((url, config) => {
+    requests.push({ url, ref: config.params.ref });
+    if (url === 'https://api.github.com/repos/acme/widgets/contents/examples' && config.params.ref === 'master') {
+      return {
+        data: [
+          { name: 'basic.js', path: 'examples/basic.js', type: 'file' },
+          { name: 'advanced', path: 'examples/advanced', type: 'dir' },
+          { name: 'link', path: 'examples/link', type: 'symlink' },
+        ],
+      };
+    }
+    notFound(url);
+  });
+
+  assert.deepEqual(await gitHubClient('https://github.com/acme/widgets').listDir('/examples/'), [
+    { name: 'basic.js', path: 'examples/basic.js', type: 'file' },
+    { name: 'advanced', path: 'examples/advanced', type: 'dir' },
+    { name: 'link', path: 'examples/link', type: 'file' },
+  ]);
+  assert.deepEqual(requests.map(request => request.ref), ['main', 'master']);
+});
+
+test('GitHub getTags returns tag names in the order the API lists them', async () => {
+  stubGet((url, config) => {
+    if (url === 'https://api.github.com/repos/acme/widgets/tags') {
+      assert.equal(config.params.per_page, 100);
+      return { data: [{ name: 'v2.0.0' }, { name: 'v1.1.0' }, { name: 'v1.0.0' }] };
+    }
+    notFound(url);
+  });
+
+  assert.deepEqual(await gitHubClient('https://github.com/acme/widgets').getTags(), ['v2.0.0', 'v1.1.0', 'v1.0.0']);
+});
+
+test('GitHub getMetadata maps the repository API fields', async () => {
+  stubGet(url => {
+    if (url === 'https://api.github.com/repos/acme/widgets') {
+      return {
+        data: {
+          description: 'Widgets for everyone',
+          default_branch: 'trunk',
+          homepage: '',
+          license: { spdx_id: 'MIT' },
+          stargazers_count: 42,
+          topics: ['widgets'],
+          archived: false,
+        },
+      };
+    }
+    notFound(url);
+  });
+
+  assert.deepEqual(await gitHubClient('https://github.com/acme/widgets').getMetadata(), {
+    description: 'Widgets for everyone',
+    defaultBranch: 'trunk',
+    homepage: undefined,
+    license: 'MIT',
+    stars: 42,
+    topics: ['widgets'],
+    archived: false,
+  });
+});
+
+test('GitHub tags and metadata fall back to empty results when the API fails', async () => {
+  stubGet(url => notFound(url));
+  const client = gitHubClient('https://github.com/acme/widgets');
+
+  assert.deepEqual(await client.getTags(), []);
+  assert.equal(await client.getMetadata(), undefined);
+});
+
+test('GitHub API requests slow down when few remain before the rate limit resets', async () => {
+  setGitHubToken('low-quota-token');
+  stubGet(() => ({ data: [], headers: { 'x-ratelimit-remaining': '1', 'x-ratelimit-reset': secondsFromNow(2) } }));
+
+  await gitHubClient('https://github.com/acme/first').getTags();
+  assert.equal(getGitHubRateLimit().remaining, 1);
+
+  const start = Date.now();
+  await gitHubClient('https://github.com/acme/second').getTags();
+  // Up to two seconds remain, split between the one remaining request and the reset
+  assert.ok(Date.now() - start >= 400, `waited ${Date.now() - start}ms`);
+});
+
+test('a 403 whose body explains a rate limit becomes a RateLimitError', async () => {
+  setGitHubToken(undefined);
+  stubGet(url => {
+    throw Object.assign(new Error(`Request failed with status code 403: ${url}`), {
+      isAxiosError: true,
+      response: { status: 403, headers: {}, data: { message: 'API rate limit exceeded for 1.2.3.4.' } },
+    });
+  });
+
+  await assert.rejects(gitHubClient('https://github.com/acme/limited').getTags(), error => {
+    assert.ok(error instanceof RateLimitError);
+    assert.match(error.message, /^GitHub rate limit nearly exhausted, resets at .*Set GITHUB_TOKEN/);
+    assert.doesNotMatch(error.message, /403/);
+    return true;
+  });
+});
+
+test('Swift packages send GITHUB_TOKEN on their README and Package.swift requests', async () => {
+  setGitHubToken('swift-token');
+  const authorization = {};
+  stubGet((url, config) => {
+    authorization[url] = config.headers?.Authorization;
+    if (url === 'https://raw.githubusercontent.com/acme/swift-widgets/main/README.md') {
+      return { data: '# Widgets\n\n## Usage\n\nimport Widgets\n' };
+    }
+    if (url === 'https://raw.githubusercontent.com/acme/swift-widgets/main/Package.swift') {
+      return { data: '// swift-tools-version:5.9\nlet package = Package(name: "Widgets")\n' };
+    }
+    notFound(url);
+  });
+
+  const result = await new PackageDocsServer()['describeSwiftPackage']({ package: 'https://github.com/acme/swift-widgets', source: 'network' });
+  assert.match(result.usage, /import Widgets/);
+  assert.deepEqual(authorization, {
+    'https://raw.githubusercontent.com/acme/swift-widgets/main/README.md': 'Bearer swift-token',
+    'https://raw.githubusercontent.com/acme/swift-widgets/main/Package.swift': 'Bearer swift-token',
+  });
+});
+
+test('repository info requests fall back to GH_TOKEN', async () => {
+  setGitHubToken(undefined);
+  process.env.GH_TOKEN = 'gh-cli-token';
+  let authorization;
+  stubGet((url, config) => {
+    authorization = config.headers?.Authorization;
+    return { data: { description: 'Widgets in Swift', default_branch: 'main' } };
+  });
+
+  const metadata = await gitHubClient('https://github.com/acme/swift-widgets').getMetadata();
+  assert.equal(metadata.description, 'Widgets in Swift');
+  assert.equal(authorization, 'Bearer gh-cli-token');
+});
+
+test('a token GitHub rejects fails with a clear message rather than empty results', async () => {
+  setGitHubToken('revoked-token');
+  stubGet(url => {
+    throw Object.assign(new Error(`Request failed with status code 401: ${url}`), {
+      isAxiosError: true,
+      response: { status: 401, headers: {}, data: { message: 'Bad credentials' } },
+    });
+  });
+  const client = gitHubClient('https://github.com/acme/swift-widgets');
+
+  await assert.rejects(client.getMetadata(), BadCredentialsError);
+  await assert.rejects(client.getTags(), /^BadCredentialsError: GitHub rejected the token in GITHUB_TOKEN \(401 Bad credentials\)$/);
+  await assert.rejects(client.listDir(), BadCredentialsError);
+});
+
+// Leaves the anonymous quota exhausted, so stays the last anonymous GitHub API test
+test('an exhausted rate limit fails without a request, but only for the credentials that used it up', async () => {
+  setGitHubToken(undefined);
+  const urls = stubGet(() => ({ data: [], headers: { 'x-ratelimit-remaining': '0', 'x-ratelimit-reset': secondsFromNow(3600) } }));
+
+  await gitHubClient('https://github.com/acme/first').getTags();
+  urls.length = 0;
+  await assert.rejects(gitHubClient('https://github.com/acme/second').getTags(), RateLimitError);
+  assert.deepEqual(urls, []);
+
+  setGitHubToken('fresh-token');
+  await gitHubClient('https://github.com/acme/second').getTags();
+  assert.deepEqual(urls, ['https://api.github.com/repos/acme/second/tags']);
+});
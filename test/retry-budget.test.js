@@ -0,0 +1,62 @@
+import { test } from 'node:test';
+import assert from 'node:assert/strict';
+import axios, { AxiosError } from 'axios';
+import { installRetryInterceptor, withRetryBudget } from '../build/utils/retry-budget.js';
+import { silentLogger } from './helpers.js';
+
+// An axios instance whose adapter fails every request with a 503, recording the URLs requested
+function failingClient() {
+  const urls = [];
+  const client = axios.create({
+    adapter: async config => {
+      urls.push(config.url);
+      const response = { status: 503, statusText: 'Service Unavailable', headers: {}, config, data: '' };
+      throw new AxiosError('Request failed with status code 503', 'ERR_BAD_RESPONSE', config, {}, response);
+    },
+  });
+  installRetryInterceptor(client, silentLogger);
+  return { client, urls };
+}
+
+test('a tool call stops retrying once its budget is spent', async () => {
+  const { client, urls } = failingClient();
+
+  await withRetryBudget(async () => {
+    await assert.rejects(client.get('https://registry.example.com/first'), error => error.response.status === 503);
+    await assert.rejects(client.get('https://registry.example.com/second'), error => error.response.status === 503);
+  }, 2);
+
+  // The first request uses both retries, leaving the second none
+  assert.deepEqual(urls, [
+    'https://registry.example.com/first',
+    'https://registry.example.com/first',
+    'https://registry.example.com/first',
+    'https://registry.example.com/second',
+  ]);
+});
+
+test('concurrent tool calls keep separate budgets', async () => {
+  const { client, urls } = failingClient();
+
+  await Promise.all(['a', 'b'].map(name => withRetryBudget(
+    () => assert.rejects(client.get(`https://registry.example.com/${name}`)),
+    1
+  )));
+
+  assert.equal(urls.filter(url => url.endsWith('/a')).length, 2);
+  assert.equal(urls.filter(url => url.endsWith('/b')).length, 2);
+});
+
+test('client errors are not retried', async () => {
+  const urls = [];
+  const client = axios.create({
+    adapter: async config => {
+      urls.push(config.url);
+      throw new AxiosError('Request failed with status code 404', 'ERR_BAD_REQUEST', config, {}, { status: 404, statusText: 'Not Found', headers: {}, config, data: '' });
+    },
+  });
+  installRetryInterceptor(client, silentLogger);
+
+  await withRetryBudget(() => assert.rejects(client.get('https://registry.example.com/missing')), 4);
+  assert.equal(urls.length, 1);
+});
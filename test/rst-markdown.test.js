@@ -0,0 +1,116 @@
+import { afterEach, test } from 'node:test';
+import assert from 'node:assert/strict';
+import { isRestructuredText, rstToMarkdown } from '../build/utils/rst-markdown.js';
+import { PackageDocsServer } from '../build/package-docs-server.js';
+import { callTool, notFound, restoreNetwork, stubGet } from './helpers.js';
+
+afterEach(restoreNetwork);
+
+const rst = [
+  '========',
+  'Requests',
+  '========',
+  '',
+  '.. image:: https://img.shields.io/pypi/v/requests.svg',
+  '    :target: https://pypi.org/project/requests/',
+  '',
+  'Requests is an **elegant** HTTP library. See the `documentation <https://requests.readthedocs.io>`_.',
+  '',
+  'Installation',
+  '------------',
+  '',
+  'Install it with ``pip``::',
+  '',
+  '    $ python -m pip install requests',
+  '',
+  'Usage',
+  '-----',
+  '',
+  '.. code-block:: python',
+  '    :linenos:',
+  '',
+  '    import requests',
+  '    r = requests.get(url)',
+  '',
+  '.. note:: Sessions reuse connections.',
+  '   Use one per host.',
+  '',
+  '.. |pypi| image:: https://img.shields.io/pypi/v/requests.svg',
+  '.. This is a comment',
+  '   spanning two lines.',
+  '',
+  'Call :func:`requests.get` or read `Advanced usage`_.',
+  '',
+  '>>> requests.codes.ok',
+  '200',
+  '',
+  'Details',
+  '~~~~~~~',
+  '',
+  'More text.',
+].join('\n');
+
+test('reStructuredText is converted to markdown headings, code, notes and links', () => {
+  assert.equal(rstToMarkdown(rst), [
+    '# Requests',
+    '',
+    '![](https://img.shields.io/pypi/v/requests.svg)',
+    '',
+    'Requests is an **elegant** HTTP library. See the [documentation](https://requests.readthedocs.io).',
+    '',
+    '## Installation',
+    '',
+    'Install it with `pip`:',
+    '',
+    '```',
+    '$ python -m pip install requests',
+    '```',
+    '',
+    '## Usage',
+    '',
+    '```python',
+    'import requests',
+    'r = requests.get(url)',
+    '```',
+    '',
+    '> **Note:** Sessions reuse connections.',
+    '> Use one per host.',
+    '',
+    'Call `requests.get` or read Advanced usage.',
+    '',
+    '```python',
+    '>>> requests.codes.ok',
+    '200',
+    '```',
+    '',
+    '### Details',
+    '',
+    'More text.',
+  ].join('\n'));
+});
+
+test('descriptions without a content type are only taken as rST when they look like it', () => {
+  assert.equal(isRestructuredText('text/x-rst; charset=UTF-8', 'plain'), true);
+  assert.equal(isRestructuredText('text/markdown', 'Title\n=====\n'), false);
+  assert.equal(isRestructuredText(undefined, 'Title\n=====\n\nText.'), true);
+  assert.equal(isRestructuredText(null, 'Just a sentence.'), false);
+  // A markdown setext heading alongside ATX headings or fences is markdown
+  assert.equal(isRestructuredText(undefined, 'Title\n=====\n\n## Usage\n'), false);
+  assert.equal(isRestructuredText(undefined, 'Title\n=====\n\n```\ncode\n```'), false);
+  // An underline shorter than its title isn't a title
+  assert.equal(isRestructuredText(undefined, 'A long title\n===\n'), false);
+});
+
+test('get_package_doc renders an rST PyPI description as markdown', async () => {
+  stubGet(url => {
+    if (url === 'https://pypi.org/pypi/rst-requests/json') {
+      return { data: { info: { name: 'rst-requests', version: '2.31.0', summary: 'HTTP for humans', description: rst, description_content_type: 'text/x-rst' } } };
+    }
+    notFound(url);
+  });
+
+  const text = await callTool(new PackageDocsServer(), 'get_package_doc', { package: 'rst-requests', language: 'python', source: 'network' });
+  assert.match(text, /## Installation/);
+  assert.match(text, /```python\nimport requests/);
+  assert.doesNotMatch(text, /code-block::|------------/);
+});
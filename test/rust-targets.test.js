@@ -0,0 +1,72 @@
+import { afterEach, test } from 'node:test';
+import assert from 'node:assert/strict';
+import { PackageDocsServer } from '../build/package-docs-server.js';
+import { RustDocsHandler } from '../build/rust-docs-integration.js';
+import { callTool, restoreNetwork, silentLogger, stubFetch } from './helpers.js';
+
+afterEach(restoreNetwork);
+
+test('documentation for a target is read through docs.rs target-redirect', async () => {
+  const urls = stubFetch(() => new Response('<main><p>Only on Windows.</p></main>', { headers: { 'content-type': 'text/html' } }));
+
+  const documentation = await new RustDocsHandler(silentLogger).getCrateDocumentation('tokio-util', '0.7.10', 'x86_64-pc-windows-msvc');
+  assert.deepEqual(urls, ['https://docs.rs/crate/tokio-util/0.7.10/target-redirect/x86_64-pc-windows-msvc/tokio_util/']);
+  assert.match(documentation, /Only on Windows\./);
+});
+
+// A server whose docs.rs lookups are answered by the given feature flags, recording the targets asked for
+function rustServer(getFeatureFlags) {
+  const server = new PackageDocsServer();
+  const targets = [];
+  Object.assign(server['rustDocsHandler'], {
+    getCrateDetails: async () => ({ name: 'tokio', description: 'An async runtime', versions: [{ version: '1.38.0' }], latestVersion: '1.38.0', downloads: 1 }),
+    getCrateDocumentation: async (crateName, version, target) => {
+      targets.push(target);
+      return 'Tokio docs.';
+    },
+    getSupportedTargets: async () => [],
+    getFeatureFlags,
+  });
+  return { server, targets };
+}
+
+test('describe_rust_package passes the target on and reports which features docs.rs built', async () => {
+  const { server, targets } = rustServer(async () => [
+    { name: 'full', enabled: true },
+    { name: 'rt', enabled: true },
+    { name: 'tracing', enabled: false },
+  ]);
+
+  const result = JSON.parse(await callTool(server, 'describe_rust_package', {
+    package: 'tokio',
+    version: '1.38.0',
+    target: 'x86_64-pc-windows-msvc',
+    features: ['rt', 'tracing', 'nope'],
+    source: 'network',
+  }));
+
+  assert.deepEqual(targets, ['x86_64-pc-windows-msvc']);
+  assert.match(result.description, /Documentation as built for x86_64-pc-windows-msvc\./);
+  assert.match(result.description, /Features included in the docs\.rs build: rt\n/);
+  assert.match(result.description, /Features not enabled in the docs\.rs build, .*: tracing\. Run `cargo doc --features tracing`/);
+  assert.match(result.description, /Unknown features for tokio: nope \(available: full, rt, tracing\)/);
+  assert.match(result.usage, /tokio = \{ version = "1\.38\.0", features = \["rt", "tracing", "nope"\] \}/);
+});
+
+test('the feature check is reported as unavailable when docs.rs has no feature list', async () => {
+  const { server } = rustServer(async () => {
+    throw new Error('docs.rs is down');
+  });
+
+  const result = JSON.parse(await callTool(server, 'describe_rust_package', { package: 'tokio', features: ['rt'], source: 'network' }));
+  assert.match(result.description, /Could not check which features docs\.rs built tokio with/);
+  assert.doesNotMatch(result.description, /Documentation as built for/);
+});
+
+test('targets that aren\'t target triples are rejected', async () => {
+  const { server, targets } = rustServer(async () => []);
+
+  const result = JSON.parse(await callTool(server, 'describe_rust_package', { package: 'tokio', target: '../../etc', source: 'network' }));
+  assert.match(result.error, /Invalid target \.\.\/\.\.\/etc: expected a target triple/);
+  assert.deepEqual(targets, []);
+});
@@ -0,0 +1,46 @@
+import { afterEach, beforeEach, test } from 'node:test';
+import assert from 'node:assert/strict';
+import { RustDocsHandler } from '../build/rust-docs-integration.js';
+import { clearRepoCache } from '../build/utils/repo-cache.js';
+import { restoreNetwork, silentLogger, stubFetch } from './helpers.js';
+
+beforeEach(clearRepoCache);
+afterEach(() => {
+  restoreNetwork();
+  delete process.env.PACKAGE_DOCS_REPO_CACHE_TTL;
+});
+
+const handler = new RustDocsHandler(silentLogger);
+
+// crates.io, listing the given latest version of every crate
+function stubCratesIo(latest) {
+  return stubFetch(url => Response.json({
+    crate: { name: 'krate', downloads: 1, max_version: latest },
+    versions: [{ num: latest, yanked: false }],
+  }));
+}
+
+test('the latest version is looked up once and reused', async () => {
+  const urls = stubCratesIo('1.0.0');
+
+  assert.equal(await handler['resolveVersion']('krate'), '1.0.0');
+  assert.equal(await handler['resolveVersion']('krate'), '1.0.0');
+  assert.equal(urls.length, 1);
+});
+
+test('new releases are picked up once the cached version expires', async () => {
+  process.env.PACKAGE_DOCS_REPO_CACHE_TTL = '0';
+  stubCratesIo('1.0.0');
+  assert.equal(await handler['resolveVersion']('krate'), '1.0.0');
+
+  stubCratesIo('1.1.0');
+  assert.equal(await handler['resolveVersion']('krate'), '1.1.0');
+});
+
+test('requested versions and unreachable registries need no cached version', async () => {
+  const urls = stubFetch(() => new Response('', { status: 503 }));
+
+  assert.equal(await handler['resolveVersion']('krate', '0.9.0'), '0.9.0');
+  assert.equal(await handler['resolveVersion']('krate'), 'latest');
+  assert.ok(urls.length > 0);
+});
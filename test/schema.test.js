@@ -0,0 +1,67 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { callTool, notFound, restoreNetwork, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+const openapi = [
+  'openapi: 3.0.3',
+  'info:',
+  '  title: Petstore',
+  '  version: 1.0.0',
+  'paths:',
+  '  /pets:',
+  '    get:',
+  '      summary: List pets',
+].join('\n')
+
+const configSchema = JSON.stringify({ $schema: 'http://json-schema.org/draft-07/schema#', type: 'object' })
+
+// A GitHub repository of the given files, listed through the contents API and read raw from main
+function stubRepo(repo, files) {
+  const api = `https://api.github.com/repos/acme/${repo}/contents/`
+  const raw = `https://raw.githubusercontent.com/acme/${repo}/main/`
+  return stubGet((url, config) => {
+    if (url.startsWith(api) && config.params.ref === 'main') {
+      const dir = url.slice(api.length)
+      const entries = new Map()
+      for (const path of Object.keys(files)) {
+        if (dir && !path.startsWith(`${dir}/`)) continue
+        const [name, ...rest] = path.slice(dir ? dir.length + 1 : 0).split('/')
+        entries.set(name, { name, path: dir ? `${dir}/${name}` : name, type: rest.length > 0 ? 'dir' : 'file' })
+      }
+      return { data: Array.from(entries.values()), headers: {} }
+    }
+    if (url.startsWith(raw) && url.slice(raw.length) in files) {
+      return { data: files[url.slice(raw.length)] }
+    }
+    notFound(url)
+  })
+}
+
+test('an openapi.yaml in the repository is located and returned', async () => {
+  const urls = stubRepo('petstore', {
+    'README.md': '# Petstore',
+    'package.json': '{"name": "petstore"}',
+    'docs/config.schema.json': configSchema,
+    'openapi.yaml': openapi,
+  })
+
+  const server = new PackageDocsServer()
+  const text = await callTool(server, 'get_schema', { package: 'github.com/acme/petstore', language: 'go' })
+
+  assert.match(text, /OpenAPI 3\.0\.3 for github\.com\/acme\/petstore \(from openapi\.yaml in https:\/\/github\.com\/acme\/petstore\)/)
+  assert.match(text, /Other schema files: docs\/config\.schema\.json/)
+  assert.ok(text.includes(`\`\`\`yaml\n${openapi}\n\`\`\``), text)
+  assert.ok(!urls.some(url => url.endsWith('/package.json')), 'files not named like a schema are not read')
+})
+
+test('a repository without a schema says where it looked', async () => {
+  stubRepo('no-schema', { 'README.md': '# No schema', 'docs/guide.md': '# Guide' })
+
+  const server = new PackageDocsServer()
+  const text = await callTool(server, 'get_schema', { package: 'github.com/acme/no-schema', language: 'go' })
+
+  assert.match(JSON.parse(text).error, /No OpenAPI spec or JSON Schema found in https:\/\/github\.com\/acme\/no-schema\. Checked the root and api, docs, spec/)
+})
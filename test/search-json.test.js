@@ -0,0 +1,73 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { SearchUtils } from '../build/search-utils.js'
+import { callTool, notFound, restoreNetwork, silentLogger, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+const searchUtils = new SearchUtils(silentLogger)
+
+// Emoji before the matches make code point offsets differ from UTF-16 ones
+const configuration = [
+  '## Configuration',
+  '',
+  ...Array.from({ length: 12 }, (_, i) => `🚀 Option ${i} is set once.`),
+  '',
+  '🚀 Set `timeout` in seconds.',
+  'A Timeout of 0 waits forever.',
+].join('\n')
+const retries = [
+  '## Retries',
+  '',
+  'Failed requests are retried three times, with a growing delay between attempts, until the',
+  'last attempt fails or the timeout is reached. Retries can be turned off per request.',
+].join('\n')
+const readme = ['# timer', '', 'Times things.', '', configuration, '', retries, '', '## Usage', '', 'Call `run()`.'].join('\n')
+
+// Exact search, so the scores are the sections' own rather than averaged with Fuse.js's
+async function searchJson(query) {
+  stubGet(url => {
+    if (url === 'https://pypi.org/pypi/timer/json') {
+      return { data: { info: { name: 'timer', version: '1.0.0', description: readme, description_content_type: 'text/markdown' } } }
+    }
+    notFound(url)
+  })
+
+  const server = new PackageDocsServer()
+  const result = JSON.parse(await callTool(server, 'search_package_docs', { package: 'timer', language: 'python', query, format: 'json', contextSize: 2, fuzzy: false }))
+  return result.searchResults
+}
+
+test('json hits locate their context and matches in the section, in code points', async () => {
+  const { results, hits } = await searchJson('timeout')
+  assert.deepEqual(results, [])
+
+  const sections = { '## Configuration': configuration, '## Retries': retries }
+  assert.deepEqual(hits.map(hit => hit.section).sort(), Object.keys(sections))
+  for (const hit of hits) {
+    const section = Array.from(sections[hit.section])
+    const context = Array.from(hit.context)
+    assert.ok(hit.offset > 0, `${hit.section} context starts part way through the section`)
+    assert.equal(section.slice(hit.offset, hit.offset + context.length).join(''), hit.context)
+    assert.ok(hit.matches.length > 0)
+    for (const { start, end } of hit.matches) {
+      assert.equal(context.slice(start, end).join('').toLowerCase(), 'timeout')
+    }
+  }
+
+  const configurationHit = hits.find(hit => hit.section === '## Configuration')
+  assert.equal(configurationHit.matches.length, 2)
+  assert.equal(configurationHit.offset, Array.from(configuration.slice(0, configuration.indexOf(configurationHit.context))).length)
+})
+
+test('json hits carry the section scores, best first', async () => {
+  const { hits } = await searchJson('timeout')
+
+  for (const hit of hits) {
+    const section = hit.section === '## Configuration' ? configuration : retries
+    assert.equal(hit.score, searchUtils.scoreSectionMatch(section, 'timeout'))
+    assert.ok(hit.score > 0 && hit.score < 1)
+  }
+  assert.deepEqual(hits.map(hit => hit.score), hits.map(hit => hit.score).sort((a, b) => a - b))
+})
@@ -0,0 +1,442 @@
+import { test } from 'node:test'
+import assert from 'node:assert/strict'
+import { DEFAULT_CONTEXT_SIZE, MAX_CONTEXT_SIZE, MIN_CONTEXT_SIZE, SearchUtils, clampContextSize, formatDocSection, formatMarkdownTable, isSearchDocArgs, parseSearchQuery, truncateMarkdown, truncateSections, truncateText } from '../build/search-utils.js'
+import { silentLogger } from './helpers.js'
+
+const searchUtils = new SearchUtils(silentLogger)
+
+test('shields.io license badges are told apart from version badges', () => {
+  const badges = {
+    'https://img.shields.io/npm/v/pkg.svg': 'version',
+    'https://img.shields.io/npm/l/pkg.svg': 'license',
+    'https://img.shields.io/pypi/v/pkg': 'version',
+    'https://img.shields.io/pypi/l/pkg': 'license',
+    'https://img.shields.io/crates/v/pkg': 'version',
+    'https://img.shields.io/crates/l/pkg': 'license',
+    'https://img.shields.io/github/v/release/owner/repo': 'version',
+    'https://img.shields.io/github/license/owner/repo': 'license',
+  }
+
+  for (const [url, kind] of Object.entries(badges)) {
+    assert.deepEqual(searchUtils.extractBadgeSignals(`![badge](${url})`).map(signal => signal.kind), [kind], url)
+  }
+})
+
+test('ranking weights decide between a heading match and a frequent one', () => {
+  const inHeading = '## Timeouts\n\nRequests wait for the server by default.'
+  const frequent = '## Requests\n\nSet a timeout per request. The timeout is in seconds; a timeout of 0 waits forever.'
+
+  assert.ok(searchUtils.scoreSectionMatch(inHeading, 'timeout') < searchUtils.scoreSectionMatch(frequent, 'timeout'))
+
+  const ranking = { title: 0, frequency: 3 }
+  assert.ok(searchUtils.scoreSectionMatch(frequent, 'timeout', ranking) < searchUtils.scoreSectionMatch(inHeading, 'timeout', ranking))
+})
+
+test('search ranking weights must be known signals with non-negative weights', () => {
+  const args = { package: 'requests', query: 'timeout', language: 'python' }
+  assert.ok(isSearchDocArgs({ ...args, ranking: { title: 0, proximity: 2.5 } }))
+  assert.ok(!isSearchDocArgs({ ...args, ranking: { title: -1 } }))
+  assert.ok(!isSearchDocArgs({ ...args, ranking: { heading: 1 } }))
+  assert.ok(!isSearchDocArgs({ ...args, ranking: { title: '2' } }))
+})
+
+test('truncation drops whole trailing sections and notes how many', () => {
+  const sections = ['## One\n\nFirst section.', '## Two\n\nSecond section.', '## Three\n\nThird section.']
+  const whole = sections.join('\n\n')
+  assert.equal(truncateSections(sections, whole.length), whole)
+
+  const truncated = truncateSections(sections, whole.length - 1)
+  assert.equal(truncated, `${sections[0]}\n\n${sections[1]}\n\n_1 more section omitted to stay within ${whole.length - 1} characters_`)
+
+  const first = truncateSections(sections, sections[0].length + 5)
+  assert.equal(first, `${sections[0]}\n\n_2 more sections omitted to stay within ${sections[0].length + 5} characters_`)
+})
+
+test('markdown is only truncated between headings, never inside a section or code block', () => {
+  const markdown = [
+    '# Tool',
+    '',
+    'Intro.',
+    '',
+    '## Install',
+    '',
+    '```sh',
+    '# not a heading',
+    'npm install tool',
+    '```',
+    '',
+    '## Usage',
+    '',
+    'Run it.',
+  ].join('\n')
+
+  for (let maxLength = 20; maxLength < markdown.length; maxLength += 5) {
+    const [content] = truncateMarkdown(markdown, maxLength).split('\n\n_')
+    assert.ok(markdown.startsWith(content), `cut mid-section at ${maxLength}`)
+    assert.ok(['Intro.', '```', 'Run it.'].some(ending => content.endsWith(ending)), `cut mid-section at ${maxLength}: ${content}`)
+  }
+  assert.match(truncateMarkdown(markdown, markdown.length - 1), /```\n\n_1 more section omitted/)
+})
+
+test('sections are re-emitted at their original heading level', () => {
+  const markdown = '# Client\n\nOverview.\n\n## API\n\n### get(url)\n\nFetch a URL.\n\n#### Options\n\nTimeouts.'
+  const sections = searchUtils.parseMarkdownDocSections(markdown)
+
+  assert.deepEqual(sections.map(formatDocSection), [
+    '# Client\n\nOverview.',
+    '## API',
+    '### get(url)\n\nFetch a URL.',
+    '#### Options\n\nTimeouts.',
+  ])
+  assert.equal(formatDocSection({ title: 'Overview', content: 'Text before any heading.', level: 0 }), '## Overview\n\nText before any heading.')
+})
+
+test('context sizes are clamped to the supported range', () => {
+  assert.equal(clampContextSize(MIN_CONTEXT_SIZE - 1), MIN_CONTEXT_SIZE)
+  assert.equal(clampContextSize(0), MIN_CONTEXT_SIZE)
+  assert.equal(clampContextSize(-5), MIN_CONTEXT_SIZE)
+  assert.equal(clampContextSize(MIN_CONTEXT_SIZE), MIN_CONTEXT_SIZE)
+  assert.equal(clampContextSize(MAX_CONTEXT_SIZE), MAX_CONTEXT_SIZE)
+  assert.equal(clampContextSize(MAX_CONTEXT_SIZE + 1), MAX_CONTEXT_SIZE)
+  assert.equal(clampContextSize(1e9), MAX_CONTEXT_SIZE)
+  assert.equal(clampContextSize(Infinity), DEFAULT_CONTEXT_SIZE)
+})
+
+test('non-integer context sizes are rounded and missing ones use the default', () => {
+  assert.equal(clampContextSize(7.4), 7)
+  assert.equal(clampContextSize(7.6), 8)
+  assert.equal(clampContextSize(1.5), MIN_CONTEXT_SIZE)
+  assert.equal(clampContextSize(undefined), DEFAULT_CONTEXT_SIZE)
+  assert.equal(clampContextSize(NaN), DEFAULT_CONTEXT_SIZE)
+  assert.equal(isSearchDocArgs({ package: 'pkg', query: 'q', language: 'npm', contextSize: '5' }), false)
+})
+
+test('extracted context stays within the clamped size however large or small the request', () => {
+  const content = Array.from({ length: 200 }, (_, i) => i === 100 ? 'the needle is here' : `line ${i}`).join('\n')
+  const lineCount = size => searchUtils.extractContextAroundMatch(content, 'needle', size).split('\n').filter(line => line !== '...').length
+
+  // Half as many lines before the match as after, the match line counted after
+  assert.equal(lineCount(10000), MAX_CONTEXT_SIZE + MAX_CONTEXT_SIZE / 2)
+  assert.equal(lineCount(0), MIN_CONTEXT_SIZE + MIN_CONTEXT_SIZE / 2)
+  assert.equal(lineCount(undefined), DEFAULT_CONTEXT_SIZE + DEFAULT_CONTEXT_SIZE / 2)
+})
+
+test('queries are parsed into alternatives of terms, with quoted phrases kept whole', () => {
+  assert.deepEqual(parseSearchQuery('timeout retry'), [['timeout', 'retry']])
+  assert.deepEqual(parseSearchQuery('Timeout OR retry'), [['timeout'], ['retry']])
+  assert.deepEqual(parseSearchQuery('"exact  Phrase" client OR pool'), [['exact phrase', 'client'], ['pool']])
+  assert.deepEqual(parseSearchQuery('"unclosed phrase'), [['unclosed phrase']])
+  assert.deepEqual(parseSearchQuery('or'), [['or']])
+})
+
+test('every term of a query must match, in any order', () => {
+  assert.equal(searchUtils.matchesQuery('Set a retry count and a timeout.', 'timeout retry'), true)
+  assert.equal(searchUtils.matchesQuery('Set a timeout.', 'timeout retry'), false)
+})
+
+test('OR matches either alternative', () => {
+  assert.equal(searchUtils.matchesQuery('Set a timeout.', 'timeout OR retry'), true)
+  assert.equal(searchUtils.matchesQuery('Set a retry count.', 'timeout OR retry'), true)
+  assert.equal(searchUtils.matchesQuery('Set a delay.', 'timeout OR retry'), false)
+})
+
+test('a quoted phrase matches only the words together', () => {
+  assert.equal(searchUtils.matchesQuery('Handle the connection timeout here.', '"connection timeout"'), true)
+  assert.equal(searchUtils.matchesQuery('The timeout of each connection.', '"connection timeout"'), false)
+})
+
+test('sections with the terms close together rank above those with them far apart', () => {
+  const words = Array.from({ length: 80 }, (_, i) => `word${i}`)
+  const close = `## Options\n\n${words.join(' ')} retry timeout`
+  const apart = `## Options\n\n${words.slice(0, 40).join(' ')} retry ${words.slice(40).join(' ')} timeout`
+
+  assert.ok(searchUtils.scoreSectionMatch(close, 'retry timeout') < searchUtils.scoreSectionMatch(apart, 'retry timeout'))
+  // Without the proximity boost, the distance between the terms doesn't count
+  const unboosted = { proximity: 0, position: 0 }
+  assert.equal(searchUtils.scoreSectionMatch(close, 'retry timeout', unboosted), searchUtils.scoreSectionMatch(apart, 'retry timeout', unboosted))
+})
+
+test('sections matching more of a query rank above those matching part of it', () => {
+  const both = '## Client\n\nSet the retry count and timeout.'
+  const one = '## Client\n\nSet the retry count and delay.'
+  assert.ok(searchUtils.scoreSectionMatch(both, 'retry timeout') < searchUtils.scoreSectionMatch(one, 'retry timeout'))
+})
+
+// Valid UTF-16 round trips through UTF-8 unchanged, while a split surrogate pair becomes U+FFFD
+function isWellFormed(text) {
+  return Buffer.from(text, 'utf8').toString('utf8') === text
+}
+
+test('Japanese text is truncated by character, never splitting one', () => {
+  const description = 'このパッケージは、HTTPリクエストを簡単に送信するためのクライアントです。𠮷野家の例も含みます。'
+  for (let maxLength = 1; maxLength < Array.from(description).length; maxLength++) {
+    const truncated = truncateText(description, maxLength)
+    assert.ok(isWellFormed(truncated), `split a character at ${maxLength}`)
+    assert.ok(truncated.endsWith('... (truncated)'))
+    assert.ok(Array.from(truncated.replace('... (truncated)', '')).length <= maxLength)
+  }
+
+  // 𠮷 is one character of two UTF-16 code units
+  const upToKanji = Array.from(description).indexOf('𠮷') + 1
+  assert.equal(truncateText(description, upToKanji), Array.from(description).slice(0, upToKanji).join('') + '... (truncated)')
+  assert.equal(truncateText(description, 1000), description)
+})
+
+test('emoji and accented text are truncated whole, preferring a word boundary', () => {
+  assert.equal(truncateText('Déjà vu 🎉🎉🎉 encore', 9), 'Déjà vu 🎉... (truncated)')
+  assert.equal(truncateText('Crème brûlée recipes for everyone', 29), 'Crème brûlée recipes for... (truncated)')
+  assert.ok(isWellFormed(truncateText('👩‍💻'.repeat(10), 7)))
+})
+
+test('markdown tables are read with escaped pipes and rows fitted to the headers', () => {
+  const markdown = [
+    '| Option | Type | Description |',
+    '| :----- | :--: | ----------: |',
+    '| `sep` | string | The separator, e.g. `a \\| b` |',
+    '| `trim` | boolean |',
+    '| `limit` | number | The most parts | extra |',
+    '',
+    'After the table.',
+  ].join('\n')
+
+  assert.deepEqual(searchUtils.extractTables(markdown), [{
+    headers: ['Option', 'Type', 'Description'],
+    rows: [
+      ['`sep`', 'string', 'The separator, e.g. `a | b`'],
+      ['`trim`', 'boolean', ''],
+      ['`limit`', 'number', 'The most parts'],
+    ],
+  }])
+})
+
+test('tables in code blocks or with a mismatched delimiter row are not extracted', () => {
+  const markdown = [
+    '```',
+    '| Option | Description |',
+    '| --- | --- |',
+    '| a | b |',
+    '```',
+    '',
+    'Name | Description',
+    '--- | --- | ---',
+    'a | b',
+    '',
+    'Name | Description',
+    '--- | ---',
+    'c | d',
+  ].join('\n')
+
+  assert.deepEqual(searchUtils.extractTables(markdown), [{ headers: ['Name', 'Description'], rows: [['c', 'd']] }])
+})
+
+test('only tables whose first column names options are option tables', () => {
+  const markdown = [
+    '| **Parameter** | Default |',
+    '| --- | --- |',
+    '| retries | 3 |',
+    '',
+    '| Version | Node |',
+    '| --- | --- |',
+    '| 2.x | >=18 |',
+    '',
+    '| Option |',
+    '| --- |',
+    '| single |',
+    '',
+    '| Flag | Meaning |',
+    '| --- | --- |',
+  ].join('\n')
+
+  assert.deepEqual(searchUtils.extractOptionTables(markdown), [{ headers: ['**Parameter**', 'Default'], rows: [['retries', '3']] }])
+})
+
+test('code blocks keep the language of their fence info string', () => {
+  const markdown = [
+    '```TypeScript {1,3} title="index.ts"',
+    'const a = 1',
+    '```',
+    '',
+    '~~~python',
+    'print("```")',
+    '~~~',
+    '',
+    '````md',
+    '```js',
+    'nested()',
+    '```',
+    '````',
+    '',
+    '```',
+    'plain',
+    '```',
+  ].join('\n')
+
+  assert.deepEqual(searchUtils.extractCodeBlocks(markdown), [
+    { language: 'typescript', code: 'const a = 1' },
+    { language: 'python', code: 'print("```")' },
+    { language: 'md', code: '```js\nnested()\n```' },
+    { language: undefined, code: 'plain' },
+  ])
+})
+
+test('code blocks can be limited to the languages of an ecosystem', () => {
+  const markdown = '```sh\nnpm i widgets\n```\n\n```js\nwidget()\n```\n\n```py\nwidget()\n```\n\n```\nuntagged\n```'
+
+  assert.deepEqual(searchUtils.extractCodeBlocks(markdown, 'npm').map(block => block.language), ['js'])
+  assert.deepEqual(searchUtils.extractCodeBlocks(markdown, 'python').map(block => block.language), ['py'])
+  // Ecosystems without a list of languages match the fence language of the same name
+  assert.deepEqual(searchUtils.extractCodeBlocks(markdown, 'sh').map(block => block.language), ['sh'])
+  assert.equal(searchUtils.formatCodeBlock({ language: 'js', code: 'widget()' }), '```js\nwidget()\n```')
+  assert.equal(searchUtils.formatCodeBlock({ code: 'untagged' }), '```\nuntagged\n```')
+})
+
+test('tables are rendered as markdown with aligned columns and escaped pipes', () => {
+  const table = { headers: ['Option', 'Type'], rows: [['separator', 'a | b'], ['x', '']] }
+  const markdown = formatMarkdownTable(table)
+
+  assert.equal(markdown, [
+    '| Option    | Type   |',
+    '| --------- | ------ |',
+    '| separator | a \\| b |',
+    '| x         |        |',
+  ].join('\n'))
+  // The rendered table reads back as the same table
+  assert.deepEqual(searchUtils.extractTables(markdown), [table])
+})
+
+test('context around a match contains the matching line, with ellipses where lines were left out', () => {
+  const content = ['## Heading', ...Array.from({ length: 40 }, (_, i) => i === 20 ? 'Set the Timeout option' : `line ${i}`)].join('\n')
+
+  for (const query of ['timeout', 'TIMEOUT', 'retries OR timeout']) {
+    const lines = searchUtils.extractContextAroundMatch(content, query, 4).split('\n')
+    assert.ok(lines.includes('Set the Timeout option'), query)
+    assert.equal(lines[0], '...')
+    assert.equal(lines[lines.length - 1], '...')
+  }
+
+  // A match near the start has nothing left out before it
+  const early = searchUtils.extractContextAroundMatch(content, 'line 0', 4).split('\n')
+  assert.equal(early[0], '## Heading')
+  assert.equal(early[early.length - 1], '...')
+})
+
+test('context for a query matching no single line is the start of the section after its heading', () => {
+  const content = ['## Heading', ...Array.from({ length: 40 }, (_, i) => `line ${i}`)].join('\n')
+  const lines = searchUtils.extractContextAroundMatch(content, 'nowhere', 4).split('\n')
+
+  assert.equal(lines[0], 'line 0')
+  assert.equal(lines[lines.length - 1], '...')
+  assert.equal(searchUtils.extractContextAroundMatch('## Heading\nshort', 'nowhere'), 'short')
+})
+
+test('a long matching line is cut to whole words around the match', () => {
+  const words = Array.from({ length: 120 }, (_, i) => `word${i}`)
+  words[60] = 'needle'
+  const line = words.join(' ')
+
+  for (const maxLineLength of [40, 80, 200]) {
+    const context = searchUtils.extractContextAroundMatch(`## Heading\n${line}`, 'needle', 10, maxLineLength).split('\n')[1]
+    assert.match(context, /needle/)
+    assert.match(context, /^\.\.\. word\d+ .* word\d+ \.\.\.$/, String(maxLineLength))
+    // Every word kept is a whole word from the line
+    for (const word of context.replace(/^\.\.\. | \.\.\.$/g, '').split(' ')) {
+      assert.ok(words.includes(word), word)
+    }
+  }
+
+  // A line within the limit is kept whole
+  assert.equal(searchUtils.extractContextAroundMatch(`## Heading\n${line}`, 'needle', 10, line.length).split('\n')[1], line)
+})
+
+const goDocAll = [
+  'package http // import "net/http"',
+  '',
+  'Package http provides HTTP client and server implementations.',
+  '',
+  'CONSTANTS',
+  '',
+  'const (',
+  '\tMethodGet  = "GET"',
+  '\tMethodPost = "POST" // RFC 7231, 4.3.3',
+  ')',
+  '    Common HTTP methods.',
+  '',
+  'FUNCTIONS',
+  '',
+  'func Get(url string) (resp *Response, err error)',
+  '    Get issues a GET to the specified URL.',
+  '',
+  'TYPES',
+  '',
+  'type Client struct {',
+  '\t// Timeout specifies a time limit (e.g. "30s") for requests made by this Client.',
+  '\tTimeout time.Duration',
+  '',
+  '\tJar CookieJar',
+  '}',
+  '    A Client is an HTTP client.',
+  '',
+  'func (c *Client) Do(req *Request) (*Response, error)',
+  '    Do sends an HTTP request and returns an HTTP response.',
+  '',
+  'func (Client) CloseIdleConnections()',
+].join('\n')
+
+test('go doc output is sectioned on whole top-level declarations', () => {
+  const sections = searchUtils.parseGoDoc(goDocAll)
+
+  assert.deepEqual(sections.map(section => section.type), ['description', 'variable', 'function', 'type', 'method', 'method'])
+  assert.match(sections[0].content, /^package http[\s\S]*implementations\.$/)
+  // Multi-line declarations stay in one section, with their doc comments, and the headings are dropped
+  assert.equal(sections[1].content, 'const (\n\tMethodGet  = "GET"\n\tMethodPost = "POST" // RFC 7231, 4.3.3\n)\n    Common HTTP methods.')
+  assert.match(sections[3].content, /^type Client struct \{[\s\S]*Jar CookieJar\n\}\n {4}A Client is an HTTP client\./)
+  assert.ok(sections.every(section => !/^(CONSTANTS|FUNCTIONS|TYPES)$/m.test(section.content)))
+})
+
+test('methods are sections of their own, also listed in their type', () => {
+  const sections = searchUtils.parseGoDoc(goDocAll)
+
+  assert.match(sections[3].content, /Methods:\n {4}func \(c \*Client\) Do\(req \*Request\) \(\*Response, error\)\n {4}func \(Client\) CloseIdleConnections\(\)$/)
+  assert.equal(sections[4].content, 'func (c *Client) Do(req *Request) (*Response, error)\n    Do sends an HTTP request and returns an HTTP response.')
+  assert.equal(searchUtils.extractSymbol(sections[4].content, 'go'), 'Client.Do')
+  assert.equal(searchUtils.extractSymbol(sections[5].content, 'go'), 'Client.CloseIdleConnections')
+  assert.equal(searchUtils.extractSymbol(sections[2].content, 'go'), 'Get')
+  assert.equal(searchUtils.extractSymbol('func (l *List[T]) Push(v T)', 'go'), 'List.Push')
+})
+
+const imageReadme = [
+  '# widgets',
+  '',
+  '[![Build](https://github.com/acme/widgets/actions/workflows/ci.yml/badge.svg)](https://github.com/acme/widgets/actions)',
+  '[![npm](https://img.shields.io/npm/v/widgets.svg)](https://npmjs.com/package/widgets)',
+  '[![Coverage][coverage-badge]][coverage]',
+  '',
+  '![Architecture diagram](docs/architecture.png "How widgets fit together")',
+  '![Data flow][flow]',
+  '![flow][]',
+  '<p align="center"><img width="400" src="https://example.com/logo.svg" alt="Widgets logo"></p>',
+  '<img src=\'docs/architecture.png\'>',
+  '',
+  '[coverage-badge]: https://codecov.io/gh/acme/widgets/branch/main/graph/badge.svg',
+  '[coverage]: https://codecov.io/gh/acme/widgets',
+  '[flow]: https://example.com/flow.svg "Data flow"',
+].join('\n')
+
+test('content images are extracted from markdown, reference and HTML images, once each', () => {
+  assert.deepEqual(searchUtils.extractImages(imageReadme), [
+    { alt: 'Architecture diagram', url: 'docs/architecture.png', isBadge: false },
+    { alt: 'Data flow', url: 'https://example.com/flow.svg', isBadge: false },
+    { alt: 'Widgets logo', url: 'https://example.com/logo.svg', isBadge: false },
+  ])
+})
+
+test('badges are left out of the images unless asked for, and flagged', () => {
+  const badges = searchUtils.extractImages(imageReadme, true).filter(image => image.isBadge)
+  assert.deepEqual(badges.map(image => image.url), [
+    'https://github.com/acme/widgets/actions/workflows/ci.yml/badge.svg',
+    'https://img.shields.io/npm/v/widgets.svg',
+    'https://codecov.io/gh/acme/widgets/branch/main/graph/badge.svg',
+  ])
+  assert.equal(searchUtils.extractImages('![ci](https://travis-ci.org/acme/widgets.svg?branch=main)').length, 0)
+  assert.deepEqual(searchUtils.extractImages('no images here'), [])
+})
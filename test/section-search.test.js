@@ -0,0 +1,104 @@
+import { test } from 'node:test'
+import assert from 'node:assert/strict'
+import { SearchUtils } from '../build/search-utils.js'
+import { SectionSearchPool, getSearchWorkers, searchSections } from '../build/section-search.js'
+import { silentLogger } from './helpers.js'
+
+const searchUtils = new SearchUtils(silentLogger)
+
+// A large README: sections of prose, options and code examples, some mentioning the query
+function syntheticSections(count) {
+  return Array.from({ length: count }, (_, i) => ({
+    type: i % 7 === 0 ? 'api' : 'general',
+    content: [
+      `## ${i % 5 === 0 ? `createClient option ${i}` : `Section ${i}`}`,
+      '',
+      `Paragraph ${i} describes the behaviour of part ${i} of the library.`,
+      i % 3 === 0 ? `Pass retries to createClient to retry failed requests ${i} times.` : `Nothing about clients here (${i}).`,
+      '',
+      '```js',
+      `const client = createClient({ retries: ${i} })`,
+      '```',
+    ].join('\n'),
+  }))
+}
+
+test('the worker pool returns what the serial search does', async () => {
+  const sections = syntheticSections(600)
+  const pool = new SectionSearchPool(searchUtils, silentLogger, 3, 1)
+  try {
+    const exact = { sections, query: 'createClient retries', language: 'javascript', contextSize: 2 }
+    const serial = searchSections(searchUtils, exact)
+    assert.ok(serial.length > 100)
+    assert.deepEqual(await pool.search(exact), serial)
+
+    const fuzzy = { ...exact, fuzzyScores: sections.map((_, i) => (i % 10) / 10) }
+    assert.deepEqual(await pool.search(fuzzy), searchSections(searchUtils, fuzzy))
+  } finally {
+    await pool.close()
+  }
+})
+
+test('searches wait for a free worker', async () => {
+  const pool = new SectionSearchPool(searchUtils, silentLogger, 2, 1)
+  try {
+    const tasks = ['createClient', 'retries', 'behaviour', 'Paragraph 42'].map((query, i) =>
+      ({ sections: syntheticSections(100 + i * 50), query, language: 'javascript', contextSize: 1 })
+    )
+    const results = await Promise.all(tasks.map(task => pool.search(task)))
+    results.forEach((matches, i) => assert.deepEqual(matches, searchSections(searchUtils, tasks[i])))
+  } finally {
+    await pool.close()
+  }
+})
+
+test('PACKAGE_DOCS_SEARCH_WORKERS sets the pool size', () => {
+  assert.equal(getSearchWorkers('0'), 0)
+  assert.equal(getSearchWorkers('6'), 6)
+  const fallback = getSearchWorkers(undefined)
+  assert.ok(fallback >= 0 && fallback <= 4)
+  assert.equal(getSearchWorkers('lots'), fallback)
+  assert.equal(getSearchWorkers('-1'), fallback)
+})
+
+test('ranking weights reach the sections searched on workers', async () => {
+  const sections = syntheticSections(600)
+  const pool = new SectionSearchPool(searchUtils, silentLogger, 2, 1)
+  try {
+    const task = { sections, query: 'createClient', language: 'javascript', contextSize: 2 }
+    const ranked = { ...task, ranking: { title: 0 } }
+    const scores = matches => matches.map(match => match.score)
+
+    assert.notDeepEqual(scores(await pool.search(ranked)), scores(await pool.search(task)))
+    assert.deepEqual(await pool.search(ranked), searchSections(searchUtils, ranked))
+  } finally {
+    await pool.close()
+  }
+})
+
+test('fuzzy ranks are lower for nearer matches', () => {
+  assert.equal(searchUtils.fuzzyRank('Call createClient first', 'createclient'), 0)
+  const near = searchUtils.fuzzyRank('Call create_client first', 'createclient')
+  const far = searchUtils.fuzzyRank('Call create a new http client first', 'createclient')
+  assert.ok(near > 0 && near < far && far < 1, `${near} ${far}`)
+  assert.equal(searchUtils.fuzzyRank('Call connect first', 'createclient'), undefined)
+})
+
+test('a near-exact fuzzy match ranks above a distant one, averaging in the weighted score', () => {
+  const sections = [
+    { type: 'general', content: '## Connecting\n\nCall create a new http client to connect.' },
+    { type: 'general', content: '## createClient\n\nCall createClient to connect.' },
+  ]
+  const fuzzyScores = [0.35, 0.01]
+  const matches = searchSections(searchUtils, { sections, query: 'createClient', language: 'javascript', contextSize: 2, fuzzyScores })
+
+  // Lower scores are better, as the server sorts matches in ascending order of score
+  assert.ok(matches[1].score < matches[0].score)
+  matches.forEach((match, i) => {
+    assert.equal(match.score, (fuzzyScores[i] + searchUtils.scoreSectionMatch(sections[i].content, 'createClient')) / 2)
+  })
+
+  // A section with the better fuzzy score can still rank below one matching the query exactly
+  const reversed = searchSections(searchUtils, { sections, query: 'createClient', language: 'javascript', contextSize: 2, fuzzyScores: [0.3, 0.32] })
+  assert.ok(reversed[1].score < reversed[0].score)
+})
@@ -0,0 +1,48 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import axios from 'axios'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { getAllowedCommands, setAllowedCommands } from '../build/utils/command-runner.js'
+
+const axiosHead = axios.head
+
+afterEach(() => {
+  axios.head = axiosHead
+  setAllowedCommands(getAllowedCommands())
+})
+
+// A server whose logged errors are collected instead of written to stderr
+function checkedServer() {
+  const server = new PackageDocsServer()
+  const errors = []
+  server['logger'] = { debug() {}, info() {}, warn() {}, error(message) { errors.push(message) } }
+  return { server, errors }
+}
+
+test('the self-check warns when neither a toolchain nor the network is available', async () => {
+  // Neither go nor python3 may be run, and the registry can't be reached
+  setAllowedCommands([], [])
+  axios.head = async () => { throw new Error('getaddrinfo ENOTFOUND registry.npmjs.org') }
+
+  const { server, errors } = checkedServer()
+  assert.equal(await server.selfCheck(), false)
+  assert.equal(errors.length, 1)
+  assert.match(errors[0], /No documentation sources are available/)
+  assert.match(errors[0], /go: go is not an allowed command/)
+  assert.match(errors[0], /python3: python3 is not an allowed command/)
+  assert.match(errors[0], /network \(registry\.npmjs\.org\): getaddrinfo ENOTFOUND/)
+})
+
+test('the self-check passes quietly when the network is available', async () => {
+  setAllowedCommands([], [])
+  const urls = []
+  axios.head = async url => {
+    urls.push(url)
+    return { status: 200 }
+  }
+
+  const { server, errors } = checkedServer()
+  assert.equal(await server.selfCheck(), true)
+  assert.deepEqual(urls, ['https://registry.npmjs.org/'])
+  assert.deepEqual(errors, [])
+})
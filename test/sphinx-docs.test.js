@@ -0,0 +1,73 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { extractSphinxApi, isSphinxPage } from '../build/utils/html-content.js'
+import { callTool, notFound, restoreNetwork, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+// A Sphinx autodoc page documenting a class with a method, and a function
+const sphinxPage = `<html><head><meta name="generator" content="Sphinx 7.2.6"></head><body>
+  <dl class="py class"><dt class="sig sig-object py" id="sessions.Session">
+    <em class="property">class </em><span class="sig-name">Session</span>(<em>auth</em>, <em>timeout=None</em>)<a class="headerlink" href="#sessions.Session">¶</a></dt>
+    <dd><p>A persistent HTTP session.</p>
+      <dl class="py method"><dt class="sig sig-object py" id="sessions.Session.get">
+        <span class="sig-name">get</span>(<em>url</em>)<a class="headerlink" href="#sessions.Session.get">¶</a></dt>
+        <dd><p>Send a GET request.</p></dd></dl>
+    </dd></dl>
+  <dl class="py function"><dt class="sig sig-object py" id="sessions.request">
+    <span class="sig-name">request</span>(<em>method</em>, <em>url</em>)<a class="headerlink" href="#sessions.request">¶</a></dt>
+    <dd><p>Send a request.</p></dd></dl>
+</body></html>`
+
+// PyPI's JSON for a project whose documentation is on Read the Docs
+function stubPyPI() {
+  return stubGet(url => {
+    if (url === 'https://pypi.org/pypi/sessions/json') {
+      return {
+        data: {
+          info: {
+            name: 'sessions',
+            version: '2.0.0',
+            summary: 'HTTP sessions',
+            project_urls: { Documentation: 'https://sessions.readthedocs.io/en/latest/' },
+          },
+        },
+      }
+    }
+    if (url === 'https://sessions.readthedocs.io/en/latest/') {
+      return { data: sphinxPage }
+    }
+    notFound(url)
+  })
+}
+
+test('classes, methods and functions are extracted from Sphinx autodoc blocks', () => {
+  assert.ok(isSphinxPage(sphinxPage))
+  assert.deepEqual(extractSphinxApi(sphinxPage), [
+    { kind: 'class', name: 'sessions.Session', signature: 'class Session(auth, timeout=None)', description: 'A persistent HTTP session.' },
+    { kind: 'method', name: 'sessions.Session.get', signature: 'get(url)', description: 'Send a GET request.' },
+    { kind: 'function', name: 'sessions.request', signature: 'request(method, url)', description: 'Send a request.' },
+  ])
+})
+
+test('the Sphinx documentation is only read when the API reference is requested', async () => {
+  const urls = stubPyPI()
+
+  const server = new PackageDocsServer()
+  const text = await callTool(server, 'describe_python_package', { package: 'sessions', source: 'network' })
+
+  assert.deepEqual(urls, ['https://pypi.org/pypi/sessions/json'])
+  assert.doesNotMatch(text, /API Reference/)
+})
+
+test('includeApiReference adds the API reference from the Sphinx documentation', async () => {
+  const urls = stubPyPI()
+
+  const server = new PackageDocsServer()
+  const result = JSON.parse(await callTool(server, 'describe_python_package', { package: 'sessions', source: 'network', includeApiReference: true }))
+
+  assert.ok(urls.includes('https://sessions.readthedocs.io/en/latest/'), urls.join('\n'))
+  assert.match(result.usage, /### sessions\.Session \(class\)\n\n```python\nclass Session\(auth, timeout=None\)\n```/)
+  assert.match(result.usage, /### sessions\.request \(function\)/)
+})
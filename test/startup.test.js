@@ -0,0 +1,44 @@
+import { test } from 'node:test';
+import assert from 'node:assert/strict';
+import { spawnSync } from 'child_process';
+import { mkdtempSync, writeFileSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { fileURLToPath, pathToFileURL } from 'url';
+
+const serverEntry = fileURLToPath(new URL('../build/index.js', import.meta.url));
+
+// Preloaded into the server process so that connecting it to stdio fails
+const failingConnect = join(mkdtempSync(join(tmpdir(), 'package-docs-startup-')), 'failing-connect.mjs');
+writeFileSync(failingConnect, `
+import { Server } from ${JSON.stringify(import.meta.resolve('@modelcontextprotocol/sdk/server/index.js'))};
+Server.prototype.connect = async function () {
+  throw new Error('stdio is unavailable');
+};
+`);
+
+function startServer(env) {
+  return spawnSync(process.execPath, ['--import', pathToFileURL(failingConnect).href, serverEntry], {
+    env: { ...process.env, MCP_VERBOSE: undefined, ...env },
+    input: '',
+    encoding: 'utf8',
+    timeout: 10000,
+  });
+}
+
+test('startup failures are reported on stderr in one line', () => {
+  const { status, stdout, stderr } = startServer({});
+  assert.equal(status, 1);
+  assert.equal(stdout, '');
+  assert.match(stderr, /Failed to start server: Error: stdio is unavailable$/m);
+  assert.doesNotMatch(stderr, /^\s+at /m);
+  assert.doesNotMatch(stderr, /Node v/);
+});
+
+test('verbose startup failures also report the stack trace and environment, still leaving stdout empty', () => {
+  const { status, stdout, stderr } = startServer({ MCP_VERBOSE: 'true' });
+  assert.equal(status, 1);
+  assert.equal(stdout, '');
+  assert.match(stderr, /stdio is unavailable\n\s+at /);
+  assert.match(stderr, new RegExp(`Node ${process.version} on ${process.platform}`));
+});
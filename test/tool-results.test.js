@@ -0,0 +1,39 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { callTool, notFound, restoreNetwork, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+function stubPyPI() {
+  return stubGet(url => {
+    if (url === 'https://pypi.org/pypi/rendered/1.0.0/json' || url === 'https://pypi.org/pypi/rendered/2.0.0/json') {
+      const version = url.split('/')[5]
+      return { data: { info: { name: 'rendered', version, summary: 'Rendered', requires_dist: version === '2.0.0' ? ['idna>=3'] : [] } } }
+    }
+    notFound(url)
+  })
+}
+
+test('markdown tools render the same document when the result is cached', async () => {
+  const urls = stubPyPI()
+  const args = { package: 'rendered', language: 'python', fromVersion: '1.0.0', toVersion: '2.0.0' }
+
+  const server = new PackageDocsServer()
+  const fresh = await callTool(server, 'compare_versions', args)
+  const requests = urls.length
+  const cached = await callTool(server, 'compare_versions', args)
+
+  assert.match(fresh, /^# rendered\n\nChanges in rendered between 1\.0\.0 and 2\.0\.0/)
+  assert.equal(cached, fresh)
+  assert.equal(urls.length, requests)
+})
+
+test('markdown tools return their errors as JSON rather than an empty document', async () => {
+  stubPyPI()
+
+  const server = new PackageDocsServer()
+  const text = await callTool(server, 'compare_versions', { package: 'rendered', language: 'python', fromVersion: '1.0.0', toVersion: '3.0.0' })
+
+  assert.match(JSON.parse(text).error, /Failed to compare rendered 1\.0\.0 and 3\.0\.0/)
+})
@@ -0,0 +1,68 @@
+import { afterEach, test } from 'node:test'
+import assert from 'node:assert/strict'
+import { PackageDocsServer } from '../build/package-docs-server.js'
+import { callTool, notFound, restoreNetwork, stubGet } from './helpers.js'
+
+afterEach(restoreNetwork)
+
+// Serve @types/fake from the registry and unpkg: index.d.ts references `files` declaration files,
+// each declaring one ambient module. Returns the unpkg requests and the most served at once.
+function stubTypesPackage(files) {
+  const declarations = {
+    'index.d.ts': Array.from({ length: files }, (_, i) => `/// <reference path="mod${i}.d.ts" />`).join('\n'),
+  }
+  for (let i = 0; i < files; i++) {
+    declarations[`mod${i}.d.ts`] = `declare module "fake/mod${i}" {\n  export function run${i}(input: string): number;\n}\n`
+  }
+
+  const reads = { urls: [], inFlight: 0, maxInFlight: 0 }
+  stubGet(async url => {
+    if (url === 'https://registry.npmjs.org/@types/fake') {
+      return {
+        data: {
+          name: '@types/fake',
+          'dist-tags': { latest: '1.0.0' },
+          versions: { '1.0.0': { name: '@types/fake', version: '1.0.0', types: 'index.d.ts' } },
+          readme: 'This package contains type definitions for fake.',
+        },
+      }
+    }
+
+    const prefix = 'https://unpkg.com/@types/fake@1.0.0/'
+    if (url.startsWith(prefix) && declarations[url.slice(prefix.length)]) {
+      reads.urls.push(url)
+      reads.inFlight++
+      reads.maxInFlight = Math.max(reads.maxInFlight, reads.inFlight)
+      await new Promise(resolve => setTimeout(resolve, 5))
+      reads.inFlight--
+      return { data: declarations[url.slice(prefix.length)] }
+    }
+    notFound(url)
+  })
+  return reads
+}
+
+test('@types packages are described from their declarations, not their README', async () => {
+  stubTypesPackage(3)
+
+  const server = new PackageDocsServer()
+  const text = await callTool(server, 'describe_npm_package', { package: '@types/fake', source: 'network' })
+
+  assert.match(text, /Type definitions for fake \(@types\/fake@1\.0\.0\)/)
+  assert.match(text, /Declared modules \(3\)/)
+  assert.match(text, /fake\/mod2/)
+  assert.match(text, /run2/)
+  assert.doesNotMatch(text, /This package contains type definitions/)
+})
+
+test('referenced declaration files are read in parallel, a few at a time, up to a cap', async () => {
+  const reads = stubTypesPackage(60)
+
+  const server = new PackageDocsServer()
+  const text = await callTool(server, 'describe_npm_package', { package: '@types/fake', source: 'network' })
+
+  assert.match(text, /Declared modules \(39\)/)
+  assert.equal(reads.urls.length, 40)
+  assert.ok(reads.maxInFlight > 1, `read ${reads.maxInFlight} at once`)
+  assert.ok(reads.maxInFlight <= 6, `read ${reads.maxInFlight} at once`)
+})